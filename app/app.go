@@ -4,11 +4,15 @@ import (
 	"claude-squad/config"
 	"claude-squad/keys"
 	"claude-squad/log"
+	"claude-squad/notify"
 	"claude-squad/session"
+	"claude-squad/session/events"
 	"claude-squad/session/git"
+	"claude-squad/session/policy"
 	"claude-squad/ui"
 	"claude-squad/ui/overlay"
 	"context"
+	"errors"
 	"fmt"
 	"os"
 	"time"
@@ -22,8 +26,11 @@ const GlobalInstanceLimit = 10
 
 // Run is the main entrypoint into the application.
 func Run(ctx context.Context, program string, autoYes bool, directMode bool, directBranch string) error {
+	h := newHome(ctx, program, autoYes, directMode, directBranch)
+	// Give the hooks a moment to deliver the events of the last actions.
+	defer h.eventBus.Close(5 * time.Second)
 	p := tea.NewProgram(
-		newHome(ctx, program, autoYes, directMode, directBranch),
+		h,
 		tea.WithAltScreen(),
 		tea.WithMouseCellMotion(), // Mouse scroll
 	)
@@ -43,6 +50,8 @@ const (
 	stateHelp
 	// stateConfirm is the state when a confirmation modal is displayed.
 	stateConfirm
+	// statePalette is the state when the command palette is displayed.
+	statePalette
 )
 
 type home struct {
@@ -90,10 +99,30 @@ type home struct {
 	spinner spinner.Model
 	// textInputOverlay handles text input with state
 	textInputOverlay *overlay.TextInputOverlay
+	// textInputSubmit handles the text entered in textInputOverlay. When nil, the text is sent to
+	// the selected instance as a prompt.
+	textInputSubmit func(string) tea.Cmd
 	// textOverlay displays text information
 	textOverlay *overlay.TextOverlay
 	// confirmationOverlay displays confirmation modals
 	confirmationOverlay *overlay.ConfirmationOverlay
+	// confirmResult delivers the result of the confirmed action, e.g. an error.
+	confirmResult tea.Cmd
+	// paletteOverlay displays the command palette and other pickers
+	paletteOverlay *overlay.PaletteOverlay
+	// paletteSelect is called with the item chosen in paletteOverlay
+	paletteSelect func(overlay.PaletteItem) (tea.Model, tea.Cmd)
+
+	// notifier tells the user when an instance needs attention
+	notifier *notify.Notifier
+	// notifyQuiet holds restored instances whose first ready status is not notified
+	notifyQuiet map[*session.Instance]bool
+	// notifyPrompted holds instances whose current prompt was already notified
+	notifyPrompted map[*session.Instance]bool
+	// statusCursor rotates which instances get their status polled first
+	statusCursor int
+	// eventBus delivers the lifecycle events of the instances to the configured hooks
+	eventBus *events.Bus
 
 	// diff watcher state
     diffWatchInst      *session.Instance
@@ -122,7 +151,7 @@ func newHome(ctx context.Context, program string, autoYes bool, directMode bool,
 		ctx:          ctx,
 		spinner:      spinner.New(spinner.WithSpinner(spinner.MiniDot)),
 		menu:         ui.NewMenu(),
-		tabbedWindow: ui.NewTabbedWindow(ui.NewPreviewPane(), ui.NewDiffPane()),
+		tabbedWindow: ui.NewTabbedWindow(ui.NewPreviewPane(), ui.NewDiffPane(), ui.NewCommitsPane(), ui.NewShellPane(), ui.NewChecksPane()),
 		errBox:       ui.NewErrBox(),
 		storage:      storage,
 		appConfig:    appConfig,
@@ -132,9 +161,40 @@ func newHome(ctx context.Context, program string, autoYes bool, directMode bool,
 		directBranch: directBranch,
 		state:        stateDefault,
 		appState:     appState,
+
+		notifier:       notify.New(appConfig.Notifications, os.Stdout),
+		notifyQuiet:    make(map[*session.Instance]bool),
+		notifyPrompted: make(map[*session.Instance]bool),
 	}
 	h.list = ui.NewList(&h.spinner, autoYes)
 
+	h.eventBus, err = events.NewBus(appConfig.Hooks)
+	if err != nil {
+		log.WarningLog.Printf("ignoring invalid hooks: %v", err)
+	}
+	session.SetEventBus(h.eventBus)
+
+	engine, err := policy.New(appConfig.AutoYesPolicy)
+	if err != nil {
+		fmt.Printf("Invalid auto_yes_policy: %v\n", err)
+		os.Exit(1)
+	}
+	session.SetPolicy(engine)
+	session.SetAutoYesBudget(appConfig.AutoYesBudget)
+	session.SetCommitMessageTemplate(appConfig.CommitMessageTemplate)
+	session.SetSandbox(appConfig.Sandbox)
+	session.SetResourceLimits(appConfig.Resources)
+	if err := appConfig.Timeouts.Validate(); err != nil {
+		fmt.Printf("Invalid timeouts: %v\n", err)
+		os.Exit(1)
+	}
+	session.SetTimeouts(appConfig.Timeouts)
+	if err := appConfig.Usage.Validate(); err != nil {
+		fmt.Printf("Invalid usage: %v\n", err)
+		os.Exit(1)
+	}
+	session.SetUsageConfig(appConfig.Usage)
+
 	// Load saved instances
 	instances, err := storage.LoadInstances()
 	if err != nil {
@@ -146,9 +206,7 @@ func newHome(ctx context.Context, program string, autoYes bool, directMode bool,
 	for _, instance := range instances {
 		// Call the finalizer immediately.
 		h.list.AddInstance(instance)()
-		if autoYes {
-			instance.AutoYes = true
-		}
+		h.notifyQuiet[instance] = true
 	}
 
 	return h
@@ -201,6 +259,9 @@ func (m *home) updateHandleWindowSizeEvent(msg tea.WindowSizeMsg) {
 	if m.textOverlay != nil {
 		m.textOverlay.SetWidth(int(float32(msg.Width) * 0.6))
 	}
+	if m.paletteOverlay != nil {
+		m.paletteOverlay.SetSize(int(float32(msg.Width)*0.6), int(float32(msg.Height)*0.6))
+	}
 
 	previewWidth, previewHeight := m.tabbedWindow.GetPreviewSize()
 	if err := m.list.SetSessionPreviewSize(previewWidth, previewHeight); err != nil {
@@ -224,6 +285,10 @@ func (m *home) Init() tea.Cmd {
 			return previewTickMsg{}
 		},
 		tickUpdateMetadataCmd,
+		m.pollPullRequests(),
+		m.scheduleCheckpoints(),
+		m.scheduleResourceSample(),
+		m.updateUsage(),
 	)
 }
 
@@ -253,14 +318,27 @@ func (m *home) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		scheduled := 0
 		const maxScheduled = 4 // simple rate limit per tick
 
-		for _, instance := range m.list.GetInstances() {
+		// With notifications on, every instance is watched. Start at a different instance each
+		// tick so that the rate limit doesn't starve the ones at the end of the list.
+		instances := m.list.GetInstances()
+		order := make([]*session.Instance, 0, len(instances))
+		if selected != nil {
+			order = append(order, selected)
+		}
+		m.statusCursor++
+		for k := range instances {
+			if instance := instances[(m.statusCursor+k)%len(instances)]; instance != selected {
+				order = append(order, instance)
+			}
+		}
+		for _, instance := range order {
 			if !instance.Started() || instance.Paused() {
 				continue
 			}
 
 			// Determine whether to process this instance:
 			warmup := now.Sub(instance.CreatedAt) < 5*time.Second
-			shouldProcess := instance == selected || instance.AutoYes || warmup
+			shouldProcess := instance == selected || instance.AutoYes || warmup || m.notifier.Enabled() || instance.HasTimeouts()
 
 			if shouldProcess && scheduled < maxScheduled {
 				cmds = append(cmds, makeTmuxStatusCmd(instance))
@@ -277,16 +355,31 @@ func (m *home) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			log.WarningLog.Printf("tmux status error: %v", msg.err)
 			return m, nil
 		}
+		if inst.Paused() {
+			// Paused while the status was captured.
+			return m, nil
+		}
+		var cmds []tea.Cmd
 		if msg.updated {
 			inst.SetStatus(session.Running)
 		} else {
 			if msg.prompt {
-				inst.TapEnter()
+				cmds = append(cmds, m.answerPrompt(inst))
 			} else {
+				if inst.Status == session.Running {
+					cmds = append(cmds, m.notifyReady(inst))
+					if m.appConfig.Checkpoints.OnReady {
+						cmds = append(cmds, m.checkpointCmd(inst, "ready"))
+					}
+					if m.appConfig.Checks.OnReady {
+						cmds = append(cmds, m.checkOnReady(inst))
+					}
+				}
 				inst.SetStatus(session.Ready)
 			}
 		}
-		return m, nil
+		cmds = append(cmds, m.notifyPrompt(inst, msg.prompt), m.checkTimeout(inst))
+		return m, tea.Batch(cmds...)
 	case gitDiffMsg:
 		inst := msg.instance
 		if msg.err != nil {
@@ -299,6 +392,28 @@ func (m *home) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			m.tabbedWindow.UpdateDiff(inst)
 		}
 		return m, nil
+	case commitMessageMsg:
+		return m, m.handleCommitMessageMsg(msg)
+	case commitsMsg:
+		return m, m.handleCommitsMsg(msg)
+	case commitDiffMsg:
+		return m, m.handleCommitDiffMsg(msg)
+	case pullRequestMsg:
+		return m, m.handlePullRequestMsg(msg)
+	case pollPullRequestsMsg:
+		return m, m.pollPullRequests()
+	case usageTickMsg:
+		return m, m.updateUsage()
+	case usageUpdatedMsg:
+		return m, m.handleUsageUpdated(msg)
+	case resourceTickMsg:
+		return m, m.sampleResources()
+	case resourcesSampledMsg:
+		return m, m.scheduleResourceSample()
+	case checkpointTickMsg:
+		return m, m.checkpointAll()
+	case checkResultMsg:
+		return m, m.handleCheckResultMsg(msg)
 	case diffWatchTickedMsg:
 		// Watcher stopped or tab hidden
 		if !m.diffWatchActive || m.diffWatchInst == nil || !m.tabbedWindow.IsInDiffTab() {
@@ -327,6 +442,7 @@ case tea.MouseMsg:
             case tea.MouseButtonWheelDown:
                 m.tabbedWindow.ScrollDown()
             }
+            return m, m.loadCommitDiff()
         } else if msg.Button == tea.MouseButtonLeft {
             // Click handling: tabs and list
             // Compute relative coordinates to components (account for top padding of 1 line)
@@ -337,8 +453,9 @@ case tea.MouseMsg:
                 relX := msg.X - lw
                 if idx, ok := m.tabbedWindow.HitTestTab(relX, relY); ok {
                     if idx != m.tabbedWindow.GetActiveTab() {
-                        _ = m.tabbedWindow.ToggleWithReset(m.list.GetSelectedInstance())
+                        _ = m.tabbedWindow.SelectTabWithReset(idx, m.list.GetSelectedInstance())
                         m.menu.SetInDiffTab(m.tabbedWindow.IsInDiffTab())
+                        m.menu.SetInCommitsTab(m.tabbedWindow.IsInCommitsTab())
                         return m, m.instanceChanged()
                     }
                 }
@@ -363,6 +480,8 @@ case tea.MouseMsg:
 	case instanceChangedMsg:
 		// Handle instance changed after confirmation action
 		return m, m.instanceChanged()
+	case killMsg:
+		return m, m.killInstances(msg.instances)
 	case spinner.TickMsg:
 		var cmd tea.Cmd
 		m.spinner, cmd = m.spinner.Update(msg)
@@ -385,7 +504,7 @@ func (m *home) handleMenuHighlighting(msg tea.KeyMsg) (cmd tea.Cmd, returnEarly
 		m.keySent = false
 		return nil, false
 	}
-	if m.state == statePrompt || m.state == stateHelp || m.state == stateConfirm {
+	if m.state == statePrompt || m.state == stateHelp || m.state == stateConfirm || m.state == statePalette {
 		return nil, false
 	}
 	// If it's in the global keymap, we should try to highlight it.
@@ -422,6 +541,10 @@ func (m *home) handleKeyPress(msg tea.KeyMsg) (mod tea.Model, cmd tea.Cmd) {
 		return m.handleHelpState(msg)
 	}
 
+	if m.state == statePalette {
+		return m.handlePaletteState(msg)
+	}
+
 	if m.state == stateNew {
 		// Handle quit commands first. Don't handle q because the user might want to type that.
 		if msg.String() == "ctrl+c" {
@@ -456,9 +579,6 @@ func (m *home) handleKeyPress(msg tea.KeyMsg) (mod tea.Model, cmd tea.Cmd) {
 			}
 			// Instance added successfully, call the finalizer.
 			m.newInstanceFinalizer()
-			if m.autoYes {
-				instance.AutoYes = true
-			}
 
 			m.newInstanceFinalizer()
 			m.state = stateDefault
@@ -512,6 +632,19 @@ func (m *home) handleKeyPress(msg tea.KeyMsg) (mod tea.Model, cmd tea.Cmd) {
 		shouldClose := m.textInputOverlay.HandleKeyPress(msg)
 
 		// Check if the form was submitted or canceled
+		if shouldClose && m.textInputSubmit != nil {
+			submit, value, submitted := m.textInputSubmit, m.textInputOverlay.GetValue(), m.textInputOverlay.IsSubmitted()
+			m.textInputOverlay = nil
+			m.textInputSubmit = nil
+			m.state = stateDefault
+			m.menu.SetState(ui.StateDefault)
+			// Reset the state first so that submit can open the next overlay.
+			var cmd tea.Cmd
+			if submitted {
+				cmd = submit(value)
+			}
+			return m, tea.Batch(tea.WindowSize(), cmd)
+		}
 		if shouldClose {
 			selected := m.list.GetSelectedInstance()
 			// TODO: this should never happen since we set the instance in the previous state.
@@ -519,10 +652,12 @@ func (m *home) handleKeyPress(msg tea.KeyMsg) (mod tea.Model, cmd tea.Cmd) {
 				return m, nil
 			}
 			if m.textInputOverlay.IsSubmitted() {
-				if err := selected.SendPrompt(m.textInputOverlay.GetValue()); err != nil {
+				prompt := m.textInputOverlay.GetValue()
+				if err := selected.SendPrompt(prompt); err != nil {
 					// TODO: we probably end up in a bad state here.
 					return m, m.handleError(err)
 				}
+				selected.Audit(session.AuditEntry{Source: session.AuditUser, Action: "prompt", Keys: prompt + "\r"})
 			}
 
 			// Close the overlay and reset state
@@ -547,7 +682,9 @@ func (m *home) handleKeyPress(msg tea.KeyMsg) (mod tea.Model, cmd tea.Cmd) {
         if shouldClose {
             m.state = stateDefault
             m.confirmationOverlay = nil
-            return m, nil
+            result := m.confirmResult
+            m.confirmResult = nil
+            return m, result
 		}
 		return m, nil
 	}
@@ -568,8 +705,8 @@ func (m *home) handleKeyPress(msg tea.KeyMsg) (mod tea.Model, cmd tea.Cmd) {
 		}
 	}
 
-	// Handle quit commands first
-	if msg.String() == "ctrl+c" || msg.String() == "q" {
+	// Handle quit commands first. Other quit keys go through the keymap.
+	if msg.String() == "ctrl+c" {
 		return m.handleQuit()
 	}
 
@@ -579,6 +716,33 @@ func (m *home) handleKeyPress(msg tea.KeyMsg) (mod tea.Model, cmd tea.Cmd) {
 	}
 
 	switch name {
+	case keys.KeyNum1, keys.KeyNum2, keys.KeyNum3, keys.KeyNum4, keys.KeyNum5,
+		keys.KeyNum6, keys.KeyNum7, keys.KeyNum8, keys.KeyNum9, keys.KeyNum0:
+		// Number key instance selection (1..9, 0 = 10)
+		idx := int(name - keys.KeyNum1)
+		if idx >= 0 && idx < m.list.NumInstances() {
+			m.list.SetSelectedInstance(idx)
+			return m, m.instanceChanged()
+		}
+		return m, nil
+	}
+	// Keys run the same actions the menu and the palette offer.
+	if a, ok := keys.LookupAction(name); ok && !a.Available(m.actionContext()) {
+		return m, nil
+	}
+	return m.runAction(name)
+}
+
+// runAction executes the action bound to name. It is shared by key presses and the command palette.
+// Callers check that the action is available.
+func (m *home) runAction(name keys.KeyName) (tea.Model, tea.Cmd) {
+	switch name {
+	case keys.KeyQuit:
+		return m.handleQuit()
+	case keys.KeyPalette:
+		return m.openPalette()
+	case keys.KeyTheme:
+		return m.openThemePicker()
 	case keys.KeyHelp:
 		return m.showHelpScreen(helpTypeGeneral{}, nil)
 	case keys.KeyPrompt:
@@ -590,6 +754,7 @@ func (m *home) handleKeyPress(msg tea.KeyMsg) (mod tea.Model, cmd tea.Cmd) {
 			Title:        "",
 			Path:         ".",
 			Program:      m.program,
+			AutoYes:      m.autoYes,
 			DirectMode:   m.directMode,
 			DirectBranch: m.directBranch,
 		})
@@ -613,6 +778,7 @@ func (m *home) handleKeyPress(msg tea.KeyMsg) (mod tea.Model, cmd tea.Cmd) {
 			Title:        "",
 			Path:         ".",
 			Program:      m.program,
+			AutoYes:      m.autoYes,
 			DirectMode:   m.directMode,
 			DirectBranch: m.directBranch,
 		})
@@ -668,9 +834,43 @@ func (m *home) handleKeyPress(msg tea.KeyMsg) (mod tea.Model, cmd tea.Cmd) {
 	case keys.KeyFilePrev:
 		m.tabbedWindow.JumpPrevFile()
 		return m, nil
+	case keys.KeyDiffLayout:
+		m.tabbedWindow.ToggleDiffLayout()
+		return m, nil
+	case keys.KeyDiffCollapse:
+		m.tabbedWindow.ToggleDiffCollapsed()
+		return m, m.saveDiffViewState()
+	case keys.KeyDiffViewed:
+		m.tabbedWindow.ToggleDiffViewed()
+		return m, m.saveDiffViewState()
+	case keys.KeyDiffMode:
+		return m.openDiffModePicker()
+	case keys.KeyRevertHunk:
+		return m.revertHunk()
+	case keys.KeyRevertFile:
+		return m.revertFile()
+	case keys.KeyApproveHunk:
+		if m.tabbedWindow.DiffRevertBlocked() {
+			return m, m.handleError(errNotRevertible)
+		}
+		if !m.tabbedWindow.ToggleDiffApproved() {
+			return m, nil
+		}
+		return m, m.saveDiffViewState()
+	case keys.KeyComment:
+		return m.openCommentInput()
+	case keys.KeySendReview:
+		return m.sendReview()
+	case keys.KeyCommitSquash:
+		return m.squashCommit()
+	case keys.KeyCommitReword:
+		return m.openRewordInput()
+	case keys.KeyCommitDrop:
+		return m.dropCommit()
     case keys.KeyTab:
         _ = m.tabbedWindow.ToggleWithReset(m.list.GetSelectedInstance())
         m.menu.SetInDiffTab(m.tabbedWindow.IsInDiffTab())
+        m.menu.SetInCommitsTab(m.tabbedWindow.IsInCommitsTab())
         return m, m.instanceChanged()
 	case keys.KeyKill:
 		selected := m.list.GetSelectedInstance()
@@ -678,76 +878,67 @@ func (m *home) handleKeyPress(msg tea.KeyMsg) (mod tea.Model, cmd tea.Cmd) {
 			return m, nil
 		}
 
-		// Create the kill action as a tea.Cmd
-		killAction := func() tea.Msg {
-			// Only check if branch is checked out for non-direct mode
-			// In direct mode, we're working on the actual branch so this check doesn't apply
-			if !selected.DirectMode {
-				// Get worktree and check if branch is checked out
-				worktree, err := selected.GetGitWorktree()
-				if err != nil {
-					return err
-				}
-
-				checkedOut, err := worktree.IsBranchCheckedOut()
-				if err != nil {
-					return err
-				}
-
-				if checkedOut {
-					return fmt.Errorf("instance %s is currently checked out", selected.Title)
-				}
-			}
-
-			// Delete from storage first
-			if err := m.storage.DeleteInstance(selected.Title); err != nil {
-				return err
-			}
-
-			// Then kill the instance
-			m.list.Kill()
-			return instanceChangedMsg{}
-		}
-
 		// Show confirmation modal
 		message := fmt.Sprintf("[!] Kill session '%s'?", selected.Title)
-		return m, m.confirmAction(message, killAction)
+		return m, m.confirmAction(message, m.killAction(selected))
 	case keys.KeySubmit:
 		selected := m.list.GetSelectedInstance()
 		if selected == nil {
 			return m, nil
 		}
 
-		// Create the push action as a tea.Cmd
-		pushAction := func() tea.Msg {
-			// Default commit message with timestamp
-			commitMsg := fmt.Sprintf("[claudesquad] update from '%s' on %s", selected.Title, time.Now().Format(time.RFC822))
-			worktree, err := selected.GetGitWorktree()
-			if err != nil {
-				return err
-			}
-			if err = worktree.PushChanges(commitMsg, true); err != nil {
-				return err
-			}
-			return nil
+		dirty, err := selected.HasUncommittedChanges()
+		if err != nil {
+			return m, m.handleError(err)
+		}
+		if dirty {
+			// Submitting the commit message confirms the push.
+			return m.openCommitDialog(fmt.Sprintf("Commit and push '%s'", selected.Title), selected, func(commitMsg string) tea.Cmd {
+				return m.pushAction(selected, commitMsg)
+			})
 		}
 
 		// Show confirmation modal
 		message := fmt.Sprintf("[!] Push changes from session '%s'?", selected.Title)
-		return m, m.confirmAction(message, pushAction)
+		return m, m.confirmAction(message, m.pushAction(selected, ""))
+	case keys.KeyPullRequest:
+		return m.openPullRequest()
+	case keys.KeyCleanupMerged:
+		return m.cleanupMerged()
+	case keys.KeyCheckpoints:
+		return m.openCheckpoints()
+	case keys.KeyBrowse:
+		selected := m.list.GetSelectedInstance()
+		if selected == nil {
+			return m, nil
+		}
+		return m, m.browseAction(selected)
 	case keys.KeyCheckout:
 		selected := m.list.GetSelectedInstance()
 		if selected == nil {
 			return m, nil
 		}
 
-		// Show help screen before pausing
-		m.showHelpScreen(helpTypeInstanceCheckout{}, func() {
-			if err := selected.Pause(); err != nil {
-				m.handleError(err)
-			}
-			m.instanceChanged()
-		})
+		checkout := func(commitMsg string) {
+			// Show help screen before pausing
+			m.showHelpScreen(helpTypeInstanceCheckout{}, func() {
+				if err := selected.PauseWithMessage(commitMsg); err != nil {
+					m.handleError(err)
+				}
+				m.instanceChanged()
+			})
+		}
+		dirty, err := selected.HasUncommittedChanges()
+		if err != nil {
+			return m, m.handleError(err)
+		}
+		if dirty {
+			return m.openCommitDialog(fmt.Sprintf("Commit changes of '%s'", selected.Title), selected, func(commitMsg string) tea.Cmd {
+				checkout(commitMsg)
+				return nil
+			})
+		}
+		checkout("")
 		return m, nil
 	case keys.KeyResume:
 		selected := m.list.GetSelectedInstance()
@@ -766,40 +957,130 @@ func (m *home) handleKeyPress(msg tea.KeyMsg) (mod tea.Model, cmd tea.Cmd) {
 		if selected == nil || selected.Paused() || !selected.TmuxAlive() {
 			return m, nil
 		}
-		// Show help screen before attaching
-		m.showHelpScreen(helpTypeInstanceAttach{}, func() {
-			ch, err := m.list.Attach()
-			if err != nil {
-				m.handleError(err)
-				return
-			}
-			<-ch
-			m.state = stateDefault
-		})
+		if m.tabbedWindow.IsInShellTab() && selected.HasShell() {
+			return m.attach(selected.AttachShell)
+		}
+		return m.attach(m.list.Attach)
+	case keys.KeyShell:
+		return m.openShell()
+	case keys.KeyChecks:
+		return m.toggleCheck()
+	case keys.KeyMute:
+		return m.toggleMute()
+	case keys.KeyAutoYes:
+		return m.toggleAutoYes()
+	case keys.KeyAudit:
+		return m.openAudit()
+	case keys.KeyResources:
+		return m.openResources()
+	default:
 		return m, nil
-    // Global fall-through handling (default state)
-    default:
-        // Esc exits preview scroll mode if active
-        if msg.String() == "esc" && m.tabbedWindow.IsPreviewInScrollMode() {
-            _ = m.tabbedWindow.ResetPreviewToNormalMode(m.list.GetSelectedInstance())
-            return m, nil
-        }
-        // Number key instance selection (1..9, 0 = 10)
-        switch msg.String() {
-        case "1", "2", "3", "4", "5", "6", "7", "8", "9", "0":
-            idx := 0
-            if msg.String() == "0" {
-                idx = 9
-            } else {
-                idx = int(msg.Runes[0]-'1')
-            }
-            if idx >= 0 && idx < m.list.NumInstances() {
-                m.list.SetSelectedInstance(idx)
-                return m, m.instanceChanged()
-            }
-        }
-        return m, nil
-    }
+	}
+}
+
+// attach shows the attach help screen and then attaches with attachFn until detached.
+func (m *home) attach(attachFn func() (chan struct{}, error)) (tea.Model, tea.Cmd) {
+	m.showHelpScreen(helpTypeInstanceAttach{}, func() {
+		ch, err := attachFn()
+		if err != nil {
+			m.handleError(err)
+			return
+		}
+		<-ch
+		m.state = stateDefault
+	})
+	return m, nil
+}
+
+// openShell opens a shell in the selected instance's worktree, or reuses its shell, shows it in
+// the shell tab and attaches to it.
+func (m *home) openShell() (tea.Model, tea.Cmd) {
+	selected := m.list.GetSelectedInstance()
+	if selected == nil || selected.Paused() {
+		return m, nil
+	}
+	if err := selected.OpenShell(); err != nil {
+		return m, m.handleError(err)
+	}
+	if err := m.tabbedWindow.SelectTabWithReset(ui.ShellTab, selected); err != nil {
+		return m, m.handleError(err)
+	}
+	m.menu.SetInDiffTab(false)
+	m.menu.SetInCommitsTab(false)
+	if cmd := m.instanceChanged(); cmd != nil {
+		return m, cmd
+	}
+	return m.attach(selected.AttachShell)
+}
+
+// killMsg asks Update to kill the instances. Killing changes the list, which only Update may
+// touch, so confirmed actions return this instead of killing.
+type killMsg struct {
+	instances []*session.Instance
+}
+
+// killAction returns a command that has Update delete the instances from storage and kill them.
+func (m *home) killAction(instances ...*session.Instance) tea.Cmd {
+	return func() tea.Msg {
+		return killMsg{instances: instances}
+	}
+}
+
+// killInstances kills the instances that are still in the list.
+func (m *home) killInstances(instances []*session.Instance) tea.Cmd {
+	var errs []error
+	for _, instance := range instances {
+		if !m.hasInstance(instance) {
+			continue
+		}
+		if err := m.killInstance(instance); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	if err := errors.Join(errs...); err != nil {
+		return tea.Batch(m.handleError(err), m.instanceChanged())
+	}
+	return m.instanceChanged()
+}
+
+// killInstance deletes the instance from storage and kills it.
+func (m *home) killInstance(instance *session.Instance) error {
+	// Only check if branch is checked out for non-direct mode
+	// In direct mode, we're working on the actual branch so this check doesn't apply
+	if !instance.DirectMode {
+		// Get worktree and check if branch is checked out
+		worktree, err := instance.GetGitWorktree()
+		if err != nil {
+			return err
+		}
+
+		checkedOut, err := worktree.IsBranchCheckedOut()
+		if err != nil {
+			return err
+		}
+
+		if checkedOut {
+			return fmt.Errorf("instance %s is currently checked out", instance.Title)
+		}
+	}
+
+	// Delete from storage first
+	if err := m.storage.DeleteInstance(instance.Title); err != nil {
+		return err
+	}
+
+	// Then kill the instance
+	m.list.KillInstance(instance)
+	m.forgetNotifications(instance)
+	return nil
+}
+
+// saveDiffViewState persists the viewed and collapsed files of the instances.
+func (m *home) saveDiffViewState() tea.Cmd {
+	if err := m.storage.SaveInstances(m.list.GetInstances()); err != nil {
+		return m.handleError(err)
+	}
+	return nil
 }
 
 // instanceChanged updates the preview pane, menu, and diff pane based on the selected instance. It returns an error
@@ -809,6 +1090,7 @@ func (m *home) instanceChanged() tea.Cmd {
 	selected := m.list.GetSelectedInstance()
 
 	m.tabbedWindow.UpdateDiff(selected)
+	m.tabbedWindow.UpdateChecks(selected)
 	m.tabbedWindow.SetInstance(selected)
 	// Update menu with current instance
 	m.menu.SetInstance(selected)
@@ -832,6 +1114,13 @@ func (m *home) instanceChanged() tea.Cmd {
 	// Stop watcher when diff tab hidden or no selection
 	m.diffWatchActive = false
 	m.diffWatchInst = nil
+	if m.tabbedWindow.IsInCommitsTab() {
+		if selected == nil {
+			m.tabbedWindow.SetCommits(nil, nil, nil)
+			return nil
+		}
+		return makeCommitsCmd(selected)
+	}
 	return nil
 }
 
@@ -986,10 +1275,8 @@ func (m *home) confirmAction(message string, action tea.Cmd) tea.Cmd {
 	// Set callbacks for confirmation and cancellation
 	m.confirmationOverlay.OnConfirm = func() {
 		m.state = stateDefault
-		// Execute the action if it exists
-		if action != nil {
-			_ = action()
-		}
+		// Run the action as a command once the overlay is closed, off the update loop.
+		m.confirmResult = action
 	}
 
 	m.confirmationOverlay.OnCancel = func() {
@@ -1031,6 +1318,11 @@ func (m *home) View() string {
 			log.ErrorLog.Printf("confirmation overlay is nil")
 		}
 		return overlay.PlaceOverlay(0, 0, m.confirmationOverlay.Render(), mainView, true, true)
+	} else if m.state == statePalette {
+		if m.paletteOverlay == nil {
+			log.ErrorLog.Printf("palette overlay is nil")
+		}
+		return overlay.PlaceOverlay(0, 0, m.paletteOverlay.Render(), mainView, true, true)
 	}
 
 	return mainView
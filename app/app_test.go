@@ -188,6 +188,28 @@ func TestConfirmationModalKeyHandling(t *testing.T) {
 	}
 }
 
+// TestConfirmActionRunsAsCommand tests that a confirmed action is returned as a command instead of
+// running inside Update.
+func TestConfirmActionRunsAsCommand(t *testing.T) {
+	h := &home{
+		ctx:       context.Background(),
+		state:     stateDefault,
+		appConfig: config.DefaultConfig(),
+	}
+	actionCalled := false
+	h.confirmAction("Push?", func() tea.Msg {
+		actionCalled = true
+		return instanceChangedMsg{}
+	})
+
+	_, cmd := h.handleKeyPress(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("y")})
+	assert.False(t, actionCalled)
+	require.NotNil(t, cmd)
+	assert.Equal(t, instanceChangedMsg{}, cmd())
+	assert.True(t, actionCalled)
+	assert.Equal(t, stateDefault, h.state)
+}
+
 // TestConfirmationMessageFormatting tests that confirmation messages are formatted correctly
 func TestConfirmationMessageFormatting(t *testing.T) {
 	testCases := []struct {
@@ -486,7 +508,7 @@ func TestTabToggleUpdatesMenu(t *testing.T) {
 		appConfig:    config.DefaultConfig(),
 		list:         list,
 		menu:         ui.NewMenu(),
-		tabbedWindow: ui.NewTabbedWindow(ui.NewPreviewPane(), ui.NewDiffPane()),
+		tabbedWindow: ui.NewTabbedWindow(ui.NewPreviewPane(), ui.NewDiffPane(), ui.NewCommitsPane(), ui.NewShellPane(), ui.NewChecksPane()),
 	}
 
 	// Initial render should not include diff scroll hint
@@ -505,8 +527,76 @@ func TestTabToggleUpdatesMenu(t *testing.T) {
 	// Verify diff watcher becomes active when in Diff tab
 	require.True(t, h.diffWatchActive)
 
-	// Toggle back to Preview tab
+	// Move on to the Commits tab, which offers the history actions
 	_, _ = h.handleKeyPress(tea.KeyMsg{Type: tea.KeyTab})
 	_, _ = h.handleKeyPress(tea.KeyMsg{Type: tea.KeyTab})
 	require.False(t, h.diffWatchActive)
+	require.Contains(t, h.menu.String(), "squash")
+	require.NotContains(t, h.menu.String(), "shift+↑")
+}
+
+// TestPaletteRunsAction verifies that an action can be searched for and run from the command palette
+func TestPaletteRunsAction(t *testing.T) {
+	spinner := spinner.New(spinner.WithSpinner(spinner.MiniDot))
+	list := ui.NewList(&spinner, false)
+
+	instance, err := session.NewInstance(session.InstanceOptions{
+		Title:   "x",
+		Path:    t.TempDir(),
+		Program: "claude",
+	})
+	require.NoError(t, err)
+	_ = list.AddInstance(instance)
+	list.SetSelectedInstance(0)
+
+	h := &home{
+		ctx:          context.Background(),
+		state:        stateDefault,
+		appConfig:    config.DefaultConfig(),
+		list:         list,
+		menu:         ui.NewMenu(),
+		tabbedWindow: ui.NewTabbedWindow(ui.NewPreviewPane(), ui.NewDiffPane(), ui.NewCommitsPane(), ui.NewShellPane(), ui.NewChecksPane()),
+	}
+
+	// First call highlights the menu, second handles the key
+	_, _ = h.handleKeyPress(tea.KeyMsg{Type: tea.KeyCtrlP})
+	_, _ = h.handleKeyPress(tea.KeyMsg{Type: tea.KeyCtrlP})
+	require.Equal(t, statePalette, h.state)
+	require.NotNil(t, h.paletteOverlay)
+
+	// Diff navigation is listed but unavailable outside the diff tab
+	for _, item := range h.paletteOverlay.Items() {
+		if item.ID == "next-hunk" {
+			assert.True(t, item.Disabled)
+		}
+	}
+
+	for _, r := range "switch tab" {
+		_, _ = h.handleKeyPress(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{r}})
+	}
+	require.NotEmpty(t, h.paletteOverlay.Items())
+	assert.Equal(t, "switch-tab", h.paletteOverlay.Items()[0].ID)
+
+	_, _ = h.handleKeyPress(tea.KeyMsg{Type: tea.KeyEnter})
+	assert.Equal(t, stateDefault, h.state)
+	assert.Nil(t, h.paletteOverlay)
+	assert.True(t, h.tabbedWindow.IsInDiffTab())
+}
+
+func TestKeysSkipUnavailableActions(t *testing.T) {
+	spinner := spinner.New(spinner.WithSpinner(spinner.MiniDot))
+	h := &home{
+		ctx:          context.Background(),
+		state:        stateDefault,
+		appConfig:    config.DefaultConfig(),
+		list:         ui.NewList(&spinner, false),
+		menu:         ui.NewMenu(),
+		tabbedWindow: ui.NewTabbedWindow(ui.NewPreviewPane(), ui.NewDiffPane(), ui.NewCommitsPane(), ui.NewShellPane(), ui.NewChecksPane()),
+	}
+
+	// Switching tabs needs a session, as in the menu and the palette.
+	// First call highlights the menu, second handles the key
+	_, _ = h.handleKeyPress(tea.KeyMsg{Type: tea.KeyTab})
+	_, _ = h.handleKeyPress(tea.KeyMsg{Type: tea.KeyTab})
+	assert.False(t, h.tabbedWindow.IsInDiffTab())
 }
@@ -0,0 +1,95 @@
+package app
+
+import (
+	"claude-squad/session"
+	"claude-squad/ui/overlay"
+	"fmt"
+	"strconv"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// openAudit lists the audit log of the selected instance, newest first. Choosing an entry shows
+// its details, including the pane at the time of the confirmation.
+func (m *home) openAudit() (tea.Model, tea.Cmd) {
+	selected := m.list.GetSelectedInstance()
+	if selected == nil {
+		return m, nil
+	}
+	entries, err := session.ReadAuditLog(selected.Title)
+	if err != nil {
+		return m, m.handleError(err)
+	}
+	if len(entries) == 0 {
+		return m, m.handleError(fmt.Errorf("nothing was sent to '%s' yet", selected.Title))
+	}
+
+	items := make([]overlay.PaletteItem, 0, len(entries))
+	for i := len(entries) - 1; i >= 0; i-- {
+		items = append(items, overlay.PaletteItem{
+			ID:    strconv.Itoa(i),
+			Title: fmt.Sprintf("%s  %s", entries[i].Time.Format("Jan 2 15:04:05"), auditSummary(entries[i])),
+			Group: entries[i].Source,
+		})
+	}
+	return m.showPalette("Audit log", items, func(item overlay.PaletteItem) (tea.Model, tea.Cmd) {
+		i, err := strconv.Atoi(item.ID)
+		if err != nil || i >= len(entries) {
+			return m, nil
+		}
+		m.textOverlay = overlay.NewTextOverlay(auditDetails(entries[i]))
+		m.state = stateHelp
+		return m, nil
+	})
+}
+
+// auditSummary describes the entry in one line, e.g. "deny Bash: rm -rf build".
+func auditSummary(entry session.AuditEntry) string {
+	var parts []string
+	if entry.Decision != "" {
+		parts = append(parts, entry.Decision)
+	}
+	if entry.Action != "" {
+		parts = append(parts, entry.Action)
+	}
+	if len(parts) == 0 {
+		parts = append(parts, "confirmation")
+	}
+	summary := strings.Join(parts, " ")
+	if i := strings.IndexByte(summary, '\n'); i >= 0 {
+		summary = summary[:i] + "..."
+	}
+	return summary
+}
+
+// auditDetails renders all fields of the entry.
+func auditDetails(entry session.AuditEntry) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "Time:     %s\n", entry.Time.Format("2006-01-02 15:04:05"))
+	fmt.Fprintf(&b, "Session:  %s\n", entry.Session)
+	fmt.Fprintf(&b, "Source:   %s\n", entry.Source)
+	if entry.Decision != "" {
+		fmt.Fprintf(&b, "Decision: %s\n", entry.Decision)
+	}
+	if entry.Rule != "" {
+		fmt.Fprintf(&b, "Rule:     %s\n", entry.Rule)
+	}
+	if entry.Action != "" {
+		fmt.Fprintf(&b, "Action:   %s\n", entry.Action)
+	}
+	fmt.Fprintf(&b, "Keys:     %s\n", truncateLines(session.DescribeKeys(entry.Keys), 10))
+	if entry.Prompt != "" {
+		fmt.Fprintf(&b, "\n%s\n", entry.Prompt)
+	}
+	return b.String()
+}
+
+// truncateLines keeps the first n lines of s.
+func truncateLines(s string, n int) string {
+	lines := strings.SplitN(s, "\n", n+1)
+	if len(lines) > n {
+		return strings.Join(lines[:n], "\n") + "\n..."
+	}
+	return s
+}
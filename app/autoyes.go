@@ -0,0 +1,48 @@
+package app
+
+import (
+	"claude-squad/notify"
+	"claude-squad/session"
+	"claude-squad/session/policy"
+	"fmt"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// answerPrompt lets AutoYes answer the confirmation of the instance. Confirmations that the AutoYes
+// policy leaves to the user are shown in the error box, and so is an instance that used up its
+// AutoYes budget.
+func (m *home) answerPrompt(instance *session.Instance) tea.Cmd {
+	answer, ok := instance.AnswerPrompt()
+	if !ok || answer.Decision != policy.Ask {
+		return nil
+	}
+	if !answer.BudgetUsedUp {
+		return m.handleError(fmt.Errorf("'%s' needs your approval for %s", instance.Title, answer.Action))
+	}
+
+	// AutoYes was turned off. The budget notification replaces the one for the prompt.
+	cmds := []tea.Cmd{m.handleError(fmt.Errorf("'%s' used up its AutoYes budget and needs your approval", instance.Title))}
+	if err := m.storage.SaveInstances(m.list.GetInstances()); err != nil {
+		cmds = append(cmds, m.handleError(err))
+	}
+	if m.notifier.Enabled() {
+		m.notifyPrompted[instance] = true
+		cmds = append(cmds, m.notifyCmd(instance, notify.KindBudget))
+	}
+	return tea.Batch(cmds...)
+}
+
+// toggleAutoYes turns AutoYes on or off for the selected instance. Turning it on starts a new
+// budget.
+func (m *home) toggleAutoYes() (tea.Model, tea.Cmd) {
+	selected := m.list.GetSelectedInstance()
+	if selected == nil {
+		return m, nil
+	}
+	selected.SetAutoYes(!selected.AutoYes)
+	if err := m.storage.SaveInstances(m.list.GetInstances()); err != nil {
+		return m, m.handleError(err)
+	}
+	return m, nil
+}
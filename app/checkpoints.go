@@ -0,0 +1,123 @@
+package app
+
+import (
+	"claude-squad/log"
+	"claude-squad/session"
+	"claude-squad/session/git"
+	"claude-squad/ui"
+	"claude-squad/ui/overlay"
+	"fmt"
+	"strconv"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// checkpointTickMsg triggers the periodic checkpoints.
+type checkpointTickMsg struct{}
+
+// scheduleCheckpoints returns a command that triggers the next periodic checkpoint, or nil if the
+// timer is off.
+func (m *home) scheduleCheckpoints() tea.Cmd {
+	interval := m.appConfig.Checkpoints.Interval
+	if interval <= 0 {
+		return nil
+	}
+	return tea.Tick(time.Duration(interval)*time.Second, func(time.Time) tea.Msg {
+		return checkpointTickMsg{}
+	})
+}
+
+// checkpointAll checkpoints every active instance and schedules the next round.
+func (m *home) checkpointAll() tea.Cmd {
+	cmds := []tea.Cmd{m.scheduleCheckpoints()}
+	for _, instance := range m.list.GetInstances() {
+		if instance.Started() && !instance.Paused() {
+			cmds = append(cmds, m.checkpointCmd(instance, "timer"))
+		}
+	}
+	return tea.Batch(cmds...)
+}
+
+// checkpointCmd returns a command that checkpoints the instance in the background. Failures are
+// only logged, since nobody asked for this particular checkpoint.
+func (m *home) checkpointCmd(instance *session.Instance, reason string) tea.Cmd {
+	keep := m.appConfig.Checkpoints.KeepCount()
+	return func() tea.Msg {
+		created, err := instance.Checkpoint(reason, keep)
+		if err != nil {
+			log.WarningLog.Printf("failed to checkpoint '%s': %v", instance.Title, err)
+		} else if created {
+			log.InfoLog.Printf("checkpointed '%s' (%s)", instance.Title, reason)
+		}
+		return nil
+	}
+}
+
+// openCheckpoints shows the checkpoint timeline of the selected instance. Choosing a checkpoint
+// offers to diff the worktree against it or to restore it.
+func (m *home) openCheckpoints() (tea.Model, tea.Cmd) {
+	selected := m.list.GetSelectedInstance()
+	if selected == nil {
+		return m, nil
+	}
+	checkpoints, err := selected.Checkpoints()
+	if err != nil {
+		return m, m.handleError(err)
+	}
+
+	items := []overlay.PaletteItem{{ID: "new", Title: "Checkpoint now"}}
+	for i, cp := range checkpoints {
+		items = append(items, overlay.PaletteItem{
+			ID:    strconv.Itoa(i),
+			Title: fmt.Sprintf("%s  %s", cp.Time.Format("Jan 2 15:04:05"), cp.Reason),
+			Group: "Checkpoint",
+			Hint:  cp.ShortSHA,
+		})
+	}
+	return m.showPalette("Checkpoints", items, func(item overlay.PaletteItem) (tea.Model, tea.Cmd) {
+		if item.ID == "new" {
+			return m, m.checkpointNow(selected)
+		}
+		i, _ := strconv.Atoi(item.ID)
+		return m.openCheckpointActions(selected, checkpoints[i])
+	})
+}
+
+// checkpointNow returns a command that checkpoints the instance and reports failures.
+func (m *home) checkpointNow(instance *session.Instance) tea.Cmd {
+	keep := m.appConfig.Checkpoints.KeepCount()
+	return func() tea.Msg {
+		if _, err := instance.Checkpoint("manual", keep); err != nil {
+			return err
+		}
+		return nil
+	}
+}
+
+// openCheckpointActions asks what to do with the chosen checkpoint.
+func (m *home) openCheckpointActions(instance *session.Instance, cp git.Checkpoint) (tea.Model, tea.Cmd) {
+	items := []overlay.PaletteItem{
+		{ID: "diff", Title: "Diff the worktree against this checkpoint"},
+		{ID: "restore", Title: "Restore this checkpoint"},
+	}
+	title := fmt.Sprintf("Checkpoint %s (%s)", cp.Time.Format("15:04:05"), cp.ShortSHA)
+	return m.showPalette(title, items, func(item overlay.PaletteItem) (tea.Model, tea.Cmd) {
+		if item.ID == "diff" {
+			instance.SetDiffSpec(git.DiffSpec{Mode: git.DiffCheckpoint, Ref: cp.SHA})
+			if !m.tabbedWindow.IsInDiffTab() {
+				_ = m.tabbedWindow.SelectTabWithReset(ui.DiffTab, instance)
+				m.menu.SetInDiffTab(true)
+				m.menu.SetInCommitsTab(false)
+			}
+			return m, m.instanceChanged()
+		}
+		message := fmt.Sprintf("[!] Restore '%s' to the checkpoint from %s? The current state is checkpointed first.", instance.Title, cp.Time.Format("15:04:05"))
+		return m, m.confirmAction(message, func() tea.Msg {
+			if err := instance.RestoreCheckpoint(cp); err != nil {
+				return err
+			}
+			return instanceChangedMsg{}
+		})
+	})
+}
@@ -0,0 +1,140 @@
+package app
+
+import (
+	"claude-squad/log"
+	"claude-squad/session"
+	"claude-squad/ui"
+	"context"
+	"fmt"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// checkResultMsg reports a finished check.
+type checkResultMsg struct {
+	instance *session.Instance
+	run      session.CheckRun
+	// onReady is true if the check ran because the instance went ready.
+	onReady bool
+}
+
+// toggleCheck runs the check of the selected instance and shows it in the checks tab, or cancels
+// the check if it is running.
+func (m *home) toggleCheck() (tea.Model, tea.Cmd) {
+	selected := m.list.GetSelectedInstance()
+	if selected == nil || selected.Paused() {
+		return m, nil
+	}
+	if selected.CancelCheck() {
+		return m, nil
+	}
+	cmd, err := m.startCheck(selected, false)
+	if err != nil {
+		return m, m.handleError(err)
+	}
+	if !m.tabbedWindow.IsInChecksTab() {
+		if err := m.tabbedWindow.SelectTabWithReset(ui.ChecksTab, selected); err != nil {
+			return m, m.handleError(err)
+		}
+		m.menu.SetInDiffTab(false)
+		m.menu.SetInCommitsTab(false)
+	}
+	return m, tea.Batch(cmd, m.instanceChanged())
+}
+
+// startCheck starts the check command configured for the instance's repository. It returns a
+// command that waits for the check in the background.
+func (m *home) startCheck(instance *session.Instance, onReady bool) (tea.Cmd, error) {
+	command := m.appConfig.Checks.CommandFor(instance.Path)
+	if command == "" {
+		return nil, fmt.Errorf("no check command is configured for this repository; set checks.command in the config")
+	}
+	var ctx context.Context
+	var cancel context.CancelFunc
+	if timeout := m.appConfig.Checks.Timeout; timeout > 0 {
+		ctx, cancel = context.WithTimeout(m.ctx, time.Duration(timeout)*time.Second)
+	} else {
+		ctx, cancel = context.WithCancel(m.ctx)
+	}
+	wait, err := instance.StartCheck(ctx, command)
+	if err != nil {
+		cancel()
+		return nil, err
+	}
+	return func() tea.Msg {
+		defer cancel()
+		return checkResultMsg{instance: instance, run: wait(), onReady: onReady}
+	}, nil
+}
+
+// checkOnReady starts the check of an instance that just went ready, unless there is no check
+// command or a check is still running. Failures are only logged.
+func (m *home) checkOnReady(instance *session.Instance) tea.Cmd {
+	if m.appConfig.Checks.CommandFor(instance.Path) == "" {
+		return nil
+	}
+	if run, ok := instance.LastCheck(); ok && run.Running {
+		return nil
+	}
+	cmd, err := m.startCheck(instance, true)
+	if err != nil {
+		log.WarningLog.Printf("failed to run the check of '%s': %v", instance.Title, err)
+		return nil
+	}
+	return cmd
+}
+
+// handleCheckResultMsg shows the finished check if it belongs to the selected instance and
+// continues the fix loop.
+func (m *home) handleCheckResultMsg(msg checkResultMsg) tea.Cmd {
+	switch {
+	case msg.run.Canceled:
+		log.InfoLog.Printf("check of '%s' was canceled after %s", msg.instance.Title, msg.run.Duration)
+	case msg.run.Passed():
+		log.InfoLog.Printf("check of '%s' passed in %s", msg.instance.Title, msg.run.Duration)
+	default:
+		log.InfoLog.Printf("check of '%s' failed with exit code %d in %s", msg.instance.Title, msg.run.ExitCode, msg.run.Duration)
+	}
+	if msg.run.Passed() && msg.instance.ResetFixLoop() {
+		m.saveFixLoop()
+	}
+	if msg.onReady {
+		m.continueFixLoop(msg.instance, msg.run)
+	}
+	if msg.instance == m.list.GetSelectedInstance() {
+		m.tabbedWindow.UpdateChecks(msg.instance)
+	}
+	return nil
+}
+
+// continueFixLoop sends the output of a check that failed after the agent went idle back to the
+// agent, while the fix loop has attempts left. Failures are only logged.
+func (m *home) continueFixLoop(instance *session.Instance, run session.CheckRun) {
+	checks := m.appConfig.Checks
+	switch {
+	case checks.FixAttempts <= 0 || run.Running || run.Canceled || run.Passed():
+		return
+	case !m.hasInstance(instance) || !instance.Started() || instance.Paused():
+		return
+	case instance.Status == session.Running:
+		// The agent is busy again, e.g. because it was prompted while the check ran.
+		return
+	case instance.FixLoop.Attempts >= checks.FixAttempts:
+		log.InfoLog.Printf("fix loop of '%s' stopped after %d attempts", instance.Title, instance.FixLoop.Attempts)
+		return
+	}
+	if err := instance.SendFixPrompt(run, checks.OutputLimit()); err != nil {
+		log.WarningLog.Printf("failed to send the failed check of '%s' to the agent: %v", instance.Title, err)
+		return
+	}
+	log.InfoLog.Printf("fix loop sent the failed check of '%s' to the agent (attempt %d of %d)", instance.Title, instance.FixLoop.Attempts, checks.FixAttempts)
+	m.saveFixLoop()
+}
+
+// saveFixLoop persists the fix loop history of the instances.
+func (m *home) saveFixLoop() {
+	if err := m.storage.SaveInstances(m.list.GetInstances()); err != nil {
+		log.WarningLog.Printf("failed to save the fix loop history: %v", err)
+	}
+}
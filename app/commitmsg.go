@@ -0,0 +1,96 @@
+package app
+
+import (
+	"claude-squad/session"
+	"claude-squad/ui/overlay"
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// commitMessageTimeout bounds how long a commit message generator may run.
+const commitMessageTimeout = 2 * time.Minute
+
+// commitMessageMsg carries a generated summary for the commit dialog it was requested for.
+type commitMessageMsg struct {
+	dialog *overlay.TextInputOverlay
+	title  string
+	// prefill is the message the dialog was opened with. It is only replaced if the user hasn't
+	// edited it.
+	prefill string
+	data    session.CommitMessageData
+	summary string
+	err     error
+}
+
+// openCommitDialog asks for the message to commit the instance's uncommitted changes with. The
+// message is prefilled from the configured template and, when a generator is configured, replaced
+// by the generated one once it arrives. onCommit runs with the submitted message.
+func (m *home) openCommitDialog(title string, instance *session.Instance, onCommit func(commitMsg string) tea.Cmd) (tea.Model, tea.Cmd) {
+	diff, err := instance.UncommittedDiff()
+	if err != nil {
+		return m, m.handleError(err)
+	}
+	data := session.NewCommitMessageData(instance.Title, instance.Branch, diff)
+	prefill, err := session.RenderCommitMessage(m.appConfig.CommitMessageTemplate, data)
+	if err != nil {
+		return m, m.handleError(err)
+	}
+
+	dialog := overlay.NewTextInputOverlay(title, prefill)
+	m.textInputOverlay = dialog
+	m.textInputSubmit = func(commitMsg string) tea.Cmd {
+		if strings.TrimSpace(commitMsg) == "" {
+			return m.handleError(fmt.Errorf("commit message must not be empty"))
+		}
+		return onCommit(strings.TrimSpace(commitMsg))
+	}
+	m.state = statePrompt
+
+	// Size the input to the window.
+	cmds := []tea.Cmd{tea.WindowSize()}
+	if generator := session.NewCommitMessageGenerator(m.appConfig.CommitMessageGenerator, instance.Program); generator != nil {
+		dialog.Title = title + " (generating...)"
+		cmds = append(cmds, m.generateCommitMessageCmd(generator, diff, commitMessageMsg{
+			dialog:  dialog,
+			title:   title,
+			prefill: prefill,
+			data:    data,
+		}))
+	}
+	return m, tea.Batch(cmds...)
+}
+
+// generateCommitMessageCmd runs generator in the background and reports the summary in msg.
+func (m *home) generateCommitMessageCmd(generator session.CommitMessageGenerator, diff string, msg commitMessageMsg) tea.Cmd {
+	return func() tea.Msg {
+		ctx, cancel := context.WithTimeout(m.ctx, commitMessageTimeout)
+		defer cancel()
+		msg.summary, msg.err = generator.GenerateCommitMessage(ctx, diff)
+		return msg
+	}
+}
+
+// handleCommitMessageMsg puts a generated message into the commit dialog if it is still open.
+func (m *home) handleCommitMessageMsg(msg commitMessageMsg) tea.Cmd {
+	if m.textInputOverlay != msg.dialog {
+		return nil
+	}
+	msg.dialog.Title = msg.title
+	if msg.err != nil {
+		return m.handleError(msg.err)
+	}
+	if msg.dialog.GetValue() != msg.prefill {
+		return nil
+	}
+	msg.data.Summary = msg.summary
+	message, err := session.RenderCommitMessage(m.appConfig.CommitMessageTemplate, msg.data)
+	if err != nil {
+		return m.handleError(err)
+	}
+	msg.dialog.SetValue(message)
+	return nil
+}
@@ -0,0 +1,135 @@
+package app
+
+import (
+	"claude-squad/session"
+	"claude-squad/session/git"
+	"claude-squad/ui/overlay"
+	"fmt"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// commitsMsg carries the commits of an instance's branch.
+type commitsMsg struct {
+	instance *session.Instance
+	commits  []git.Commit
+	err      error
+}
+
+// commitDiffMsg carries the diff of a single commit.
+type commitDiffMsg struct {
+	instance *session.Instance
+	sha      string
+	stats    *git.DiffStats
+	err      error
+}
+
+// makeCommitsCmd lists the commits of the instance's branch in the background.
+func makeCommitsCmd(instance *session.Instance) tea.Cmd {
+	return func() tea.Msg {
+		commits, err := instance.Commits()
+		return commitsMsg{instance: instance, commits: commits, err: err}
+	}
+}
+
+// makeCommitDiffCmd loads the diff of a commit in the background.
+func makeCommitDiffCmd(instance *session.Instance, sha string) tea.Cmd {
+	return func() tea.Msg {
+		stats, err := instance.CommitDiff(sha)
+		return commitDiffMsg{instance: instance, sha: sha, stats: stats, err: err}
+	}
+}
+
+// loadCommitDiff returns a command that loads the diff of the selected commit if it isn't
+// shown yet.
+func (m *home) loadCommitDiff() tea.Cmd {
+	selected := m.list.GetSelectedInstance()
+	sha, ok := m.tabbedWindow.PendingCommitDiff()
+	if selected == nil || !ok {
+		return nil
+	}
+	return makeCommitDiffCmd(selected, sha)
+}
+
+// handleCommitsMsg updates the commits tab with the result of makeCommitsCmd.
+func (m *home) handleCommitsMsg(msg commitsMsg) tea.Cmd {
+	if msg.instance != m.list.GetSelectedInstance() {
+		return nil
+	}
+	m.tabbedWindow.SetCommits(msg.instance, msg.commits, msg.err)
+	return m.loadCommitDiff()
+}
+
+// handleCommitDiffMsg shows a commit diff loaded by makeCommitDiffCmd.
+func (m *home) handleCommitDiffMsg(msg commitDiffMsg) tea.Cmd {
+	if msg.instance != m.list.GetSelectedInstance() {
+		return nil
+	}
+	stats := msg.stats
+	if msg.err != nil {
+		stats = &git.DiffStats{Error: msg.err}
+	}
+	m.tabbedWindow.SetCommitDiff(msg.sha, stats)
+	return nil
+}
+
+// rewriteAction returns a command that runs a history rewrite and refreshes the commits tab.
+func rewriteAction(rewrite func() error) tea.Cmd {
+	return func() tea.Msg {
+		if err := rewrite(); err != nil {
+			return err
+		}
+		return instanceChangedMsg{}
+	}
+}
+
+// squashCommit asks for confirmation and squashes the selected commit into its parent.
+func (m *home) squashCommit() (tea.Model, tea.Cmd) {
+	selected := m.list.GetSelectedInstance()
+	commit, ok := m.tabbedWindow.SelectedCommit()
+	if selected == nil || !ok {
+		return m, nil
+	}
+	message := fmt.Sprintf("[!] Squash %s into its parent?", commit.ShortSHA)
+	return m, m.confirmAction(message, rewriteAction(func() error {
+		return selected.SquashCommit(commit.SHA)
+	}))
+}
+
+// dropCommit asks for confirmation and removes the selected commit from the branch.
+func (m *home) dropCommit() (tea.Model, tea.Cmd) {
+	selected := m.list.GetSelectedInstance()
+	commit, ok := m.tabbedWindow.SelectedCommit()
+	if selected == nil || !ok {
+		return m, nil
+	}
+	message := fmt.Sprintf("[!] Drop %s (%s)? Its changes will be lost.", commit.ShortSHA, commit.Subject)
+	return m, m.confirmAction(message, rewriteAction(func() error {
+		return selected.DropCommit(commit.SHA)
+	}))
+}
+
+// openRewordInput asks for a new message for the selected commit.
+func (m *home) openRewordInput() (tea.Model, tea.Cmd) {
+	selected := m.list.GetSelectedInstance()
+	commit, ok := m.tabbedWindow.SelectedCommit()
+	if selected == nil || !ok {
+		return m, nil
+	}
+	current, err := selected.CommitMessage(commit.SHA)
+	if err != nil {
+		return m, m.handleError(err)
+	}
+	m.textInputOverlay = overlay.NewTextInputOverlay(fmt.Sprintf("Reword %s", commit.ShortSHA), current)
+	m.textInputSubmit = func(message string) tea.Cmd {
+		if message == current {
+			return nil
+		}
+		return rewriteAction(func() error {
+			return selected.RewordCommit(commit.SHA, message)
+		})
+	}
+	m.state = statePrompt
+	// Size the input to the window.
+	return m, tea.WindowSize()
+}
@@ -0,0 +1,202 @@
+package app
+
+import (
+	"claude-squad/forge"
+	"claude-squad/log"
+	"claude-squad/session"
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// pushTimeout bounds pushing the branch, e.g. when ssh waits for a passphrase nobody can enter.
+const pushTimeout = time.Minute
+
+// pullRequestTimeout bounds pushing the branch and calling the forge's API.
+const pullRequestTimeout = 2 * time.Minute
+
+// pushAction returns a command that commits uncommitted changes with commitMsg, pushes the
+// instance's branch and opens it in the browser.
+func (m *home) pushAction(instance *session.Instance, commitMsg string) tea.Cmd {
+	cfg := m.appConfig.Forge
+	return func() tea.Msg {
+		ctx, cancel := context.WithTimeout(m.ctx, pushTimeout)
+		defer cancel()
+		branchURL, err := instance.Push(ctx, commitMsg, cfg)
+		if err != nil {
+			return err
+		}
+		openInBrowser(branchURL)
+		return nil
+	}
+}
+
+// pullRequestMsg reports the status of a pull request opened or polled for an instance.
+type pullRequestMsg struct {
+	instance *session.Instance
+	status   forge.PullRequestStatus
+	err      error
+}
+
+// pollPullRequestsMsg triggers a check of the open pull requests.
+type pollPullRequestsMsg struct{}
+
+// pullRequestAction returns a command that pushes the instance's branch, opens a pull request
+// for it and shows the pull request in the browser.
+func (m *home) pullRequestAction(instance *session.Instance, commitMsg string) tea.Cmd {
+	cfg := m.appConfig.Forge
+	return func() tea.Msg {
+		ctx, cancel := context.WithTimeout(m.ctx, pullRequestTimeout)
+		defer cancel()
+		status, err := instance.OpenPullRequest(ctx, commitMsg, cfg)
+		if err != nil {
+			return err
+		}
+		log.InfoLog.Printf("opened pull request for '%s': %s", instance.Title, status.URL)
+		openInBrowser(status.URL)
+		return pullRequestMsg{instance: instance, status: status}
+	}
+}
+
+// schedulePullRequestPoll returns a command that triggers the next check of the open pull
+// requests, or nil if polling is off.
+func (m *home) schedulePullRequestPoll() tea.Cmd {
+	interval := m.appConfig.Forge.Poll()
+	if interval == 0 {
+		return nil
+	}
+	return tea.Tick(interval, func(time.Time) tea.Msg {
+		return pollPullRequestsMsg{}
+	})
+}
+
+// pollPullRequests fetches the status of every pull request that is still open and schedules
+// the next poll.
+func (m *home) pollPullRequests() tea.Cmd {
+	cfg := m.appConfig.Forge
+	cmds := []tea.Cmd{m.schedulePullRequestPoll()}
+	for _, instance := range m.list.GetInstances() {
+		if instance.PullRequest == nil || instance.PullRequest.Done() || !instance.Started() {
+			continue
+		}
+		instance := instance
+		cmds = append(cmds, func() tea.Msg {
+			ctx, cancel := context.WithTimeout(m.ctx, pullRequestTimeout)
+			defer cancel()
+			status, err := instance.FetchPullRequestStatus(ctx, cfg)
+			return pullRequestMsg{instance: instance, status: status, err: err}
+		})
+	}
+	return tea.Batch(cmds...)
+}
+
+// handlePullRequestMsg stores the status of an instance's pull request. When the pull request
+// was just merged, killing the session is offered.
+func (m *home) handlePullRequestMsg(msg pullRequestMsg) tea.Cmd {
+	if msg.err != nil {
+		// Polling runs in the background; a flaky network shouldn't flood the error box.
+		log.WarningLog.Printf("failed to check pull request of '%s': %v", msg.instance.Title, msg.err)
+		return nil
+	}
+	if !m.hasInstance(msg.instance) {
+		return nil
+	}
+	wasMerged := msg.instance.PullRequest != nil && msg.instance.PullRequest.State == forge.StateMerged
+	status := msg.status
+	msg.instance.PullRequest = &status
+	if err := m.storage.SaveInstances(m.list.GetInstances()); err != nil {
+		return m.handleError(err)
+	}
+
+	if status.State != forge.StateMerged || wasMerged {
+		return nil
+	}
+	log.InfoLog.Printf("pull request #%d of '%s' was merged", status.Number, msg.instance.Title)
+	if m.state != stateDefault {
+		// Don't interrupt; the cleanup action picks it up later.
+		return nil
+	}
+	message := fmt.Sprintf("[!] Pull request #%d of '%s' was merged. Kill the session?", status.Number, msg.instance.Title)
+	return m.confirmAction(message, m.killAction(msg.instance))
+}
+
+// cleanupMerged offers to kill every session whose pull request was merged.
+func (m *home) cleanupMerged() (tea.Model, tea.Cmd) {
+	var merged []*session.Instance
+	var titles []string
+	for _, instance := range m.list.GetInstances() {
+		if instance.PullRequest != nil && instance.PullRequest.State == forge.StateMerged {
+			merged = append(merged, instance)
+			titles = append(titles, instance.Title)
+		}
+	}
+	if len(merged) == 0 {
+		return m, m.handleError(fmt.Errorf("no session has a merged pull request"))
+	}
+
+	message := fmt.Sprintf("[!] Kill %d merged session(s): %s?", len(merged), strings.Join(titles, ", "))
+	return m, m.confirmAction(message, m.killAction(merged...))
+}
+
+// hasInstance returns true if instance is still in the list.
+func (m *home) hasInstance(instance *session.Instance) bool {
+	for _, item := range m.list.GetInstances() {
+		if item == instance {
+			return true
+		}
+	}
+	return false
+}
+
+// openPullRequest opens a pull request for the selected instance. Uncommitted changes are
+// committed first with a message from the commit dialog.
+func (m *home) openPullRequest() (tea.Model, tea.Cmd) {
+	selected := m.list.GetSelectedInstance()
+	if selected == nil {
+		return m, nil
+	}
+	dirty, err := selected.HasUncommittedChanges()
+	if err != nil {
+		return m, m.handleError(err)
+	}
+	if dirty {
+		return m.openCommitDialog(fmt.Sprintf("Commit and open a pull request for '%s'", selected.Title), selected, func(commitMsg string) tea.Cmd {
+			return m.pullRequestAction(selected, commitMsg)
+		})
+	}
+	message := fmt.Sprintf("[!] Push '%s' and open a pull request?", selected.Title)
+	return m, m.confirmAction(message, m.pullRequestAction(selected, ""))
+}
+
+// browseAction returns a command that opens the instance's branch on the forge in the browser.
+func (m *home) browseAction(instance *session.Instance) tea.Cmd {
+	cfg := m.appConfig.Forge
+	return func() tea.Msg {
+		provider, err := instance.Forge(cfg)
+		if err != nil {
+			return err
+		}
+		branchURL := provider.BranchURL(instance.Branch)
+		if branchURL == "" {
+			return fmt.Errorf("remote %s has no web page to browse", cfg.RemoteName())
+		}
+		if err := forge.OpenURL(branchURL); err != nil {
+			return err
+		}
+		return nil
+	}
+}
+
+// openInBrowser opens url if it is set. Failing to open a browser isn't worth failing the action
+// over, so errors are only logged.
+func openInBrowser(url string) {
+	if url == "" {
+		return
+	}
+	if err := forge.OpenURL(url); err != nil {
+		log.ErrorLog.Printf("failed to open %s: %v", url, err)
+	}
+}
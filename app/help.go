@@ -1,6 +1,7 @@
 package app
 
 import (
+	"claude-squad/keys"
 	"claude-squad/log"
 	"claude-squad/session"
 	"claude-squad/ui"
@@ -34,32 +35,46 @@ func helpStart(instance *session.Instance) helpText {
 }
 
 func (h helpTypeGeneral) toContent() string {
+	left := lipgloss.JoinVertical(lipgloss.Left,
+		helpSection(keys.GroupSession),
+		keyStyle.Render(fmt.Sprintf("%-10s", keys.AttachHelp(keys.AttachDetach)))+descStyle.Render(" - Detach from session"),
+		"",
+		helpSection(keys.GroupActions),
+		"",
+		helpSection(keys.GroupSystem),
+	)
+	right := lipgloss.JoinVertical(lipgloss.Left,
+		helpSection(keys.GroupNavigation),
+		"",
+		helpSection(keys.GroupDiff),
+	)
 	content := lipgloss.JoinVertical(lipgloss.Left,
 		titleStyle.Render("Claude Squad"),
 		"",
 		"A terminal UI that manages multiple Claude Code (and other local agents) in separate workspaces.",
 		"",
-		headerStyle.Render("Managing:"),
-		keyStyle.Render("n")+descStyle.Render("         - Create a new session"),
-		keyStyle.Render("N")+descStyle.Render("         - Create a new session with a prompt"),
-		keyStyle.Render("D")+descStyle.Render("         - Kill (delete) the selected session"),
-		keyStyle.Render("↑/j, ↓/k")+descStyle.Render("  - Navigate between sessions"),
-		keyStyle.Render("↵/o")+descStyle.Render("       - Attach to the selected session"),
-		keyStyle.Render("ctrl-q")+descStyle.Render("    - Detach from session"),
-		"",
-		headerStyle.Render("Handoff:"),
-		keyStyle.Render("p")+descStyle.Render("         - Commit and push branch to github"),
-		keyStyle.Render("c")+descStyle.Render("         - Checkout: commit changes and pause session"),
-		keyStyle.Render("r")+descStyle.Render("         - Resume a paused session"),
-		"",
-		headerStyle.Render("Other:"),
-		keyStyle.Render("tab")+descStyle.Render("       - Switch between preview and diff tabs"),
-		keyStyle.Render("shift-↓/↑")+descStyle.Render(" - Scroll in diff view"),
-		keyStyle.Render("q")+descStyle.Render("         - Quit the application"),
+		lipgloss.JoinHorizontal(lipgloss.Top, left, "    ", right),
 	)
 	return content
 }
 
+// helpSection renders the header and key bindings for every action in the group, in registry order.
+func helpSection(group keys.ActionGroup) string {
+	lines := []string{headerStyle.Render(group.String() + ":")}
+	for _, a := range keys.Actions {
+		if a.Group != group {
+			continue
+		}
+		lines = append(lines, helpKey(a.Name, 10)+descStyle.Render(" - "+a.Description))
+	}
+	return lipgloss.JoinVertical(lipgloss.Left, lines...)
+}
+
+// helpKey renders the keys currently bound to name, padded to width.
+func helpKey(name keys.KeyName, width int) string {
+	return keyStyle.Render(fmt.Sprintf("%-*s", width, keys.GlobalkeyBindings[name].Help().Key))
+}
+
 func (h helpTypeInstanceStart) toContent() string {
 	content := lipgloss.JoinVertical(lipgloss.Left,
 		titleStyle.Render("Instance Created"),
@@ -71,13 +86,13 @@ func (h helpTypeInstanceStart) toContent() string {
 			lipgloss.NewStyle().Bold(true).Render(h.instance.Program))),
 		"",
 		headerStyle.Render("Managing:"),
-		keyStyle.Render("↵/o")+descStyle.Render("   - Attach to the session to interact with it directly"),
-		keyStyle.Render("tab")+descStyle.Render("   - Switch preview panes to view session diff"),
-		keyStyle.Render("D")+descStyle.Render("     - Kill (delete) the selected session"),
+		helpKey(keys.KeyEnter, 5)+descStyle.Render(" - Attach to the session to interact with it directly"),
+		helpKey(keys.KeyTab, 5)+descStyle.Render(" - Switch preview panes to view session diff"),
+		helpKey(keys.KeyKill, 5)+descStyle.Render(" - Kill (delete) the selected session"),
 		"",
 		headerStyle.Render("Handoff:"),
-		keyStyle.Render("c")+descStyle.Render("     - Checkout this instance's branch"),
-		keyStyle.Render("p")+descStyle.Render("     - Push branch to GitHub to create a PR"),
+		helpKey(keys.KeyCheckout, 5)+descStyle.Render(" - Checkout this instance's branch"),
+		helpKey(keys.KeySubmit, 5)+descStyle.Render(" - Push branch to GitHub to create a PR"),
 	)
 	return content
 }
@@ -86,7 +101,7 @@ func (h helpTypeInstanceAttach) toContent() string {
 	content := lipgloss.JoinVertical(lipgloss.Left,
 		titleStyle.Render("Attaching to Instance"),
 		"",
-		descStyle.Render("To detach from a session, press ")+keyStyle.Render("ctrl-q"),
+		descStyle.Render("To detach from a session, press ")+keyStyle.Render(keys.AttachHelp(keys.AttachDetach)),
 	)
 	return content
 }
@@ -100,8 +115,8 @@ func (h helpTypeInstanceCheckout) toContent() string {
 		"Feel free to make changes to the branch and commit them. When resuming, the session will continue from where you left off.",
 		"",
 		headerStyle.Render("Commands:"),
-		keyStyle.Render("c")+descStyle.Render(" - Checkout: commit changes locally and pause session"),
-		keyStyle.Render("r")+descStyle.Render(" - Resume a paused session"),
+		helpKey(keys.KeyCheckout, 0)+descStyle.Render(" - Checkout: commit changes locally and pause session"),
+		helpKey(keys.KeyResume, 0)+descStyle.Render(" - Resume a paused session"),
 	)
 	return content
 }
@@ -120,12 +135,21 @@ func (h helpTypeInstanceCheckout) mask() uint32 {
 }
 
 var (
-	titleStyle  = lipgloss.NewStyle().Bold(true).Underline(true).Foreground(lipgloss.Color("#7D56F4"))
-	headerStyle = lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("#36CFC9"))
-	keyStyle    = lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("#FFCC00"))
-	descStyle   = lipgloss.NewStyle().Foreground(lipgloss.Color("#FFFFFF"))
+	titleStyle  lipgloss.Style
+	headerStyle lipgloss.Style
+	keyStyle    lipgloss.Style
+	descStyle   lipgloss.Style
 )
 
+func init() {
+	ui.OnThemeChange(func() {
+		titleStyle = lipgloss.NewStyle().Bold(true).Underline(true).Foreground(ui.Theme.Accent)
+		headerStyle = lipgloss.NewStyle().Bold(true).Foreground(ui.Theme.AccentAlt)
+		keyStyle = lipgloss.NewStyle().Bold(true).Foreground(ui.Theme.Warn)
+		descStyle = lipgloss.NewStyle().Foreground(ui.Theme.Fg)
+	})
+}
+
 // showHelpScreen displays the help screen overlay if it hasn't been shown before
 func (m *home) showHelpScreen(helpType helpText, onDismiss func()) (tea.Model, tea.Cmd) {
 	// Get the flag for this help type
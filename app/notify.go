@@ -0,0 +1,75 @@
+package app
+
+import (
+	"claude-squad/log"
+	"claude-squad/notify"
+	"claude-squad/session"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// notifyReady notifies that the instance went from running to ready. The first time a restored
+// instance goes ready is skipped: it only reflects the first look at its pane after starting.
+func (m *home) notifyReady(instance *session.Instance) tea.Cmd {
+	if !m.notifier.Enabled() {
+		return nil
+	}
+	if m.notifyQuiet[instance] {
+		delete(m.notifyQuiet, instance)
+		return nil
+	}
+	return m.notifyCmd(instance, notify.KindReady)
+}
+
+// notifyPrompt notifies once per prompt that the instance waits for a confirmation. Prompts of
+// AutoYes instances are answered automatically, so they don't need attention unless the AutoYes
+// policy leaves them to the user.
+func (m *home) notifyPrompt(instance *session.Instance, prompt bool) tea.Cmd {
+	if !m.notifier.Enabled() {
+		return nil
+	}
+	if !prompt || (instance.AutoYes && !instance.AwaitingApproval()) {
+		delete(m.notifyPrompted, instance)
+		return nil
+	}
+	if m.notifyPrompted[instance] {
+		return nil
+	}
+	m.notifyPrompted[instance] = true
+	return m.notifyCmd(instance, notify.KindPrompt)
+}
+
+// notifyCmd returns a command that sends the notification in the background, or nil if the
+// instance is muted. Failures are only logged.
+func (m *home) notifyCmd(instance *session.Instance, kind notify.Kind) tea.Cmd {
+	if instance.Muted {
+		return nil
+	}
+	event := notify.Event{Session: instance.Title, Kind: kind}
+	return func() tea.Msg {
+		if err := m.notifier.Notify(event); err != nil {
+			log.WarningLog.Printf("failed to notify that '%s' is %s: %v", event.Session, event.Kind, err)
+		}
+		return nil
+	}
+}
+
+// forgetNotifications drops the notification state of a killed instance.
+func (m *home) forgetNotifications(instance *session.Instance) {
+	delete(m.notifyQuiet, instance)
+	delete(m.notifyPrompted, instance)
+	m.notifier.Forget(instance.Title)
+}
+
+// toggleMute mutes or unmutes the notifications of the selected instance.
+func (m *home) toggleMute() (tea.Model, tea.Cmd) {
+	selected := m.list.GetSelectedInstance()
+	if selected == nil {
+		return m, nil
+	}
+	selected.Muted = !selected.Muted
+	if err := m.storage.SaveInstances(m.list.GetInstances()); err != nil {
+		return m, m.handleError(err)
+	}
+	return m, nil
+}
@@ -0,0 +1,51 @@
+package app
+
+import (
+	"bytes"
+	"claude-squad/notify"
+	"claude-squad/session"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestNotifyOncePerPrompt(t *testing.T) {
+	var terminal bytes.Buffer
+	h := &home{
+		notifier:       notify.New(notify.Config{Methods: []string{notify.MethodBell}, RateLimit: -1}, &terminal),
+		notifyQuiet:    make(map[*session.Instance]bool),
+		notifyPrompted: make(map[*session.Instance]bool),
+	}
+	inst := &session.Instance{Title: "feature"}
+	cmd := h.notifyPrompt(inst, true)
+	require.NotNil(t, cmd)
+	cmd()
+	require.Equal(t, "\a", terminal.String())
+	require.Nil(t, h.notifyPrompt(inst, true), "the same prompt is only notified once")
+
+	// Once the prompt is gone, the next one is notified again.
+	require.Nil(t, h.notifyPrompt(inst, false))
+	require.NotNil(t, h.notifyPrompt(inst, true))
+
+	// AutoYes answers prompts by itself and muted instances never notify.
+	require.Nil(t, h.notifyPrompt(&session.Instance{Title: "auto", AutoYes: true}, true))
+	require.Nil(t, h.notifyReady(&session.Instance{Title: "muted", Muted: true}))
+}
+
+func TestNotifyReadySkipsRestoredInstances(t *testing.T) {
+	h := &home{
+		notifier:       notify.New(notify.Config{Methods: []string{notify.MethodBell}}, &bytes.Buffer{}),
+		notifyQuiet:    make(map[*session.Instance]bool),
+		notifyPrompted: make(map[*session.Instance]bool),
+	}
+	restored := &session.Instance{Title: "restored"}
+	h.notifyQuiet[restored] = true
+
+	require.Nil(t, h.notifyReady(restored))
+	require.NotNil(t, h.notifyReady(restored))
+
+	// Without notifications nothing is tracked.
+	h = &home{}
+	require.Nil(t, h.notifyReady(restored))
+	require.Nil(t, h.notifyPrompt(restored, true))
+}
@@ -0,0 +1,141 @@
+package app
+
+import (
+	"claude-squad/config"
+	"claude-squad/keys"
+	"claude-squad/session/git"
+	"claude-squad/ui"
+	"claude-squad/ui/overlay"
+	"strconv"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// actionContext returns the state that action availability is evaluated against.
+func (m *home) actionContext() keys.ActionContext {
+	selected := m.list.GetSelectedInstance()
+	return keys.ActionContext{
+		HasInstance:  selected != nil,
+		Paused:       selected != nil && selected.Paused(),
+		InDiffTab:    m.tabbedWindow.IsInDiffTab(),
+		InCommitsTab: m.tabbedWindow.IsInCommitsTab(),
+	}
+}
+
+// showPalette opens a palette overlay. onSelect is called with the chosen item once it is closed
+// with enter.
+func (m *home) showPalette(title string, items []overlay.PaletteItem, onSelect func(overlay.PaletteItem) (tea.Model, tea.Cmd)) (tea.Model, tea.Cmd) {
+	m.paletteOverlay = overlay.NewPaletteOverlay(title, items)
+	m.paletteSelect = onSelect
+	m.state = statePalette
+	// Size the palette to the window.
+	return m, tea.WindowSize()
+}
+
+// openPalette shows the command palette listing every action in the registry. Actions that
+// can't run right now are listed but disabled.
+func (m *home) openPalette() (tea.Model, tea.Cmd) {
+	ctx := m.actionContext()
+	items := make([]overlay.PaletteItem, 0, len(keys.Actions))
+	for _, a := range keys.Actions {
+		if a.Name == keys.KeyPalette {
+			continue
+		}
+		items = append(items, overlay.PaletteItem{
+			ID:       a.ID,
+			Title:    a.Description,
+			Group:    a.Group.String(),
+			Hint:     a.Binding().Help().Key,
+			Disabled: !a.Available(ctx),
+		})
+	}
+	return m.showPalette("Commands", items, func(item overlay.PaletteItem) (tea.Model, tea.Cmd) {
+		for _, a := range keys.Actions {
+			if a.ID != item.ID {
+				continue
+			}
+			// The selection may have changed since the palette was opened.
+			if !a.Available(m.actionContext()) {
+				return m, nil
+			}
+			return m.runAction(a.Name)
+		}
+		return m, nil
+	})
+}
+
+// openThemePicker shows a palette of the built-in and custom themes. The chosen theme is applied
+// immediately and saved to the config.
+func (m *home) openThemePicker() (tea.Model, tea.Cmd) {
+	var items []overlay.PaletteItem
+	for _, name := range ui.ThemeNames(m.appConfig.Themes) {
+		item := overlay.PaletteItem{ID: name, Title: name}
+		if name == m.appConfig.Theme {
+			item.Hint = "current"
+		}
+		items = append(items, item)
+	}
+	return m.showPalette("Themes", items, func(item overlay.PaletteItem) (tea.Model, tea.Cmd) {
+		palette, err := ui.ResolveTheme(item.ID, m.appConfig.Themes)
+		if err != nil {
+			return m, m.handleError(err)
+		}
+		ui.SetTheme(palette)
+		m.appConfig.Theme = item.ID
+		if err := config.SaveConfig(m.appConfig); err != nil {
+			return m, m.handleError(err)
+		}
+		// Re-render the cached diff with the new colors.
+		return m, m.instanceChanged()
+	})
+}
+
+// openDiffModePicker shows a palette of what the diff tab can compare: the fixed modes and every
+// branch of the selected instance's repository.
+func (m *home) openDiffModePicker() (tea.Model, tea.Cmd) {
+	selected := m.list.GetSelectedInstance()
+	if selected == nil {
+		return m, nil
+	}
+	branches, err := selected.Branches()
+	if err != nil {
+		return m, m.handleError(err)
+	}
+
+	specs := []git.DiffSpec{{Mode: git.DiffSinceBase}, {Mode: git.DiffUncommitted}, {Mode: git.DiffLastCommit}}
+	for _, b := range branches {
+		specs = append(specs, git.DiffSpec{Mode: git.DiffAgainstRef, Ref: b})
+	}
+	items := make([]overlay.PaletteItem, len(specs))
+	for i, spec := range specs {
+		items[i] = overlay.PaletteItem{ID: strconv.Itoa(i), Title: spec.String(), Group: "Show"}
+		if spec.Mode == git.DiffAgainstRef {
+			items[i].Group = "Compare with branch"
+		}
+		if spec == selected.DiffSpec() {
+			items[i].Hint = "current"
+		}
+	}
+	return m.showPalette("Compare", items, func(item overlay.PaletteItem) (tea.Model, tea.Cmd) {
+		i, _ := strconv.Atoi(item.ID)
+		selected.SetDiffSpec(specs[i])
+		return m, m.instanceChanged()
+	})
+}
+
+// handlePaletteState handles key events when a palette is open.
+func (m *home) handlePaletteState(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	if !m.paletteOverlay.HandleKeyPress(msg) {
+		return m, nil
+	}
+
+	selected, onSelect := m.paletteOverlay.Selected, m.paletteSelect
+	m.paletteOverlay = nil
+	m.paletteSelect = nil
+	m.state = stateDefault
+	m.menu.SetState(ui.StateDefault)
+	if selected == nil || onSelect == nil {
+		return m, nil
+	}
+	return onSelect(*selected)
+}
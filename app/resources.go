@@ -0,0 +1,107 @@
+package app
+
+import (
+	"claude-squad/log"
+	"claude-squad/session"
+	"claude-squad/session/resources"
+	"claude-squad/ui/overlay"
+	"fmt"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// resourceInterval is how often the resources of the agents are measured.
+const resourceInterval = 2 * time.Second
+
+// resourceTickMsg triggers the next measurement of the agents' resources.
+type resourceTickMsg struct{}
+
+// resourcesSampledMsg is sent when the agents' resources were measured.
+type resourcesSampledMsg struct{}
+
+// resourceLogEvery keeps a dying pane from flooding the log.
+var resourceLogEvery = log.NewEvery(time.Minute)
+
+// scheduleResourceSample returns a command that triggers the next measurement.
+func (m *home) scheduleResourceSample() tea.Cmd {
+	return tea.Tick(resourceInterval, func(time.Time) tea.Msg {
+		return resourceTickMsg{}
+	})
+}
+
+// sampleResources measures the resources of every running agent in the background. The next
+// measurement is scheduled once this one is done, so that two never run at the same time.
+func (m *home) sampleResources() tea.Cmd {
+	var instances []*session.Instance
+	for _, instance := range m.list.GetInstances() {
+		if instance.Started() && !instance.Paused() {
+			instances = append(instances, instance)
+		}
+	}
+	return func() tea.Msg {
+		for _, instance := range instances {
+			if err := instance.SampleResources(); err != nil && resourceLogEvery.ShouldLog() {
+				log.WarningLog.Printf("failed to measure the resources of '%s': %v", instance.Title, err)
+			}
+		}
+		return resourcesSampledMsg{}
+	}
+}
+
+// openResources shows the resource limits and usage of the selected instance and its processes.
+func (m *home) openResources() (tea.Model, tea.Cmd) {
+	selected := m.list.GetSelectedInstance()
+	if selected == nil {
+		return m, nil
+	}
+	if err := resources.Supported(); err != nil {
+		return m, m.handleError(err)
+	}
+	group := selected.ResourceGroup()
+	usage, ok := selected.ResourceUsage()
+	if group == nil || !ok {
+		return m, m.handleError(fmt.Errorf("the resources of '%s' were not measured yet", selected.Title))
+	}
+	procs, err := group.Processes()
+	if err != nil {
+		return m, m.handleError(err)
+	}
+	m.textOverlay = overlay.NewTextOverlay(resourceDetails(selected.Title, group, usage, procs))
+	m.state = stateHelp
+	return m, nil
+}
+
+// maxResourceProcesses is the number of processes listed in the resource details.
+const maxResourceProcesses = 15
+
+// resourceDetails renders the limits, the usage and the biggest processes of an agent.
+func resourceDetails(title string, group *resources.Group, usage resources.Usage, procs []resources.Process) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "Resources of '%s'\n\n", title)
+	fmt.Fprintf(&b, "Limits:    %s\n", group.Limits())
+	switch {
+	case group.CgroupPath() != "":
+		fmt.Fprintf(&b, "Cgroup:    %s\n", group.CgroupPath())
+	case group.LimitErr() != nil:
+		fmt.Fprintf(&b, "Cgroup:    not enforced, %v\n", group.LimitErr())
+	}
+	fmt.Fprintf(&b, "CPU:       %.0f%%\n", usage.CPU)
+	memory := resources.FormatBytes(usage.Memory)
+	if usage.MemoryMax > 0 {
+		memory += " of " + resources.FormatBytes(usage.MemoryMax)
+	}
+	fmt.Fprintf(&b, "Memory:    %s\n", memory)
+	fmt.Fprintf(&b, "Processes: %d\n\n", usage.Processes)
+
+	fmt.Fprintf(&b, "%8s %8s %9s  %s\n", "PID", "MEMORY", "CPU TIME", "COMMAND")
+	for n, p := range procs {
+		if n == maxResourceProcesses {
+			fmt.Fprintf(&b, "... and %d more\n", len(procs)-n)
+			break
+		}
+		fmt.Fprintf(&b, "%8d %8s %9s  %s\n", p.PID, resources.FormatBytes(p.RSS), p.CPUTime.Round(time.Second), p.Name)
+	}
+	return b.String()
+}
@@ -0,0 +1,85 @@
+package app
+
+import (
+	"claude-squad/session"
+	"claude-squad/ui/overlay"
+	"errors"
+	"fmt"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// revertAction returns a command that reverts patch in the instance's worktree and refreshes
+// the diff.
+func revertAction(instance *session.Instance, patch string) tea.Cmd {
+	return func() tea.Msg {
+		if err := instance.RevertPatch(patch); err != nil {
+			return err
+		}
+		return instanceChangedMsg{}
+	}
+}
+
+// errNotRevertible is shown when reverting or approving in a diff of commits or other refs.
+var errNotRevertible = errors.New("switch to the base diff to revert or approve changes")
+
+// revertHunk asks for confirmation and reverts the hunk selected in the diff tab.
+func (m *home) revertHunk() (tea.Model, tea.Cmd) {
+	if m.tabbedWindow.DiffRevertBlocked() {
+		return m, m.handleError(errNotRevertible)
+	}
+	selected := m.list.GetSelectedInstance()
+	patch, ok := m.tabbedWindow.SelectedDiffHunk()
+	if selected == nil || !ok {
+		return m, nil
+	}
+	return m, m.confirmAction("[!] Revert the selected hunk?", revertAction(selected, patch))
+}
+
+// revertFile asks for confirmation and reverts all changes to the file selected in the diff tab.
+func (m *home) revertFile() (tea.Model, tea.Cmd) {
+	if m.tabbedWindow.DiffRevertBlocked() {
+		return m, m.handleError(errNotRevertible)
+	}
+	selected := m.list.GetSelectedInstance()
+	path, patch, ok := m.tabbedWindow.SelectedDiffFile()
+	if selected == nil || !ok {
+		return m, nil
+	}
+	return m, m.confirmAction(fmt.Sprintf("[!] Revert all changes to %s?", path), revertAction(selected, patch))
+}
+
+// openCommentInput asks for a review comment on the line selected in the diff tab.
+func (m *home) openCommentInput() (tea.Model, tea.Cmd) {
+	comment, ok := m.tabbedWindow.SelectedDiffLine()
+	if !ok {
+		return m, m.handleError(fmt.Errorf("select a line in the diff to comment on it"))
+	}
+	m.textInputOverlay = overlay.NewTextInputOverlay(fmt.Sprintf("Comment on %s:%d", comment.Path, comment.Line), "")
+	m.textInputSubmit = func(text string) tea.Cmd {
+		if strings.TrimSpace(text) == "" {
+			return nil
+		}
+		comment.Text = text
+		m.tabbedWindow.AddDiffComment(comment)
+		return m.saveDiffViewState()
+	}
+	m.state = statePrompt
+	// Size the input to the window.
+	return m, tea.WindowSize()
+}
+
+// sendReview sends the pending review comments of the selected instance to its agent.
+func (m *home) sendReview() (tea.Model, tea.Cmd) {
+	selected := m.list.GetSelectedInstance()
+	if selected == nil {
+		return m, nil
+	}
+	if err := selected.SendReview(); err != nil {
+		return m, m.handleError(err)
+	}
+	// Drop the sent comments from the diff.
+	m.tabbedWindow.UpdateDiff(selected)
+	return m, m.saveDiffViewState()
+}
@@ -0,0 +1,48 @@
+package app
+
+import (
+	"claude-squad/log"
+	"claude-squad/notify"
+	"claude-squad/session"
+	"fmt"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// checkTimeout pauses, kills or notifies about the instance if it was ready or running for too
+// long. It runs after every status update of the instance.
+func (m *home) checkTimeout(instance *session.Instance) tea.Cmd {
+	switch instance.CheckTimeout(time.Now()) {
+	case session.TimeoutPause:
+		log.InfoLog.Printf("pausing idle session '%s'", instance.Title)
+		return m.pauseInstance(instance, notify.KindIdlePaused)
+	case session.TimeoutNotify:
+		log.InfoLog.Printf("'%s' is running for longer than its timeout", instance.Title)
+		return tea.Batch(
+			m.handleError(fmt.Errorf("'%s' has been running for longer than its timeout", instance.Title)),
+			m.notifyCmd(instance, notify.KindLongRunning),
+		)
+	case session.TimeoutKill:
+		log.InfoLog.Printf("killing '%s', which ran for longer than its timeout", instance.Title)
+		notifyCmd := m.notifyCmd(instance, notify.KindTimedOut)
+		// Kill here rather than in a Cmd: it changes the list and the notification state, which only
+		// Update may touch.
+		if err := m.killInstance(instance); err != nil {
+			return tea.Batch(notifyCmd, m.handleError(fmt.Errorf("failed to kill '%s': %w", instance.Title, err)))
+		}
+		return tea.Batch(notifyCmd, m.instanceChanged())
+	}
+	return nil
+}
+
+// pauseInstance pauses the instance like the checkout key does, because of what kind tells.
+func (m *home) pauseInstance(instance *session.Instance, kind notify.Kind) tea.Cmd {
+	if err := instance.Pause(); err != nil {
+		return m.handleError(fmt.Errorf("failed to pause '%s': %w", instance.Title, err))
+	}
+	if err := m.storage.SaveInstances(m.list.GetInstances()); err != nil {
+		return m.handleError(err)
+	}
+	return tea.Batch(m.notifyCmd(instance, kind), m.instanceChanged())
+}
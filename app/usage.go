@@ -0,0 +1,81 @@
+package app
+
+import (
+	"claude-squad/config"
+	"claude-squad/log"
+	"claude-squad/notify"
+	"claude-squad/session"
+	"fmt"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// usageInterval is how often the token usage of the agents is read.
+const usageInterval = 10 * time.Second
+
+// usageTickMsg triggers the next read of the agents' usage.
+type usageTickMsg struct{}
+
+// usageUpdatedMsg is sent when the agents' usage was read, with the sessions that went over a
+// cost budget.
+type usageUpdatedMsg struct {
+	alerts map[*session.Instance]*session.UsageAlert
+}
+
+// usageLogEvery keeps an unreadable transcript from flooding the log.
+var usageLogEvery = log.NewEvery(time.Minute)
+
+// scheduleUsageUpdate returns a command that triggers the next read of the usage.
+func (m *home) scheduleUsageUpdate() tea.Cmd {
+	return tea.Tick(usageInterval, func(time.Time) tea.Msg {
+		return usageTickMsg{}
+	})
+}
+
+// updateUsage reads the usage of every running agent in the background. The next read is
+// scheduled once this one is done, so that two never run at the same time.
+func (m *home) updateUsage() tea.Cmd {
+	var instances []*session.Instance
+	for _, instance := range m.list.GetInstances() {
+		if instance.Started() && !instance.Paused() {
+			instances = append(instances, instance)
+		}
+	}
+	return func() tea.Msg {
+		alerts := make(map[*session.Instance]*session.UsageAlert)
+		for _, instance := range instances {
+			alert, err := instance.UpdateUsage(time.Now())
+			if err != nil {
+				if usageLogEvery.ShouldLog() {
+					log.WarningLog.Printf("failed to read the usage of '%s': %v", instance.Title, err)
+				}
+				continue
+			}
+			if alert != nil {
+				alerts[instance] = alert
+			}
+		}
+		return usageUpdatedMsg{alerts: alerts}
+	}
+}
+
+// handleUsageUpdated warns about or pauses the sessions that went over a cost budget.
+func (m *home) handleUsageUpdated(msg usageUpdatedMsg) tea.Cmd {
+	cmds := []tea.Cmd{m.scheduleUsageUpdate()}
+	for instance, alert := range msg.alerts {
+		if !m.hasInstance(instance) || instance.Paused() {
+			continue
+		}
+		log.InfoLog.Printf("'%s' went over %s", instance.Title, alert.Reason)
+		if alert.Action == config.BudgetPause {
+			cmds = append(cmds, m.pauseInstance(instance, notify.KindCostBudget))
+			continue
+		}
+		cmds = append(cmds,
+			m.handleError(fmt.Errorf("'%s' went over %s", instance.Title, alert.Reason)),
+			m.notifyCmd(instance, notify.KindCostBudget),
+		)
+	}
+	return tea.Batch(cmds...)
+}
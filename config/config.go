@@ -1,7 +1,13 @@
 package config
 
 import (
+	"claude-squad/forge"
 	"claude-squad/log"
+	"claude-squad/notify"
+	"claude-squad/session/events"
+	"claude-squad/session/policy"
+	"claude-squad/session/resources"
+	"claude-squad/session/usage"
 	"encoding/json"
 	"fmt"
 	"os"
@@ -36,6 +42,256 @@ type Config struct {
 	DaemonPollInterval int `json:"daemon_poll_interval"`
 	// BranchPrefix is the prefix used for git branches created by the application.
 	BranchPrefix string `json:"branch_prefix"`
+	// Keymap rebinds actions. Keys are action IDs (e.g. "kill", "next-hunk") or attach-mode
+	// actions (e.g. "detach"); values are keys in bubbletea notation such as "ctrl+x" or "K".
+	// Keys given for an action replace its defaults.
+	Keymap map[string][]string `json:"keymap,omitempty"`
+	// Theme is the name of the color theme: "default", "high-contrast", "no-color" or a key of
+	// Themes. When empty, the default theme is used unless NO_COLOR is set.
+	Theme string `json:"theme,omitempty"`
+	// Themes defines custom palettes by name. Each maps palette roles (fg, fg_muted, bg_alt,
+	// accent, accent_alt, ok, warn, danger, hint) to colors; "base" names the built-in or custom theme to extend.
+	Themes map[string]map[string]string `json:"themes,omitempty"`
+	// CommitMessageTemplate is a Go text/template for the commit message suggested when pushing
+	// or checking out a session. Fields: .Title, .Branch, .Summary, .Files and .Date. Defaults to
+	// "{{.Summary}}".
+	CommitMessageTemplate string `json:"commit_message_template,omitempty"`
+	// CommitMessageGenerator fills in .Summary. "agent" asks the session's agent to summarize the
+	// diff; any other value is a shell command that reads the diff on stdin and prints the summary.
+	// When empty, the summary lists the changed files.
+	CommitMessageGenerator string `json:"commit_message_generator,omitempty"`
+	// Forge configures where sessions are pushed and how pull requests are opened. By default
+	// branches are pushed to origin and the forge is detected from its URL.
+	Forge forge.Config `json:"forge"`
+	// Checkpoints configures automatic snapshots of session worktrees. Off by default.
+	Checkpoints CheckpointConfig `json:"checkpoints"`
+	// Checks configures the check command, e.g. the tests or a linter, that is run in session
+	// worktrees.
+	Checks CheckConfig `json:"checks"`
+	// Notifications configures how you are told that a session finished or waits for a
+	// confirmation. Off by default.
+	Notifications notify.Config `json:"notifications"`
+	// Hooks receive the lifecycle events of sessions, e.g. created, paused or pushed.
+	Hooks []events.HookConfig `json:"hooks,omitempty"`
+	// AutoYesPolicy limits which confirmations AutoYes approves. Without rules it approves all.
+	AutoYesPolicy policy.Config `json:"auto_yes_policy"`
+	// AutoYesBudget limits how long and how much AutoYes approves per session. Off by default.
+	AutoYesBudget AutoYesBudget `json:"auto_yes_budget"`
+	// Sandbox runs new agents in a bubblewrap sandbox on Linux. Off by default.
+	Sandbox SandboxConfig `json:"sandbox"`
+	// Resources limits the CPU, memory and processes of every agent with cgroups on Linux. Off by
+	// default.
+	Resources resources.Limits `json:"resources"`
+	// Timeouts pause idle sessions and catch sessions that run for too long. Off by default.
+	Timeouts TimeoutConfig `json:"timeouts"`
+	// Usage configures the prices that token usage is priced with and the cost budgets.
+	Usage UsageConfig `json:"usage"`
+	// Log sets the level, format and rotation of the logs.
+	Log log.Options `json:"log"`
+}
+
+// DefaultCheckpointKeep is the number of checkpoints kept per session when Keep is not set.
+const DefaultCheckpointKeep = 50
+
+// CheckpointConfig configures automatic checkpoints. Checkpoints snapshot the worktree to hidden
+// refs without touching the branch or index, and are skipped when nothing changed.
+type CheckpointConfig struct {
+	// Interval is the number of seconds between checkpoints of active sessions. 0 turns the timer
+	// off.
+	Interval int `json:"interval,omitempty"`
+	// OnReady takes a checkpoint whenever a session goes from running to ready.
+	OnReady bool `json:"on_ready,omitempty"`
+	// Keep is the number of checkpoints kept per session. Defaults to DefaultCheckpointKeep.
+	Keep int `json:"keep,omitempty"`
+}
+
+// KeepCount returns the number of checkpoints to keep per session.
+func (c CheckpointConfig) KeepCount() int {
+	if c.Keep <= 0 {
+		return DefaultCheckpointKeep
+	}
+	return c.Keep
+}
+
+// AutoYesBudget limits AutoYes per session. A session that used up its budget drops back to manual
+// approval until AutoYes is turned on again.
+type AutoYesBudget struct {
+	// Approvals is the number of confirmations AutoYes may approve. 0 means no limit.
+	Approvals int `json:"approvals,omitempty"`
+	// Minutes is how long AutoYes stays on after it was turned on. 0 means no limit.
+	Minutes int `json:"minutes,omitempty"`
+}
+
+// SandboxConfig configures the sandbox of agents. In the sandbox the filesystem is read-only except
+// for the session's worktree, the repository's git directory and the Writable paths.
+type SandboxConfig struct {
+	// Enabled runs new sessions in the sandbox.
+	Enabled bool `json:"enabled,omitempty"`
+	// NoNetwork turns off the network in the sandbox. Agents that call a hosted model need it.
+	NoNetwork bool `json:"no_network,omitempty"`
+	// Writable are paths the agent may write to besides its worktree, e.g. where it keeps its
+	// state. Defaults to DefaultSandboxWritable.
+	Writable []string `json:"writable,omitempty"`
+	// Hidden are paths the agent can't read, e.g. credentials. Defaults to DefaultSandboxHidden.
+	Hidden []string `json:"hidden,omitempty"`
+	// Repos replace the sandbox configuration per repository. Keys are repository paths or
+	// directory names.
+	Repos map[string]SandboxConfig `json:"repos,omitempty"`
+}
+
+// DefaultSandboxWritable are the paths agents may write to when SandboxConfig.Writable is not set:
+// the state of Claude Code, aider and Gemini CLI, and caches.
+var DefaultSandboxWritable = []string{"~/.claude", "~/.claude.json", "~/.aider", "~/.gemini", "~/.cache"}
+
+// DefaultSandboxHidden are the paths hidden from agents when SandboxConfig.Hidden is not set.
+var DefaultSandboxHidden = []string{"~/.ssh", "~/.gnupg", "~/.aws", "~/.config/gh", "~/.netrc"}
+
+// For returns the sandbox configuration of the repository.
+func (c SandboxConfig) For(repoPath string) SandboxConfig {
+	if repoPath != "" {
+		if repo, ok := c.Repos[filepath.Clean(repoPath)]; ok {
+			return repo.withDefaults()
+		}
+		if repo, ok := c.Repos[filepath.Base(repoPath)]; ok {
+			return repo.withDefaults()
+		}
+	}
+	return c.withDefaults()
+}
+
+func (c SandboxConfig) withDefaults() SandboxConfig {
+	c.Repos = nil
+	if c.Writable == nil {
+		c.Writable = DefaultSandboxWritable
+	}
+	if c.Hidden == nil {
+		c.Hidden = DefaultSandboxHidden
+	}
+	return c
+}
+
+// What happens to a session that runs for longer than TimeoutConfig.RunningMinutes.
+const (
+	// TimeoutNotify notifies about the session. It is the default.
+	TimeoutNotify = "notify"
+	// TimeoutKill kills the session.
+	TimeoutKill = "kill"
+)
+
+// TimeoutConfig configures the timeouts of sessions. They are measured from the last time a
+// session went ready or running.
+type TimeoutConfig struct {
+	// IdleMinutes pauses sessions that are ready for this many minutes. 0 turns it off.
+	IdleMinutes int `json:"idle_minutes,omitempty"`
+	// RunningMinutes is how long a session may run without a break. 0 turns it off.
+	RunningMinutes int `json:"running_minutes,omitempty"`
+	// OnRunning is what happens to a session that runs for longer: "notify" or "kill".
+	OnRunning string `json:"on_running,omitempty"`
+	// Repos overrides the timeouts per repository. Keys are repository paths or directory names.
+	Repos map[string]TimeoutConfig `json:"repos,omitempty"`
+}
+
+// For returns the timeouts of the repository.
+func (c TimeoutConfig) For(repoPath string) TimeoutConfig {
+	if repoPath != "" {
+		if repo, ok := c.Repos[filepath.Clean(repoPath)]; ok {
+			c = repo
+		} else if repo, ok := c.Repos[filepath.Base(repoPath)]; ok {
+			c = repo
+		}
+	}
+	c.Repos = nil
+	return c
+}
+
+// Enabled returns true if any timeout is set.
+func (c TimeoutConfig) Enabled() bool {
+	return c.IdleMinutes > 0 || c.RunningMinutes > 0
+}
+
+// Validate returns an error if OnRunning is unknown, here or for a repository.
+func (c TimeoutConfig) Validate() error {
+	if c.OnRunning != "" && c.OnRunning != TimeoutNotify && c.OnRunning != TimeoutKill {
+		return fmt.Errorf("on_running must be %q or %q, not %q", TimeoutNotify, TimeoutKill, c.OnRunning)
+	}
+	for repo, cfg := range c.Repos {
+		if err := cfg.Validate(); err != nil {
+			return fmt.Errorf("%s: %w", repo, err)
+		}
+	}
+	return nil
+}
+
+// What happens to a session that goes over a cost budget.
+const (
+	// BudgetWarn shows a message and notifies. It is the default.
+	BudgetWarn = "warn"
+	// BudgetPause pauses the session.
+	BudgetPause = "pause"
+)
+
+// UsageConfig configures the usage tracking of agents.
+type UsageConfig struct {
+	// Prices override usage.DefaultPrices. Keys match model names containing them.
+	Prices map[string]usage.Price `json:"prices,omitempty"`
+	// SessionBudget is the cost in USD a session may spend. 0 turns it off.
+	SessionBudget float64 `json:"session_budget,omitempty"`
+	// DailyBudget is the cost in USD all sessions together may spend per day. 0 turns it off.
+	DailyBudget float64 `json:"daily_budget,omitempty"`
+	// OnBudget is what happens to a session that spends more: "warn" or "pause".
+	OnBudget string `json:"on_budget,omitempty"`
+}
+
+// Validate returns an error if OnBudget is unknown.
+func (c UsageConfig) Validate() error {
+	if c.OnBudget != "" && c.OnBudget != BudgetWarn && c.OnBudget != BudgetPause {
+		return fmt.Errorf("on_budget must be %q or %q, not %q", BudgetWarn, BudgetPause, c.OnBudget)
+	}
+	return nil
+}
+
+// CheckConfig configures the check command.
+type CheckConfig struct {
+	// Command is run with the user's shell in the session's worktree, e.g. "go test ./...".
+	// Checks are off when there is no command for the repository.
+	Command string `json:"command,omitempty"`
+	// Repos overrides Command per repository. Keys are repository paths or directory names.
+	Repos map[string]string `json:"repos,omitempty"`
+	// OnReady runs the check whenever a session goes from running to ready.
+	OnReady bool `json:"on_ready,omitempty"`
+	// Timeout is the number of seconds after which a check is canceled. 0 means no limit.
+	Timeout int `json:"timeout,omitempty"`
+	// FixAttempts turns on the fix loop: when a check run by OnReady fails, its output is sent to
+	// the agent as a prompt, up to this many times until the check passes. 0 turns it off.
+	FixAttempts int `json:"fix_attempts,omitempty"`
+	// FixOutputLimit is the number of bytes of check output sent to the agent. Defaults to
+	// DefaultFixOutputLimit.
+	FixOutputLimit int `json:"fix_output_limit,omitempty"`
+}
+
+// DefaultFixOutputLimit is the number of bytes of check output sent by the fix loop when
+// FixOutputLimit is not set.
+const DefaultFixOutputLimit = 8000
+
+// OutputLimit returns the number of bytes of check output sent by the fix loop.
+func (c CheckConfig) OutputLimit() int {
+	if c.FixOutputLimit <= 0 {
+		return DefaultFixOutputLimit
+	}
+	return c.FixOutputLimit
+}
+
+// CommandFor returns the check command for the repository at repoPath, or "" if there is none.
+func (c CheckConfig) CommandFor(repoPath string) string {
+	if repoPath != "" {
+		if command, ok := c.Repos[filepath.Clean(repoPath)]; ok {
+			return command
+		}
+		if command, ok := c.Repos[filepath.Base(repoPath)]; ok {
+			return command
+		}
+	}
+	return c.Command
 }
 
 // DefaultConfig returns the default configuration
@@ -112,6 +112,66 @@ func TestDefaultConfig(t *testing.T) {
 
 }
 
+func TestCheckCommandFor(t *testing.T) {
+	checks := CheckConfig{
+		Command: "make test",
+		Repos: map[string]string{
+			"/src/api": "go test ./...",
+			"web":      "npm test",
+		},
+	}
+
+	assert.Equal(t, "go test ./...", checks.CommandFor("/src/api/"))
+	assert.Equal(t, "npm test", checks.CommandFor("/home/me/web"))
+	assert.Equal(t, "make test", checks.CommandFor("/src/other"))
+	assert.Equal(t, "", CheckConfig{}.CommandFor("/src/api"))
+}
+
+func TestSandboxFor(t *testing.T) {
+	sandbox := SandboxConfig{
+		Enabled: true,
+		Hidden:  []string{"~/.ssh"},
+		Repos: map[string]SandboxConfig{
+			"scratch":  {},
+			"/src/api": {Enabled: true, NoNetwork: true, Writable: []string{}},
+		},
+	}
+
+	cfg := sandbox.For("/src/web")
+	assert.True(t, cfg.Enabled)
+	assert.Equal(t, []string{"~/.ssh"}, cfg.Hidden)
+	assert.Equal(t, DefaultSandboxWritable, cfg.Writable)
+	assert.Nil(t, cfg.Repos)
+
+	assert.False(t, sandbox.For("/home/me/scratch").Enabled)
+
+	cfg = sandbox.For("/src/api")
+	assert.True(t, cfg.NoNetwork)
+	assert.Empty(t, cfg.Writable, "an empty list turns the defaults off")
+	assert.Equal(t, DefaultSandboxHidden, cfg.Hidden)
+}
+
+func TestTimeoutConfig(t *testing.T) {
+	timeouts := TimeoutConfig{
+		IdleMinutes: 30,
+		Repos: map[string]TimeoutConfig{
+			"infra": {RunningMinutes: 10, OnRunning: TimeoutKill},
+		},
+	}
+	assert.Equal(t, TimeoutConfig{IdleMinutes: 30}, timeouts.For("/src/api"))
+	assert.Equal(t, TimeoutConfig{RunningMinutes: 10, OnRunning: TimeoutKill}, timeouts.For("/src/infra"))
+	assert.NoError(t, timeouts.Validate())
+
+	timeouts.Repos["web"] = TimeoutConfig{OnRunning: "pause"}
+	assert.Error(t, timeouts.Validate())
+}
+
+func TestUsageConfigValidate(t *testing.T) {
+	assert.NoError(t, UsageConfig{}.Validate())
+	assert.NoError(t, UsageConfig{SessionBudget: 5, OnBudget: BudgetPause}.Validate())
+	assert.Error(t, UsageConfig{OnBudget: "kill"}.Validate())
+}
+
 func TestGetConfigDir(t *testing.T) {
 	t.Run("returns valid config directory", func(t *testing.T) {
 		configDir, err := GetConfigDir()
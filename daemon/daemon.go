@@ -3,12 +3,17 @@ package daemon
 import (
 	"claude-squad/config"
 	"claude-squad/log"
+	"claude-squad/notify"
 	"claude-squad/session"
+	"claude-squad/session/events"
+	"claude-squad/session/policy"
+	"errors"
 	"fmt"
 	"os"
 	"os/exec"
 	"os/signal"
 	"path/filepath"
+	"slices"
 	"sync"
 	"syscall"
 	"time"
@@ -28,36 +33,91 @@ func RunDaemon(cfg *config.Config) error {
 	if err != nil {
 		return fmt.Errorf("failed to load instacnes: %w", err)
 	}
-	for _, instance := range instances {
-		// Assume AutoYes is true if the daemon is running.
-		instance.AutoYes = true
-	}
 
 	pollInterval := time.Duration(cfg.DaemonPollInterval) * time.Millisecond
 
 	// If we get an error for a session, it's likely that we'll keep getting the error. Log every 30 seconds.
 	everyN := log.NewEvery(60 * time.Second)
 
+	// The daemon has no terminal, so only notify-send and the command can notify.
+	notifier := notify.New(cfg.Notifications, nil)
+	watcher := newReadyWatcher()
+
+	bus, err := events.NewBus(cfg.Hooks)
+	if err != nil {
+		log.WarningLog.Printf("ignoring invalid hooks: %v", err)
+	}
+	session.SetEventBus(bus)
+	defer bus.Close(5 * time.Second)
+
+	engine, err := policy.New(cfg.AutoYesPolicy)
+	if err != nil {
+		return fmt.Errorf("invalid auto_yes_policy: %w", err)
+	}
+	session.SetPolicy(engine)
+	session.SetAutoYesBudget(cfg.AutoYesBudget)
+	session.SetCommitMessageTemplate(cfg.CommitMessageTemplate)
+	if err := cfg.Timeouts.Validate(); err != nil {
+		return fmt.Errorf("invalid timeouts: %w", err)
+	}
+	session.SetTimeouts(cfg.Timeouts)
+	if err := cfg.Usage.Validate(); err != nil {
+		return fmt.Errorf("invalid usage: %w", err)
+	}
+	session.SetUsageConfig(cfg.Usage)
+
 	wg := &sync.WaitGroup{}
 	wg.Add(1)
 	stopCh := make(chan struct{})
 	go func() {
 		defer wg.Done()
 		ticker := time.NewTimer(pollInterval)
+		var lastUsageUpdate time.Time
 		for {
+			var killed []*session.Instance
+			updateUsage := time.Since(lastUsageUpdate) >= usageInterval
+			if updateUsage {
+				lastUsageUpdate = time.Now()
+			}
 			for _, instance := range instances {
 				// We only store started instances, but check anyway.
 				if instance.Started() && !instance.Paused() {
-					if _, hasPrompt := instance.HasUpdated(); hasPrompt {
-						instance.TapEnter()
+					updated, hasPrompt := instance.HasUpdated()
+					if watcher.update(instance.Title, updated) {
+						notifyAsync(notifier, instance, notify.KindReady)
+					}
+					if status, ok := watcher.status(instance.Title, hasPrompt); ok {
+						instance.SetStatus(status)
+					}
+					if watcher.prompt(instance.Title, hasPrompt) && !instance.AutoYes {
+						// Without AutoYes the confirmation waits for the user.
+						log.InfoLog.Printf("'%s' is waiting for a confirmation", instance.Title)
+						notifyAsync(notifier, instance, notify.KindPrompt)
+					}
+					if hasPrompt {
+						if answer, ok := instance.AnswerPrompt(); ok && answer.BudgetUsedUp {
+							log.InfoLog.Printf("'%s' used up its AutoYes budget", instance.Title)
+							notifyAsync(notifier, instance, notify.KindBudget)
+						} else if ok && answer.Decision == policy.Ask {
+							log.InfoLog.Printf("'%s' needs approval for %s", instance.Title, answer.Action)
+							notifyAsync(notifier, instance, notify.KindPrompt)
+						}
 						if err := instance.UpdateDiffStats(); err != nil {
 							if everyN.ShouldLog() {
 								log.WarningLog.Printf("could not update diff stats for %s: %v", instance.Title, err)
 							}
 						}
 					}
+					if applyTimeout(storage, notifier, instance) {
+						killed = append(killed, instance)
+					} else if updateUsage {
+						applyUsage(storage, notifier, instance)
+					}
 				}
 			}
+			for _, instance := range killed {
+				instances = slices.DeleteFunc(instances, func(i *session.Instance) bool { return i == instance })
+			}
 
 			// Handle stop before ticker.
 			select {
@@ -87,6 +147,130 @@ func RunDaemon(cfg *config.Config) error {
 	return nil
 }
 
+// notifyAsync sends a notification about the instance in the background.
+func notifyAsync(notifier *notify.Notifier, instance *session.Instance, kind notify.Kind) {
+	if !notifier.Enabled() || instance.Muted {
+		return
+	}
+	event := notify.Event{Session: instance.Title, Kind: kind}
+	go func() {
+		if err := notifier.Notify(event); err != nil {
+			log.WarningLog.Printf("failed to notify that '%s' is %s: %v", event.Session, event.Kind, err)
+		}
+	}()
+}
+
+// applyTimeout pauses, kills or notifies about the instance if it was ready or running for too
+// long. It returns true if the instance was killed.
+func applyTimeout(storage *session.Storage, notifier *notify.Notifier, instance *session.Instance) bool {
+	switch instance.CheckTimeout(time.Now()) {
+	case session.TimeoutPause:
+		log.InfoLog.Printf("pausing idle session '%s'", instance.Title)
+		if err := instance.Pause(); err != nil {
+			log.ErrorLog.Printf("failed to pause idle session '%s': %v", instance.Title, err)
+			return false
+		}
+		if err := storage.UpdateInstance(instance); err != nil {
+			log.ErrorLog.Printf("failed to save '%s': %v", instance.Title, err)
+		}
+		notifyAsync(notifier, instance, notify.KindIdlePaused)
+	case session.TimeoutNotify:
+		log.InfoLog.Printf("'%s' is running for longer than its timeout", instance.Title)
+		notifyAsync(notifier, instance, notify.KindLongRunning)
+	case session.TimeoutKill:
+		log.InfoLog.Printf("killing '%s', which ran for longer than its timeout", instance.Title)
+		if err := storage.DeleteInstance(instance.Title); err != nil {
+			log.ErrorLog.Printf("failed to delete '%s': %v", instance.Title, err)
+			return false
+		}
+		if err := instance.Kill(); err != nil {
+			log.ErrorLog.Printf("failed to kill '%s': %v", instance.Title, err)
+		}
+		notifyAsync(notifier, instance, notify.KindTimedOut)
+		return true
+	}
+	return false
+}
+
+// usageInterval is how often the daemon reads the token usage of the agents.
+const usageInterval = 30 * time.Second
+
+// applyUsage reads the usage of the instance and warns about it or pauses it if it went over a
+// cost budget.
+func applyUsage(storage *session.Storage, notifier *notify.Notifier, instance *session.Instance) {
+	alert, err := instance.UpdateUsage(time.Now())
+	if err != nil {
+		log.WarningLog.Printf("failed to read the usage of '%s': %v", instance.Title, err)
+		return
+	}
+	if alert == nil {
+		return
+	}
+	log.InfoLog.Printf("'%s' went over %s", instance.Title, alert.Reason)
+	if alert.Action == config.BudgetPause {
+		if err := instance.Pause(); err != nil {
+			log.ErrorLog.Printf("failed to pause '%s': %v", instance.Title, err)
+			return
+		}
+		if err := storage.UpdateInstance(instance); err != nil {
+			log.ErrorLog.Printf("failed to save '%s': %v", instance.Title, err)
+		}
+	}
+	notifyAsync(notifier, instance, notify.KindCostBudget)
+}
+
+// readyWatcher detects sessions that go from running to ready in the results of HasUpdated.
+type readyWatcher struct {
+	seen     map[string]bool
+	running  map[string]bool
+	prompted map[string]bool
+}
+
+func newReadyWatcher() *readyWatcher {
+	return &readyWatcher{
+		seen:     make(map[string]bool),
+		running:  make(map[string]bool),
+		prompted: make(map[string]bool),
+	}
+}
+
+// prompt records whether a session waits for a confirmation and returns true if it just started
+// to, so that every confirmation is notified once, like the app does.
+func (w *readyWatcher) prompt(title string, hasPrompt bool) bool {
+	was := w.prompted[title]
+	w.prompted[title] = hasPrompt
+	return hasPrompt && !was
+}
+
+// update records whether the pane of a session changed since the last poll and returns true if
+// the session just went ready. The first poll only sets the baseline, since any content counts
+// as a change.
+func (w *readyWatcher) update(title string, updated bool) bool {
+	if !w.seen[title] {
+		w.seen[title] = true
+		return false
+	}
+	wasRunning := w.running[title]
+	w.running[title] = updated
+	return wasRunning && !updated
+}
+
+// status returns the status of a session after the last update: running if its pane changed, and
+// ready if it didn't and no confirmation is pending. It returns false before the baseline is set
+// and while a confirmation is pending.
+func (w *readyWatcher) status(title string, hasPrompt bool) (session.Status, bool) {
+	running, ok := w.running[title]
+	switch {
+	case !ok:
+		return 0, false
+	case running:
+		return session.Running, true
+	case !hasPrompt:
+		return session.Ready, true
+	}
+	return 0, false
+}
+
 // LaunchDaemon launches the daemon process.
 func LaunchDaemon() error {
 	// Find the claude squad binary.
@@ -126,6 +310,13 @@ func LaunchDaemon() error {
 	return nil
 }
 
+// stopTimeout bounds how long StopDaemon waits for the daemon to exit, which includes the time it
+// gives hooks to deliver their events.
+const stopTimeout = 10 * time.Second
+
+// stopPollInterval is how often StopDaemon checks whether the daemon exited.
+const stopPollInterval = 100 * time.Millisecond
+
 // StopDaemon attempts to stop a running daemon process if it exists. Returns no error if the daemon is not found
 // (assumes the daemon does not exist).
 func StopDaemon() error {
@@ -153,9 +344,20 @@ func StopDaemon() error {
 		return fmt.Errorf("failed to find daemon process: %w", err)
 	}
 
-	if err := proc.Kill(); err != nil {
+	// Let the daemon deliver queued hook events and save the instances before falling back to a kill.
+	if err := terminate(proc); err != nil && !errors.Is(err, os.ErrProcessDone) {
 		return fmt.Errorf("failed to stop daemon process: %w", err)
 	}
+	deadline := time.Now().Add(stopTimeout)
+	for running(proc) && time.Now().Before(deadline) {
+		time.Sleep(stopPollInterval)
+	}
+	if running(proc) {
+		log.WarningLog.Printf("daemon process (PID: %d) didn't exit in %s, killing it", pid, stopTimeout)
+		if err := proc.Kill(); err != nil && !errors.Is(err, os.ErrProcessDone) {
+			return fmt.Errorf("failed to kill daemon process: %w", err)
+		}
+	}
 
 	// Clean up PID file
 	if err := os.Remove(pidFile); err != nil {
@@ -3,6 +3,8 @@
 package daemon
 
 import (
+	"errors"
+	"os"
 	"syscall"
 )
 
@@ -12,3 +14,14 @@ func getSysProcAttr() *syscall.SysProcAttr {
 		Setsid: true, // Create a new session
 	}
 }
+
+// terminate asks the process to exit, so that it can deliver queued events and save the instances.
+func terminate(proc *os.Process) error {
+	return proc.Signal(syscall.SIGTERM)
+}
+
+// running returns true if the process still exists.
+func running(proc *os.Process) bool {
+	err := proc.Signal(syscall.Signal(0))
+	return err == nil || errors.Is(err, syscall.EPERM)
+}
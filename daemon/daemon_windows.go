@@ -4,6 +4,7 @@ package daemon
 
 import (
 	"golang.org/x/sys/windows"
+	"os"
 	"syscall"
 )
 
@@ -13,3 +14,13 @@ func getSysProcAttr() *syscall.SysProcAttr {
 		CreationFlags: windows.CREATE_NEW_PROCESS_GROUP | windows.DETACHED_PROCESS,
 	}
 }
+
+// terminate kills the process: Windows can't send it a signal to exit on its own.
+func terminate(proc *os.Process) error {
+	return proc.Kill()
+}
+
+// running returns false: terminate has already killed the process.
+func running(*os.Process) bool {
+	return false
+}
@@ -0,0 +1,351 @@
+// Package forge pushes session branches and opens pull requests on the forge hosting a git
+// remote: GitHub, GitLab, Gitea or a plain git server.
+package forge
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"os"
+	"os/exec"
+	"runtime"
+	"strings"
+	"time"
+)
+
+// Forge types accepted in Config.Type.
+const (
+	TypeGitHub = "github"
+	TypeGitLab = "gitlab"
+	TypeGitea  = "gitea"
+	// TypeNone is a plain git remote. It can be pushed to but has no pull requests or web pages.
+	TypeNone = "none"
+)
+
+// DefaultRemote is the remote pushed to when none is configured.
+const DefaultRemote = "origin"
+
+// Config selects and configures the forge. The zero value pushes to origin and detects the forge
+// from its URL.
+type Config struct {
+	// Remote is the git remote to push to. Defaults to "origin".
+	Remote string `json:"remote,omitempty"`
+	// Type is "github", "gitlab", "gitea" or "none". When empty it is detected from the remote URL.
+	Type string `json:"type,omitempty"`
+	// URL is the web address of the forge, e.g. "https://git.example.com:3000" for a self-hosted
+	// instance. Defaults to https on the remote's host.
+	URL string `json:"url,omitempty"`
+	// TokenEnv names the environment variable holding the API token used to open pull requests.
+	// Defaults to GITHUB_TOKEN (or GH_TOKEN), GITLAB_TOKEN or GITEA_TOKEN.
+	TokenEnv string `json:"token_env,omitempty"`
+	// BaseBranch is the branch pull requests are opened against. Defaults to the remote's HEAD.
+	BaseBranch string `json:"base_branch,omitempty"`
+	// PollInterval is the number of seconds between checks of open pull requests. Defaults to 60;
+	// a negative value turns polling off.
+	PollInterval int `json:"poll_interval,omitempty"`
+}
+
+// RemoteName returns the configured remote or DefaultRemote.
+func (c Config) RemoteName() string {
+	if c.Remote == "" {
+		return DefaultRemote
+	}
+	return c.Remote
+}
+
+// DefaultPollInterval is used when Config.PollInterval is zero.
+const DefaultPollInterval = 60 * time.Second
+
+// Poll returns how often open pull requests are checked, or 0 if polling is off.
+func (c Config) Poll() time.Duration {
+	switch {
+	case c.PollInterval < 0:
+		return 0
+	case c.PollInterval == 0:
+		return DefaultPollInterval
+	default:
+		return time.Duration(c.PollInterval) * time.Second
+	}
+}
+
+// Pull request states.
+const (
+	StateOpen   = "open"
+	StateMerged = "merged"
+	StateClosed = "closed"
+)
+
+// Combined CI check results. An empty value means there are no checks.
+const (
+	ChecksPending = "pending"
+	ChecksSuccess = "success"
+	ChecksFailure = "failure"
+)
+
+// Review decisions. An empty value means no decision yet.
+const (
+	ReviewApproved         = "approved"
+	ReviewChangesRequested = "changes_requested"
+)
+
+// PullRequestStatus is the state of a pull request on the forge.
+type PullRequestStatus struct {
+	Number int    `json:"number"`
+	URL    string `json:"url"`
+	// State is StateOpen, StateMerged or StateClosed.
+	State string `json:"state"`
+	// Checks is the combined result of the CI checks of the head commit.
+	Checks string `json:"checks,omitempty"`
+	// Review is the review decision.
+	Review string `json:"review,omitempty"`
+	// CheckedAt is when the status was last fetched.
+	CheckedAt time.Time `json:"checked_at"`
+}
+
+// Done returns true once the pull request is merged or closed and won't change anymore.
+func (s PullRequestStatus) Done() bool {
+	return s.State == StateMerged || s.State == StateClosed
+}
+
+// PullRequest describes a pull request (merge request on GitLab) to open.
+type PullRequest struct {
+	// Head is the branch with the changes, Base the branch to merge them into.
+	Head  string
+	Base  string
+	Title string
+	Body  string
+}
+
+// Provider performs the operations of a forge.
+type Provider interface {
+	// Type returns one of the Type constants.
+	Type() string
+	// Push pushes branch from the repository in dir to the remote and sets it as upstream.
+	Push(ctx context.Context, dir, branch string) error
+	// BranchURL returns the web page of branch, or "" if the forge has none.
+	BranchURL(branch string) string
+	// CreatePullRequest opens a pull request and returns its status.
+	CreatePullRequest(ctx context.Context, pr PullRequest) (PullRequestStatus, error)
+	// Status fetches the status of the pull request with the given number.
+	Status(ctx context.Context, number int) (PullRequestStatus, error)
+}
+
+// Repo is a repository on a forge, as parsed from a remote URL.
+type Repo struct {
+	// Host is the host name of the remote, without port.
+	Host string
+	// Path is the repository path on the forge, e.g. "owner/repo" or "group/subgroup/repo".
+	Path string
+}
+
+// Owner returns everything but the last element of the repository path.
+func (r Repo) Owner() string {
+	if i := strings.LastIndex(r.Path, "/"); i >= 0 {
+		return r.Path[:i]
+	}
+	return ""
+}
+
+// Name returns the last element of the repository path.
+func (r Repo) Name() string {
+	return r.Path[strings.LastIndex(r.Path, "/")+1:]
+}
+
+// ParseRemoteURL parses the URL of a git remote: scp-like ("git@host:owner/repo.git"),
+// ssh://, git:// or http(s) URLs.
+func ParseRemoteURL(remoteURL string) (Repo, error) {
+	raw := strings.TrimSpace(remoteURL)
+	var host, path string
+	if !strings.Contains(raw, "://") {
+		// scp-like syntax: [user@]host:path
+		colon := strings.Index(raw, ":")
+		if colon <= 0 {
+			return Repo{}, fmt.Errorf("unsupported remote URL %q", remoteURL)
+		}
+		host = raw[:colon]
+		if at := strings.LastIndex(host, "@"); at >= 0 {
+			host = host[at+1:]
+		}
+		path = raw[colon+1:]
+	} else {
+		u, err := url.Parse(raw)
+		if err != nil {
+			return Repo{}, fmt.Errorf("unsupported remote URL %q: %w", remoteURL, err)
+		}
+		host = u.Hostname()
+		path = u.Path
+	}
+	path = strings.TrimSuffix(strings.Trim(path, "/"), ".git")
+	if host == "" || path == "" {
+		return Repo{}, fmt.Errorf("unsupported remote URL %q", remoteURL)
+	}
+	return Repo{Host: host, Path: path}, nil
+}
+
+// DetectType guesses the forge from a remote host.
+func DetectType(host string) string {
+	host = strings.ToLower(host)
+	switch {
+	case host == "github.com" || strings.HasPrefix(host, "github."):
+		return TypeGitHub
+	case host == "gitlab.com" || strings.HasPrefix(host, "gitlab."):
+		return TypeGitLab
+	case host == "codeberg.org" || strings.HasPrefix(host, "gitea."):
+		return TypeGitea
+	default:
+		return TypeNone
+	}
+}
+
+// New returns the provider for a remote.
+func New(cfg Config, remoteURL string) (Provider, error) {
+	repo, err := ParseRemoteURL(remoteURL)
+	if err != nil {
+		// Remotes like local paths can still be pushed to.
+		if cfg.Type == "" || cfg.Type == TypeNone {
+			return &plain{remote: remote{name: cfg.RemoteName()}}, nil
+		}
+		return nil, err
+	}
+	kind := cfg.Type
+	if kind == "" {
+		kind = DetectType(repo.Host)
+	}
+	web := strings.TrimSuffix(cfg.URL, "/")
+	if web == "" {
+		web = "https://" + repo.Host
+	}
+	base := remote{name: cfg.RemoteName(), repo: repo, web: web}
+
+	switch kind {
+	case TypeGitHub:
+		return &gitHub{remote: base, token: token(cfg.TokenEnv, "GITHUB_TOKEN", "GH_TOKEN")}, nil
+	case TypeGitLab:
+		return &gitLab{remote: base, token: token(cfg.TokenEnv, "GITLAB_TOKEN")}, nil
+	case TypeGitea:
+		return &gitea{remote: base, token: token(cfg.TokenEnv, "GITEA_TOKEN")}, nil
+	case TypeNone:
+		return &plain{remote: base}, nil
+	default:
+		return nil, fmt.Errorf("unknown forge type %q", kind)
+	}
+}
+
+// token returns the value of the first environment variable that is set, preferring the
+// configured one.
+func token(configured string, defaults ...string) string {
+	if configured != "" {
+		return os.Getenv(configured)
+	}
+	for _, name := range defaults {
+		if v := os.Getenv(name); v != "" {
+			return v
+		}
+	}
+	return ""
+}
+
+// remote implements the parts shared by every provider.
+type remote struct {
+	name string
+	repo Repo
+	// web is the base URL of the forge's web interface.
+	web string
+}
+
+// Push runs a plain git push, so it works with whatever credentials git is set up with.
+func (r remote) Push(ctx context.Context, dir, branch string) error {
+	cmd := exec.CommandContext(ctx, "git", "push", "-u", r.name, branch)
+	cmd.Dir = dir
+	// When ctx is canceled, don't wait for children of git, e.g. ssh, that hold on to the output.
+	cmd.WaitDelay = time.Second
+	if output, err := cmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("failed to push branch to %s: %s (%w)", r.name, strings.TrimSpace(string(output)), err)
+	}
+	return nil
+}
+
+// repoURL returns the web page of the repository.
+func (r remote) repoURL() string {
+	return r.web + "/" + r.repo.Path
+}
+
+// plain is a git remote without a known forge.
+type plain struct {
+	remote
+}
+
+func (p *plain) Type() string { return TypeNone }
+
+func (p *plain) BranchURL(string) string { return "" }
+
+func (p *plain) CreatePullRequest(context.Context, PullRequest) (PullRequestStatus, error) {
+	return PullRequestStatus{}, p.errNoForge()
+}
+
+func (p *plain) Status(context.Context, int) (PullRequestStatus, error) {
+	return PullRequestStatus{}, p.errNoForge()
+}
+
+func (p *plain) errNoForge() error {
+	return fmt.Errorf("remote %s is not on a known forge; set forge.type in the config to use pull requests", p.name)
+}
+
+// combineChecks merges check results: any failure fails, otherwise anything pending is pending.
+func combineChecks(results ...string) string {
+	combined := ""
+	for _, r := range results {
+		switch {
+		case r == ChecksFailure:
+			return ChecksFailure
+		case r == ChecksPending:
+			combined = ChecksPending
+		case r == ChecksSuccess && combined == "":
+			combined = ChecksSuccess
+		}
+	}
+	return combined
+}
+
+// reviewDecision derives the review decision from reviews in chronological order. Only the
+// latest approving or change-requesting review of each reviewer counts.
+func reviewDecision(reviews []review) string {
+	latest := make(map[string]string)
+	for _, r := range reviews {
+		if r.decision != "" {
+			latest[r.user] = r.decision
+		}
+	}
+	decision := ""
+	for _, d := range latest {
+		if d == ReviewChangesRequested {
+			return ReviewChangesRequested
+		}
+		decision = ReviewApproved
+	}
+	return decision
+}
+
+// review is a single review of a pull request. decision is empty for plain comments.
+type review struct {
+	user     string
+	decision string
+}
+
+// OpenURL opens url in the default browser.
+func OpenURL(url string) error {
+	var cmd *exec.Cmd
+	switch runtime.GOOS {
+	case "darwin":
+		cmd = exec.Command("open", url)
+	case "windows":
+		cmd = exec.Command("rundll32", "url.dll,FileProtocolHandler", url)
+	default:
+		cmd = exec.Command("xdg-open", url)
+	}
+	if err := cmd.Start(); err != nil {
+		return fmt.Errorf("failed to open %s: %w", url, err)
+	}
+	go func() { _ = cmd.Wait() }()
+	return nil
+}
@@ -0,0 +1,235 @@
+package forge
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseRemoteURL(t *testing.T) {
+	cases := []struct {
+		url  string
+		want Repo
+	}{
+		{"git@github.com:owner/repo.git", Repo{Host: "github.com", Path: "owner/repo"}},
+		{"github.com:owner/repo", Repo{Host: "github.com", Path: "owner/repo"}},
+		{"https://github.com/owner/repo.git", Repo{Host: "github.com", Path: "owner/repo"}},
+		{"ssh://git@gitlab.com:2222/group/sub/repo.git", Repo{Host: "gitlab.com", Path: "group/sub/repo"}},
+		{"http://user:pw@localhost:3000/owner/repo/", Repo{Host: "localhost", Path: "owner/repo"}},
+	}
+	for _, c := range cases {
+		got, err := ParseRemoteURL(c.url)
+		require.NoError(t, err, c.url)
+		assert.Equal(t, c.want, got, c.url)
+	}
+	assert.Equal(t, "group/sub", Repo{Path: "group/sub/repo"}.Owner())
+	assert.Equal(t, "repo", Repo{Path: "group/sub/repo"}.Name())
+
+	for _, bad := range []string{"", "/srv/git/repo.git", "https://example.com"} {
+		_, err := ParseRemoteURL(bad)
+		assert.Error(t, err, bad)
+	}
+}
+
+func TestNewSelectsProvider(t *testing.T) {
+	cases := []struct {
+		cfg       Config
+		url       string
+		wantType  string
+		branchURL string
+	}{
+		{Config{}, "git@github.com:o/r.git", TypeGitHub, "https://github.com/o/r/tree/me/fix%20it"},
+		{Config{}, "https://gitlab.com/g/s/r.git", TypeGitLab, "https://gitlab.com/g/s/r/-/tree/me/fix%20it"},
+		{Config{}, "https://codeberg.org/o/r.git", TypeGitea, "https://codeberg.org/o/r/src/branch/me/fix%20it"},
+		{Config{}, "git@git.example.com:o/r.git", TypeNone, ""},
+		{Config{Type: TypeGitea, URL: "http://git.example.com:3000/"}, "git@git.example.com:o/r.git", TypeGitea, "http://git.example.com:3000/o/r/src/branch/me/fix%20it"},
+	}
+	for _, c := range cases {
+		p, err := New(c.cfg, c.url)
+		require.NoError(t, err, c.url)
+		assert.Equal(t, c.wantType, p.Type(), c.url)
+		assert.Equal(t, c.branchURL, p.BranchURL("me/fix it"), c.url)
+	}
+
+	_, err := New(Config{Type: "bitbucket"}, "git@github.com:o/r.git")
+	assert.Error(t, err)
+
+	// Remotes that aren't URLs can only be pushed to.
+	p, err := New(Config{}, "/srv/git/repo.git")
+	require.NoError(t, err)
+	assert.Equal(t, TypeNone, p.Type())
+	_, err = New(Config{Type: TypeGitHub}, "/srv/git/repo.git")
+	assert.Error(t, err)
+
+	p, err = New(Config{}, "git@git.example.com:o/r.git")
+	require.NoError(t, err)
+	_, err = p.CreatePullRequest(context.Background(), PullRequest{Head: "a", Base: "main"})
+	assert.Error(t, err, "plain remotes can't open pull requests")
+}
+
+func TestGiteaCreatePullRequest(t *testing.T) {
+	var got map[string]string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost || r.URL.Path != "/api/v1/repos/owner/repo/pulls" {
+			http.NotFound(w, r)
+			return
+		}
+		if r.Header.Get("Authorization") != "token secret" {
+			w.WriteHeader(http.StatusUnauthorized)
+			_, _ = w.Write([]byte(`{"message":"token is required"}`))
+			return
+		}
+		if err := json.NewDecoder(r.Body).Decode(&got); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		if got["head"] == "exists" {
+			w.WriteHeader(http.StatusConflict)
+			_, _ = w.Write([]byte(`{"message":"pull request already exists for these targets"}`))
+			return
+		}
+		w.WriteHeader(http.StatusCreated)
+		_, _ = w.Write([]byte(`{"number":7,"html_url":"` + "http://" + r.Host + `/owner/repo/pulls/7"}`))
+	}))
+	defer server.Close()
+
+	t.Setenv("CS_TEST_GITEA_TOKEN", "secret")
+	cfg := Config{Type: TypeGitea, URL: server.URL, TokenEnv: "CS_TEST_GITEA_TOKEN"}
+	p, err := New(cfg, server.URL+"/owner/repo.git")
+	require.NoError(t, err)
+
+	status, err := p.CreatePullRequest(context.Background(), PullRequest{Head: "me/feature", Base: "main", Title: "Add feature", Body: "Details"})
+	require.NoError(t, err)
+	assert.Equal(t, server.URL+"/owner/repo/pulls/7", status.URL)
+	assert.Equal(t, 7, status.Number)
+	assert.Equal(t, StateOpen, status.State)
+	assert.Equal(t, map[string]string{"head": "me/feature", "base": "main", "title": "Add feature", "body": "Details"}, got)
+
+	_, err = p.CreatePullRequest(context.Background(), PullRequest{Head: "exists", Base: "main", Title: "x"})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "pull request already exists")
+
+	t.Setenv("CS_TEST_GITEA_TOKEN", "wrong")
+	p, err = New(cfg, server.URL+"/owner/repo.git")
+	require.NoError(t, err)
+	_, err = p.CreatePullRequest(context.Background(), PullRequest{Head: "me/feature", Base: "main", Title: "x"})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "token is required")
+
+	t.Setenv("CS_TEST_GITEA_TOKEN", "")
+	p, err = New(cfg, server.URL+"/owner/repo.git")
+	require.NoError(t, err)
+	_, err = p.CreatePullRequest(context.Background(), PullRequest{Head: "me/feature", Base: "main", Title: "x"})
+	assert.Error(t, err, "a missing token is reported before calling the API")
+}
+
+func TestGiteaStatus(t *testing.T) {
+	pull := `{"number":7,"html_url":"https://git.example.com/owner/repo/pulls/7","state":"open","merged":false,"head":{"sha":"abc"}}`
+	commitStatus := `{"state":"pending","total_count":2}`
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "token secret" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		switch r.URL.Path {
+		case "/api/v1/repos/owner/repo/pulls/7":
+			_, _ = w.Write([]byte(pull))
+		case "/api/v1/repos/owner/repo/commits/abc/status":
+			_, _ = w.Write([]byte(commitStatus))
+		case "/api/v1/repos/owner/repo/pulls/7/reviews":
+			_, _ = w.Write([]byte(`[
+				{"state":"REQUEST_CHANGES","user":{"login":"ann"}},
+				{"state":"APPROVED","user":{"login":"bob"}},
+				{"state":"COMMENT","user":{"login":"ann"}},
+				{"state":"APPROVED","user":{"login":"ann"}}
+			]`))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	t.Setenv("CS_TEST_GITEA_TOKEN", "secret")
+	p, err := New(Config{Type: TypeGitea, URL: server.URL, TokenEnv: "CS_TEST_GITEA_TOKEN"}, server.URL+"/owner/repo.git")
+	require.NoError(t, err)
+
+	status, err := p.Status(context.Background(), 7)
+	require.NoError(t, err)
+	assert.Equal(t, 7, status.Number)
+	assert.Equal(t, StateOpen, status.State)
+	assert.Equal(t, ChecksPending, status.Checks)
+	// ann approved after requesting changes, so only the latest review counts.
+	assert.Equal(t, ReviewApproved, status.Review)
+	assert.False(t, status.Done())
+
+	pull = `{"number":7,"state":"closed","merged":true,"head":{"sha":"abc"}}`
+	commitStatus = `{"state":"","total_count":0}`
+	status, err = p.Status(context.Background(), 7)
+	require.NoError(t, err)
+	assert.Equal(t, StateMerged, status.State)
+	assert.Empty(t, status.Checks)
+	assert.True(t, status.Done())
+
+	_, err = p.Status(context.Background(), 8)
+	assert.Error(t, err)
+}
+
+func TestCombineChecks(t *testing.T) {
+	assert.Equal(t, "", combineChecks())
+	assert.Equal(t, "", combineChecks("", ""))
+	assert.Equal(t, ChecksSuccess, combineChecks("", ChecksSuccess))
+	assert.Equal(t, ChecksPending, combineChecks(ChecksSuccess, ChecksPending, ChecksSuccess))
+	assert.Equal(t, ChecksFailure, combineChecks(ChecksPending, ChecksFailure, ChecksSuccess))
+}
+
+func TestReviewDecision(t *testing.T) {
+	assert.Equal(t, "", reviewDecision(nil))
+	assert.Equal(t, "", reviewDecision([]review{{user: "a"}}))
+	assert.Equal(t, ReviewApproved, reviewDecision([]review{{"a", ReviewApproved}, {"a", ""}}))
+	assert.Equal(t, ReviewChangesRequested, reviewDecision([]review{{"a", ReviewApproved}, {"b", ReviewChangesRequested}}))
+	assert.Equal(t, ReviewApproved, reviewDecision([]review{{"b", ReviewChangesRequested}, {"b", ReviewApproved}}))
+}
+
+func TestPushToPlainRemote(t *testing.T) {
+	for _, kv := range [][2]string{
+		{"GIT_AUTHOR_NAME", "t"}, {"GIT_AUTHOR_EMAIL", "t@example.com"},
+		{"GIT_COMMITTER_NAME", "t"}, {"GIT_COMMITTER_EMAIL", "t@example.com"},
+	} {
+		t.Setenv(kv[0], kv[1])
+	}
+	tmp := t.TempDir()
+	bare := filepath.Join(tmp, "remote.git")
+	work := filepath.Join(tmp, "work")
+	git := func(dir string, args ...string) {
+		t.Helper()
+		cmd := exec.Command("git", args...)
+		cmd.Dir = dir
+		out, err := cmd.CombinedOutput()
+		require.NoError(t, err, "git %v: %s", args, out)
+	}
+	git(tmp, "init", "--bare", bare)
+	git(tmp, "init", work)
+	require.NoError(t, os.WriteFile(filepath.Join(work, "a.txt"), []byte("a\n"), 0644))
+	git(work, "add", "a.txt")
+	git(work, "commit", "-m", "init")
+	git(work, "checkout", "-b", "me/feature")
+	git(work, "remote", "add", "upstream", bare)
+
+	// The remote is named in the config; the forge type doesn't matter for pushing.
+	p, err := New(Config{Remote: "upstream", Type: TypeNone}, "git@example.com:o/r.git")
+	require.NoError(t, err)
+	require.NoError(t, p.Push(context.Background(), work, "me/feature"))
+	git(bare, "rev-parse", "--verify", "refs/heads/me/feature")
+
+	p, err = New(Config{}, "git@example.com:o/r.git")
+	require.NoError(t, err)
+	assert.Error(t, p.Push(context.Background(), work, "me/feature"), "origin doesn't exist")
+}
@@ -0,0 +1,405 @@
+package forge
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os/exec"
+	"strings"
+	"time"
+)
+
+// gitHub talks to the GitHub REST API.
+type gitHub struct {
+	remote
+	token string
+}
+
+func (g *gitHub) Type() string { return TypeGitHub }
+
+func (g *gitHub) BranchURL(branch string) string {
+	return g.repoURL() + "/tree/" + escapeBranch(branch)
+}
+
+// apiURL returns the REST API root: api.github.com for github.com and /api/v3 on GitHub Enterprise.
+func (g *gitHub) apiURL() string {
+	if g.web == "https://github.com" {
+		return "https://api.github.com"
+	}
+	return g.web + "/api/v3"
+}
+
+// header returns the API request headers. Without a token, the GitHub CLI's login is used if it
+// is installed.
+func (g *gitHub) header(ctx context.Context) (http.Header, error) {
+	token := g.token
+	if token == "" {
+		if out, err := exec.CommandContext(ctx, "gh", "auth", "token").Output(); err == nil {
+			token = strings.TrimSpace(string(out))
+		}
+	}
+	if token == "" {
+		return nil, fmt.Errorf("no GitHub token: set GITHUB_TOKEN or log in with 'gh auth login'")
+	}
+	header := http.Header{}
+	header.Set("Authorization", "Bearer "+token)
+	header.Set("Accept", "application/vnd.github+json")
+	return header, nil
+}
+
+// gitHubPull is the part of a GitHub or Gitea pull request that is used.
+type gitHubPull struct {
+	Number  int    `json:"number"`
+	HTMLURL string `json:"html_url"`
+	State   string `json:"state"`
+	Merged  bool   `json:"merged"`
+	Head    struct {
+		SHA string `json:"sha"`
+	} `json:"head"`
+}
+
+func (p gitHubPull) status() PullRequestStatus {
+	s := PullRequestStatus{Number: p.Number, URL: p.HTMLURL, State: StateOpen, CheckedAt: time.Now()}
+	switch {
+	case p.Merged:
+		s.State = StateMerged
+	case p.State == "closed":
+		s.State = StateClosed
+	}
+	return s
+}
+
+// gitHubReview is a GitHub or Gitea pull request review.
+type gitHubReview struct {
+	State string `json:"state"`
+	User  struct {
+		Login string `json:"login"`
+	} `json:"user"`
+}
+
+// reviews converts GitHub and Gitea reviews. Gitea calls a change request REQUEST_CHANGES.
+func reviews(raw []gitHubReview) []review {
+	out := make([]review, 0, len(raw))
+	for _, r := range raw {
+		rv := review{user: r.User.Login}
+		switch r.State {
+		case "APPROVED":
+			rv.decision = ReviewApproved
+		case "CHANGES_REQUESTED", "REQUEST_CHANGES":
+			rv.decision = ReviewChangesRequested
+		}
+		out = append(out, rv)
+	}
+	return out
+}
+
+// commitStatus maps the combined commit status of GitHub and Gitea to a check result.
+func commitStatus(state string, total int) string {
+	if total == 0 {
+		return ""
+	}
+	switch state {
+	case "success":
+		return ChecksSuccess
+	case "pending":
+		return ChecksPending
+	default:
+		return ChecksFailure
+	}
+}
+
+func (g *gitHub) CreatePullRequest(ctx context.Context, pr PullRequest) (PullRequestStatus, error) {
+	header, err := g.header(ctx)
+	if err != nil {
+		return PullRequestStatus{}, err
+	}
+	var resp gitHubPull
+	err = doJSON(ctx, http.MethodPost, fmt.Sprintf("%s/repos/%s/pulls", g.apiURL(), g.repo.Path), header, map[string]string{
+		"title": pr.Title,
+		"head":  pr.Head,
+		"base":  pr.Base,
+		"body":  pr.Body,
+	}, &resp)
+	if err != nil {
+		return PullRequestStatus{}, fmt.Errorf("failed to open pull request: %w", err)
+	}
+	return resp.status(), nil
+}
+
+func (g *gitHub) Status(ctx context.Context, number int) (PullRequestStatus, error) {
+	header, err := g.header(ctx)
+	if err != nil {
+		return PullRequestStatus{}, err
+	}
+	repoAPI := fmt.Sprintf("%s/repos/%s", g.apiURL(), g.repo.Path)
+	var pull gitHubPull
+	if err := doJSON(ctx, http.MethodGet, fmt.Sprintf("%s/pulls/%d", repoAPI, number), header, nil, &pull); err != nil {
+		return PullRequestStatus{}, fmt.Errorf("failed to get pull request #%d: %w", number, err)
+	}
+	status := pull.status()
+
+	// GitHub Actions report check runs; other CI systems may use commit statuses.
+	var runs struct {
+		CheckRuns []struct {
+			Status     string `json:"status"`
+			Conclusion string `json:"conclusion"`
+		} `json:"check_runs"`
+	}
+	if err := doJSON(ctx, http.MethodGet, fmt.Sprintf("%s/commits/%s/check-runs", repoAPI, pull.Head.SHA), header, nil, &runs); err != nil {
+		return PullRequestStatus{}, fmt.Errorf("failed to get checks: %w", err)
+	}
+	var combined struct {
+		State      string `json:"state"`
+		TotalCount int    `json:"total_count"`
+	}
+	if err := doJSON(ctx, http.MethodGet, fmt.Sprintf("%s/commits/%s/status", repoAPI, pull.Head.SHA), header, nil, &combined); err != nil {
+		return PullRequestStatus{}, fmt.Errorf("failed to get commit status: %w", err)
+	}
+	results := []string{commitStatus(combined.State, combined.TotalCount)}
+	for _, run := range runs.CheckRuns {
+		switch {
+		case run.Status != "completed":
+			results = append(results, ChecksPending)
+		case run.Conclusion == "success" || run.Conclusion == "neutral" || run.Conclusion == "skipped":
+			results = append(results, ChecksSuccess)
+		default:
+			results = append(results, ChecksFailure)
+		}
+	}
+	status.Checks = combineChecks(results...)
+
+	var rawReviews []gitHubReview
+	if err := doJSON(ctx, http.MethodGet, fmt.Sprintf("%s/pulls/%d/reviews", repoAPI, number), header, nil, &rawReviews); err != nil {
+		return PullRequestStatus{}, fmt.Errorf("failed to get reviews: %w", err)
+	}
+	status.Review = reviewDecision(reviews(rawReviews))
+	return status, nil
+}
+
+// gitLab talks to the GitLab REST API. Pull requests are merge requests there.
+type gitLab struct {
+	remote
+	token string
+}
+
+func (g *gitLab) Type() string { return TypeGitLab }
+
+func (g *gitLab) BranchURL(branch string) string {
+	return g.repoURL() + "/-/tree/" + escapeBranch(branch)
+}
+
+func (g *gitLab) header() (http.Header, error) {
+	if g.token == "" {
+		return nil, fmt.Errorf("no GitLab token: set GITLAB_TOKEN")
+	}
+	header := http.Header{}
+	header.Set("PRIVATE-TOKEN", g.token)
+	return header, nil
+}
+
+// projectURL returns the API URL of the project.
+func (g *gitLab) projectURL() string {
+	return fmt.Sprintf("%s/api/v4/projects/%s", g.web, url.PathEscape(g.repo.Path))
+}
+
+// gitLabMergeRequest is the part of a GitLab merge request that is used.
+type gitLabMergeRequest struct {
+	IID          int    `json:"iid"`
+	WebURL       string `json:"web_url"`
+	State        string `json:"state"`
+	HeadPipeline *struct {
+		Status string `json:"status"`
+	} `json:"head_pipeline"`
+}
+
+func (mr gitLabMergeRequest) status() PullRequestStatus {
+	s := PullRequestStatus{Number: mr.IID, URL: mr.WebURL, State: StateOpen, CheckedAt: time.Now()}
+	switch mr.State {
+	case "merged":
+		s.State = StateMerged
+	case "closed", "locked":
+		s.State = StateClosed
+	}
+	if mr.HeadPipeline != nil {
+		switch mr.HeadPipeline.Status {
+		case "success", "skipped", "manual":
+			s.Checks = ChecksSuccess
+		case "failed", "canceled":
+			s.Checks = ChecksFailure
+		default:
+			s.Checks = ChecksPending
+		}
+	}
+	return s
+}
+
+func (g *gitLab) CreatePullRequest(ctx context.Context, pr PullRequest) (PullRequestStatus, error) {
+	header, err := g.header()
+	if err != nil {
+		return PullRequestStatus{}, err
+	}
+	var resp gitLabMergeRequest
+	err = doJSON(ctx, http.MethodPost, g.projectURL()+"/merge_requests", header, map[string]string{
+		"title":         pr.Title,
+		"source_branch": pr.Head,
+		"target_branch": pr.Base,
+		"description":   pr.Body,
+	}, &resp)
+	if err != nil {
+		return PullRequestStatus{}, fmt.Errorf("failed to open merge request: %w", err)
+	}
+	return resp.status(), nil
+}
+
+func (g *gitLab) Status(ctx context.Context, number int) (PullRequestStatus, error) {
+	header, err := g.header()
+	if err != nil {
+		return PullRequestStatus{}, err
+	}
+	mrURL := fmt.Sprintf("%s/merge_requests/%d", g.projectURL(), number)
+	var mr gitLabMergeRequest
+	if err := doJSON(ctx, http.MethodGet, mrURL, header, nil, &mr); err != nil {
+		return PullRequestStatus{}, fmt.Errorf("failed to get merge request !%d: %w", number, err)
+	}
+	status := mr.status()
+
+	var approvals struct {
+		Approved bool `json:"approved"`
+	}
+	if err := doJSON(ctx, http.MethodGet, mrURL+"/approvals", header, nil, &approvals); err != nil {
+		return PullRequestStatus{}, fmt.Errorf("failed to get approvals: %w", err)
+	}
+	if approvals.Approved {
+		status.Review = ReviewApproved
+	}
+	return status, nil
+}
+
+// gitea talks to the Gitea (and Forgejo) REST API.
+type gitea struct {
+	remote
+	token string
+}
+
+func (g *gitea) Type() string { return TypeGitea }
+
+func (g *gitea) BranchURL(branch string) string {
+	return g.repoURL() + "/src/branch/" + escapeBranch(branch)
+}
+
+func (g *gitea) header() (http.Header, error) {
+	if g.token == "" {
+		return nil, fmt.Errorf("no Gitea token: set GITEA_TOKEN")
+	}
+	header := http.Header{}
+	header.Set("Authorization", "token "+g.token)
+	return header, nil
+}
+
+// repoAPI returns the API URL of the repository.
+func (g *gitea) repoAPI() string {
+	return fmt.Sprintf("%s/api/v1/repos/%s/%s", g.web, url.PathEscape(g.repo.Owner()), url.PathEscape(g.repo.Name()))
+}
+
+func (g *gitea) CreatePullRequest(ctx context.Context, pr PullRequest) (PullRequestStatus, error) {
+	header, err := g.header()
+	if err != nil {
+		return PullRequestStatus{}, err
+	}
+	var resp gitHubPull
+	err = doJSON(ctx, http.MethodPost, g.repoAPI()+"/pulls", header, map[string]string{
+		"title": pr.Title,
+		"head":  pr.Head,
+		"base":  pr.Base,
+		"body":  pr.Body,
+	}, &resp)
+	if err != nil {
+		return PullRequestStatus{}, fmt.Errorf("failed to open pull request: %w", err)
+	}
+	return resp.status(), nil
+}
+
+func (g *gitea) Status(ctx context.Context, number int) (PullRequestStatus, error) {
+	header, err := g.header()
+	if err != nil {
+		return PullRequestStatus{}, err
+	}
+	var pull gitHubPull
+	if err := doJSON(ctx, http.MethodGet, fmt.Sprintf("%s/pulls/%d", g.repoAPI(), number), header, nil, &pull); err != nil {
+		return PullRequestStatus{}, fmt.Errorf("failed to get pull request #%d: %w", number, err)
+	}
+	status := pull.status()
+
+	var combined struct {
+		State      string `json:"state"`
+		TotalCount int    `json:"total_count"`
+	}
+	if err := doJSON(ctx, http.MethodGet, fmt.Sprintf("%s/commits/%s/status", g.repoAPI(), pull.Head.SHA), header, nil, &combined); err != nil {
+		return PullRequestStatus{}, fmt.Errorf("failed to get commit status: %w", err)
+	}
+	status.Checks = commitStatus(combined.State, combined.TotalCount)
+
+	var rawReviews []gitHubReview
+	if err := doJSON(ctx, http.MethodGet, fmt.Sprintf("%s/pulls/%d/reviews", g.repoAPI(), number), header, nil, &rawReviews); err != nil {
+		return PullRequestStatus{}, fmt.Errorf("failed to get reviews: %w", err)
+	}
+	status.Review = reviewDecision(reviews(rawReviews))
+	return status, nil
+}
+
+// escapeBranch escapes a branch name for a URL path, keeping its slashes.
+func escapeBranch(branch string) string {
+	parts := strings.Split(branch, "/")
+	for i, p := range parts {
+		parts[i] = url.PathEscape(p)
+	}
+	return strings.Join(parts, "/")
+}
+
+// doJSON sends a request with body encoded as JSON, unless it is nil, and decodes the response
+// into out. Error responses are reported with the message the forge returned.
+func doJSON(ctx context.Context, method, endpoint string, header http.Header, body, out any) error {
+	var reqBody io.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			return err
+		}
+		reqBody = bytes.NewReader(data)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, endpoint, reqBody)
+	if err != nil {
+		return err
+	}
+	req.Header = header.Clone()
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	respBody, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		var apiErr struct {
+			Message any `json:"message"`
+		}
+		if json.Unmarshal(respBody, &apiErr) == nil && apiErr.Message != nil {
+			return fmt.Errorf("%s: %v", resp.Status, apiErr.Message)
+		}
+		return fmt.Errorf("%s", resp.Status)
+	}
+	if err := json.Unmarshal(respBody, out); err != nil {
+		return fmt.Errorf("unexpected response: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,179 @@
+package keys
+
+import "github.com/charmbracelet/bubbles/key"
+
+// ActionGroup categorizes actions for the menu, the help screen and the command palette.
+type ActionGroup int
+
+const (
+	GroupSession ActionGroup = iota
+	GroupActions
+	GroupNavigation
+	GroupDiff
+	GroupCommits
+	GroupSystem
+)
+
+// String returns the header used for the group on the help screen and in the palette.
+func (g ActionGroup) String() string {
+	switch g {
+	case GroupSession:
+		return "Managing"
+	case GroupActions:
+		return "Actions"
+	case GroupNavigation:
+		return "Navigation"
+	case GroupDiff:
+		return "Diff"
+	case GroupCommits:
+		return "Commits"
+	default:
+		return "Other"
+	}
+}
+
+// Requirement is a bit mask of conditions that must hold for an action to be available.
+type Requirement uint8
+
+const (
+	// RequiresInstance means an instance has to be selected.
+	RequiresInstance Requirement = 1 << iota
+	// RequiresActive means the selected instance must not be paused.
+	RequiresActive
+	// RequiresPaused means the selected instance must be paused.
+	RequiresPaused
+	// RequiresDiffTab means the diff tab has to be visible.
+	RequiresDiffTab
+	// RequiresCommitsTab means the commits tab has to be visible.
+	RequiresCommitsTab
+)
+
+// ActionContext is a snapshot of the app state that action availability is evaluated against.
+type ActionContext struct {
+	HasInstance bool
+	Paused      bool
+	InDiffTab   bool
+	// InCommitsTab is true while the commits tab is visible.
+	InCommitsTab bool
+}
+
+// Action describes a user-facing command. The registry below is the single source of truth for
+// the bottom menu, the help screen and the command palette.
+type Action struct {
+	// Name is the key binding that triggers the action.
+	Name KeyName
+	// ID is a stable identifier for the action.
+	ID string
+	// Description is a sentence-case explanation shown in the help screen and palette.
+	Description string
+	Group       ActionGroup
+	// Requires lists the conditions under which the action can run.
+	Requires Requirement
+	// Menu is true if the action is advertised in the bottom menu.
+	Menu bool
+	// MenuRequires narrows when the action is advertised in the menu without affecting
+	// whether it can run.
+	MenuRequires Requirement
+}
+
+// Actions is the ordered registry of every action in the app.
+var Actions = []Action{
+	{Name: KeyNew, ID: "new", Description: "Create a new session", Group: GroupSession, Menu: true},
+	{Name: KeyPrompt, ID: "new-with-prompt", Description: "Create a new session with a prompt", Group: GroupSession, Menu: true},
+	{Name: KeyKill, ID: "kill", Description: "Kill (delete) the selected session", Group: GroupSession, Requires: RequiresInstance, Menu: true},
+	{Name: KeyCleanupMerged, ID: "cleanup-merged", Description: "Kill the sessions whose pull request was merged", Group: GroupSession},
+	{Name: KeyUp, ID: "up", Description: "Select the previous session", Group: GroupSession},
+	{Name: KeyDown, ID: "down", Description: "Select the next session", Group: GroupSession},
+
+	{Name: KeyEnter, ID: "attach", Description: "Attach to the selected session", Group: GroupActions, Requires: RequiresInstance | RequiresActive, Menu: true},
+	{Name: KeySubmit, ID: "push", Description: "Commit and push the branch", Group: GroupActions, Requires: RequiresInstance | RequiresActive, Menu: true},
+	{Name: KeyCheckout, ID: "checkout", Description: "Checkout: commit changes and pause session", Group: GroupActions, Requires: RequiresInstance | RequiresActive, Menu: true},
+	{Name: KeyPullRequest, ID: "pull-request", Description: "Push the branch and open a pull request", Group: GroupActions, Requires: RequiresInstance | RequiresActive},
+	{Name: KeyBrowse, ID: "browse-branch", Description: "Open the branch on the forge in the browser", Group: GroupActions, Requires: RequiresInstance | RequiresActive},
+	{Name: KeyShell, ID: "shell", Description: "Open a shell in the session's worktree and attach to it", Group: GroupActions, Requires: RequiresInstance | RequiresActive},
+	{Name: KeyChecks, ID: "checks", Description: "Run the check command in the session's worktree, or cancel the running check", Group: GroupActions, Requires: RequiresInstance | RequiresActive},
+	{Name: KeyMute, ID: "mute", Description: "Mute or unmute notifications for the session", Group: GroupActions, Requires: RequiresInstance},
+	{Name: KeyAutoYes, ID: "autoyes", Description: "Turn AutoYes on or off for the session", Group: GroupActions, Requires: RequiresInstance},
+	{Name: KeyAudit, ID: "audit", Description: "Show the audit log of confirmations answered and prompts sent for the session", Group: GroupActions, Requires: RequiresInstance},
+	{Name: KeyResources, ID: "resources", Description: "Show the CPU, memory and processes of the session's agent", Group: GroupActions, Requires: RequiresInstance},
+	{Name: KeyCheckpoints, ID: "checkpoints", Description: "Show the checkpoint timeline to diff or restore the worktree", Group: GroupActions, Requires: RequiresInstance | RequiresActive},
+	{Name: KeyResume, ID: "resume", Description: "Resume a paused session", Group: GroupActions, Requires: RequiresInstance | RequiresPaused, Menu: true},
+
+	{Name: KeyShiftUp, ID: "scroll-up", Description: "Scroll up in the active pane", Group: GroupNavigation, Requires: RequiresInstance, Menu: true, MenuRequires: RequiresDiffTab},
+	{Name: KeyShiftDown, ID: "scroll-down", Description: "Scroll down in the active pane", Group: GroupNavigation, Requires: RequiresInstance},
+	{Name: KeyPgUp, ID: "page-up", Description: "Scroll up one page", Group: GroupNavigation, Requires: RequiresInstance},
+	{Name: KeyPgDn, ID: "page-down", Description: "Scroll down one page", Group: GroupNavigation, Requires: RequiresInstance},
+	{Name: KeyHalfUp, ID: "half-page-up", Description: "Scroll up half a page", Group: GroupNavigation, Requires: RequiresInstance},
+	{Name: KeyHalfDown, ID: "half-page-down", Description: "Scroll down half a page", Group: GroupNavigation, Requires: RequiresInstance},
+	{Name: KeyGoTop, ID: "top", Description: "Jump to the top of the active pane", Group: GroupNavigation, Requires: RequiresInstance},
+	{Name: KeyGoBottom, ID: "bottom", Description: "Jump to the bottom of the active pane", Group: GroupNavigation, Requires: RequiresInstance},
+
+	{Name: KeyHunkPrev, ID: "prev-hunk", Description: "Jump to the previous hunk", Group: GroupDiff, Requires: RequiresInstance | RequiresDiffTab},
+	{Name: KeyHunkNext, ID: "next-hunk", Description: "Jump to the next hunk", Group: GroupDiff, Requires: RequiresInstance | RequiresDiffTab},
+	{Name: KeyFilePrev, ID: "prev-file", Description: "Jump to the previous file", Group: GroupDiff, Requires: RequiresInstance | RequiresDiffTab},
+	{Name: KeyFileNext, ID: "next-file", Description: "Jump to the next file", Group: GroupDiff, Requires: RequiresInstance | RequiresDiffTab},
+	{Name: KeyDiffLayout, ID: "toggle-side-by-side", Description: "Toggle unified / side-by-side diff", Group: GroupDiff, Requires: RequiresInstance | RequiresDiffTab},
+	{Name: KeyDiffCollapse, ID: "toggle-collapsed", Description: "Collapse or expand the current file", Group: GroupDiff, Requires: RequiresInstance | RequiresDiffTab},
+	{Name: KeyDiffViewed, ID: "toggle-viewed", Description: "Mark the current file as viewed", Group: GroupDiff, Requires: RequiresInstance | RequiresDiffTab},
+	{Name: KeyDiffMode, ID: "diff-mode", Description: "Choose what the diff compares", Group: GroupDiff, Requires: RequiresInstance | RequiresActive | RequiresDiffTab},
+	{Name: KeyRevertHunk, ID: "revert-hunk", Description: "Revert the selected hunk in the worktree", Group: GroupDiff, Requires: RequiresInstance | RequiresActive | RequiresDiffTab},
+	{Name: KeyRevertFile, ID: "revert-file", Description: "Revert all changes to the selected file", Group: GroupDiff, Requires: RequiresInstance | RequiresActive | RequiresDiffTab},
+	{Name: KeyApproveHunk, ID: "toggle-approved", Description: "Mark the selected hunk as approved", Group: GroupDiff, Requires: RequiresInstance | RequiresDiffTab},
+	{Name: KeyComment, ID: "comment", Description: "Comment on the selected line", Group: GroupDiff, Requires: RequiresInstance | RequiresDiffTab},
+	{Name: KeySendReview, ID: "send-review", Description: "Send review comments to the agent", Group: GroupDiff, Requires: RequiresInstance | RequiresActive | RequiresDiffTab},
+
+	{Name: KeyCommitSquash, ID: "squash-commit", Description: "Squash the selected commit into its parent", Group: GroupCommits, Requires: RequiresInstance | RequiresActive | RequiresCommitsTab, Menu: true},
+	{Name: KeyCommitReword, ID: "reword-commit", Description: "Change the message of the selected commit", Group: GroupCommits, Requires: RequiresInstance | RequiresActive | RequiresCommitsTab, Menu: true},
+	{Name: KeyCommitDrop, ID: "drop-commit", Description: "Drop the selected commit from the branch", Group: GroupCommits, Requires: RequiresInstance | RequiresActive | RequiresCommitsTab, Menu: true},
+
+	{Name: KeyTab, ID: "switch-tab", Description: "Switch between the preview, diff, commits, shell and checks tabs", Group: GroupSystem, Requires: RequiresInstance, Menu: true},
+	{Name: KeyHelp, ID: "help", Description: "Show the help screen", Group: GroupSystem, Menu: true},
+	{Name: KeyPalette, ID: "palette", Description: "Open the command palette", Group: GroupSystem, Menu: true},
+	{Name: KeyTheme, ID: "theme", Description: "Switch the color theme", Group: GroupSystem},
+	{Name: KeyQuit, ID: "quit", Description: "Quit the application", Group: GroupSystem, Menu: true},
+}
+
+// satisfies reports whether ctx meets every condition in r.
+func (r Requirement) satisfies(ctx ActionContext) bool {
+	if r&RequiresInstance != 0 && !ctx.HasInstance {
+		return false
+	}
+	if r&RequiresActive != 0 && (!ctx.HasInstance || ctx.Paused) {
+		return false
+	}
+	if r&RequiresPaused != 0 && (!ctx.HasInstance || !ctx.Paused) {
+		return false
+	}
+	if r&RequiresDiffTab != 0 && !ctx.InDiffTab {
+		return false
+	}
+	if r&RequiresCommitsTab != 0 && !ctx.InCommitsTab {
+		return false
+	}
+	return true
+}
+
+// Available returns true if the action can run in the given context.
+func (a Action) Available(ctx ActionContext) bool {
+	return a.Requires.satisfies(ctx)
+}
+
+// InMenu returns true if the action should be advertised in the bottom menu for the given context.
+func (a Action) InMenu(ctx ActionContext) bool {
+	return a.Menu && a.Available(ctx) && a.MenuRequires.satisfies(ctx)
+}
+
+// Binding returns the key binding currently bound to the action.
+func (a Action) Binding() key.Binding {
+	return GlobalkeyBindings[a.Name]
+}
+
+// LookupAction returns the registry entry for the given key name.
+func LookupAction(name KeyName) (Action, bool) {
+	for _, a := range Actions {
+		if a.Name == name {
+			return a, true
+		}
+	}
+	return Action{}, false
+}
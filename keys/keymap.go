@@ -0,0 +1,297 @@
+package keys
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/charmbracelet/bubbles/key"
+)
+
+// AttachAction is an action handled by the input loop while attached to a session. These keys are
+// read as raw terminal bytes rather than bubbletea key messages.
+type AttachAction string
+
+const (
+	AttachDetach     AttachAction = "detach"
+	AttachPageUp     AttachAction = "attach-page-up"
+	AttachPageDown   AttachAction = "attach-page-down"
+	AttachTop        AttachAction = "attach-top"
+	AttachBottom     AttachAction = "attach-bottom"
+	AttachScrollUp   AttachAction = "attach-scroll-up"
+	AttachScrollDown AttachAction = "attach-scroll-down"
+	AttachHalfUp     AttachAction = "attach-half-page-up"
+	AttachHalfDown   AttachAction = "attach-half-page-down"
+)
+
+var defaultAttachKeys = map[AttachAction][]string{
+	AttachDetach:     {"ctrl+q"},
+	AttachPageUp:     {"pgup"},
+	AttachPageDown:   {"pgdown"},
+	AttachTop:        {"home"},
+	AttachBottom:     {"end"},
+	AttachScrollUp:   {"shift+up"},
+	AttachScrollDown: {"shift+down"},
+	AttachHalfUp:     {"ctrl+u"},
+	AttachHalfDown:   {"ctrl+d"},
+}
+
+// attachKeys holds the current attach-mode bindings. It is replaced by ApplyKeymap.
+var attachKeys = copyAttachKeys(defaultAttachKeys)
+
+// The defaults are captured before any keymap is applied so that ApplyKeymap always starts from
+// the built-in bindings.
+var (
+	defaultKeyStringsMap = copyStringsMap(GlobalKeyStringsMap)
+	defaultKeyBindings   = copyBindings(GlobalkeyBindings)
+)
+
+// ApplyKeymap rebinds actions according to keymap, which maps action IDs (see Actions) and attach
+// actions (see AttachAction) to the keys that trigger them. Keys given for an action replace its
+// default keys. An error is returned, and the current bindings are left untouched, if an ID is
+// unknown, a key can't be parsed, or a key would trigger more than one action.
+func ApplyKeymap(keymap map[string][]string) error {
+	stringsMap := copyStringsMap(defaultKeyStringsMap)
+	bindings := copyBindings(defaultKeyBindings)
+	attach := copyAttachKeys(defaultAttachKeys)
+
+	// Iterate in a stable order so that errors are deterministic.
+	ids := make([]string, 0, len(keymap))
+	for id := range keymap {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+
+	remapped := make(map[KeyName][]string)
+	for _, id := range ids {
+		keyStrings := keymap[id]
+		if len(keyStrings) == 0 {
+			return fmt.Errorf("keymap: no keys given for %q", id)
+		}
+		for _, k := range keyStrings {
+			if strings.TrimSpace(k) == "" {
+				return fmt.Errorf("keymap: empty key given for %q", id)
+			}
+		}
+
+		if _, ok := defaultAttachKeys[AttachAction(id)]; ok {
+			for _, k := range keyStrings {
+				if _, err := KeySequences(k); err != nil {
+					return fmt.Errorf("keymap: %q: %w", id, err)
+				}
+			}
+			attach[AttachAction(id)] = keyStrings
+			continue
+		}
+
+		action, ok := actionByID(id)
+		if !ok {
+			return fmt.Errorf("keymap: unknown action %q", id)
+		}
+		remapped[action.Name] = keyStrings
+	}
+
+	// Drop the default keys of every remapped action before adding the new ones, so a key can be
+	// moved from one action to another in a single keymap.
+	for k, name := range stringsMap {
+		if _, ok := remapped[name]; ok {
+			delete(stringsMap, k)
+		}
+	}
+	var conflicts []string
+	for _, id := range ids {
+		action, ok := actionByID(id)
+		if !ok {
+			continue
+		}
+		for _, k := range keymap[id] {
+			if other, taken := stringsMap[k]; taken && other != action.Name {
+				conflicts = append(conflicts, fmt.Sprintf("%q is bound to both %s and %s", k, id, describe(other)))
+				continue
+			}
+			stringsMap[k] = action.Name
+		}
+		binding := bindings[action.Name]
+		bindings[action.Name] = key.NewBinding(
+			key.WithKeys(keymap[id]...),
+			key.WithHelp(HelpKey(keymap[id]...), binding.Help().Desc),
+		)
+	}
+	conflicts = append(conflicts, attachConflicts(attach)...)
+	if len(conflicts) > 0 {
+		return fmt.Errorf("keymap: conflicting bindings: %s", strings.Join(conflicts, "; "))
+	}
+
+	GlobalKeyStringsMap = stringsMap
+	GlobalkeyBindings = bindings
+	attachKeys = attach
+	return nil
+}
+
+// attachConflicts reports attach-mode keys that produce the same terminal input.
+func attachConflicts(attach map[AttachAction][]string) []string {
+	actions := make([]string, 0, len(attach))
+	for a := range attach {
+		actions = append(actions, string(a))
+	}
+	sort.Strings(actions)
+
+	var conflicts []string
+	seen := make(map[string]AttachAction)
+	for _, a := range actions {
+		for _, k := range attach[AttachAction(a)] {
+			seqs, _ := KeySequences(k)
+			for _, seq := range seqs {
+				if other, ok := seen[string(seq)]; ok && other != AttachAction(a) {
+					conflicts = append(conflicts, fmt.Sprintf("%q is bound to both %s and %s", k, other, a))
+					continue
+				}
+				seen[string(seq)] = AttachAction(a)
+			}
+		}
+	}
+	return conflicts
+}
+
+// AttachKeys returns the keys bound to an attach-mode action.
+func AttachKeys(a AttachAction) []string {
+	return attachKeys[a]
+}
+
+// AttachSequences returns the terminal byte sequences that trigger an attach-mode action.
+func AttachSequences(a AttachAction) [][]byte {
+	var seqs [][]byte
+	for _, k := range attachKeys[a] {
+		s, err := KeySequences(k)
+		if err != nil {
+			// ApplyKeymap rejects keys that can't be parsed.
+			continue
+		}
+		seqs = append(seqs, s...)
+	}
+	return seqs
+}
+
+// AttachHelp returns the display form of the keys bound to an attach-mode action, e.g. "ctrl-q".
+func AttachHelp(a AttachAction) string {
+	return strings.ReplaceAll(strings.Join(attachKeys[a], "/"), "ctrl+", "ctrl-")
+}
+
+var specialSequences = map[string][][]byte{
+	"enter":      {{'\r'}},
+	"tab":        {{'\t'}},
+	"esc":        {{27}},
+	"backspace":  {{127}},
+	"space":      {{' '}},
+	"up":         {{27, '[', 'A'}, {27, 'O', 'A'}},
+	"down":       {{27, '[', 'B'}, {27, 'O', 'B'}},
+	"right":      {{27, '[', 'C'}, {27, 'O', 'C'}},
+	"left":       {{27, '[', 'D'}, {27, 'O', 'D'}},
+	"shift+up":   {{27, '[', '1', ';', '2', 'A'}},
+	"shift+down": {{27, '[', '1', ';', '2', 'B'}},
+	"pgup":       {{27, '[', '5', '~'}},
+	"pgdown":     {{27, '[', '6', '~'}},
+	"home":       {{27, '[', 'H'}, {27, '[', '1', '~'}, {27, 'O', 'H'}},
+	"end":        {{27, '[', 'F'}, {27, '[', '4', '~'}, {27, 'O', 'F'}},
+}
+
+// KeySequences converts a key in bubbletea notation (e.g. "ctrl+q", "pgup", "x") into the byte
+// sequences a terminal may send for it.
+func KeySequences(k string) ([][]byte, error) {
+	if seqs, ok := specialSequences[k]; ok {
+		return seqs, nil
+	}
+	if rest, ok := strings.CutPrefix(k, "ctrl+"); ok && len(rest) == 1 {
+		c := rest[0]
+		switch {
+		case c >= 'a' && c <= 'z':
+			return [][]byte{{c - 'a' + 1}}, nil
+		case c >= '[' && c <= '_':
+			return [][]byte{{c - '@'}}, nil
+		}
+	}
+	if rest, ok := strings.CutPrefix(k, "alt+"); ok && utf8.RuneCountInString(rest) == 1 {
+		return [][]byte{append([]byte{27}, rest...)}, nil
+	}
+	if utf8.RuneCountInString(k) == 1 {
+		return [][]byte{[]byte(k)}, nil
+	}
+	return nil, fmt.Errorf("unsupported key %q", k)
+}
+
+var helpNames = map[string]string{
+	"up":         "↑",
+	"down":       "↓",
+	"left":       "←",
+	"right":      "→",
+	"shift+up":   "shift+↑",
+	"shift+down": "shift+↓",
+	"enter":      "↵",
+	"pgup":       "PgUp",
+	"pgdown":     "PgDn",
+	"home":       "Home",
+	"end":        "End",
+}
+
+// HelpKey returns the short display form of a list of keys, as shown in the menu and help screen.
+func HelpKey(keyStrings ...string) string {
+	parts := make([]string, 0, len(keyStrings))
+	for _, k := range keyStrings {
+		if name, ok := helpNames[k]; ok {
+			parts = append(parts, name)
+		} else if rest, ok := strings.CutPrefix(k, "ctrl+"); ok {
+			parts = append(parts, "C-"+rest)
+		} else {
+			parts = append(parts, k)
+		}
+	}
+	return strings.Join(parts, "/")
+}
+
+func actionByID(id string) (Action, bool) {
+	for _, a := range Actions {
+		if a.ID == id {
+			return a, true
+		}
+	}
+	return Action{}, false
+}
+
+// describe returns the action ID for a key name, falling back to the key name's help text.
+func describe(name KeyName) string {
+	if a, ok := LookupAction(name); ok {
+		return a.ID
+	}
+	if name >= KeyNum1 && name <= KeyNum0 {
+		return fmt.Sprintf("select session %d", (name-KeyNum1+1)%10)
+	}
+	if b, ok := defaultKeyBindings[name]; ok {
+		return b.Help().Desc
+	}
+	return fmt.Sprintf("key %d", name)
+}
+
+func copyStringsMap(m map[string]KeyName) map[string]KeyName {
+	out := make(map[string]KeyName, len(m))
+	for k, v := range m {
+		out[k] = v
+	}
+	return out
+}
+
+func copyBindings(m map[KeyName]key.Binding) map[KeyName]key.Binding {
+	out := make(map[KeyName]key.Binding, len(m))
+	for k, v := range m {
+		out[k] = v
+	}
+	return out
+}
+
+func copyAttachKeys(m map[AttachAction][]string) map[AttachAction][]string {
+	out := make(map[AttachAction][]string, len(m))
+	for k, v := range m {
+		out[k] = v
+	}
+	return out
+}
@@ -0,0 +1,71 @@
+package keys
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestApplyKeymapRemapsAction(t *testing.T) {
+	t.Cleanup(func() { require.NoError(t, ApplyKeymap(nil)) })
+
+	require.NoError(t, ApplyKeymap(map[string][]string{"kill": {"ctrl+x", "X"}}))
+
+	assert.Equal(t, KeyKill, GlobalKeyStringsMap["ctrl+x"])
+	assert.Equal(t, KeyKill, GlobalKeyStringsMap["X"])
+	_, stillBound := GlobalKeyStringsMap["D"]
+	assert.False(t, stillBound, "default key should be replaced")
+	assert.Equal(t, "C-x/X", GlobalkeyBindings[KeyKill].Help().Key)
+	assert.Equal(t, "kill", GlobalkeyBindings[KeyKill].Help().Desc)
+}
+
+func TestApplyKeymapMovesKeyBetweenActions(t *testing.T) {
+	t.Cleanup(func() { require.NoError(t, ApplyKeymap(nil)) })
+
+	// "j" can move to kill because down is remapped in the same keymap.
+	require.NoError(t, ApplyKeymap(map[string][]string{
+		"kill": {"j"},
+		"down": {"down"},
+	}))
+	assert.Equal(t, KeyKill, GlobalKeyStringsMap["j"])
+	assert.Equal(t, KeyDown, GlobalKeyStringsMap["down"])
+}
+
+func TestApplyKeymapRejectsInvalidKeymaps(t *testing.T) {
+	t.Cleanup(func() { require.NoError(t, ApplyKeymap(nil)) })
+
+	tests := []struct {
+		name   string
+		keymap map[string][]string
+		errMsg string
+	}{
+		{"conflict with default", map[string][]string{"kill": {"j"}}, `"j" is bound to both kill and down`},
+		{"conflict between remaps", map[string][]string{"kill": {"x"}, "new": {"x"}}, "conflicting"},
+		{"unknown action", map[string][]string{"explode": {"x"}}, "unknown action"},
+		{"no keys", map[string][]string{"kill": {}}, "no keys"},
+		{"unparseable attach key", map[string][]string{"detach": {"f13"}}, "unsupported key"},
+		{"attach conflict", map[string][]string{"detach": {"ctrl+u"}}, "detach"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ApplyKeymap(tt.keymap)
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.errMsg)
+			// The previous bindings are kept.
+			assert.Equal(t, KeyKill, GlobalKeyStringsMap["D"])
+			assert.Equal(t, []string{"ctrl+q"}, AttachKeys(AttachDetach))
+		})
+	}
+}
+
+func TestAttachKeys(t *testing.T) {
+	t.Cleanup(func() { require.NoError(t, ApplyKeymap(nil)) })
+
+	assert.Equal(t, [][]byte{{17}}, AttachSequences(AttachDetach))
+	assert.Equal(t, "ctrl-q", AttachHelp(AttachDetach))
+
+	require.NoError(t, ApplyKeymap(map[string][]string{"detach": {"ctrl+]"}}))
+	assert.Equal(t, [][]byte{{29}}, AttachSequences(AttachDetach))
+	assert.Equal(t, "ctrl-]", AttachHelp(AttachDetach))
+}
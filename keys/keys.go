@@ -24,6 +24,8 @@ const (
     KeyResume
     KeyPrompt // New key for entering a prompt
     KeyHelp   // Key for showing help screen
+    KeyPalette // Key for opening the command palette
+    KeyTheme   // Key for switching the color theme
 
     // Diff keybindings
     KeyShiftUp
@@ -44,6 +46,48 @@ const (
     KeyFileNext
     KeyHunkPrev
     KeyHunkNext
+    KeyDiffLayout
+    KeyDiffCollapse
+    KeyDiffViewed
+    KeyDiffMode
+
+    // Diff review
+    KeyRevertHunk
+    KeyRevertFile
+    KeyApproveHunk
+    KeyComment
+    KeySendReview
+
+    // Forge
+    KeyPullRequest
+    KeyBrowse
+    KeyCleanupMerged
+
+    // Checkpoints
+    KeyCheckpoints
+
+    // Shell
+    KeyShell
+
+    // Checks
+    KeyChecks
+
+    // Notifications
+    KeyMute
+
+    // AutoYes
+    KeyAutoYes
+
+    // Audit log
+    KeyAudit
+
+    // Resource usage
+    KeyResources
+
+    // Commit history
+    KeyCommitSquash
+    KeyCommitReword
+    KeyCommitDrop
 
     // Number selection
     KeyNum1
@@ -58,7 +102,8 @@ const (
     KeyNum0
 )
 
-// GlobalKeyStringsMap is a global, immutable map string to keybinding.
+// GlobalKeyStringsMap is a global map of key string to keybinding. It holds the defaults until
+// ApplyKeymap replaces it with the user's keymap.
 var GlobalKeyStringsMap = map[string]KeyName{
     "up":         KeyUp,
     "k":          KeyUp,
@@ -77,6 +122,9 @@ var GlobalKeyStringsMap = map[string]KeyName{
     "r":          KeyResume,
     "p":          KeySubmit,
     "?":          KeyHelp,
+    "ctrl+p":     KeyPalette,
+    ":":          KeyPalette,
+    "T":          KeyTheme,
 
     // Scroll/navigation
     "pgup":       KeyPgUp,
@@ -93,6 +141,46 @@ var GlobalKeyStringsMap = map[string]KeyName{
     "]":          KeyHunkNext,
     "{":          KeyFilePrev,
     "}":          KeyFileNext,
+    "s":          KeyDiffLayout,
+    "z":          KeyDiffCollapse,
+    "v":          KeyDiffViewed,
+    "m":          KeyDiffMode,
+    "x":          KeyRevertHunk,
+    "R":          KeyRevertFile,
+    "a":          KeyApproveHunk,
+    "C":          KeyComment,
+    "S":          KeySendReview,
+
+    // Forge
+    "P":          KeyPullRequest,
+    "b":          KeyBrowse,
+    "K":          KeyCleanupMerged,
+
+    // Checkpoints
+    "t":          KeyCheckpoints,
+
+    // Shell
+    "!":          KeyShell,
+
+    // Checks
+    "e":          KeyChecks,
+
+    // Notifications
+    "u":          KeyMute,
+
+    // AutoYes
+    "y":          KeyAutoYes,
+
+    // Audit log
+    "A":          KeyAudit,
+
+    // Resource usage
+    "M":          KeyResources,
+
+    // Commit history
+    "f":          KeyCommitSquash,
+    "w":          KeyCommitReword,
+    "d":          KeyCommitDrop,
 
     // Number keys
     "1":          KeyNum1,
@@ -107,7 +195,8 @@ var GlobalKeyStringsMap = map[string]KeyName{
     "0":          KeyNum0,
 }
 
-// GlobalkeyBindings is a global, immutable map of KeyName tot keybinding.
+// GlobalkeyBindings is a global map of KeyName to keybinding. Like GlobalKeyStringsMap, it is
+// replaced by ApplyKeymap.
 var GlobalkeyBindings = map[KeyName]key.Binding{
 	KeyUp: key.NewBinding(
 		key.WithKeys("up", "k"),
@@ -141,6 +230,14 @@ var GlobalkeyBindings = map[KeyName]key.Binding{
 		key.WithKeys("?"),
 		key.WithHelp("?", "help"),
 	),
+	KeyPalette: key.NewBinding(
+		key.WithKeys("ctrl+p", ":"),
+		key.WithHelp("C-p", "commands"),
+	),
+	KeyTheme: key.NewBinding(
+		key.WithKeys("T"),
+		key.WithHelp("T", "theme"),
+	),
 	KeyQuit: key.NewBinding(
 		key.WithKeys("q"),
 		key.WithHelp("q", "quit"),
@@ -223,4 +320,102 @@ var GlobalkeyBindings = map[KeyName]key.Binding{
         key.WithKeys("]"),
         key.WithHelp("]", "next hunk"),
     ),
+    KeyDiffLayout: key.NewBinding(
+        key.WithKeys("s"),
+        key.WithHelp("s", "side-by-side"),
+    ),
+    KeyDiffCollapse: key.NewBinding(
+        key.WithKeys("z"),
+        key.WithHelp("z", "collapse"),
+    ),
+    KeyDiffViewed: key.NewBinding(
+        key.WithKeys("v"),
+        key.WithHelp("v", "viewed"),
+    ),
+    KeyDiffMode: key.NewBinding(
+        key.WithKeys("m"),
+        key.WithHelp("m", "compare"),
+    ),
+    // --- Diff review ---
+    KeyRevertHunk: key.NewBinding(
+        key.WithKeys("x"),
+        key.WithHelp("x", "revert hunk"),
+    ),
+    KeyRevertFile: key.NewBinding(
+        key.WithKeys("R"),
+        key.WithHelp("R", "revert file"),
+    ),
+    KeyApproveHunk: key.NewBinding(
+        key.WithKeys("a"),
+        key.WithHelp("a", "approve"),
+    ),
+    KeyComment: key.NewBinding(
+        key.WithKeys("C"),
+        key.WithHelp("C", "comment"),
+    ),
+    KeySendReview: key.NewBinding(
+        key.WithKeys("S"),
+        key.WithHelp("S", "send review"),
+    ),
+    // --- Forge ---
+    KeyPullRequest: key.NewBinding(
+        key.WithKeys("P"),
+        key.WithHelp("P", "pull request"),
+    ),
+    KeyBrowse: key.NewBinding(
+        key.WithKeys("b"),
+        key.WithHelp("b", "browse"),
+    ),
+    KeyCleanupMerged: key.NewBinding(
+        key.WithKeys("K"),
+        key.WithHelp("K", "clean up merged"),
+    ),
+    // --- Checkpoints ---
+    KeyCheckpoints: key.NewBinding(
+        key.WithKeys("t"),
+        key.WithHelp("t", "checkpoints"),
+    ),
+    // --- Shell ---
+    KeyShell: key.NewBinding(
+        key.WithKeys("!"),
+        key.WithHelp("!", "shell"),
+    ),
+    // --- Checks ---
+    KeyChecks: key.NewBinding(
+        key.WithKeys("e"),
+        key.WithHelp("e", "run checks"),
+    ),
+    // --- Notifications ---
+    KeyMute: key.NewBinding(
+        key.WithKeys("u"),
+        key.WithHelp("u", "mute"),
+    ),
+    // --- AutoYes ---
+    KeyAutoYes: key.NewBinding(
+        key.WithKeys("y"),
+        key.WithHelp("y", "autoyes"),
+    ),
+    // --- Audit log ---
+    KeyAudit: key.NewBinding(
+        key.WithKeys("A"),
+        key.WithHelp("A", "audit log"),
+    ),
+    // --- Resource usage ---
+    KeyResources: key.NewBinding(
+        key.WithKeys("M"),
+        key.WithHelp("M", "resources"),
+    ),
+    // --- Commit history ---
+    KeyCommitSquash: key.NewBinding(
+        key.WithKeys("f"),
+        key.WithHelp("f", "squash"),
+    ),
+    KeyCommitReword: key.NewBinding(
+        key.WithKeys("w"),
+        key.WithHelp("w", "reword"),
+    ),
+    KeyCommitDrop: key.NewBinding(
+        key.WithKeys("d"),
+        key.WithHelp("d", "drop"),
+    ),
 }
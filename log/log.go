@@ -1,51 +1,309 @@
+// Package log writes the logs of the app and the daemon. Logs are structured (text or JSON),
+// leveled and rotated by size, and kept in the logs directory of the config directory. What
+// concerns a single session can also be written to a file of its own.
 package log
 
 import (
+	"context"
+	"errors"
 	"fmt"
+	"io"
 	"log"
+	"log/slog"
+	"net/url"
 	"os"
 	"path/filepath"
+	"strings"
+	"sync"
 	"time"
 )
 
 var (
-	WarningLog *log.Logger
+	// Logger is the structured logger. The printf-style loggers below write to it as well.
+	Logger *slog.Logger
+
+	DebugLog   *log.Logger
 	InfoLog    *log.Logger
+	WarningLog *log.Logger
 	ErrorLog   *log.Logger
 )
 
-var logFileName = filepath.Join(os.TempDir(), "claudesquad.log")
+// Log formats.
+const (
+	FormatText = "text"
+	FormatJSON = "json"
+)
 
-var globalLogFile *os.File
+const (
+	// DefaultMaxSizeMB is the size at which log files are rotated by default.
+	DefaultMaxSizeMB = 10
+	// DefaultMaxFiles is the number of rotated log files kept by default.
+	DefaultMaxFiles = 3
+	// KeepNoFiles is the MaxFiles that keeps no rotated log files.
+	KeepNoFiles = -1
+)
+
+// Options configure logging.
+type Options struct {
+	// Level is "debug", "info", "warn" or "error". Defaults to "info".
+	Level string `json:"level,omitempty"`
+	// Format is "text" or "json". Defaults to "text".
+	Format string `json:"format,omitempty"`
+	// MaxSizeMB is the size in megabytes at which a log file is rotated. Defaults to
+	// DefaultMaxSizeMB.
+	MaxSizeMB int `json:"max_size_mb,omitempty"`
+	// MaxFiles is the number of rotated files kept next to a log file. Defaults to DefaultMaxFiles;
+	// KeepNoFiles starts the log file over without keeping the old one.
+	MaxFiles int `json:"max_files,omitempty"`
+	// PerInstance also writes what concerns a session to a log file of its own.
+	PerInstance bool `json:"per_instance,omitempty"`
+}
+
+// Validate returns an error if the level or format is unknown.
+func (o Options) Validate() error {
+	if _, err := ParseLevel(o.Level); err != nil {
+		return err
+	}
+	if o.Format != "" && o.Format != FormatText && o.Format != FormatJSON {
+		return fmt.Errorf("log format must be %q or %q, not %q", FormatText, FormatJSON, o.Format)
+	}
+	if o.MaxSizeMB < 0 {
+		return fmt.Errorf("max_size_mb must not be negative")
+	}
+	if o.MaxFiles < KeepNoFiles {
+		return fmt.Errorf("max_files must be %d to keep no rotated files, or more", KeepNoFiles)
+	}
+	return nil
+}
+
+// ParseLevel parses a level name. An empty name is the info level.
+func ParseLevel(name string) (slog.Level, error) {
+	switch strings.ToLower(name) {
+	case "debug":
+		return slog.LevelDebug, nil
+	case "", "info":
+		return slog.LevelInfo, nil
+	case "warn", "warning":
+		return slog.LevelWarn, nil
+	case "error":
+		return slog.LevelError, nil
+	default:
+		return 0, fmt.Errorf("unknown log level %q, use debug, info, warn or error", name)
+	}
+}
 
-// Initialize should be called once at the beginning of the program to set up logging.
-// defer Close() after calling this function. It sets the go log output to the file in
-// the os temp directory.
+// AppLogName and DaemonLogName are the log files of the app and the daemon in Dir. They are
+// separate so that each is rotated by a single process.
+const (
+	AppLogName    = "claudesquad.log"
+	DaemonLogName = "daemon.log"
+)
 
-func Initialize(daemon bool) {
-	f, err := os.OpenFile(logFileName, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0666)
+// Dir returns the directory logs are written to: logs in the config directory, or in the temp
+// directory if there is no home directory.
+func Dir() string {
+	home, err := os.UserHomeDir()
 	if err != nil {
+		return filepath.Join(os.TempDir(), "claude-squad-logs")
+	}
+	return filepath.Join(home, ".claude-squad", "logs")
+}
+
+// InstanceLogPath returns the path of the log file of the session with the title.
+func InstanceLogPath(title string) string {
+	return filepath.Join(Dir(), "instances", url.PathEscape(title)+".log")
+}
+
+var (
+	mu        sync.Mutex
+	daemon    bool
+	options   Options
+	level     slog.Level
+	logFile   *rotatingFile
+	instances map[string]*instanceLog
+)
+
+// instanceLog is the logger of a session and its file, which is nil without PerInstance.
+type instanceLog struct {
+	logger *slog.Logger
+	file   *rotatingFile
+}
+
+// Initialize should be called once at the beginning of the program to set up logging with the
+// default options. defer Close() after calling this function. Configure applies the options of
+// the config once it's loaded.
+func Initialize(isDaemon bool) {
+	mu.Lock()
+	defer mu.Unlock()
+	daemon = isDaemon
+	if err := setup(Options{}); err != nil {
 		panic(fmt.Sprintf("could not open log file: %s", err))
 	}
+}
+
+// Configure replaces the options of the logs.
+func Configure(opts Options) error {
+	if err := opts.Validate(); err != nil {
+		return err
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	closeFiles()
+	return setup(opts)
+}
 
-	// Set log format to include timestamp and file/line number
-	log.SetFlags(log.LstdFlags | log.Lshortfile)
+// setup opens the log file and creates the loggers. mu must be held.
+func setup(opts Options) error {
+	if opts.MaxSizeMB == 0 {
+		opts.MaxSizeMB = DefaultMaxSizeMB
+	}
+	switch opts.MaxFiles {
+	case 0:
+		opts.MaxFiles = DefaultMaxFiles
+	case KeepNoFiles:
+		opts.MaxFiles = 0
+	}
+	level, _ = ParseLevel(opts.Level)
+	options = opts
 
-	fmtS := "%s"
+	name := AppLogName
 	if daemon {
-		fmtS = "[DAEMON] %s"
+		name = DaemonLogName
 	}
-	InfoLog = log.New(f, fmt.Sprintf(fmtS, "INFO:"), log.Ldate|log.Ltime|log.Lshortfile)
-	WarningLog = log.New(f, fmt.Sprintf(fmtS, "WARNING:"), log.Ldate|log.Ltime|log.Lshortfile)
-	ErrorLog = log.New(f, fmt.Sprintf(fmtS, "ERROR:"), log.Ldate|log.Ltime|log.Lshortfile)
+	f, err := openRotating(filepath.Join(Dir(), name), int64(opts.MaxSizeMB)<<20, opts.MaxFiles)
+	if err != nil {
+		return err
+	}
+	logFile = f
+	instances = make(map[string]*instanceLog)
 
-	globalLogFile = f
+	Logger = slog.New(newHandler(f))
+	if daemon {
+		Logger = Logger.With("process", "daemon")
+	}
+	DebugLog = slog.NewLogLogger(Logger.Handler(), slog.LevelDebug)
+	InfoLog = slog.NewLogLogger(Logger.Handler(), slog.LevelInfo)
+	WarningLog = slog.NewLogLogger(Logger.Handler(), slog.LevelWarn)
+	ErrorLog = slog.NewLogLogger(Logger.Handler(), slog.LevelError)
+	return nil
+}
+
+// newHandler returns a handler in the configured format that writes to w. Sources are shortened
+// to the file name and line. mu must be held.
+func newHandler(w io.Writer) slog.Handler {
+	opts := &slog.HandlerOptions{
+		AddSource: true,
+		Level:     level,
+		ReplaceAttr: func(groups []string, a slog.Attr) slog.Attr {
+			if a.Key == slog.SourceKey && len(groups) == 0 {
+				if source, ok := a.Value.Any().(*slog.Source); ok {
+					a.Value = slog.StringValue(fmt.Sprintf("%s:%d", filepath.Base(source.File), source.Line))
+				}
+			}
+			return a
+		},
+	}
+	if options.Format == FormatJSON {
+		return slog.NewJSONHandler(w, opts)
+	}
+	return slog.NewTextHandler(w, opts)
+}
+
+// ForInstance returns the logger of the session with the title. Its records name the session
+// and, with PerInstance, are also written to the session's own log file.
+func ForInstance(title string) *slog.Logger {
+	mu.Lock()
+	defer mu.Unlock()
+	if Logger == nil {
+		return slog.New(slog.NewTextHandler(io.Discard, nil))
+	}
+	if l, ok := instances[title]; ok {
+		return l.logger
+	}
+	l := &instanceLog{logger: Logger.With("instance", title)}
+	if options.PerInstance {
+		f, err := openRotating(InstanceLogPath(title), int64(options.MaxSizeMB)<<20, options.MaxFiles)
+		if err != nil {
+			Logger.Warn("failed to open the log file of the instance", "instance", title, "err", err)
+		} else {
+			l.file = f
+			handler := teeHandler{Logger.Handler(), newHandler(f)}
+			l.logger = slog.New(handler).With("instance", title)
+		}
+	}
+	instances[title] = l
+	return l.logger
+}
+
+// teeHandler passes records to all of its handlers.
+type teeHandler []slog.Handler
+
+func (t teeHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	for _, h := range t {
+		if h.Enabled(ctx, level) {
+			return true
+		}
+	}
+	return false
+}
+
+func (t teeHandler) Handle(ctx context.Context, record slog.Record) error {
+	var errs []error
+	for _, h := range t {
+		if h.Enabled(ctx, record.Level) {
+			errs = append(errs, h.Handle(ctx, record.Clone()))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func (t teeHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	handlers := make(teeHandler, len(t))
+	for i, h := range t {
+		handlers[i] = h.WithAttrs(attrs)
+	}
+	return handlers
+}
+
+func (t teeHandler) WithGroup(name string) slog.Handler {
+	handlers := make(teeHandler, len(t))
+	for i, h := range t {
+		handlers[i] = h.WithGroup(name)
+	}
+	return handlers
+}
+
+// CloseInstance closes the log file of the session with the title, e.g. when it's killed.
+func CloseInstance(title string) {
+	mu.Lock()
+	defer mu.Unlock()
+	if l, ok := instances[title]; ok {
+		if l.file != nil {
+			_ = l.file.Close()
+		}
+		delete(instances, title)
+	}
+}
+
+// closeFiles closes the log files. mu must be held.
+func closeFiles() {
+	for _, l := range instances {
+		if l.file != nil {
+			_ = l.file.Close()
+		}
+	}
+	instances = nil
+	if logFile != nil {
+		_ = logFile.Close()
+		logFile = nil
+	}
 }
 
+// Close closes the log files.
 func Close() {
-	_ = globalLogFile.Close()
-	// TODO: maybe only print if verbose flag is set?
-	fmt.Println("wrote logs to " + logFileName)
+	mu.Lock()
+	defer mu.Unlock()
+	closeFiles()
 }
 
 // Every is used to log at most once every timeout duration.
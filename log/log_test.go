@@ -0,0 +1,152 @@
+package log
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRotatingFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "app.log")
+	f, err := openRotating(path, 10, 2)
+	require.NoError(t, err)
+	for _, line := range []string{"one\n", "two\n", "three\n", "four\n", "five\n"} {
+		_, err := f.Write([]byte(line))
+		require.NoError(t, err)
+	}
+	require.NoError(t, f.Close())
+
+	read := func(path string) string {
+		data, err := os.ReadFile(path)
+		require.NoError(t, err)
+		return string(data)
+	}
+	assert.Equal(t, "four\nfive\n", read(path))
+	assert.Equal(t, "three\n", read(path+".1"))
+	assert.Equal(t, "one\ntwo\n", read(path+".2"))
+	assert.NoFileExists(t, path+".3")
+}
+
+func TestRotatingFileKeepsNone(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	Initialize(false)
+	defer Close()
+
+	assert.Error(t, Configure(Options{MaxFiles: -2}))
+	require.NoError(t, Configure(Options{MaxFiles: KeepNoFiles}))
+	mu.Lock()
+	f := logFile
+	mu.Unlock()
+	f.maxSize = 10
+	for _, line := range []string{"one\n", "two\n", "three\n"} {
+		_, err := f.Write([]byte(line))
+		require.NoError(t, err)
+	}
+
+	data, err := os.ReadFile(filepath.Join(Dir(), AppLogName))
+	require.NoError(t, err)
+	assert.Equal(t, "three\n", string(data))
+	assert.NoFileExists(t, filepath.Join(Dir(), AppLogName+".1"))
+}
+
+func TestConfigure(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	Initialize(false)
+	defer Close()
+
+	assert.Error(t, Configure(Options{Level: "verbose"}))
+	assert.Error(t, Configure(Options{Format: "xml"}))
+	require.NoError(t, Configure(Options{Level: "warn", Format: FormatJSON, PerInstance: true}))
+
+	InfoLog.Printf("dropped")
+	WarningLog.Printf("disk almost full")
+	ForInstance("fix/login").Error("failed to pause", "err", "worktree is locked")
+	CloseInstance("fix/login")
+
+	data, err := os.ReadFile(filepath.Join(Dir(), AppLogName))
+	require.NoError(t, err)
+	lines := strings.Split(strings.TrimSpace(string(data)), "\n")
+	require.Len(t, lines, 2)
+	assert.Contains(t, lines[0], `"level":"WARN"`)
+	assert.Contains(t, lines[0], `"msg":"disk almost full"`)
+	assert.Contains(t, lines[0], `"source":"log_test.go:`)
+	assert.Contains(t, lines[1], `"instance":"fix/login"`)
+
+	data, err = os.ReadFile(InstanceLogPath("fix/login"))
+	require.NoError(t, err)
+	assert.Contains(t, string(data), `"msg":"failed to pause"`)
+	assert.Contains(t, string(data), `"err":"worktree is locked"`)
+}
+
+func TestTail(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "app.log")
+	require.NoError(t, os.WriteFile(path, []byte("a\nb\nc\n"), 0600))
+
+	var out bytes.Buffer
+	offset, err := Tail(&out, path, 2)
+	require.NoError(t, err)
+	assert.Equal(t, "b\nc\n", out.String())
+	assert.Equal(t, int64(6), offset)
+
+	out.Reset()
+	_, err = Tail(&out, path, 5)
+	require.NoError(t, err)
+	assert.Equal(t, "a\nb\nc\n", out.String())
+
+	offset, err = Tail(&out, filepath.Join(t.TempDir(), "missing.log"), 2)
+	require.NoError(t, err)
+	assert.Zero(t, offset)
+}
+
+// syncBuffer is a buffer that Follow can write to while the test reads it.
+type syncBuffer struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func (b *syncBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.Write(p)
+}
+
+func (b *syncBuffer) String() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.String()
+}
+
+func TestFollow(t *testing.T) {
+	followInterval = 10 * time.Millisecond
+	path := filepath.Join(t.TempDir(), "app.log")
+	f, err := openRotating(path, 12, 1)
+	require.NoError(t, err)
+	defer f.Close()
+	_, err = f.Write([]byte("old\n"))
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	out := &syncBuffer{}
+	done := make(chan error)
+	go func() { done <- Follow(ctx, out, path, 4) }()
+
+	_, err = f.Write([]byte("new\n"))
+	require.NoError(t, err)
+	require.Eventually(t, func() bool { return out.String() == "new\n" }, time.Second, 5*time.Millisecond)
+
+	// The next line rotates the file.
+	_, err = f.Write([]byte("rotated\n"))
+	require.NoError(t, err)
+	require.Eventually(t, func() bool { return out.String() == "new\nrotated\n" }, time.Second, 5*time.Millisecond)
+
+	cancel()
+	require.NoError(t, <-done)
+}
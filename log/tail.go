@@ -0,0 +1,101 @@
+package log
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"os"
+	"time"
+)
+
+// followInterval is how often Follow looks for new lines.
+var followInterval = 250 * time.Millisecond
+
+// Tail writes the last n lines of the log file at path to w, or all of it if n is 0 or less. It
+// returns the size of the file, where Follow continues. A missing file has no lines.
+func Tail(w io.Writer, path string, n int) (int64, error) {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, err
+	}
+	size := int64(len(data))
+	if n > 0 {
+		data = lastLines(data, n)
+	}
+	_, err = w.Write(data)
+	return size, err
+}
+
+// lastLines returns the last n lines of data.
+func lastLines(data []byte, n int) []byte {
+	end := bytes.TrimSuffix(data, []byte("\n"))
+	for i := 0; i < n; i++ {
+		idx := bytes.LastIndexByte(end, '\n')
+		if idx < 0 {
+			return data
+		}
+		end = end[:idx]
+	}
+	return data[len(end)+1:]
+}
+
+// Follow writes what is appended to the log file at path from offset on to w until ctx is done.
+// When the file is rotated it continues with the new file. It waits for a missing file to be
+// created.
+func Follow(ctx context.Context, w io.Writer, path string, offset int64) error {
+	var f *os.File
+	defer func() {
+		if f != nil {
+			_ = f.Close()
+		}
+	}()
+	ticker := time.NewTicker(followInterval)
+	defer ticker.Stop()
+	for {
+		info, err := os.Stat(path)
+		if err != nil && !errors.Is(err, os.ErrNotExist) {
+			return err
+		}
+		if info != nil {
+			if f != nil {
+				// The file was rotated: finish the old one, then start the new one from the top.
+				if current, err := f.Stat(); err == nil && !os.SameFile(current, info) {
+					if _, err := io.Copy(w, f); err != nil {
+						return err
+					}
+					_ = f.Close()
+					f, offset = nil, 0
+				} else if info.Size() < offset {
+					_ = f.Close()
+					f, offset = nil, 0
+				}
+			}
+			if f == nil {
+				if f, err = os.Open(path); err != nil {
+					return err
+				}
+				if offset > info.Size() {
+					offset = 0
+				}
+				if _, err := f.Seek(offset, io.SeekStart); err != nil {
+					return err
+				}
+			}
+			n, err := io.Copy(w, f)
+			if err != nil {
+				return err
+			}
+			offset += n
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
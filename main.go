@@ -5,14 +5,20 @@ import (
 	cmd2 "claude-squad/cmd"
 	"claude-squad/config"
 	"claude-squad/daemon"
+	"claude-squad/keys"
 	"claude-squad/log"
 	"claude-squad/session"
 	"claude-squad/session/git"
 	"claude-squad/session/tmux"
+	"claude-squad/session/usage"
+	"claude-squad/ui"
 	"context"
 	"encoding/json"
 	"fmt"
+	"os"
+	"os/signal"
 	"path/filepath"
+	"time"
 
 	"github.com/spf13/cobra"
 )
@@ -24,6 +30,7 @@ var (
 	daemonFlag   bool
 	directFlag   bool
 	directBranch string
+	logLevelFlag string
 	rootCmd      = &cobra.Command{
 		Use:   "claude-squad",
 		Short: "Claude Squad - Manage multiple AI agents like Claude Code, Aider, Codex, and Amp.",
@@ -34,6 +41,9 @@ var (
 
 			if daemonFlag {
 				cfg := config.LoadConfig()
+				if err := configureLogging(cfg); err != nil {
+					return err
+				}
 				err := daemon.RunDaemon(cfg)
 				log.ErrorLog.Printf("failed to start daemon %v", err)
 				return err
@@ -50,6 +60,17 @@ var (
 			}
 
 			cfg := config.LoadConfig()
+			if err := configureLogging(cfg); err != nil {
+				return err
+			}
+			if err := keys.ApplyKeymap(cfg.Keymap); err != nil {
+				return fmt.Errorf("invalid config: %w", err)
+			}
+			palette, err := ui.ResolveTheme(cfg.Theme, cfg.Themes)
+			if err != nil {
+				return fmt.Errorf("invalid config: %w", err)
+			}
+			ui.SetTheme(palette)
 
 			// Program flag overrides config
 			program := cfg.DefaultProgram
@@ -67,13 +88,19 @@ var (
 				return fmt.Errorf("direct mode requires a branch name. Use -b or --branch to specify one")
 			}
 
-			if autoYes {
-				defer func() {
-					if err := daemon.LaunchDaemon(); err != nil {
-						log.ErrorLog.Printf("failed to launch daemon: %v", err)
-					}
-				}()
-			}
+			// The daemon keeps answering for the sessions with AutoYes on after we quit, keeps
+			// their timeouts and cost budgets, and notifies about the sessions that need you.
+			defer func() {
+				timeouts := cfg.Timeouts.Enabled() || len(cfg.Timeouts.Repos) > 0
+				budgets := cfg.Usage.SessionBudget > 0 || cfg.Usage.DailyBudget > 0
+				notifications := cfg.Notifications.Background()
+				if !autoYes && !timeouts && !budgets && !notifications && !hasAutoYesInstances() {
+					return
+				}
+				if err := daemon.LaunchDaemon(); err != nil {
+					log.ErrorLog.Printf("failed to launch daemon: %v", err)
+				}
+			}()
 			// Kill any daemon that's running.
 			if err := daemon.StopDaemon(); err != nil {
 				log.ErrorLog.Printf("failed to stop daemon: %v", err)
@@ -136,11 +163,101 @@ var (
 			configJson, _ := json.MarshalIndent(cfg, "", "  ")
 
 			fmt.Printf("Config: %s\n%s\n", filepath.Join(configDir, config.ConfigFileName), configJson)
+			fmt.Printf("Logs: %s\n", log.Dir())
 
 			return nil
 		},
 	}
 
+	auditFormat string
+	auditCmd    = &cobra.Command{
+		Use:   "audit [session]",
+		Short: "Export the audit log of confirmations answered and prompts sent to sessions",
+		Args:  cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			log.Initialize(false)
+			defer log.Close()
+
+			var entries []session.AuditEntry
+			var err error
+			if len(args) == 1 {
+				entries, err = session.ReadAuditLog(args[0])
+			} else {
+				entries, err = session.ReadAuditLogs()
+			}
+			if err != nil {
+				return fmt.Errorf("failed to read the audit log: %w", err)
+			}
+			return session.WriteAuditLog(os.Stdout, entries, auditFormat)
+		},
+	}
+
+	usageBy   string
+	usageDays int
+	usageCmd  = &cobra.Command{
+		Use:   "usage",
+		Short: "Report the tokens and cost sessions used, by session, repository or day",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			log.Initialize(false)
+			defer log.Close()
+
+			dir, err := session.UsageDir()
+			if err != nil {
+				return err
+			}
+			ledgers, err := usage.LoadLedgers(dir)
+			if err != nil {
+				return fmt.Errorf("failed to read the usage: %w", err)
+			}
+			since := ""
+			if usageDays > 0 {
+				since = usage.Day(time.Now().AddDate(0, 0, 1-usageDays))
+			}
+			rows, err := usage.Report(ledgers, usageBy, since)
+			if err != nil {
+				return err
+			}
+			return usage.WriteReport(os.Stdout, usageBy, rows)
+		},
+	}
+
+	logsFollow   bool
+	logsInstance string
+	logsDaemon   bool
+	logsLines    int
+	logsCmd      = &cobra.Command{
+		Use:   "logs",
+		Short: "Print the logs of the app, the daemon or a session",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			path := filepath.Join(log.Dir(), log.AppLogName)
+			switch {
+			case logsInstance != "":
+				path = log.InstanceLogPath(logsInstance)
+			case logsDaemon:
+				path = filepath.Join(log.Dir(), log.DaemonLogName)
+			}
+			if _, err := os.Stat(path); err != nil && !logsFollow {
+				if logsInstance != "" {
+					return fmt.Errorf("no logs for '%s', set log.per_instance in the config to write them", logsInstance)
+				}
+				return fmt.Errorf("no logs at %s", path)
+			}
+
+			offset, err := log.Tail(os.Stdout, path, logsLines)
+			if err != nil {
+				return fmt.Errorf("failed to read the logs: %w", err)
+			}
+			if !logsFollow {
+				return nil
+			}
+			ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+			defer stop()
+			return log.Follow(ctx, os.Stdout, path, offset)
+		},
+	}
+
 	versionCmd = &cobra.Command{
 		Use:   "version",
 		Short: "Print the version number of claude-squad",
@@ -163,15 +280,55 @@ func init() {
 	rootCmd.Flags().StringVarP(&directBranch, "branch", "b", "",
 		"Branch to edit in direct mode (e.g., 'main', 'master', 'feature-branch')")
 
+	rootCmd.PersistentFlags().StringVar(&logLevelFlag, "log-level", "",
+		"Log level: debug, info, warn or error (overrides the config)")
+
 	// Hide the daemonFlag as it's only for internal use
 	err := rootCmd.Flags().MarkHidden("daemon")
 	if err != nil {
 		panic(err)
 	}
 
+	auditCmd.Flags().StringVar(&auditFormat, "format", "json", "Output format: json (one entry per line) or csv")
+	usageCmd.Flags().StringVar(&usageBy, "by", usage.BySession, "Group by session, repo or day")
+	usageCmd.Flags().IntVar(&usageDays, "days", 0, "Only count the last days, including today (0 for all)")
+	logsCmd.Flags().BoolVarP(&logsFollow, "follow", "f", false, "Keep printing new lines")
+	logsCmd.Flags().StringVar(&logsInstance, "instance", "", "Print the logs of the session with this title")
+	logsCmd.Flags().BoolVar(&logsDaemon, "daemon", false, "Print the logs of the background daemon")
+	logsCmd.Flags().IntVarP(&logsLines, "lines", "n", 100, "Number of lines to print (0 for all)")
+
 	rootCmd.AddCommand(debugCmd)
 	rootCmd.AddCommand(versionCmd)
 	rootCmd.AddCommand(resetCmd)
+	rootCmd.AddCommand(auditCmd)
+	rootCmd.AddCommand(usageCmd)
+	rootCmd.AddCommand(logsCmd)
+}
+
+// configureLogging applies the log options of the config, with the level of --log-level.
+func configureLogging(cfg *config.Config) error {
+	opts := cfg.Log
+	if logLevelFlag != "" {
+		opts.Level = logLevelFlag
+	}
+	if err := log.Configure(opts); err != nil {
+		return fmt.Errorf("invalid log config: %w", err)
+	}
+	return nil
+}
+
+// hasAutoYesInstances returns true if AutoYes is on for any stored session.
+func hasAutoYesInstances() bool {
+	storage, err := session.NewStorage(config.LoadState())
+	if err != nil {
+		log.ErrorLog.Printf("failed to initialize storage: %v", err)
+		return false
+	}
+	autoYes, err := storage.HasAutoYes()
+	if err != nil {
+		log.ErrorLog.Printf("failed to read instances: %v", err)
+	}
+	return autoYes
 }
 
 func main() {
@@ -0,0 +1,284 @@
+// Package notify tells the user that a session needs attention: with the terminal bell, desktop
+// notifications through terminal escape sequences or notify-send, or a command of their own.
+package notify
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Notification methods accepted in Config.Methods.
+const (
+	// MethodBell rings the terminal bell.
+	MethodBell = "bell"
+	// MethodOSC9 sends a desktop notification with the OSC 9 escape sequence (iTerm2, kitty,
+	// WezTerm, Windows Terminal).
+	MethodOSC9 = "osc9"
+	// MethodOSC777 sends a desktop notification with the OSC 777 escape sequence (rxvt-unicode,
+	// foot, Ghostty, VTE based terminals).
+	MethodOSC777 = "osc777"
+	// MethodNotifySend runs notify-send, which shows a desktop notification on Linux.
+	MethodNotifySend = "notify-send"
+	// MethodCommand runs Config.Command.
+	MethodCommand = "command"
+)
+
+// DefaultRateLimit is the minimum time between two notifications of the same kind for the same
+// session when Config.RateLimit is zero.
+const DefaultRateLimit = 30 * time.Second
+
+// commandTimeout bounds how long notify-send and the user's command may run.
+const commandTimeout = 10 * time.Second
+
+// Config configures notifications. The zero value turns them off.
+type Config struct {
+	// Methods lists how to notify: "bell", "osc9", "osc777", "notify-send" and "command".
+	Methods []string `json:"methods,omitempty"`
+	// Command is run with the user's shell by the "command" method. The session title, the event
+	// and a message are passed in CS_SESSION, CS_EVENT and CS_MESSAGE.
+	Command string `json:"command,omitempty"`
+	// Events lists the events to notify about, e.g. "ready" and "prompt". Defaults to all.
+	Events []string `json:"events,omitempty"`
+	// RateLimit is the minimum number of seconds between two notifications of the same kind for
+	// the same session. Defaults to 30; a negative value turns the limit off.
+	RateLimit int `json:"rate_limit,omitempty"`
+}
+
+// Background returns true if a method works without a terminal, so that the background daemon can
+// notify after the app quit: notify-send or the command.
+func (c Config) Background() bool {
+	for _, method := range c.Methods {
+		if method == MethodNotifySend || method == MethodCommand {
+			return true
+		}
+	}
+	return false
+}
+
+// Kind is what happened to a session.
+type Kind string
+
+const (
+	// KindReady means the agent finished working and waits for input.
+	KindReady Kind = "ready"
+	// KindPrompt means the agent asks for a confirmation, e.g. to run a command.
+	KindPrompt Kind = "prompt"
+	// KindBudget means AutoYes used up its budget and the agent waits for a confirmation.
+	KindBudget Kind = "budget"
+	// KindLongRunning means the agent has been running for longer than its timeout.
+	KindLongRunning Kind = "long_running"
+	// KindIdlePaused means the session was paused because it was ready for too long.
+	KindIdlePaused Kind = "idle_paused"
+	// KindTimedOut means the session was killed because it was running for too long.
+	KindTimedOut Kind = "timed_out"
+	// KindCostBudget means the session spent more than a cost budget allows.
+	KindCostBudget Kind = "cost_budget"
+)
+
+// Event is something a session needs attention for.
+type Event struct {
+	// Session is the title of the session.
+	Session string
+	Kind    Kind
+}
+
+// Message describes the event in a short sentence.
+func (e Event) Message() string {
+	switch e.Kind {
+	case KindPrompt:
+		return fmt.Sprintf("'%s' is waiting for your confirmation", e.Session)
+	case KindBudget:
+		return fmt.Sprintf("'%s' used up its AutoYes budget and is waiting for your confirmation", e.Session)
+	case KindLongRunning:
+		return fmt.Sprintf("'%s' has been running for longer than its timeout", e.Session)
+	case KindIdlePaused:
+		return fmt.Sprintf("'%s' was idle and has been paused", e.Session)
+	case KindTimedOut:
+		return fmt.Sprintf("'%s' ran for longer than its timeout and has been killed", e.Session)
+	case KindCostBudget:
+		return fmt.Sprintf("'%s' went over a cost budget", e.Session)
+	default:
+		return fmt.Sprintf("'%s' is ready", e.Session)
+	}
+}
+
+// Notifier sends notifications as configured. It is safe for concurrent use.
+type Notifier struct {
+	cfg Config
+	// terminal receives the bell and escape sequences. When nil, e.g. in the daemon, which has no
+	// terminal, those methods are skipped.
+	terminal io.Writer
+	// inTmux wraps escape sequences so that tmux passes them on to the outer terminal.
+	inTmux bool
+
+	mu   sync.Mutex
+	last map[limitKey]time.Time
+	now  func() time.Time
+}
+
+// limitKey is what the rate limit applies to: a ready notification doesn't hold back a prompt.
+type limitKey struct {
+	session string
+	kind    Kind
+}
+
+// New returns a notifier for cfg that writes terminal notifications to terminal, which may be nil.
+func New(cfg Config, terminal io.Writer) *Notifier {
+	return &Notifier{
+		cfg:      cfg,
+		terminal: terminal,
+		inTmux:   os.Getenv("TMUX") != "",
+		last:     make(map[limitKey]time.Time),
+		now:      time.Now,
+	}
+}
+
+// Enabled returns true if any notification method is configured.
+func (n *Notifier) Enabled() bool {
+	return n != nil && len(n.cfg.Methods) > 0
+}
+
+// Notify sends e with every configured method, unless the event kind is not wanted or the session
+// was notified of it less than the rate limit ago. It blocks while notify-send or the command run.
+func (n *Notifier) Notify(e Event) error {
+	if !n.Enabled() || !n.wants(e.Kind) || !n.allow(e) {
+		return nil
+	}
+	var errs []error
+	for _, method := range n.cfg.Methods {
+		if err := n.send(method, e); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", method, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// Forget drops the rate limit state of a session, e.g. after it was killed.
+func (n *Notifier) Forget(session string) {
+	if n == nil {
+		return
+	}
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	for key := range n.last {
+		if key.session == session {
+			delete(n.last, key)
+		}
+	}
+}
+
+func (n *Notifier) wants(kind Kind) bool {
+	if len(n.cfg.Events) == 0 {
+		return true
+	}
+	for _, event := range n.cfg.Events {
+		if Kind(event) == kind {
+			return true
+		}
+	}
+	return false
+}
+
+// allow records the notification of e and returns false if it comes too soon after the previous
+// one of the same kind for the session.
+func (n *Notifier) allow(e Event) bool {
+	limit := DefaultRateLimit
+	if n.cfg.RateLimit < 0 {
+		limit = 0
+	} else if n.cfg.RateLimit > 0 {
+		limit = time.Duration(n.cfg.RateLimit) * time.Second
+	}
+
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	now := n.now()
+	key := limitKey{session: e.Session, kind: e.Kind}
+	if last, ok := n.last[key]; ok && now.Sub(last) < limit {
+		return false
+	}
+	n.last[key] = now
+	return true
+}
+
+func (n *Notifier) send(method string, e Event) error {
+	switch method {
+	case MethodBell:
+		return n.writeTerminal("\a", false)
+	case MethodOSC9:
+		return n.writeTerminal("\x1b]9;"+sanitize(e.Message())+"\a", true)
+	case MethodOSC777:
+		title := "Claude Squad"
+		body := strings.ReplaceAll(sanitize(e.Message()), ";", ",")
+		return n.writeTerminal("\x1b]777;notify;"+title+";"+body+"\a", true)
+	case MethodNotifySend:
+		return run(nil, "notify-send", "Claude Squad", e.Message())
+	case MethodCommand:
+		if n.cfg.Command == "" {
+			return fmt.Errorf("no command configured")
+		}
+		env := []string{
+			"CS_SESSION=" + e.Session,
+			"CS_EVENT=" + string(e.Kind),
+			"CS_MESSAGE=" + e.Message(),
+		}
+		return run(env, shell(), "-c", n.cfg.Command)
+	default:
+		return fmt.Errorf("unknown notification method")
+	}
+}
+
+// writeTerminal writes seq to the terminal. Escape sequences are wrapped in a tmux passthrough
+// when running inside tmux.
+func (n *Notifier) writeTerminal(seq string, escape bool) error {
+	if n.terminal == nil {
+		return nil
+	}
+	if escape && n.inTmux {
+		seq = "\x1bPtmux;" + strings.ReplaceAll(seq, "\x1b", "\x1b\x1b") + "\x1b\\"
+	}
+	_, err := io.WriteString(n.terminal, seq)
+	return err
+}
+
+// run runs a command with a timeout, adding env to the environment. Its output is included in
+// the error.
+func run(env []string, name string, args ...string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), commandTimeout)
+	defer cancel()
+	cmd := exec.CommandContext(ctx, name, args...)
+	if env != nil {
+		cmd.Env = append(os.Environ(), env...)
+	}
+	if out, err := cmd.CombinedOutput(); err != nil {
+		if len(out) > 0 {
+			return fmt.Errorf("%w: %s", err, strings.TrimSpace(string(out)))
+		}
+		return err
+	}
+	return nil
+}
+
+// shell returns the user's shell.
+func shell() string {
+	if shell := os.Getenv("SHELL"); shell != "" {
+		return shell
+	}
+	return "/bin/sh"
+}
+
+// sanitize removes control characters, which would end or corrupt an escape sequence.
+func sanitize(s string) string {
+	return strings.Map(func(r rune) rune {
+		if r < 0x20 || r == 0x7f {
+			return -1
+		}
+		return r
+	}, s)
+}
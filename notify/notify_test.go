@@ -0,0 +1,91 @@
+package notify
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func newTestNotifier(cfg Config) (*Notifier, *bytes.Buffer, *time.Time) {
+	var terminal bytes.Buffer
+	n := New(cfg, &terminal)
+	n.inTmux = false
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	n.now = func() time.Time { return now }
+	return n, &terminal, &now
+}
+
+func TestNotifyTerminal(t *testing.T) {
+	n, terminal, _ := newTestNotifier(Config{Methods: []string{MethodBell, MethodOSC9, MethodOSC777}, RateLimit: -1})
+	require.NoError(t, n.Notify(Event{Session: "fix;\x1bbug", Kind: KindReady}))
+	require.Equal(t, "\a\x1b]9;'fix;bug' is ready\a\x1b]777;notify;Claude Squad;'fix,bug' is ready\a", terminal.String())
+
+	// Inside tmux the escape sequences are passed through to the outer terminal.
+	terminal.Reset()
+	n.inTmux = true
+	require.NoError(t, n.Notify(Event{Session: "a", Kind: KindPrompt}))
+	require.Contains(t, terminal.String(), "\x1bPtmux;\x1b\x1b]9;'a' is waiting for your confirmation\a\x1b\\")
+}
+
+func TestNotifyRateLimit(t *testing.T) {
+	n, terminal, now := newTestNotifier(Config{Methods: []string{MethodBell}, RateLimit: 10})
+	require.NoError(t, n.Notify(Event{Session: "a", Kind: KindReady}))
+	require.NoError(t, n.Notify(Event{Session: "a", Kind: KindReady}))
+	require.NoError(t, n.Notify(Event{Session: "b", Kind: KindReady}))
+	require.Equal(t, 2, strings.Count(terminal.String(), "\a"), "the second ready notification for a is too soon")
+
+	// A prompt right after ready still needs attention.
+	require.NoError(t, n.Notify(Event{Session: "a", Kind: KindPrompt}))
+	require.Equal(t, 3, strings.Count(terminal.String(), "\a"))
+
+	*now = now.Add(11 * time.Second)
+	require.NoError(t, n.Notify(Event{Session: "a", Kind: KindReady}))
+	require.Equal(t, 4, strings.Count(terminal.String(), "\a"))
+
+	// Forgetting a session resets its limit.
+	n.Forget("a")
+	require.NoError(t, n.Notify(Event{Session: "a", Kind: KindReady}))
+	require.Equal(t, 5, strings.Count(terminal.String(), "\a"))
+}
+
+func TestNotifyEvents(t *testing.T) {
+	n, terminal, _ := newTestNotifier(Config{Methods: []string{MethodBell}, Events: []string{"prompt"}, RateLimit: -1})
+	require.NoError(t, n.Notify(Event{Session: "a", Kind: KindReady}))
+	require.Empty(t, terminal.String())
+	require.NoError(t, n.Notify(Event{Session: "a", Kind: KindPrompt}))
+	require.Equal(t, "\a", terminal.String())
+
+	var disabled *Notifier
+	require.False(t, disabled.Enabled())
+	require.False(t, New(Config{}, nil).Enabled())
+}
+
+func TestConfigBackground(t *testing.T) {
+	require.False(t, Config{}.Background())
+	require.False(t, Config{Methods: []string{MethodBell, MethodOSC9}}.Background())
+	require.True(t, Config{Methods: []string{MethodBell, MethodNotifySend}}.Background())
+	require.True(t, Config{Methods: []string{MethodCommand}}.Background())
+}
+
+func TestNotifyCommand(t *testing.T) {
+	out := filepath.Join(t.TempDir(), "out")
+	t.Setenv("SHELL", "/bin/sh")
+	n, _, _ := newTestNotifier(Config{
+		Methods: []string{MethodCommand},
+		Command: `echo "$CS_SESSION|$CS_EVENT|$CS_MESSAGE" > "` + out + `"`,
+	})
+	require.NoError(t, n.Notify(Event{Session: "api", Kind: KindPrompt}))
+	data, err := os.ReadFile(out)
+	require.NoError(t, err)
+	require.Equal(t, "api|prompt|'api' is waiting for your confirmation\n", string(data))
+
+	n, _, _ = newTestNotifier(Config{Methods: []string{MethodCommand, "smoke-signal"}, Command: "exit 2"})
+	err = n.Notify(Event{Session: "api", Kind: KindReady})
+	require.ErrorContains(t, err, "command: exit status 2")
+	require.ErrorContains(t, err, "smoke-signal: unknown notification method")
+}
@@ -0,0 +1,149 @@
+package session
+
+import (
+	"claude-squad/config"
+	"claude-squad/session/events"
+	"claude-squad/session/policy"
+	"time"
+)
+
+// autoYesPolicy decides which confirmations AutoYes may answer. Without it AutoYes approves
+// everything.
+var autoYesPolicy *policy.Engine
+
+// SetPolicy sets the policy that AutoYes answers confirmations with.
+func SetPolicy(engine *policy.Engine) {
+	autoYesPolicy = engine
+}
+
+// autoYesBudget limits AutoYes per instance.
+var autoYesBudget config.AutoYesBudget
+
+// SetAutoYesBudget sets the budget after which instances drop back to manual approval.
+func SetAutoYesBudget(budget config.AutoYesBudget) {
+	autoYesBudget = budget
+}
+
+// SetAutoYes turns AutoYes on or off for the instance. Turning it on starts a new budget.
+func (i *Instance) SetAutoYes(on bool) {
+	i.AutoYes = on
+	i.AutoYesApprovals = 0
+	i.AutoYesSince = time.Time{}
+	if on {
+		i.AutoYesSince = time.Now()
+	}
+}
+
+// AutoYesRemaining returns the approvals and the time left in the instance's AutoYes budget. They
+// are negative if the budget doesn't limit them.
+func (i *Instance) AutoYesRemaining(now time.Time) (approvals int, left time.Duration) {
+	approvals, left = -1, -1
+	if autoYesBudget.Approvals > 0 {
+		approvals = max(autoYesBudget.Approvals-i.AutoYesApprovals, 0)
+	}
+	if autoYesBudget.Minutes > 0 {
+		left = max(i.AutoYesSince.Add(time.Duration(autoYesBudget.Minutes)*time.Minute).Sub(now), 0)
+	}
+	return approvals, left
+}
+
+// budgetUsedUp returns true if the instance has no approvals or time left.
+func (i *Instance) budgetUsedUp(now time.Time) bool {
+	approvals, left := i.AutoYesRemaining(now)
+	return approvals == 0 || left == 0
+}
+
+// PromptAnswer is how AutoYes handled a confirmation.
+type PromptAnswer struct {
+	Decision policy.Decision
+	// Action is the confirmed action. It is empty if it could not be read from the pane.
+	Action policy.Action
+	// Rule is the policy rule that decided, or nil if no rule matched.
+	Rule *policy.Rule
+	// Keys are the keys sent to the agent. They are empty when the user is asked.
+	Keys string
+	// BudgetUsedUp is true if AutoYes was turned off because the instance used up its budget. The
+	// confirmation is left to the user.
+	BudgetUsedUp bool
+}
+
+// AnswerPrompt answers the pending confirmation if AutoYes is enabled. Actions the policy allows
+// are approved, denied ones are rejected and the rest is left to the user. Once the instance used
+// up its budget, AutoYes is turned off and the confirmation left to the user as well. It returns
+// false if nothing was decided, e.g. because the confirmation was already left to the user.
+func (i *Instance) AnswerPrompt() (PromptAnswer, bool) {
+	if !i.started || !i.AutoYes || i.escalated.Load() {
+		return PromptAnswer{}, false
+	}
+
+	content, _, _, err := i.tmuxSession.CaptureUnified(false, 0)
+	if err != nil {
+		i.logger().Error("failed to capture the confirmation", "err", err)
+		return PromptAnswer{}, false
+	}
+	adapter := policy.AdapterFor(i.Program)
+	answer := PromptAnswer{Decision: policy.Allow, Keys: "\r"}
+	if adapter != nil {
+		answer.Action, _ = adapter.PendingAction(content)
+	}
+	entry := AuditEntry{Source: AuditAutoYes, Prompt: policy.Excerpt(content, auditExcerptLines)}
+	if answer.Action.Tool != "" {
+		entry.Action = answer.Action.String()
+	}
+
+	if i.budgetUsedUp(time.Now()) {
+		i.SetAutoYes(false)
+		answer = PromptAnswer{Decision: policy.Ask, Action: answer.Action, BudgetUsedUp: true}
+		entry.Decision = string(answer.Decision)
+		entry.Rule = "budget used up"
+		i.Audit(entry)
+		return answer, true
+	}
+	if autoYesPolicy != nil {
+		entry.Source = AuditPolicy
+		answer.Decision, answer.Rule = autoYesPolicy.Evaluate(i.Path, answer.Action)
+		switch {
+		case answer.Decision == policy.Deny && adapter != nil:
+			answer.Keys = adapter.DenyKeys()
+		case answer.Decision != policy.Allow:
+			// We don't know how to reject the confirmations of unknown agents.
+			answer.Decision = policy.Ask
+			answer.Keys = ""
+		}
+	}
+
+	event := events.Event{Action: entry.Action}
+	switch answer.Decision {
+	case policy.Allow:
+		if err := i.tmuxSession.TapEnter(); err != nil {
+			i.logger().Error("failed to approve the confirmation", "err", err)
+			return PromptAnswer{}, false
+		}
+		i.AutoYesApprovals++
+		event.Type = events.AutoApproved
+	case policy.Deny:
+		if err := i.tmuxSession.SendKeys(answer.Keys); err != nil {
+			i.logger().Error("failed to reject the confirmation", "err", err)
+			return PromptAnswer{}, false
+		}
+		event.Type = events.AutoDenied
+	default:
+		i.escalated.Store(true)
+		event.Type = events.ApprovalNeeded
+	}
+	i.publish(event)
+
+	entry.Decision = string(answer.Decision)
+	entry.Keys = answer.Keys
+	if answer.Rule != nil {
+		entry.Rule = answer.Rule.String()
+	}
+	i.Audit(entry)
+	return answer, true
+}
+
+// AwaitingApproval returns true while the AutoYes policy leaves the current confirmation to the
+// user.
+func (i *Instance) AwaitingApproval() bool {
+	return i.escalated.Load()
+}
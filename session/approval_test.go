@@ -0,0 +1,55 @@
+package session
+
+import (
+	"claude-squad/config"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestAutoYesBudget(t *testing.T) {
+	defer SetAutoYesBudget(config.AutoYesBudget{})
+
+	instance := &Instance{Title: "api"}
+	instance.SetAutoYes(true)
+	require.True(t, instance.AutoYes)
+	now := instance.AutoYesSince
+
+	// Without a budget nothing is limited.
+	approvals, left := instance.AutoYesRemaining(now.Add(24 * time.Hour))
+	require.Equal(t, -1, approvals)
+	require.Equal(t, time.Duration(-1), left)
+	require.False(t, instance.budgetUsedUp(now.Add(24*time.Hour)))
+
+	SetAutoYesBudget(config.AutoYesBudget{Approvals: 3, Minutes: 10})
+	instance.AutoYesApprovals = 2
+	approvals, left = instance.AutoYesRemaining(now.Add(4 * time.Minute))
+	require.Equal(t, 1, approvals)
+	require.Equal(t, 6*time.Minute, left)
+	require.False(t, instance.budgetUsedUp(now.Add(4*time.Minute)))
+	require.True(t, instance.budgetUsedUp(now.Add(11*time.Minute)), "out of time")
+
+	instance.AutoYesApprovals = 3
+	require.True(t, instance.budgetUsedUp(now), "out of approvals")
+
+	// Turning AutoYes on again starts a new budget.
+	instance.SetAutoYes(false)
+	require.True(t, instance.AutoYesSince.IsZero())
+	instance.SetAutoYes(true)
+	require.Zero(t, instance.AutoYesApprovals)
+	require.False(t, instance.budgetUsedUp(time.Now()))
+}
+
+func TestAutoYesPersisted(t *testing.T) {
+	since := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	instance := &Instance{Title: "api", AutoYes: true, AutoYesSince: since, AutoYesApprovals: 4}
+	data := instance.ToInstanceData()
+	require.Equal(t, 4, data.Approvals)
+
+	restored, err := FromInstanceData(data)
+	require.NoError(t, err)
+	require.True(t, restored.AutoYes)
+	require.Equal(t, since, restored.AutoYesSince)
+	require.Equal(t, 4, restored.AutoYesApprovals)
+}
@@ -0,0 +1,194 @@
+package session
+
+import (
+	"bufio"
+	"claude-squad/config"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Sources of audit entries.
+const (
+	// AuditAutoYes means AutoYes approved a confirmation without a policy.
+	AuditAutoYes = "autoyes"
+	// AuditPolicy means the AutoYes policy decided a confirmation.
+	AuditPolicy = "policy"
+	// AuditUser means the user sent the keys from Claude Squad.
+	AuditUser = "user"
+	// AuditFixLoop means the fix loop sent failing check output.
+	AuditFixLoop = "fix_loop"
+)
+
+// auditExcerptLines is the number of pane lines recorded with a confirmation.
+const auditExcerptLines = 20
+
+// AuditEntry records keys sent to a session's agent on its behalf or by the user.
+type AuditEntry struct {
+	Time    time.Time `json:"time"`
+	Session string    `json:"session"`
+	Branch  string    `json:"branch,omitempty"`
+	// Source is who decided to send the keys: "autoyes", "policy", "user" or "fix_loop".
+	Source string `json:"source"`
+	// Decision is "allow", "deny" or "ask" for confirmations.
+	Decision string `json:"decision,omitempty"`
+	// Rule is the policy rule that decided, if any.
+	Rule string `json:"rule,omitempty"`
+	// Action is the confirmed action, e.g. "Bash: go test ./...".
+	Action string `json:"action,omitempty"`
+	// Keys are the keys sent to the agent. They are empty if the user was asked.
+	Keys string `json:"keys"`
+	// Prompt is the end of the pane when the confirmation was answered.
+	Prompt string `json:"prompt,omitempty"`
+}
+
+// DescribeKeys returns the keys with enter and escape spelled out, e.g. "n<enter>".
+func DescribeKeys(keys string) string {
+	if keys == "" {
+		return "none"
+	}
+	return strings.NewReplacer("\r", "<enter>", "\x1b", "<esc>").Replace(keys)
+}
+
+func auditDir() (string, error) {
+	configDir, err := config.GetConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(configDir, "audit"), nil
+}
+
+// AuditLogPath returns the path of the audit log of the session with the title.
+func AuditLogPath(title string) (string, error) {
+	dir, err := auditDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, url.PathEscape(title)+".jsonl"), nil
+}
+
+// Audit appends the entry to the instance's audit log. Failures are only logged: they must not
+// keep keys from being sent.
+func (i *Instance) Audit(entry AuditEntry) {
+	if entry.Time.IsZero() {
+		entry.Time = time.Now()
+	}
+	entry.Session = i.Title
+	entry.Branch = i.Branch
+	if err := appendAuditEntry(entry); err != nil {
+		i.logger().Warn("failed to write the audit log", "err", err)
+	}
+}
+
+func appendAuditEntry(entry AuditEntry) error {
+	path, err := AuditLogPath(entry.Session)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	line, err := json.Marshal(entry)
+	if err != nil {
+		return err
+	}
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0600)
+	if err != nil {
+		return err
+	}
+	if _, err := f.Write(append(line, '\n')); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// ReadAuditLog returns the audit entries of the session with the title, oldest first.
+func ReadAuditLog(title string) ([]AuditEntry, error) {
+	path, err := AuditLogPath(title)
+	if err != nil {
+		return nil, err
+	}
+	entries, err := readAuditFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	return entries, err
+}
+
+// ReadAuditLogs returns the audit entries of all sessions, including killed ones, oldest first.
+func ReadAuditLogs() ([]AuditEntry, error) {
+	dir, err := auditDir()
+	if err != nil {
+		return nil, err
+	}
+	paths, err := filepath.Glob(filepath.Join(dir, "*.jsonl"))
+	if err != nil {
+		return nil, err
+	}
+	var all []AuditEntry
+	for _, path := range paths {
+		entries, err := readAuditFile(path)
+		if err != nil {
+			return nil, err
+		}
+		all = append(all, entries...)
+	}
+	sort.SliceStable(all, func(a, b int) bool { return all[a].Time.Before(all[b].Time) })
+	return all, nil
+}
+
+// WriteAuditLog writes the entries to w as JSON lines ("json") or as CSV with a header ("csv").
+func WriteAuditLog(w io.Writer, entries []AuditEntry, format string) error {
+	switch format {
+	case "json":
+		enc := json.NewEncoder(w)
+		for _, entry := range entries {
+			if err := enc.Encode(entry); err != nil {
+				return err
+			}
+		}
+		return nil
+	case "csv":
+		cw := csv.NewWriter(w)
+		_ = cw.Write([]string{"time", "session", "branch", "source", "decision", "rule", "action", "keys", "prompt"})
+		for _, e := range entries {
+			_ = cw.Write([]string{e.Time.Format(time.RFC3339), e.Session, e.Branch, e.Source, e.Decision, e.Rule, e.Action, e.Keys, e.Prompt})
+		}
+		cw.Flush()
+		return cw.Error()
+	default:
+		return fmt.Errorf("unknown format %q, use json or csv", format)
+	}
+}
+
+func readAuditFile(path string) ([]AuditEntry, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var entries []AuditEntry
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 64*1024), 4*1024*1024)
+	for n := 1; scanner.Scan(); n++ {
+		if len(scanner.Bytes()) == 0 {
+			continue
+		}
+		var entry AuditEntry
+		if err := json.Unmarshal(scanner.Bytes(), &entry); err != nil {
+			return nil, fmt.Errorf("%s:%d: %w", path, n, err)
+		}
+		entries = append(entries, entry)
+	}
+	return entries, scanner.Err()
+}
@@ -0,0 +1,59 @@
+package session
+
+import (
+	"bytes"
+	"encoding/csv"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestAuditLog(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+
+	entries, err := ReadAuditLog("api/v2")
+	require.NoError(t, err)
+	require.Empty(t, entries)
+
+	api := &Instance{Title: "api/v2", Branch: "cs/api"}
+	web := &Instance{Title: "web", Branch: "cs/web"}
+	start := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	api.Audit(AuditEntry{Time: start, Source: AuditPolicy, Decision: "deny", Rule: `deny Bash command "rm *"`, Action: "Bash: rm -rf build", Keys: "\x1b"})
+	web.Audit(AuditEntry{Time: start.Add(time.Minute), Source: AuditUser, Action: "prompt", Keys: "fix the tests\r"})
+	api.Audit(AuditEntry{Time: start.Add(2 * time.Minute), Source: AuditAutoYes, Decision: "allow", Keys: "\r"})
+
+	path, err := AuditLogPath("api/v2")
+	require.NoError(t, err)
+	info, err := os.Stat(path)
+	require.NoError(t, err, "the title is escaped into a single file name")
+	require.Equal(t, os.FileMode(0600), info.Mode().Perm())
+
+	entries, err = ReadAuditLog("api/v2")
+	require.NoError(t, err)
+	require.Len(t, entries, 2)
+	require.Equal(t, "cs/api", entries[0].Branch)
+	require.Equal(t, "deny", entries[0].Decision)
+	require.Equal(t, AuditAutoYes, entries[1].Source)
+
+	all, err := ReadAuditLogs()
+	require.NoError(t, err)
+	require.Len(t, all, 3)
+	require.Equal(t, []string{"api/v2", "web", "api/v2"}, []string{all[0].Session, all[1].Session, all[2].Session})
+
+	var out bytes.Buffer
+	require.NoError(t, WriteAuditLog(&out, all, "csv"))
+	records, err := csv.NewReader(&out).ReadAll()
+	require.NoError(t, err)
+	require.Len(t, records, 4)
+	require.Equal(t, []string{"2024-01-01T12:01:00Z", "web", "cs/web", "user", "", "", "prompt", "fix the tests\r", ""}, records[2])
+
+	out.Reset()
+	require.NoError(t, WriteAuditLog(&out, all[:1], "json"))
+	require.Contains(t, out.String(), `"keys":"\u001b"`)
+	require.ErrorContains(t, WriteAuditLog(&out, all, "xml"), `unknown format "xml"`)
+
+	require.Equal(t, "n<enter>", DescribeKeys("n\r"))
+	require.Equal(t, "none", DescribeKeys(""))
+}
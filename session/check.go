@@ -0,0 +1,148 @@
+package session
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os/exec"
+	"time"
+)
+
+// maxCheckOutput is the number of bytes of check output that are kept. Older output is dropped.
+const maxCheckOutput = 1 << 20
+
+// CheckRun is a run of the check command, e.g. the tests, in an instance's worktree.
+type CheckRun struct {
+	// Command is the shell command that was run.
+	Command string
+	// StartedAt is when the run started.
+	StartedAt time.Time
+	// Duration is how long the run took. It is zero while the check is running.
+	Duration time.Duration
+	// Running is true until the command exits.
+	Running bool
+	// ExitCode is the exit code of the command, or -1 if it was killed or failed to run.
+	ExitCode int
+	// Canceled is true if the run was canceled or timed out.
+	Canceled bool
+}
+
+// Passed returns true if the command finished on its own and exited with 0.
+func (r CheckRun) Passed() bool {
+	return !r.Running && !r.Canceled && r.ExitCode == 0
+}
+
+// Elapsed returns how long the run took, or how long it has been running.
+func (r CheckRun) Elapsed() time.Duration {
+	if r.Running {
+		return time.Since(r.StartedAt)
+	}
+	return r.Duration
+}
+
+// checkRun is the state of an instance's check. It is guarded by Instance.checkMu.
+type checkRun struct {
+	run    CheckRun
+	output []byte
+	cancel context.CancelFunc
+}
+
+// checkWriter collects the output of a check, keeping the last maxCheckOutput bytes.
+type checkWriter struct {
+	instance *Instance
+	run      *checkRun
+}
+
+func (w *checkWriter) Write(p []byte) (int, error) {
+	w.instance.checkMu.Lock()
+	defer w.instance.checkMu.Unlock()
+	w.run.output = append(w.run.output, p...)
+	if over := len(w.run.output) - maxCheckOutput; over > 0 {
+		w.run.output = w.run.output[over:]
+	}
+	return len(p), nil
+}
+
+// StartCheck starts command with the user's shell in the instance's worktree. It returns a
+// function that waits for the command to exit and returns the finished run. Canceling ctx or
+// calling CancelCheck stops the command.
+func (i *Instance) StartCheck(ctx context.Context, command string) (func() CheckRun, error) {
+	if !i.started || i.Status == Paused {
+		return nil, fmt.Errorf("cannot run checks for an instance that has not been started or is paused")
+	}
+	i.checkMu.Lock()
+	defer i.checkMu.Unlock()
+	if i.check != nil && i.check.run.Running {
+		return nil, fmt.Errorf("a check is already running for '%s'", i.Title)
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	run := &checkRun{
+		run:    CheckRun{Command: command, StartedAt: time.Now(), Running: true},
+		cancel: cancel,
+	}
+	cmd := exec.CommandContext(ctx, shellProgram(), "-c", command)
+	cmd.Dir = i.gitWorktree.GetWorktreePath()
+	output := &checkWriter{instance: i, run: run}
+	cmd.Stdout = output
+	cmd.Stderr = output
+	// Don't wait for leftover children that still hold the output pipe after a cancel.
+	cmd.WaitDelay = time.Second
+	killProcessGroup(cmd)
+	if err := cmd.Start(); err != nil {
+		cancel()
+		return nil, fmt.Errorf("failed to start check: %w", err)
+	}
+	i.check = run
+
+	return func() CheckRun {
+		err := cmd.Wait()
+		defer cancel()
+
+		i.checkMu.Lock()
+		defer i.checkMu.Unlock()
+		run.run.Running = false
+		run.run.Duration = time.Since(run.run.StartedAt)
+		run.run.Canceled = ctx.Err() != nil
+		run.run.ExitCode = -1
+		if cmd.ProcessState != nil {
+			run.run.ExitCode = cmd.ProcessState.ExitCode()
+		}
+		var exitErr *exec.ExitError
+		if err != nil && !errors.As(err, &exitErr) && !run.run.Canceled {
+			run.output = append(run.output, fmt.Sprintf("\n%v\n", err)...)
+		}
+		return run.run
+	}, nil
+}
+
+// CancelCheck stops the running check. It returns false if no check is running.
+func (i *Instance) CancelCheck() bool {
+	i.checkMu.Lock()
+	defer i.checkMu.Unlock()
+	if i.check == nil || !i.check.run.Running {
+		return false
+	}
+	i.check.cancel()
+	return true
+}
+
+// LastCheck returns the running or the last finished check, or false if no check has run.
+func (i *Instance) LastCheck() (CheckRun, bool) {
+	i.checkMu.Lock()
+	defer i.checkMu.Unlock()
+	if i.check == nil {
+		return CheckRun{}, false
+	}
+	return i.check.run, true
+}
+
+// CheckOutput returns the combined output of the running or the last finished check.
+func (i *Instance) CheckOutput() string {
+	i.checkMu.Lock()
+	defer i.checkMu.Unlock()
+	if i.check == nil {
+		return ""
+	}
+	return string(i.check.output)
+}
@@ -0,0 +1,73 @@
+package session
+
+import (
+	"claude-squad/session/git"
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func newCheckInstance(t *testing.T) *Instance {
+	t.Helper()
+	dir := t.TempDir()
+	return &Instance{
+		Title:       "checks",
+		Path:        dir,
+		Status:      Ready,
+		started:     true,
+		gitWorktree: git.NewGitWorktreeFromStorage(dir, dir, "checks", "me/checks", ""),
+	}
+}
+
+func TestCheckRunsInWorktree(t *testing.T) {
+	inst := newCheckInstance(t)
+	require.NoError(t, os.WriteFile(filepath.Join(inst.Path, "marker.txt"), []byte("here\n"), 0644))
+
+	_, ok := inst.LastCheck()
+	require.False(t, ok)
+
+	wait, err := inst.StartCheck(context.Background(), "cat marker.txt; echo oops >&2; exit 3")
+	require.NoError(t, err)
+	_, err = inst.StartCheck(context.Background(), "true")
+	require.Error(t, err, "a second check must not start while one is running")
+
+	run := wait()
+	require.False(t, run.Running)
+	require.False(t, run.Passed())
+	require.Equal(t, 3, run.ExitCode)
+	require.Contains(t, inst.CheckOutput(), "here\n")
+	require.Contains(t, inst.CheckOutput(), "oops\n")
+
+	last, ok := inst.LastCheck()
+	require.True(t, ok)
+	require.Equal(t, run, last)
+
+	wait, err = inst.StartCheck(context.Background(), "true")
+	require.NoError(t, err)
+	require.True(t, wait().Passed())
+	require.Empty(t, inst.CheckOutput())
+}
+
+func TestCancelCheck(t *testing.T) {
+	inst := newCheckInstance(t)
+	require.False(t, inst.CancelCheck())
+
+	// The sleep runs in a child of the shell, which must be killed too.
+	wait, err := inst.StartCheck(context.Background(), "sleep 30; echo done")
+	require.NoError(t, err)
+	run, _ := inst.LastCheck()
+	require.True(t, run.Running)
+
+	start := time.Now()
+	require.True(t, inst.CancelCheck())
+	run = wait()
+	require.Less(t, time.Since(start), 10*time.Second)
+	require.True(t, run.Canceled)
+	require.False(t, run.Passed())
+	require.NotContains(t, inst.CheckOutput(), "done")
+	require.False(t, inst.CancelCheck())
+}
@@ -0,0 +1,17 @@
+//go:build !windows
+
+package session
+
+import (
+	"os/exec"
+	"syscall"
+)
+
+// killProcessGroup runs cmd in its own process group and makes canceling it kill the whole group,
+// so that e.g. test binaries started by the check don't outlive it.
+func killProcessGroup(cmd *exec.Cmd) {
+	cmd.SysProcAttr = &syscall.SysProcAttr{Setpgid: true}
+	cmd.Cancel = func() error {
+		return syscall.Kill(-cmd.Process.Pid, syscall.SIGKILL)
+	}
+}
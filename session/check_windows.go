@@ -0,0 +1,8 @@
+//go:build windows
+
+package session
+
+import "os/exec"
+
+// killProcessGroup is a no-op on Windows, where canceling cmd only kills the shell.
+func killProcessGroup(cmd *exec.Cmd) {}
@@ -0,0 +1,39 @@
+package session
+
+import (
+	"claude-squad/session/git"
+)
+
+// Checkpoint snapshots the instance's worktree and prunes checkpoints beyond the newest keep. It
+// returns false if nothing changed since the latest checkpoint.
+func (i *Instance) Checkpoint(reason string, keep int) (bool, error) {
+	gw, err := i.worktreeForHistory()
+	if err != nil {
+		return false, err
+	}
+	_, created, err := gw.CreateCheckpoint(reason)
+	if err != nil || !created {
+		return false, err
+	}
+	return true, gw.PruneCheckpoints(keep)
+}
+
+// Checkpoints returns the checkpoints of the instance, newest first.
+func (i *Instance) Checkpoints() ([]git.Checkpoint, error) {
+	gw, err := i.worktreeForHistory()
+	if err != nil {
+		return nil, err
+	}
+	return gw.Checkpoints()
+}
+
+// RestoreCheckpoint makes the instance's worktree match cp. The current state is checkpointed
+// first, so the restore can be undone.
+func (i *Instance) RestoreCheckpoint(cp git.Checkpoint) error {
+	gw, err := i.worktreeForHistory()
+	if err != nil {
+		return err
+	}
+	_, err = gw.RestoreCheckpoint(cp)
+	return err
+}
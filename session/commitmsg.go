@@ -0,0 +1,235 @@
+package session
+
+import (
+	"bytes"
+	"claude-squad/log"
+	"claude-squad/session/git"
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"text/template"
+	"time"
+)
+
+// DefaultCommitMessageTemplate is used when no commit message template is configured.
+const DefaultCommitMessageTemplate = "{{.Summary}}"
+
+// commitMessageTemplate is the configured commit message template, used when the user isn't asked
+// for a message, e.g. when a session is paused.
+var commitMessageTemplate string
+
+// SetCommitMessageTemplate sets the commit message template of commits the user isn't asked about.
+// An empty template is DefaultCommitMessageTemplate.
+func SetCommitMessageTemplate(tmpl string) {
+	commitMessageTemplate = tmpl
+}
+
+// maxGeneratorDiff limits the size of the diff handed to a commit message generator.
+const maxGeneratorDiff = 100_000
+
+// commitMessagePrompt asks an agent to summarize the diff it gets on stdin.
+const commitMessagePrompt = "Write a git commit message for the diff on stdin. Reply with the message only: " +
+	"a summary line of at most 72 characters in the imperative mood, optionally followed by a blank line and a short body."
+
+// CommitMessageData is the data available to commit message templates.
+type CommitMessageData struct {
+	// Title is the session title.
+	Title  string
+	Branch string
+	// Summary describes the change. It is generated when a generator is configured and lists the
+	// changed files otherwise.
+	Summary string
+	// Files are the paths of the changed files.
+	Files []string
+	Date  time.Time
+}
+
+// NewCommitMessageData describes the changes in diff for a commit message template.
+func NewCommitMessageData(title, branch, diff string) CommitMessageData {
+	data := CommitMessageData{Title: title, Branch: branch, Date: time.Now()}
+	files := git.ParseDiff(diff)
+	var added, deleted, updated []string
+	for i := range files {
+		f := &files[i]
+		data.Files = append(data.Files, f.Path())
+		switch {
+		case f.IsNew():
+			added = append(added, f.Path())
+		case f.IsDeleted():
+			deleted = append(deleted, f.Path())
+		default:
+			updated = append(updated, f.Path())
+		}
+	}
+
+	var parts []string
+	for _, group := range []struct {
+		verb  string
+		paths []string
+	}{{"Update", updated}, {"Add", added}, {"Delete", deleted}} {
+		if len(group.paths) == 0 {
+			continue
+		}
+		verb := group.verb
+		if len(parts) > 0 {
+			verb = strings.ToLower(verb)
+		}
+		parts = append(parts, verb+" "+describePaths(group.paths))
+	}
+	if len(parts) == 0 {
+		data.Summary = fmt.Sprintf("Update from '%s'", title)
+	} else {
+		data.Summary = strings.Join(parts, ", ")
+	}
+	return data
+}
+
+// describePaths names up to three files and counts the rest.
+func describePaths(paths []string) string {
+	switch len(paths) {
+	case 1:
+		return paths[0]
+	case 2, 3:
+		return strings.Join(paths[:len(paths)-1], ", ") + " and " + paths[len(paths)-1]
+	default:
+		return fmt.Sprintf("%d files", len(paths))
+	}
+}
+
+// RenderCommitMessage executes a commit message template. An empty template uses
+// DefaultCommitMessageTemplate.
+func RenderCommitMessage(tmpl string, data CommitMessageData) (string, error) {
+	if strings.TrimSpace(tmpl) == "" {
+		tmpl = DefaultCommitMessageTemplate
+	}
+	t, err := template.New("commit").Parse(tmpl)
+	if err != nil {
+		return "", fmt.Errorf("invalid commit message template: %w", err)
+	}
+	var buf bytes.Buffer
+	if err := t.Execute(&buf, data); err != nil {
+		return "", fmt.Errorf("failed to render commit message template: %w", err)
+	}
+	message := strings.TrimSpace(buf.String())
+	if message == "" {
+		return "", fmt.Errorf("commit message template rendered an empty message")
+	}
+	return message, nil
+}
+
+// CommitMessageGenerator summarizes a diff for a commit message.
+type CommitMessageGenerator interface {
+	GenerateCommitMessage(ctx context.Context, diff string) (string, error)
+}
+
+// NewCommitMessageGenerator returns the generator for a commit_message_generator setting: "agent"
+// asks the agent program to summarize the diff, any other value is a shell command that reads the
+// diff on stdin and prints the message. It returns nil when setting is empty.
+func NewCommitMessageGenerator(setting, program string) CommitMessageGenerator {
+	switch strings.TrimSpace(setting) {
+	case "":
+		return nil
+	case "agent":
+		return agentGenerator{program: program}
+	default:
+		return commandGenerator{command: setting}
+	}
+}
+
+// commandGenerator runs a shell command with the diff on stdin.
+type commandGenerator struct {
+	command string
+}
+
+func (g commandGenerator) GenerateCommitMessage(ctx context.Context, diff string) (string, error) {
+	shell := os.Getenv("SHELL")
+	if shell == "" {
+		shell = "/bin/sh"
+	}
+	return runGenerator(exec.CommandContext(ctx, shell, "-c", g.command), diff)
+}
+
+// agentGenerator runs the agent program in its non-interactive mode.
+type agentGenerator struct {
+	program string
+}
+
+func (g agentGenerator) GenerateCommitMessage(ctx context.Context, diff string) (string, error) {
+	args, err := agentSummaryArgs(g.program)
+	if err != nil {
+		return "", err
+	}
+	return runGenerator(exec.CommandContext(ctx, args[0], args[1:]...), diff)
+}
+
+// agentSummaryArgs returns the command line that makes program answer commitMessagePrompt
+// without starting an interactive session.
+func agentSummaryArgs(program string) ([]string, error) {
+	fields := strings.Fields(program)
+	if len(fields) == 0 {
+		return nil, fmt.Errorf("no agent program configured")
+	}
+	switch filepath.Base(fields[0]) {
+	case "claude", "gemini":
+		return append(fields, "-p", commitMessagePrompt), nil
+	case "codex":
+		return append(fields, "exec", commitMessagePrompt), nil
+	default:
+		return nil, fmt.Errorf("%s can't summarize a diff non-interactively; configure a command instead", fields[0])
+	}
+}
+
+// runGenerator runs cmd with diff on stdin and returns its trimmed output.
+func runGenerator(cmd *exec.Cmd, diff string) (string, error) {
+	if len(diff) > maxGeneratorDiff {
+		diff = diff[:maxGeneratorDiff]
+	}
+	cmd.Stdin = strings.NewReader(diff)
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil {
+		return "", fmt.Errorf("commit message generator failed: %s (%w)", strings.TrimSpace(stderr.String()), err)
+	}
+	message := strings.TrimSpace(string(out))
+	if message == "" {
+		return "", fmt.Errorf("commit message generator returned an empty message")
+	}
+	return message, nil
+}
+
+// UncommittedDiff returns the uncommitted changes in the instance's worktree.
+func (i *Instance) UncommittedDiff() (string, error) {
+	if !i.started || i.Status == Paused {
+		return "", fmt.Errorf("cannot read the changes of an instance that has not been started or is paused")
+	}
+	stats := i.gitWorktree.DiffWith(git.DiffSpec{Mode: git.DiffUncommitted})
+	if stats.Error != nil {
+		return "", stats.Error
+	}
+	return stats.Content, nil
+}
+
+// HasUncommittedChanges returns true if the instance's worktree has changes that aren't committed.
+func (i *Instance) HasUncommittedChanges() (bool, error) {
+	if !i.started || i.Status == Paused {
+		return false, nil
+	}
+	return i.gitWorktree.IsDirty()
+}
+
+// defaultCommitMessage renders the configured commit message template for the uncommitted changes.
+func (i *Instance) defaultCommitMessage() string {
+	diff, err := i.UncommittedDiff()
+	if err != nil {
+		log.WarningLog.Printf("failed to read changes for the commit message: %v", err)
+	}
+	message, err := RenderCommitMessage(commitMessageTemplate, NewCommitMessageData(i.Title, i.gitWorktree.GetBranchName(), diff))
+	if err != nil {
+		return fmt.Sprintf("Update from '%s'", i.Title)
+	}
+	return message
+}
@@ -0,0 +1,83 @@
+package session
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const commitTestDiff = `diff --git a/main.go b/main.go
+index 1111111..2222222 100644
+--- a/main.go
++++ b/main.go
+@@ -1 +1 @@
+-old
++new
+diff --git a/new.go b/new.go
+new file mode 100644
+index 0000000..3333333
+--- /dev/null
++++ b/new.go
+@@ -0,0 +1 @@
++package main
+diff --git a/gone.go b/gone.go
+deleted file mode 100644
+index 4444444..0000000
+--- a/gone.go
++++ /dev/null
+@@ -1 +0,0 @@
+-package main
+`
+
+func TestNewCommitMessageData(t *testing.T) {
+	data := NewCommitMessageData("fix-login", "me/fix-login", commitTestDiff)
+	assert.Equal(t, "Update main.go, add new.go, delete gone.go", data.Summary)
+	assert.Equal(t, []string{"main.go", "new.go", "gone.go"}, data.Files)
+
+	data = NewCommitMessageData("fix-login", "me/fix-login", "")
+	assert.Equal(t, "Update from 'fix-login'", data.Summary)
+
+	assert.Equal(t, "a, b and c", describePaths([]string{"a", "b", "c"}))
+	assert.Equal(t, "4 files", describePaths([]string{"a", "b", "c", "d"}))
+}
+
+func TestRenderCommitMessage(t *testing.T) {
+	data := NewCommitMessageData("fix-login", "me/fix-login", commitTestDiff)
+	message, err := RenderCommitMessage("", data)
+	require.NoError(t, err)
+	assert.Equal(t, data.Summary, message)
+
+	message, err = RenderCommitMessage("[{{.Title}}] {{.Summary}}\n\nBranch: {{.Branch}}, {{len .Files}} files", data)
+	require.NoError(t, err)
+	assert.Equal(t, "[fix-login] Update main.go, add new.go, delete gone.go\n\nBranch: me/fix-login, 3 files", message)
+
+	_, err = RenderCommitMessage("{{.Nope", data)
+	assert.Error(t, err)
+	_, err = RenderCommitMessage("{{if false}}x{{end}}", data)
+	assert.Error(t, err)
+}
+
+func TestCommitMessageGenerators(t *testing.T) {
+	assert.Nil(t, NewCommitMessageGenerator("", "claude"))
+
+	// Command generators read the diff on stdin.
+	t.Setenv("SHELL", "/bin/sh")
+	generator := NewCommitMessageGenerator("grep -c '^diff --git'", "claude")
+	message, err := generator.GenerateCommitMessage(context.Background(), commitTestDiff)
+	require.NoError(t, err)
+	assert.Equal(t, "3", message)
+
+	_, err = NewCommitMessageGenerator("true", "claude").GenerateCommitMessage(context.Background(), commitTestDiff)
+	assert.Error(t, err, "empty output is an error")
+
+	args, err := agentSummaryArgs("/usr/local/bin/claude --model opus")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"/usr/local/bin/claude", "--model", "opus", "-p", commitMessagePrompt}, args)
+	args, err = agentSummaryArgs("codex")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"codex", "exec", commitMessagePrompt}, args)
+	_, err = agentSummaryArgs("aider")
+	assert.Error(t, err)
+}
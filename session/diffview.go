@@ -0,0 +1,122 @@
+package session
+
+import (
+	"fmt"
+	"strings"
+)
+
+// DiffViewState is the per-instance state of the diff viewer. It is persisted with the instance.
+type DiffViewState struct {
+	// Viewed maps file paths to the hash of the file's diff at the time it was marked viewed. A
+	// file only counts as viewed while its diff is unchanged.
+	Viewed map[string]string `json:"viewed,omitempty"`
+	// Collapsed holds the paths of files whose hunks are hidden.
+	Collapsed map[string]bool `json:"collapsed,omitempty"`
+	// Approved holds the hunks marked approved, keyed by file path and hunk hash.
+	Approved map[string]bool `json:"approved,omitempty"`
+	// Comments are the review comments that haven't been sent to the agent yet.
+	Comments []ReviewComment `json:"comments,omitempty"`
+}
+
+// ReviewComment is a note on a line of the diff that is sent back to the agent.
+type ReviewComment struct {
+	Path string `json:"path"`
+	// Line is the line number in the new file, or in the old file if Removed is set.
+	Line    int  `json:"line"`
+	Removed bool `json:"removed,omitempty"`
+	// Code is the commented line. It is quoted in the prompt for context.
+	Code string `json:"code"`
+	Text string `json:"text"`
+}
+
+// IsViewed returns true if the file was marked viewed and its diff still has the given hash.
+func (s *DiffViewState) IsViewed(path, hash string) bool {
+	h, ok := s.Viewed[path]
+	return ok && h == hash
+}
+
+// ViewedStale returns true if the file was marked viewed but has changed since.
+func (s *DiffViewState) ViewedStale(path, hash string) bool {
+	h, ok := s.Viewed[path]
+	return ok && h != hash
+}
+
+// SetViewed marks or unmarks a file as viewed. Viewed files are collapsed.
+func (s *DiffViewState) SetViewed(path, hash string, viewed bool) {
+	if viewed {
+		if s.Viewed == nil {
+			s.Viewed = make(map[string]string)
+		}
+		s.Viewed[path] = hash
+	} else {
+		delete(s.Viewed, path)
+	}
+	s.SetCollapsed(path, viewed)
+}
+
+// IsCollapsed returns true if the file's hunks are hidden.
+func (s *DiffViewState) IsCollapsed(path string) bool {
+	return s.Collapsed[path]
+}
+
+// SetCollapsed collapses or expands a file.
+func (s *DiffViewState) SetCollapsed(path string, collapsed bool) {
+	if collapsed {
+		if s.Collapsed == nil {
+			s.Collapsed = make(map[string]bool)
+		}
+		s.Collapsed[path] = true
+	} else {
+		delete(s.Collapsed, path)
+	}
+}
+
+func approvalKey(path, hunkHash string) string {
+	return path + ":" + hunkHash
+}
+
+// IsApproved returns true if the hunk with the given hash was approved.
+func (s *DiffViewState) IsApproved(path, hunkHash string) bool {
+	return s.Approved[approvalKey(path, hunkHash)]
+}
+
+// SetApproved marks or unmarks a hunk as approved.
+func (s *DiffViewState) SetApproved(path, hunkHash string, approved bool) {
+	if approved {
+		if s.Approved == nil {
+			s.Approved = make(map[string]bool)
+		}
+		s.Approved[approvalKey(path, hunkHash)] = true
+	} else {
+		delete(s.Approved, approvalKey(path, hunkHash))
+	}
+}
+
+// AddComment adds a review comment.
+func (s *DiffViewState) AddComment(c ReviewComment) {
+	s.Comments = append(s.Comments, c)
+}
+
+// ReviewPrompt formats the pending comments as a single prompt for the agent. It returns an empty
+// string if there are no comments.
+func (s *DiffViewState) ReviewPrompt() string {
+	if len(s.Comments) == 0 {
+		return ""
+	}
+	var b strings.Builder
+	b.WriteString("Please address the following review comments on your changes:\n")
+	for i, c := range s.Comments {
+		location := fmt.Sprintf("%s:%d", c.Path, c.Line)
+		if c.Removed {
+			location += " (removed line)"
+		}
+		fmt.Fprintf(&b, "\n%d. %s\n", i+1, location)
+		if code := strings.TrimSpace(c.Code); code != "" {
+			fmt.Fprintf(&b, "   > %s\n", code)
+		}
+		for _, line := range strings.Split(strings.TrimSpace(c.Text), "\n") {
+			fmt.Fprintf(&b, "   %s\n", line)
+		}
+	}
+	return b.String()
+}
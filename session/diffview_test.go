@@ -0,0 +1,36 @@
+package session
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestReviewPrompt(t *testing.T) {
+	var s DiffViewState
+	assert.Empty(t, s.ReviewPrompt())
+
+	s.AddComment(ReviewComment{Path: "main.go", Line: 12, Code: "	x := 1 ", Text: "Use a constant.\nAnd name it."})
+	s.AddComment(ReviewComment{Path: "util.go", Line: 3, Removed: true, Code: "return nil", Text: "Keep this."})
+	assert.Equal(t, `Please address the following review comments on your changes:
+
+1. main.go:12
+   > x := 1
+   Use a constant.
+   And name it.
+
+2. util.go:3 (removed line)
+   > return nil
+   Keep this.
+`, s.ReviewPrompt())
+}
+
+func TestApprovedHunks(t *testing.T) {
+	var s DiffViewState
+	s.SetApproved("a.go", "h1", true)
+	assert.True(t, s.IsApproved("a.go", "h1"))
+	assert.False(t, s.IsApproved("a.go", "h2"))
+	assert.False(t, s.IsApproved("b.go", "h1"))
+	s.SetApproved("a.go", "h1", false)
+	assert.False(t, s.IsApproved("a.go", "h1"))
+}
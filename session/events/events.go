@@ -0,0 +1,287 @@
+// Package events publishes the lifecycle events of sessions to configured hooks: shell commands
+// that read the event as JSON on stdin, HTTP webhooks and append-only JSONL files.
+package events
+
+import (
+	"claude-squad/log"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// Type is the kind of an event.
+type Type string
+
+const (
+	// Created is published when a new session is set up.
+	Created Type = "created"
+	// Started is published when a new session's agent was started.
+	Started Type = "started"
+	// StatusChanged is published when a session goes running, ready, loading or paused.
+	StatusChanged Type = "status_changed"
+	// PromptDetected is published when the agent starts asking for a confirmation.
+	PromptDetected Type = "prompt_detected"
+	// AutoApproved is published when AutoYes approves a confirmation.
+	AutoApproved Type = "auto_approved"
+	// AutoDenied is published when the AutoYes policy rejects a confirmation.
+	AutoDenied Type = "auto_denied"
+	// ApprovalNeeded is published when the AutoYes policy leaves a confirmation to the user.
+	ApprovalNeeded Type = "approval_needed"
+	// Paused is published when a session was paused.
+	Paused Type = "paused"
+	// Resumed is published when a paused session was resumed.
+	Resumed Type = "resumed"
+	// Killed is published when a session was killed.
+	Killed Type = "killed"
+	// Pushed is published when a session's branch was pushed.
+	Pushed Type = "pushed"
+)
+
+// Event is something that happened to a session. It is what hooks receive, encoded as JSON.
+type Event struct {
+	Type Type      `json:"type"`
+	Time time.Time `json:"time"`
+	// Session is the title of the session.
+	Session string `json:"session"`
+	Branch  string `json:"branch,omitempty"`
+	// Path is the repository the session works on.
+	Path    string `json:"path,omitempty"`
+	Program string `json:"program,omitempty"`
+	// Status is the status of the session after the event.
+	Status string `json:"status,omitempty"`
+	// PreviousStatus is the status before a StatusChanged event.
+	PreviousStatus string `json:"previous_status,omitempty"`
+	// URL is the web page of a pushed branch or opened pull request, if any.
+	URL string `json:"url,omitempty"`
+	// Action is the confirmed action of AutoYes events, e.g. "Bash: go test ./...".
+	Action string `json:"action,omitempty"`
+}
+
+// Hook types accepted in HookConfig.Type.
+const (
+	HookCommand = "command"
+	HookWebhook = "webhook"
+	HookFile    = "file"
+)
+
+const (
+	// DefaultTimeout bounds a delivery attempt when HookConfig.Timeout is not set.
+	DefaultTimeout = 10 * time.Second
+	// DefaultRetries is the number of retries after a failed delivery when HookConfig.Retries is
+	// not set.
+	DefaultRetries = 2
+	// queueSize is the number of events a hook can fall behind before events are dropped.
+	queueSize = 256
+	// retryBackoff is the wait before the first retry. It doubles with every retry.
+	retryBackoff = time.Second
+)
+
+// HookConfig configures a subscriber of the events.
+type HookConfig struct {
+	// Type is "command", "webhook" or "file".
+	Type string `json:"type"`
+	// Command is run with the user's shell for command hooks. The event is written to its stdin
+	// as JSON; CS_EVENT and CS_SESSION hold its type and session.
+	Command string `json:"command,omitempty"`
+	// URL receives the event as a JSON POST for webhooks.
+	URL string `json:"url,omitempty"`
+	// Headers are added to webhook requests, e.g. for authorization.
+	Headers map[string]string `json:"headers,omitempty"`
+	// Path is the file that file hooks append the events to, one JSON object per line.
+	Path string `json:"path,omitempty"`
+	// Events lists the event types sent to the hook. Defaults to all.
+	Events []Type `json:"events,omitempty"`
+	// Timeout is the number of seconds a delivery attempt may take. Defaults to 10.
+	Timeout int `json:"timeout,omitempty"`
+	// Retries is the number of retries after a failed delivery. Defaults to 2; a negative value
+	// turns retries off.
+	Retries int `json:"retries,omitempty"`
+}
+
+// deliverFunc delivers one encoded event.
+type deliverFunc func(ctx context.Context, e Event, payload []byte) error
+
+// hook delivers the events queued for one subscriber in order, in its own goroutine.
+type hook struct {
+	name    string
+	events  []Type
+	timeout time.Duration
+	retries int
+	backoff time.Duration
+	deliver deliverFunc
+	queue   chan Event
+}
+
+// Bus fans events out to the hooks. Publishing never blocks: every hook has its own queue and
+// goroutine, so a slow hook only delays its own events. A nil Bus drops all events.
+type Bus struct {
+	mu     sync.RWMutex
+	closed bool
+	hooks  []*hook
+	wg     sync.WaitGroup
+}
+
+// NewBus starts a bus with a hook for each config. Invalid configs are reported in the error and
+// left out; the returned bus is usable either way.
+func NewBus(configs []HookConfig) (*Bus, error) {
+	b := &Bus{}
+	var errs []error
+	for i, cfg := range configs {
+		h, err := newHook(cfg)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("hook %d: %w", i+1, err))
+			continue
+		}
+		b.start(h)
+	}
+	return b, errors.Join(errs...)
+}
+
+func newHook(cfg HookConfig) (*hook, error) {
+	h := &hook{
+		events:  cfg.Events,
+		timeout: DefaultTimeout,
+		retries: DefaultRetries,
+		backoff: retryBackoff,
+		queue:   make(chan Event, queueSize),
+	}
+	if cfg.Timeout > 0 {
+		h.timeout = time.Duration(cfg.Timeout) * time.Second
+	}
+	if cfg.Retries < 0 {
+		h.retries = 0
+	} else if cfg.Retries > 0 {
+		h.retries = cfg.Retries
+	}
+
+	switch cfg.Type {
+	case HookCommand:
+		if cfg.Command == "" {
+			return nil, fmt.Errorf("command hook without a command")
+		}
+		h.name = "command " + cfg.Command
+		h.deliver = commandDeliverer(cfg.Command)
+	case HookWebhook:
+		if cfg.URL == "" {
+			return nil, fmt.Errorf("webhook without a url")
+		}
+		h.name = "webhook " + cfg.URL
+		h.deliver = webhookDeliverer(cfg.URL, cfg.Headers)
+	case HookFile:
+		if cfg.Path == "" {
+			return nil, fmt.Errorf("file hook without a path")
+		}
+		h.name = "file " + cfg.Path
+		h.deliver = fileDeliverer(cfg.Path)
+	default:
+		return nil, fmt.Errorf("unknown hook type %q", cfg.Type)
+	}
+	return h, nil
+}
+
+func (b *Bus) start(h *hook) {
+	b.hooks = append(b.hooks, h)
+	b.wg.Add(1)
+	go func() {
+		defer b.wg.Done()
+		h.run()
+	}()
+}
+
+// Publish queues e for every hook that wants it. Hooks that fell too far behind drop it.
+func (b *Bus) Publish(e Event) {
+	if b == nil {
+		return
+	}
+	if e.Time.IsZero() {
+		e.Time = time.Now()
+	}
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	if b.closed {
+		return
+	}
+	for _, h := range b.hooks {
+		if !h.wants(e.Type) {
+			continue
+		}
+		select {
+		case h.queue <- e:
+		default:
+			log.WarningLog.Printf("%s is falling behind, dropped the %s event of '%s'", h.name, e.Type, e.Session)
+		}
+	}
+}
+
+// Close stops accepting events and waits up to timeout for the queued ones to be delivered.
+func (b *Bus) Close(timeout time.Duration) {
+	if b == nil {
+		return
+	}
+	b.mu.Lock()
+	if b.closed {
+		b.mu.Unlock()
+		return
+	}
+	b.closed = true
+	for _, h := range b.hooks {
+		close(h.queue)
+	}
+	b.mu.Unlock()
+
+	done := make(chan struct{})
+	go func() {
+		b.wg.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(timeout):
+		log.WarningLog.Printf("gave up waiting for hooks to deliver their events")
+	}
+}
+
+func (h *hook) wants(t Type) bool {
+	if len(h.events) == 0 {
+		return true
+	}
+	for _, want := range h.events {
+		if want == t {
+			return true
+		}
+	}
+	return false
+}
+
+// run delivers the queued events until the queue is closed.
+func (h *hook) run() {
+	for e := range h.queue {
+		payload, err := json.Marshal(e)
+		if err != nil {
+			log.ErrorLog.Printf("failed to encode the %s event of '%s': %v", e.Type, e.Session, err)
+			continue
+		}
+		if err := h.deliverWithRetries(e, payload); err != nil {
+			log.WarningLog.Printf("%s failed to deliver the %s event of '%s': %v", h.name, e.Type, e.Session, err)
+		}
+	}
+}
+
+func (h *hook) deliverWithRetries(e Event, payload []byte) error {
+	var err error
+	for attempt := 0; attempt <= h.retries; attempt++ {
+		if attempt > 0 {
+			time.Sleep(h.backoff << (attempt - 1))
+		}
+		ctx, cancel := context.WithTimeout(context.Background(), h.timeout)
+		err = h.deliver(ctx, e, payload)
+		cancel()
+		if err == nil {
+			return nil
+		}
+	}
+	return err
+}
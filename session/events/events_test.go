@@ -0,0 +1,128 @@
+package events
+
+import (
+	"claude-squad/log"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestMain(m *testing.M) {
+	log.Initialize(false)
+	defer log.Close()
+	os.Exit(m.Run())
+}
+
+func readEvents(t *testing.T, path string) []Event {
+	t.Helper()
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	var events []Event
+	for _, line := range strings.Split(strings.TrimSpace(string(data)), "\n") {
+		var e Event
+		require.NoError(t, json.Unmarshal([]byte(line), &e))
+		events = append(events, e)
+	}
+	return events
+}
+
+func TestFileHook(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "events.jsonl")
+	bus, err := NewBus([]HookConfig{{Type: HookFile, Path: path, Events: []Type{Created, Killed}}})
+	require.NoError(t, err)
+
+	bus.Publish(Event{Type: Created, Session: "api", Branch: "cs/api"})
+	bus.Publish(Event{Type: StatusChanged, Session: "api", Status: "ready"})
+	bus.Publish(Event{Type: Killed, Session: "api"})
+	bus.Close(5 * time.Second)
+
+	// Events published after closing are dropped.
+	bus.Publish(Event{Type: Killed, Session: "web"})
+
+	events := readEvents(t, path)
+	require.Len(t, events, 2, "status_changed is filtered out")
+	require.Equal(t, Created, events[0].Type)
+	require.Equal(t, "cs/api", events[0].Branch)
+	require.False(t, events[0].Time.IsZero())
+	require.Equal(t, Killed, events[1].Type)
+}
+
+func TestWebhookRetries(t *testing.T) {
+	var calls atomic.Int32
+	var body atomic.Value
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if calls.Add(1) == 1 {
+			http.Error(w, "try again", http.StatusInternalServerError)
+			return
+		}
+		require.Equal(t, "Bearer secret", r.Header.Get("Authorization"))
+		data, _ := io.ReadAll(r.Body)
+		body.Store(string(data))
+	}))
+	defer server.Close()
+
+	bus, err := NewBus([]HookConfig{{Type: HookWebhook, URL: server.URL, Headers: map[string]string{"Authorization": "Bearer secret"}}})
+	require.NoError(t, err)
+	bus.hooks[0].backoff = time.Millisecond
+
+	bus.Publish(Event{Type: Pushed, Session: "api", URL: "https://example.com/branch"})
+	bus.Close(5 * time.Second)
+
+	require.EqualValues(t, 2, calls.Load())
+	var e Event
+	require.NoError(t, json.Unmarshal([]byte(body.Load().(string)), &e))
+	require.Equal(t, Pushed, e.Type)
+	require.Equal(t, "https://example.com/branch", e.URL)
+}
+
+func TestCommandHook(t *testing.T) {
+	out := filepath.Join(t.TempDir(), "out")
+	t.Setenv("SHELL", "/bin/sh")
+	bus, err := NewBus([]HookConfig{{Type: HookCommand, Command: `{ echo "$CS_EVENT|$CS_SESSION"; cat; } > "` + out + `"`}})
+	require.NoError(t, err)
+
+	bus.Publish(Event{Type: Paused, Session: "api"})
+	bus.Close(5 * time.Second)
+
+	data, err := os.ReadFile(out)
+	require.NoError(t, err)
+	header, payload, _ := strings.Cut(string(data), "\n")
+	require.Equal(t, "paused|api", header)
+	var e Event
+	require.NoError(t, json.Unmarshal([]byte(payload), &e))
+	require.Equal(t, Paused, e.Type)
+}
+
+func TestSlowHookDoesNotBlock(t *testing.T) {
+	t.Setenv("SHELL", "/bin/sh")
+	bus, err := NewBus([]HookConfig{{Type: HookCommand, Command: "sleep 5", Timeout: 1, Retries: -1}})
+	require.NoError(t, err)
+
+	start := time.Now()
+	for i := 0; i < queueSize+10; i++ {
+		bus.Publish(Event{Type: StatusChanged, Session: "api"})
+	}
+	require.Less(t, time.Since(start), time.Second)
+	bus.Close(10 * time.Millisecond)
+}
+
+func TestNewBusInvalidHooks(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "events.jsonl")
+	bus, err := NewBus([]HookConfig{{Type: "carrier-pigeon"}, {Type: HookWebhook}, {Type: HookFile, Path: path}})
+	require.ErrorContains(t, err, `hook 1: unknown hook type "carrier-pigeon"`)
+	require.ErrorContains(t, err, "hook 2: webhook without a url")
+	require.Len(t, bus.hooks, 1)
+
+	var nilBus *Bus
+	nilBus.Publish(Event{Type: Created})
+	nilBus.Close(time.Second)
+}
@@ -0,0 +1,79 @@
+package events
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"os/exec"
+	"strings"
+	"time"
+)
+
+// commandDeliverer runs command with the user's shell and the event as JSON on stdin.
+func commandDeliverer(command string) deliverFunc {
+	return func(ctx context.Context, e Event, payload []byte) error {
+		cmd := exec.CommandContext(ctx, shell(), "-c", command)
+		cmd.Stdin = bytes.NewReader(payload)
+		cmd.Env = append(os.Environ(), "CS_EVENT="+string(e.Type), "CS_SESSION="+e.Session)
+		// Don't wait for children that keep the output open after a timeout.
+		cmd.WaitDelay = time.Second
+		if out, err := cmd.CombinedOutput(); err != nil {
+			if len(out) > 0 {
+				return fmt.Errorf("%w: %s", err, strings.TrimSpace(string(out)))
+			}
+			return err
+		}
+		return nil
+	}
+}
+
+// webhookDeliverer POSTs the event as JSON to url.
+func webhookDeliverer(url string, headers map[string]string) deliverFunc {
+	return func(ctx context.Context, e Event, payload []byte) error {
+		req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(payload))
+		if err != nil {
+			return err
+		}
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("User-Agent", "claude-squad")
+		for k, v := range headers {
+			req.Header.Set(k, v)
+		}
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			return err
+		}
+		defer resp.Body.Close()
+		if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+			body, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+			return fmt.Errorf("%s: %s", resp.Status, strings.TrimSpace(string(body)))
+		}
+		return nil
+	}
+}
+
+// fileDeliverer appends the event as a line of JSON to path.
+func fileDeliverer(path string) deliverFunc {
+	return func(ctx context.Context, e Event, payload []byte) error {
+		f, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+		if err != nil {
+			return err
+		}
+		if _, err := f.Write(append(payload, '\n')); err != nil {
+			f.Close()
+			return err
+		}
+		return f.Close()
+	}
+}
+
+// shell returns the user's shell.
+func shell() string {
+	if shell := os.Getenv("SHELL"); shell != "" {
+		return shell
+	}
+	return "/bin/sh"
+}
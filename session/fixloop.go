@@ -0,0 +1,101 @@
+package session
+
+import (
+	"fmt"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// maxFixIterations is the number of fix loop iterations kept in an instance's history.
+const maxFixIterations = 100
+
+// FixIteration records a failed check whose output was sent to the agent by the fix loop.
+type FixIteration struct {
+	// Time is when the prompt was sent.
+	Time time.Time `json:"time"`
+	// Command is the check command that failed.
+	Command string `json:"command"`
+	// ExitCode is the exit code of the failed check.
+	ExitCode int `json:"exit_code"`
+	// Duration is how long the failed check took.
+	Duration time.Duration `json:"duration"`
+	// Attempt is the number of the iteration since the check last passed, starting at 1.
+	Attempt int `json:"attempt"`
+	// Truncated is true if only the end of the check output was sent.
+	Truncated bool `json:"truncated,omitempty"`
+}
+
+// FixLoopState is the fix loop history of an instance.
+type FixLoopState struct {
+	// Iterations are the prompts sent by the fix loop, oldest first.
+	Iterations []FixIteration `json:"iterations,omitempty"`
+	// Attempts is the number of prompts sent since the check last passed.
+	Attempts int `json:"attempts,omitempty"`
+}
+
+// SendFixPrompt sends the output of the failed check run to the agent and asks it to fix the
+// failures. At most limit bytes of output are sent, taken from the end. The prompt is recorded in
+// the fix loop history.
+func (i *Instance) SendFixPrompt(run CheckRun, limit int) error {
+	output, truncated := tailOutput(i.CheckOutput(), limit)
+	prompt := fixPrompt(run, output, truncated)
+	if err := i.SendPrompt(prompt); err != nil {
+		return err
+	}
+	i.Audit(AuditEntry{Source: AuditFixLoop, Action: "check failed: " + run.Command, Keys: prompt + "\r"})
+
+	i.FixLoop.Attempts++
+	i.FixLoop.Iterations = append(i.FixLoop.Iterations, FixIteration{
+		Time:      time.Now(),
+		Command:   run.Command,
+		ExitCode:  run.ExitCode,
+		Duration:  run.Duration,
+		Attempt:   i.FixLoop.Attempts,
+		Truncated: truncated,
+	})
+	if over := len(i.FixLoop.Iterations) - maxFixIterations; over > 0 {
+		i.FixLoop.Iterations = i.FixLoop.Iterations[over:]
+	}
+	return nil
+}
+
+// ResetFixLoop starts counting fix loop attempts from zero again, e.g. after the check passed.
+// It returns false if there was nothing to reset.
+func (i *Instance) ResetFixLoop() bool {
+	if i.FixLoop.Attempts == 0 {
+		return false
+	}
+	i.FixLoop.Attempts = 0
+	return true
+}
+
+// fixPrompt asks the agent to fix the failures of a check run.
+func fixPrompt(run CheckRun, output string, truncated bool) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "The check `%s` failed with exit code %d. Please fix the failures.\n\n", run.Command, run.ExitCode)
+	if truncated {
+		b.WriteString("Output (truncated to the end):\n")
+	} else {
+		b.WriteString("Output:\n")
+	}
+	b.WriteString("```\n")
+	b.WriteString(strings.TrimRight(output, "\n"))
+	b.WriteString("\n```\n")
+	return b.String()
+}
+
+// tailOutput returns the last limit bytes of output, cut at a line start if possible.
+func tailOutput(output string, limit int) (string, bool) {
+	if limit <= 0 || len(output) <= limit {
+		return output, false
+	}
+	tail := output[len(output)-limit:]
+	if nl := strings.IndexByte(tail, '\n'); nl >= 0 && nl < len(tail)-1 {
+		tail = tail[nl+1:]
+	}
+	for len(tail) > 0 && !utf8.RuneStart(tail[0]) {
+		tail = tail[1:]
+	}
+	return tail, true
+}
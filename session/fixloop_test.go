@@ -0,0 +1,45 @@
+package session
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestTailOutput(t *testing.T) {
+	out, truncated := tailOutput("short\n", 100)
+	require.False(t, truncated)
+	require.Equal(t, "short\n", out)
+
+	// The cut moves to the next line start so no half line is sent.
+	out, truncated = tailOutput("first line\nsecond line\nFAIL: TestX\n", 20)
+	require.True(t, truncated)
+	require.Equal(t, "FAIL: TestX\n", out)
+
+	// Without a line break the cut still lands on a rune boundary.
+	out, truncated = tailOutput(strings.Repeat("é", 10), 5)
+	require.True(t, truncated)
+	require.Equal(t, "éé", out)
+}
+
+func TestFixPrompt(t *testing.T) {
+	run := CheckRun{Command: "go test ./...", ExitCode: 1}
+	prompt := fixPrompt(run, "--- FAIL: TestX\n", true)
+	require.Contains(t, prompt, "The check `go test ./...` failed with exit code 1.")
+	require.Contains(t, prompt, "truncated")
+	require.Contains(t, prompt, "```\n--- FAIL: TestX\n```\n")
+}
+
+func TestResetFixLoop(t *testing.T) {
+	inst := &Instance{Title: "fix"}
+	require.False(t, inst.ResetFixLoop())
+
+	inst.FixLoop = FixLoopState{Attempts: 2, Iterations: []FixIteration{{Attempt: 1}, {Attempt: 2}}}
+	require.True(t, inst.ResetFixLoop())
+	require.Zero(t, inst.FixLoop.Attempts)
+	require.Len(t, inst.FixLoop.Iterations, 2, "the history is kept")
+
+	data := inst.ToInstanceData()
+	require.Len(t, data.FixLoop.Iterations, 2)
+}
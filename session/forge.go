@@ -0,0 +1,102 @@
+package session
+
+import (
+	"claude-squad/forge"
+	"claude-squad/session/events"
+	"context"
+	"fmt"
+	"strings"
+)
+
+// Forge returns the forge provider of the repository the instance works on. Unlike pushing, this
+// also works for paused instances.
+func (i *Instance) Forge(cfg forge.Config) (forge.Provider, error) {
+	if !i.started {
+		return nil, fmt.Errorf("cannot access the forge of an instance that has not been started")
+	}
+	remoteURL, err := i.gitWorktree.RemoteURL(cfg.RemoteName())
+	if err != nil {
+		return nil, err
+	}
+	return forge.New(cfg, remoteURL)
+}
+
+// Push commits uncommitted changes with commitMsg and pushes the instance branch, until ctx is
+// canceled. It returns the web page of the branch, or "" if the forge has none.
+func (i *Instance) Push(ctx context.Context, commitMsg string, cfg forge.Config) (string, error) {
+	if _, err := i.worktreeForHistory(); err != nil {
+		return "", err
+	}
+	provider, err := i.Forge(cfg)
+	if err != nil {
+		return "", err
+	}
+	if err := i.gitWorktree.PushChanges(ctx, commitMsg, provider); err != nil {
+		return "", err
+	}
+	url := provider.BranchURL(i.gitWorktree.GetBranchName())
+	i.publish(events.Event{Type: events.Pushed, URL: url})
+	return url, nil
+}
+
+// OpenPullRequest pushes the instance branch like Push and opens a pull request for it. A single
+// commit provides the title and description; otherwise the session title is used and the commits
+// are listed. It returns the status of the new pull request.
+func (i *Instance) OpenPullRequest(ctx context.Context, commitMsg string, cfg forge.Config) (forge.PullRequestStatus, error) {
+	if _, err := i.worktreeForHistory(); err != nil {
+		return forge.PullRequestStatus{}, err
+	}
+	provider, err := i.Forge(cfg)
+	if err != nil {
+		return forge.PullRequestStatus{}, err
+	}
+	if err := i.gitWorktree.PushChanges(ctx, commitMsg, provider); err != nil {
+		return forge.PullRequestStatus{}, err
+	}
+	i.publish(events.Event{Type: events.Pushed, URL: provider.BranchURL(i.gitWorktree.GetBranchName())})
+	commits, err := i.gitWorktree.Commits()
+	if err != nil {
+		return forge.PullRequestStatus{}, err
+	}
+	if len(commits) == 0 {
+		return forge.PullRequestStatus{}, fmt.Errorf("the session has no commits to open a pull request for")
+	}
+
+	pr := forge.PullRequest{
+		Head:  i.gitWorktree.GetBranchName(),
+		Base:  cfg.BaseBranch,
+		Title: i.Title,
+	}
+	if pr.Base == "" {
+		pr.Base = i.gitWorktree.DefaultBranch(cfg.RemoteName())
+	}
+	if len(commits) == 1 {
+		message, err := i.gitWorktree.CommitMessage(commits[0].SHA)
+		if err != nil {
+			return forge.PullRequestStatus{}, err
+		}
+		subject, body, _ := strings.Cut(message, "\n")
+		pr.Title, pr.Body = subject, strings.TrimSpace(body)
+	} else {
+		// Commits are listed newest first.
+		var body strings.Builder
+		for j := len(commits) - 1; j >= 0; j-- {
+			fmt.Fprintf(&body, "- %s\n", commits[j].Subject)
+		}
+		pr.Body = body.String()
+	}
+	return provider.CreatePullRequest(ctx, pr)
+}
+
+// FetchPullRequestStatus fetches the current status of the instance's pull request. It doesn't
+// update the instance.
+func (i *Instance) FetchPullRequestStatus(ctx context.Context, cfg forge.Config) (forge.PullRequestStatus, error) {
+	if i.PullRequest == nil {
+		return forge.PullRequestStatus{}, fmt.Errorf("instance '%s' has no pull request", i.Title)
+	}
+	provider, err := i.Forge(cfg)
+	if err != nil {
+		return forge.PullRequestStatus{}, err
+	}
+	return provider.Status(ctx, i.PullRequest.Number)
+}
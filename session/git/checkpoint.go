@@ -0,0 +1,190 @@
+package git
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// checkpointRefPrefix is the namespace of checkpoint refs. It is outside refs/heads and refs/tags,
+// so checkpoints don't show up as branches or tags and are never pushed.
+const checkpointRefPrefix = "refs/claude-squad/checkpoints/"
+
+// Checkpoint is a snapshot of the worktree, tracked and untracked files, stored as a commit on top
+// of HEAD under a hidden ref. Ignored files are not included.
+type Checkpoint struct {
+	Ref      string
+	SHA      string
+	ShortSHA string
+	// Tree is the SHA of the snapshot's tree. Identical snapshots have the same tree.
+	Tree string
+	Time time.Time
+	// Reason says what triggered the checkpoint, e.g. "timer" or "ready".
+	Reason string
+}
+
+// checkpointRefs returns the prefix of the checkpoint refs of this worktree's branch.
+func (g *GitWorktree) checkpointRefs() string {
+	return checkpointRefPrefix + g.branchName + "/"
+}
+
+// CreateCheckpoint snapshots the worktree. The branch, index and working tree are not modified.
+// If nothing changed since the latest checkpoint, no checkpoint is created and the latest one is
+// returned with created set to false.
+func (g *GitWorktree) CreateCheckpoint(reason string) (checkpoint Checkpoint, created bool, err error) {
+	tree, err := g.snapshotTree()
+	if err != nil {
+		return Checkpoint{}, false, err
+	}
+	existing, err := g.Checkpoints()
+	if err != nil {
+		return Checkpoint{}, false, err
+	}
+	if len(existing) > 0 && existing[0].Tree == tree {
+		return existing[0], false, nil
+	}
+
+	out, err := g.runGitCommand(g.worktreePath, "commit-tree", tree, "-p", "HEAD", "-m", "checkpoint: "+reason)
+	if err != nil {
+		return Checkpoint{}, false, fmt.Errorf("failed to create checkpoint: %w", err)
+	}
+	sha := strings.TrimSpace(out)
+	now := time.Now()
+	// Fixed-width timestamps make the refs sort chronologically.
+	ref := fmt.Sprintf("%s%020d", g.checkpointRefs(), now.UnixNano())
+	if _, err := g.runGitCommand(g.worktreePath, "update-ref", ref, sha); err != nil {
+		return Checkpoint{}, false, fmt.Errorf("failed to store checkpoint: %w", err)
+	}
+	return Checkpoint{Ref: ref, SHA: sha, ShortSHA: shortSHA(sha), Tree: tree, Time: now, Reason: reason}, true, nil
+}
+
+// snapshotTree writes the state of the worktree, including untracked files, to a tree object
+// using a temporary copy of the index.
+func (g *GitWorktree) snapshotTree() (string, error) {
+	index, err := g.tempIndex()
+	if err != nil {
+		return "", err
+	}
+	defer os.Remove(index)
+
+	env := []string{"GIT_INDEX_FILE=" + index}
+	if _, err := g.runGitCommandEnv(g.worktreePath, env, "add", "--all", "."); err != nil {
+		return "", fmt.Errorf("failed to snapshot worktree: %w", err)
+	}
+	out, err := g.runGitCommandEnv(g.worktreePath, env, "write-tree")
+	if err != nil {
+		return "", fmt.Errorf("failed to snapshot worktree: %w", err)
+	}
+	return strings.TrimSpace(out), nil
+}
+
+// Checkpoints returns the checkpoints of the branch, newest first.
+func (g *GitWorktree) Checkpoints() ([]Checkpoint, error) {
+	out, err := g.runGitCommand(g.worktreePath, "for-each-ref", "--sort=-refname",
+		"--format=%(refname)%1f%(objectname)%1f%(tree)%1f%(subject)", g.checkpointRefs())
+	if err != nil {
+		return nil, fmt.Errorf("failed to list checkpoints: %w", err)
+	}
+	var checkpoints []Checkpoint
+	for _, line := range strings.Split(strings.TrimSpace(out), "\n") {
+		fields := strings.Split(line, "\x1f")
+		if len(fields) != 4 {
+			continue
+		}
+		nanos, err := strconv.ParseInt(filepath.Base(fields[0]), 10, 64)
+		if err != nil {
+			continue
+		}
+		checkpoints = append(checkpoints, Checkpoint{
+			Ref:      fields[0],
+			SHA:      fields[1],
+			ShortSHA: shortSHA(fields[1]),
+			Tree:     fields[2],
+			Time:     time.Unix(0, nanos),
+			Reason:   strings.TrimPrefix(fields[3], "checkpoint: "),
+		})
+	}
+	return checkpoints, nil
+}
+
+// PruneCheckpoints deletes all but the newest keep checkpoints.
+func (g *GitWorktree) PruneCheckpoints(keep int) error {
+	checkpoints, err := g.Checkpoints()
+	if err != nil {
+		return err
+	}
+	if len(checkpoints) <= keep {
+		return nil
+	}
+	return g.deleteRefs(g.worktreePath, checkpoints[keep:])
+}
+
+// DeleteCheckpoints deletes every checkpoint of the branch.
+func (g *GitWorktree) DeleteCheckpoints() error {
+	// The worktree may be gone already, so work in the repository.
+	out, err := g.runGitCommand(g.repoPath, "for-each-ref", "--format=%(refname)", g.checkpointRefs())
+	if err != nil {
+		return fmt.Errorf("failed to list checkpoints: %w", err)
+	}
+	var checkpoints []Checkpoint
+	for _, ref := range strings.Fields(out) {
+		checkpoints = append(checkpoints, Checkpoint{Ref: ref})
+	}
+	return g.deleteRefs(g.repoPath, checkpoints)
+}
+
+func (g *GitWorktree) deleteRefs(dir string, checkpoints []Checkpoint) error {
+	var errs []error
+	for _, cp := range checkpoints {
+		if _, err := g.runGitCommand(dir, "update-ref", "-d", cp.Ref); err != nil {
+			errs = append(errs, fmt.Errorf("failed to delete checkpoint %s: %w", cp.Ref, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// RestoreCheckpoint makes the working tree match cp: files are brought back to their state in the
+// checkpoint and files created since are deleted. The current state is checkpointed first so the
+// restore can be undone, and that checkpoint is returned. HEAD is not moved; the index is reset
+// to HEAD, so staged changes become unstaged.
+func (g *GitWorktree) RestoreCheckpoint(cp Checkpoint) (Checkpoint, error) {
+	if !strings.HasPrefix(cp.Ref, g.checkpointRefs()) {
+		return Checkpoint{}, fmt.Errorf("checkpoint %s does not belong to branch %s", cp.ShortSHA, g.branchName)
+	}
+	current, _, err := g.CreateCheckpoint("before restore")
+	if err != nil {
+		return Checkpoint{}, err
+	}
+
+	// Files that exist now but not in the checkpoint.
+	out, err := g.runGitCommand(g.worktreePath, "diff", "--name-only", "-z", "--no-renames", "--diff-filter=A", cp.SHA, current.SHA)
+	if err != nil {
+		return Checkpoint{}, fmt.Errorf("failed to compare with checkpoint: %w", err)
+	}
+	if _, err := g.runGitCommand(g.worktreePath, "checkout", cp.SHA, "--", "."); err != nil {
+		return Checkpoint{}, fmt.Errorf("failed to restore checkpoint: %w", err)
+	}
+	for _, path := range strings.Split(out, "\x00") {
+		if path == "" {
+			continue
+		}
+		if err := os.Remove(filepath.Join(g.worktreePath, path)); err != nil && !os.IsNotExist(err) {
+			return Checkpoint{}, fmt.Errorf("failed to remove %s: %w", path, err)
+		}
+	}
+	if _, err := g.runGitCommand(g.worktreePath, "reset", "--quiet"); err != nil {
+		return Checkpoint{}, fmt.Errorf("failed to reset index: %w", err)
+	}
+	return current, nil
+}
+
+func shortSHA(sha string) string {
+	if len(sha) > 7 {
+		return sha[:7]
+	}
+	return sha
+}
@@ -0,0 +1,112 @@
+package git
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestCheckpoints(t *testing.T) {
+	gw := setupCommitsWorktree(t)
+	read := func(name string) string {
+		t.Helper()
+		data, err := os.ReadFile(filepath.Join(gw.worktreePath, name))
+		if err != nil {
+			t.Fatalf("read %s: %v", name, err)
+		}
+		return string(data)
+	}
+	write := func(name, content string) {
+		t.Helper()
+		if err := os.WriteFile(filepath.Join(gw.worktreePath, name), []byte(content), 0644); err != nil {
+			t.Fatalf("write %s: %v", name, err)
+		}
+	}
+	head := func() string {
+		t.Helper()
+		out, err := gw.runGitCommand(gw.worktreePath, "rev-parse", "HEAD")
+		if err != nil {
+			t.Fatalf("rev-parse: %v", err)
+		}
+		return strings.TrimSpace(out)
+	}
+
+	// The snapshot includes the modified base.txt and an untracked file.
+	write("untracked.txt", "new\n")
+	headBefore := head()
+	first, created, err := gw.CreateCheckpoint("timer")
+	if err != nil || !created {
+		t.Fatalf("CreateCheckpoint: created=%v err=%v", created, err)
+	}
+	if head() != headBefore {
+		t.Fatalf("checkpoint moved HEAD")
+	}
+	if status, _ := gw.runGitCommand(gw.worktreePath, "status", "--porcelain"); !strings.Contains(status, "?? untracked.txt") || !strings.Contains(status, " M base.txt") {
+		t.Fatalf("checkpoint changed the index:\n%s", status)
+	}
+
+	// Nothing changed, so there's nothing new to keep.
+	if _, created, err := gw.CreateCheckpoint("ready"); err != nil || created {
+		t.Fatalf("expected no new checkpoint, created=%v err=%v", created, err)
+	}
+
+	// The agent wrecks the worktree.
+	write("base.txt", "broken\n")
+	write("junk.txt", "junk\n")
+	if err := os.Remove(filepath.Join(gw.worktreePath, "one.txt")); err != nil {
+		t.Fatalf("remove one.txt: %v", err)
+	}
+	if _, created, err := gw.CreateCheckpoint("ready"); err != nil || !created {
+		t.Fatalf("CreateCheckpoint: created=%v err=%v", created, err)
+	}
+
+	checkpoints, err := gw.Checkpoints()
+	if err != nil {
+		t.Fatalf("Checkpoints: %v", err)
+	}
+	if len(checkpoints) != 2 || checkpoints[0].Reason != "ready" || checkpoints[1].Reason != "timer" || checkpoints[1].SHA != first.SHA {
+		t.Fatalf("unexpected checkpoints: %+v", checkpoints)
+	}
+
+	stats := gw.DiffWith(DiffSpec{Mode: DiffCheckpoint, Ref: first.SHA})
+	if stats.Error != nil || !strings.Contains(stats.Content, "junk.txt") || !strings.Contains(stats.Content, "+broken") {
+		t.Fatalf("unexpected checkpoint diff: %v\n%s", stats.Error, stats.Content)
+	}
+
+	if _, err := gw.RestoreCheckpoint(first); err != nil {
+		t.Fatalf("RestoreCheckpoint: %v", err)
+	}
+	if got := read("base.txt"); got != "base\nwip\n" {
+		t.Fatalf("base.txt = %q", got)
+	}
+	if got := read("one.txt"); got != "one\n" {
+		t.Fatalf("one.txt = %q", got)
+	}
+	if got := read("untracked.txt"); got != "new\n" {
+		t.Fatalf("untracked.txt = %q", got)
+	}
+	if _, err := os.Stat(filepath.Join(gw.worktreePath, "junk.txt")); !os.IsNotExist(err) {
+		t.Fatalf("junk.txt should be gone, got %v", err)
+	}
+	if head() != headBefore {
+		t.Fatalf("restore moved HEAD")
+	}
+	if status, _ := gw.runGitCommand(gw.worktreePath, "status", "--porcelain"); !strings.Contains(status, "?? untracked.txt") {
+		t.Fatalf("restored untracked file should stay untracked:\n%s", status)
+	}
+
+	// The wrecked state was already checkpointed, so the restore didn't add another one.
+	if err := gw.PruneCheckpoints(1); err != nil {
+		t.Fatalf("PruneCheckpoints: %v", err)
+	}
+	if checkpoints, _ := gw.Checkpoints(); len(checkpoints) != 1 || checkpoints[0].Reason != "ready" {
+		t.Fatalf("unexpected checkpoints after pruning: %+v", checkpoints)
+	}
+	if err := gw.DeleteCheckpoints(); err != nil {
+		t.Fatalf("DeleteCheckpoints: %v", err)
+	}
+	if checkpoints, _ := gw.Checkpoints(); len(checkpoints) != 0 {
+		t.Fatalf("expected no checkpoints, got %+v", checkpoints)
+	}
+}
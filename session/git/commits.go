@@ -0,0 +1,169 @@
+package git
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Commit is a commit made on the session branch since the base commit.
+type Commit struct {
+	SHA      string
+	ShortSHA string
+	Author   string
+	Date     time.Time
+	Subject  string
+	// Files, Added and Removed summarize the commit's changes.
+	Files   int
+	Added   int
+	Removed int
+}
+
+// Commits returns the commits in BaseCommitSHA..HEAD, newest first.
+func (g *GitWorktree) Commits() ([]Commit, error) {
+	if g.GetBaseCommitSHA() == "" {
+		return nil, fmt.Errorf("base commit SHA not set")
+	}
+	// Each record starts with a record separator, followed by the fields and the numstat lines.
+	out, err := g.runGitCommand(g.worktreePath, "log", "--numstat", "--no-ext-diff",
+		"--format=%x1e%H%x1f%h%x1f%an%x1f%at%x1f%s", g.GetBaseCommitSHA()+"..HEAD")
+	if err != nil {
+		return nil, fmt.Errorf("failed to list commits: %w", err)
+	}
+
+	var commits []Commit
+	for _, record := range strings.Split(out, "\x1e") {
+		lines := strings.Split(strings.TrimSpace(record), "\n")
+		fields := strings.Split(lines[0], "\x1f")
+		if len(fields) != 5 {
+			continue
+		}
+		c := Commit{SHA: fields[0], ShortSHA: fields[1], Author: fields[2], Subject: fields[4]}
+		if ts, err := strconv.ParseInt(fields[3], 10, 64); err == nil {
+			c.Date = time.Unix(ts, 0)
+		}
+		for _, line := range lines[1:] {
+			// Format: added\tremoved\tpath. Binary files show '-' instead of numbers.
+			parts := strings.SplitN(line, "\t", 3)
+			if len(parts) < 3 {
+				continue
+			}
+			c.Files++
+			if v, err := strconv.Atoi(parts[0]); err == nil {
+				c.Added += v
+			}
+			if v, err := strconv.Atoi(parts[1]); err == nil {
+				c.Removed += v
+			}
+		}
+		commits = append(commits, c)
+	}
+	return commits, nil
+}
+
+// CommitMessage returns the full message of a commit.
+func (g *GitWorktree) CommitMessage(sha string) (string, error) {
+	out, err := g.runGitCommand(g.worktreePath, "log", "-1", "--format=%B", sha)
+	if err != nil {
+		return "", fmt.Errorf("failed to read commit message: %w", err)
+	}
+	return strings.TrimSpace(out), nil
+}
+
+// DropCommit removes a commit from the session branch. Later commits are replayed on its parent
+// and uncommitted changes are kept.
+func (g *GitWorktree) DropCommit(sha string) error {
+	if err := g.checkRewritable(sha); err != nil {
+		return err
+	}
+	return g.replayOnto(sha+"^", sha)
+}
+
+// RewordCommit changes the message of a commit on the session branch.
+func (g *GitWorktree) RewordCommit(sha, message string) error {
+	if strings.TrimSpace(message) == "" {
+		return fmt.Errorf("commit message must not be empty")
+	}
+	if err := g.checkRewritable(sha); err != nil {
+		return err
+	}
+	rewritten, err := g.commitTree(sha, sha, sha+"^", message)
+	if err != nil {
+		return err
+	}
+	return g.replayOnto(rewritten, sha)
+}
+
+// SquashCommit melds a commit into its parent, keeping both messages. The parent must be on the
+// session branch as well.
+func (g *GitWorktree) SquashCommit(sha string) error {
+	if err := g.checkRewritable(sha); err != nil {
+		return err
+	}
+	parent := sha + "^"
+	if err := g.checkRewritable(parent); err != nil {
+		return fmt.Errorf("cannot squash the first commit of the session: %w", err)
+	}
+	parentMessage, err := g.CommitMessage(parent)
+	if err != nil {
+		return err
+	}
+	message, err := g.CommitMessage(sha)
+	if err != nil {
+		return err
+	}
+	squashed, err := g.commitTree(sha, parent, parent+"^", parentMessage+"\n\n"+message)
+	if err != nil {
+		return err
+	}
+	return g.replayOnto(squashed, sha)
+}
+
+// checkRewritable returns an error unless rev is a commit in BaseCommitSHA..HEAD.
+func (g *GitWorktree) checkRewritable(rev string) error {
+	if g.GetBaseCommitSHA() == "" {
+		return fmt.Errorf("base commit SHA not set")
+	}
+	out, err := g.runGitCommand(g.worktreePath, "rev-list", "--count", g.GetBaseCommitSHA()+".."+rev)
+	if err != nil {
+		return fmt.Errorf("unknown commit %s: %w", rev, err)
+	}
+	if strings.TrimSpace(out) == "0" {
+		return fmt.Errorf("commit %s is not on the session branch", rev)
+	}
+	if _, err := g.runGitCommand(g.worktreePath, "merge-base", "--is-ancestor", rev, "HEAD"); err != nil {
+		return fmt.Errorf("commit %s is not on the session branch", rev)
+	}
+	return nil
+}
+
+// commitTree creates a commit with the tree of treeRev, the author of authorRev and parent as its
+// only parent. It returns the new commit's SHA.
+func (g *GitWorktree) commitTree(treeRev, authorRev, parent, message string) (string, error) {
+	out, err := g.runGitCommand(g.worktreePath, "log", "-1", "--format=%an%x1f%ae%x1f%ad", "--date=raw", authorRev)
+	if err != nil {
+		return "", fmt.Errorf("failed to read commit author: %w", err)
+	}
+	author := strings.Split(strings.TrimSpace(out), "\x1f")
+	if len(author) != 3 {
+		return "", fmt.Errorf("unexpected author of %s: %q", authorRev, out)
+	}
+	env := []string{"GIT_AUTHOR_NAME=" + author[0], "GIT_AUTHOR_EMAIL=" + author[1], "GIT_AUTHOR_DATE=" + author[2]}
+	out, err = g.runGitCommandEnv(g.worktreePath, env, "commit-tree", treeRev+"^{tree}", "-p", parent, "-m", message)
+	if err != nil {
+		return "", fmt.Errorf("failed to create commit: %w", err)
+	}
+	return strings.TrimSpace(out), nil
+}
+
+// replayOnto rebases the commits after upstream onto newBase. Uncommitted changes are stashed
+// and restored. On conflicts the rebase is aborted and the branch is left unchanged.
+func (g *GitWorktree) replayOnto(newBase, upstream string) error {
+	env := []string{"GIT_EDITOR=true"}
+	if _, err := g.runGitCommandEnv(g.worktreePath, env, "rebase", "--autostash", "--onto", newBase, upstream); err != nil {
+		_, _ = g.runGitCommand(g.worktreePath, "rebase", "--abort")
+		return fmt.Errorf("failed to rewrite history: %w", err)
+	}
+	return nil
+}
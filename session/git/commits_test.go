@@ -0,0 +1,182 @@
+package git
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing/object"
+)
+
+// setupCommitsWorktree returns a worktree with commits "one", "two" and "three" on top of the
+// base commit, each adding a file of the same name, and an uncommitted change to base.txt.
+func setupCommitsWorktree(t *testing.T) *GitWorktree {
+	t.Helper()
+	for _, kv := range [][2]string{
+		{"GIT_AUTHOR_NAME", "t"}, {"GIT_AUTHOR_EMAIL", "t@example.com"},
+		{"GIT_COMMITTER_NAME", "t"}, {"GIT_COMMITTER_EMAIL", "t@example.com"},
+	} {
+		t.Setenv(kv[0], kv[1])
+	}
+
+	repoPath := filepath.Join(t.TempDir(), "repo")
+	repo, err := git.PlainInit(repoPath, false)
+	if err != nil {
+		t.Fatalf("init repo: %v", err)
+	}
+	wt, err := repo.Worktree()
+	if err != nil {
+		t.Fatalf("worktree: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(repoPath, "base.txt"), []byte("base\n"), 0644); err != nil {
+		t.Fatalf("write base.txt: %v", err)
+	}
+	if _, err := wt.Add("base.txt"); err != nil {
+		t.Fatalf("add base.txt: %v", err)
+	}
+	if _, err := wt.Commit("init", &git.CommitOptions{Author: &object.Signature{Name: "t", Email: "t@example.com"}}); err != nil {
+		t.Fatalf("commit: %v", err)
+	}
+
+	gw, _, err := NewGitWorktree(repoPath, "commits")
+	if err != nil {
+		t.Fatalf("NewGitWorktree: %v", err)
+	}
+	if err := gw.Setup(); err != nil {
+		t.Fatalf("setup worktree: %v", err)
+	}
+	t.Cleanup(func() { _ = gw.Cleanup() })
+
+	for _, name := range []string{"one", "two", "three"} {
+		if err := os.WriteFile(filepath.Join(gw.worktreePath, name+".txt"), []byte(name+"\n"), 0644); err != nil {
+			t.Fatalf("write %s: %v", name, err)
+		}
+		if _, err := gw.runGitCommand(gw.worktreePath, "add", name+".txt"); err != nil {
+			t.Fatalf("add %s: %v", name, err)
+		}
+		if _, err := gw.runGitCommand(gw.worktreePath, "commit", "-m", name); err != nil {
+			t.Fatalf("commit %s: %v", name, err)
+		}
+	}
+	if err := os.WriteFile(filepath.Join(gw.worktreePath, "base.txt"), []byte("base\nwip\n"), 0644); err != nil {
+		t.Fatalf("modify base.txt: %v", err)
+	}
+	return gw
+}
+
+func subjects(t *testing.T, gw *GitWorktree) []string {
+	t.Helper()
+	commits, err := gw.Commits()
+	if err != nil {
+		t.Fatalf("Commits: %v", err)
+	}
+	var subjects []string
+	for _, c := range commits {
+		subjects = append(subjects, c.Subject)
+	}
+	return subjects
+}
+
+func assertSubjects(t *testing.T, gw *GitWorktree, want ...string) {
+	t.Helper()
+	got := subjects(t, gw)
+	if len(got) != len(want) {
+		t.Fatalf("expected commits %v, got %v", want, got)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("expected commits %v, got %v", want, got)
+		}
+	}
+}
+
+// assertWIP checks that the uncommitted change survived a history rewrite.
+func assertWIP(t *testing.T, gw *GitWorktree) {
+	t.Helper()
+	content, err := os.ReadFile(filepath.Join(gw.worktreePath, "base.txt"))
+	if err != nil || string(content) != "base\nwip\n" {
+		t.Fatalf("uncommitted change lost: %q, %v", content, err)
+	}
+}
+
+func TestCommits(t *testing.T) {
+	gw := setupCommitsWorktree(t)
+	commits, err := gw.Commits()
+	if err != nil {
+		t.Fatalf("Commits: %v", err)
+	}
+	if len(commits) != 3 {
+		t.Fatalf("expected 3 commits, got %d", len(commits))
+	}
+	c := commits[0]
+	if c.Subject != "three" || c.Author != "t" || c.Files != 1 || c.Added != 1 || c.Removed != 0 {
+		t.Fatalf("unexpected commit %+v", c)
+	}
+	if c.Date.IsZero() || c.ShortSHA == "" || len(c.SHA) < len(c.ShortSHA) {
+		t.Fatalf("unexpected commit metadata %+v", c)
+	}
+
+	stats := gw.DiffWith(DiffSpec{Mode: DiffCommit, Ref: commits[1].SHA})
+	if stats.Error != nil {
+		t.Fatalf("commit diff: %v", stats.Error)
+	}
+	files := ParseDiff(stats.Content)
+	if len(files) != 1 || files[0].Path() != "two.txt" {
+		t.Fatalf("expected the diff of two.txt, got %+v", files)
+	}
+}
+
+func TestRewordCommit(t *testing.T) {
+	gw := setupCommitsWorktree(t)
+	commits, _ := gw.Commits()
+	if err := gw.RewordCommit(commits[1].SHA, "second"); err != nil {
+		t.Fatalf("RewordCommit: %v", err)
+	}
+	assertSubjects(t, gw, "three", "second", "one")
+	assertWIP(t, gw)
+
+	if err := gw.RewordCommit(commits[0].SHA, " "); err == nil {
+		t.Fatalf("expected an error for an empty message")
+	}
+}
+
+func TestSquashCommit(t *testing.T) {
+	gw := setupCommitsWorktree(t)
+	commits, _ := gw.Commits()
+	if err := gw.SquashCommit(commits[0].SHA); err != nil {
+		t.Fatalf("SquashCommit: %v", err)
+	}
+	assertSubjects(t, gw, "two", "one")
+	assertWIP(t, gw)
+	message, err := gw.CommitMessage("HEAD")
+	if err != nil || message != "two\n\nthree" {
+		t.Fatalf("unexpected squashed message %q, %v", message, err)
+	}
+	if _, err := os.Stat(filepath.Join(gw.worktreePath, "three.txt")); err != nil {
+		t.Fatalf("squashed changes lost: %v", err)
+	}
+
+	// The first commit of the session has no parent on the branch to squash into.
+	commits, _ = gw.Commits()
+	if err := gw.SquashCommit(commits[len(commits)-1].SHA); err == nil {
+		t.Fatalf("expected an error when squashing the first commit")
+	}
+}
+
+func TestDropCommit(t *testing.T) {
+	gw := setupCommitsWorktree(t)
+	commits, _ := gw.Commits()
+	if err := gw.DropCommit(commits[1].SHA); err != nil {
+		t.Fatalf("DropCommit: %v", err)
+	}
+	assertSubjects(t, gw, "three", "one")
+	assertWIP(t, gw)
+	if _, err := os.Stat(filepath.Join(gw.worktreePath, "two.txt")); !os.IsNotExist(err) {
+		t.Fatalf("expected two.txt to be gone, got %v", err)
+	}
+
+	if err := gw.DropCommit(gw.GetBaseCommitSHA()); err == nil {
+		t.Fatalf("expected an error when dropping the base commit")
+	}
+}
@@ -1,6 +1,10 @@
 package git
 
 import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
 	"strconv"
 	"strings"
 )
@@ -13,6 +17,8 @@ type DiffStats struct {
 	Added int
 	// Removed is the number of removed lines
 	Removed int
+	// Spec is what the diff compares. It is the zero value for Diff.
+	Spec DiffSpec
 	// Error holds any error that occurred during diff computation
 	// This allows propagating setup errors (like missing base commit) without breaking the flow
 	Error error
@@ -55,22 +61,89 @@ func (g *GitWorktree) Diff() *DiffStats {
 	return stats
 }
 
-// DiffFull returns the full diff content and statistics. This operation is more expensive
-// and will stage untracked files with intent-to-add to include them in the diff.
-func (g *GitWorktree) DiffFull() *DiffStats {
-	stats := &DiffStats{}
+// DiffMode selects what a full diff compares the worktree with.
+type DiffMode int
 
-	// Stage untracked files with intent-to-add so they appear in the diff output
-	if _, err := g.runGitCommand(g.worktreePath, "add", "-N", "."); err != nil {
-		stats.Error = err
-		return stats
+const (
+	// DiffSinceBase shows every change since the session started, committed or not.
+	DiffSinceBase DiffMode = iota
+	// DiffUncommitted shows the changes that haven't been committed yet.
+	DiffUncommitted
+	// DiffLastCommit shows the changes of the last commit only.
+	DiffLastCommit
+	// DiffAgainstRef shows the differences between a ref, like the tip of main, and the worktree.
+	DiffAgainstRef
+	// DiffCommit shows the changes of the commit named by Ref.
+	DiffCommit
+	// DiffCheckpoint shows the differences between the checkpoint commit named by Ref and the
+	// worktree.
+	DiffCheckpoint
+)
+
+// DiffSpec selects the changes returned by DiffWith.
+type DiffSpec struct {
+	Mode DiffMode
+	// Ref is the branch, tag or commit compared with in DiffAgainstRef mode, the commit shown in
+	// DiffCommit mode or the checkpoint commit in DiffCheckpoint mode.
+	Ref string
+}
+
+// String returns a short description of the compared changes.
+func (s DiffSpec) String() string {
+	switch s.Mode {
+	case DiffUncommitted:
+		return "uncommitted changes"
+	case DiffLastCommit:
+		return "last commit"
+	case DiffAgainstRef:
+		return "compared with " + s.Ref
+	case DiffCommit:
+		return "commit " + s.Ref
+	case DiffCheckpoint:
+		return "compared with checkpoint " + shortSHA(s.Ref)
+	default:
+		return "changes since base"
 	}
+}
+
+// DiffFull returns the full diff content and statistics of the changes since the base commit,
+// including untracked files. This operation is more expensive than Diff.
+func (g *GitWorktree) DiffFull() *DiffStats {
+	return g.DiffWith(DiffSpec{})
+}
 
-	content, err := g.runGitCommand(g.worktreePath, "--no-pager", "diff", g.GetBaseCommitSHA())
+// DiffWith returns the full diff content and statistics for the given spec. Diffs against the
+// working tree include untracked files. The repository is not modified.
+func (g *GitWorktree) DiffWith(spec DiffSpec) *DiffStats {
+	stats := &DiffStats{Spec: spec}
+
+	var content string
+	var err error
+	switch spec.Mode {
+	case DiffLastCommit:
+		content, err = g.diffCommit("HEAD")
+	case DiffCommit:
+		content, err = g.diffCommit(spec.Ref)
+	case DiffUncommitted:
+		content, err = g.diffWorkingTree("HEAD")
+	case DiffAgainstRef, DiffCheckpoint:
+		if _, verr := g.runGitCommand(g.worktreePath, "rev-parse", "--verify", "--quiet", spec.Ref+"^{commit}"); verr != nil {
+			err = fmt.Errorf("unknown ref %q", spec.Ref)
+			break
+		}
+		content, err = g.diffWorkingTree(spec.Ref)
+	default:
+		if g.GetBaseCommitSHA() == "" {
+			err = fmt.Errorf("base commit SHA not set")
+			break
+		}
+		content, err = g.diffWorkingTree(g.GetBaseCommitSHA())
+	}
 	if err != nil {
 		stats.Error = err
 		return stats
 	}
+
 	// Count additions/removals from content body
 	for _, line := range strings.Split(content, "\n") {
 		if strings.HasPrefix(line, "+") && !strings.HasPrefix(line, "+++") {
@@ -82,3 +155,85 @@ func (g *GitWorktree) DiffFull() *DiffStats {
 	stats.Content = content
 	return stats
 }
+
+// diffWorkingTree diffs the working tree against from. Untracked files are added with
+// intent-to-add to a temporary copy of the index, so they show up as new files while the real
+// index stays untouched.
+func (g *GitWorktree) diffWorkingTree(from string) (string, error) {
+	index, err := g.tempIndex()
+	if err != nil {
+		return "", err
+	}
+	defer os.Remove(index)
+
+	env := []string{"GIT_INDEX_FILE=" + index}
+	if _, err := g.runGitCommandEnv(g.worktreePath, env, "add", "--intent-to-add", "."); err != nil {
+		return "", err
+	}
+	return g.runGitCommandEnv(g.worktreePath, env, "--no-pager", "diff", "--no-ext-diff", from)
+}
+
+// tempIndex returns the path of a copy of the worktree's index. The caller must remove it.
+func (g *GitWorktree) tempIndex() (string, error) {
+	out, err := g.runGitCommand(g.worktreePath, "rev-parse", "--git-path", "index")
+	if err != nil {
+		return "", err
+	}
+	indexPath := strings.TrimSpace(out)
+	if !filepath.IsAbs(indexPath) {
+		indexPath = filepath.Join(g.worktreePath, indexPath)
+	}
+
+	tmp, err := os.CreateTemp("", "claudesquad-index-*")
+	if err != nil {
+		return "", fmt.Errorf("failed to create temporary index: %w", err)
+	}
+	defer tmp.Close()
+	src, err := os.Open(indexPath)
+	if os.IsNotExist(err) {
+		// Git doesn't accept an empty file as index, but creates a missing one.
+		_ = os.Remove(tmp.Name())
+		return tmp.Name(), nil
+	}
+	if err != nil {
+		_ = os.Remove(tmp.Name())
+		return "", fmt.Errorf("failed to read index: %w", err)
+	}
+	defer src.Close()
+	if _, err := io.Copy(tmp, src); err != nil {
+		_ = os.Remove(tmp.Name())
+		return "", fmt.Errorf("failed to copy index: %w", err)
+	}
+	return tmp.Name(), nil
+}
+
+// diffCommit diffs a commit against its first parent, or against the empty tree for a root commit.
+func (g *GitWorktree) diffCommit(rev string) (string, error) {
+	parent := rev + "~1"
+	if _, err := g.runGitCommand(g.worktreePath, "rev-parse", "--verify", "--quiet", parent+"^{commit}"); err != nil {
+		out, err := g.runGitCommand(g.worktreePath, "hash-object", "-t", "tree", os.DevNull)
+		if err != nil {
+			return "", err
+		}
+		parent = strings.TrimSpace(out)
+	}
+	return g.runGitCommand(g.worktreePath, "--no-pager", "diff", "--no-ext-diff", parent, rev)
+}
+
+// Branches returns the local and remote-tracking branches of the repository.
+func (g *GitWorktree) Branches() ([]string, error) {
+	out, err := g.runGitCommand(g.worktreePath, "for-each-ref", "--format=%(refname:short)%09%(symref)", "refs/heads", "refs/remotes")
+	if err != nil {
+		return nil, fmt.Errorf("failed to list branches: %w", err)
+	}
+	var branches []string
+	for _, line := range strings.Split(strings.TrimSpace(out), "\n") {
+		name, symref, _ := strings.Cut(line, "\t")
+		// Skip symbolic refs like origin/HEAD.
+		if name == "" || symref != "" {
+			continue
+		}
+		branches = append(branches, name)
+	}
+	return branches, nil
+}
@@ -0,0 +1,282 @@
+package git
+
+import (
+	"fmt"
+	"hash/fnv"
+	"strconv"
+	"strings"
+)
+
+// LineKind is the type of a line in a diff hunk.
+type LineKind int
+
+const (
+	LineContext LineKind = iota
+	LineAdded
+	LineRemoved
+)
+
+// DiffLine is a single line of a hunk.
+type DiffLine struct {
+	Kind LineKind
+	// Text is the line content without the leading '+', '-' or ' '.
+	Text string
+	// OldLine and NewLine are the 1-based line numbers in the old and new file. They are 0 when
+	// the line doesn't exist on that side.
+	OldLine int
+	NewLine int
+	// NoNewline is set when the line is followed by "\ No newline at end of file".
+	NoNewline bool
+}
+
+// Hunk is a contiguous block of changes in a file.
+type Hunk struct {
+	// Header is the full "@@ -a,b +c,d @@ ..." line.
+	Header   string
+	OldStart int
+	OldCount int
+	NewStart int
+	NewCount int
+	Lines    []DiffLine
+}
+
+// FileDiff holds the changes to a single file.
+type FileDiff struct {
+	OldPath string
+	NewPath string
+	// Header holds the lines from "diff --git" up to the first hunk.
+	Header []string
+	Hunks  []Hunk
+	Binary bool
+	// Added and Removed count the changed lines in the file.
+	Added   int
+	Removed int
+}
+
+// Path returns the path of the file after the change, or before it if the file was deleted.
+func (f *FileDiff) Path() string {
+	if f.NewPath == "" {
+		return f.OldPath
+	}
+	return f.NewPath
+}
+
+// IsNew returns true if the file was created by the change.
+func (f *FileDiff) IsNew() bool { return f.OldPath == "" }
+
+// IsDeleted returns true if the file was removed by the change.
+func (f *FileDiff) IsDeleted() bool { return f.NewPath == "" }
+
+// Patch returns the file's diff in unified format, suitable for git apply.
+func (f *FileDiff) Patch() string {
+	var b strings.Builder
+	for _, h := range f.Header {
+		b.WriteString(h)
+		b.WriteByte('\n')
+	}
+	for i := range f.Hunks {
+		f.Hunks[i].write(&b)
+	}
+	return b.String()
+}
+
+// HunkPatch returns a patch containing only the given hunk of the file, suitable for git apply.
+func (f *FileDiff) HunkPatch(hunk int) string {
+	var b strings.Builder
+	for _, h := range f.Header {
+		b.WriteString(h)
+		b.WriteByte('\n')
+	}
+	f.Hunks[hunk].write(&b)
+	return b.String()
+}
+
+// Hash returns a short fingerprint of the file's diff. It changes whenever the diff does.
+func (f *FileDiff) Hash() string {
+	h := fnv.New64a()
+	_, _ = h.Write([]byte(f.Patch()))
+	return strconv.FormatUint(h.Sum64(), 16)
+}
+
+// Hash returns a short fingerprint of the hunk's lines. Unlike the header, it doesn't change when
+// the hunk moves because of changes elsewhere in the file.
+func (h *Hunk) Hash() string {
+	f := fnv.New64a()
+	for _, l := range h.Lines {
+		_, _ = fmt.Fprintf(f, "%d%s\n", l.Kind, l.Text)
+	}
+	return strconv.FormatUint(f.Sum64(), 16)
+}
+
+func (h *Hunk) write(b *strings.Builder) {
+	b.WriteString(h.Header)
+	b.WriteByte('\n')
+	for _, l := range h.Lines {
+		switch l.Kind {
+		case LineAdded:
+			b.WriteByte('+')
+		case LineRemoved:
+			b.WriteByte('-')
+		default:
+			b.WriteByte(' ')
+		}
+		b.WriteString(l.Text)
+		b.WriteByte('\n')
+		if l.NoNewline {
+			b.WriteString("\\ No newline at end of file\n")
+		}
+	}
+}
+
+// ParseDiff parses the output of git diff into per-file changes. Lines that can't be attributed
+// to a file are ignored.
+func ParseDiff(content string) []FileDiff {
+	var files []FileDiff
+	var file *FileDiff
+	var hunk *Hunk
+	oldNo, newNo := 0, 0
+
+	lines := strings.Split(content, "\n")
+	// A trailing newline produces an empty last element that is not part of the diff.
+	if len(lines) > 0 && lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+	for _, line := range lines {
+		if strings.HasPrefix(line, "diff --git ") {
+			files = append(files, FileDiff{})
+			file = &files[len(files)-1]
+			hunk = nil
+			file.OldPath, file.NewPath = parseGitHeaderPaths(line)
+			file.Header = append(file.Header, line)
+			continue
+		}
+		if file == nil {
+			continue
+		}
+
+		if strings.HasPrefix(line, "@@") {
+			h, err := parseHunkHeader(line)
+			if err != nil {
+				// Not a hunk header after all; keep it as metadata.
+				file.Header = append(file.Header, line)
+				continue
+			}
+			file.Hunks = append(file.Hunks, h)
+			hunk = &file.Hunks[len(file.Hunks)-1]
+			oldNo, newNo = h.OldStart, h.NewStart
+			continue
+		}
+
+		if hunk == nil {
+			file.Header = append(file.Header, line)
+			switch {
+			case strings.HasPrefix(line, "--- "):
+				file.OldPath = parsePatchPath(strings.TrimPrefix(line, "--- "), "a/")
+			case strings.HasPrefix(line, "+++ "):
+				file.NewPath = parsePatchPath(strings.TrimPrefix(line, "+++ "), "b/")
+			case strings.HasPrefix(line, "new file mode"):
+				file.OldPath = ""
+			case strings.HasPrefix(line, "deleted file mode"):
+				file.NewPath = ""
+			case strings.HasPrefix(line, "Binary files") || line == "GIT binary patch":
+				file.Binary = true
+			}
+			continue
+		}
+
+		if line == "" {
+			// Some tools strip the trailing space of empty context lines.
+			line = " "
+		}
+		switch line[0] {
+		case '+':
+			hunk.Lines = append(hunk.Lines, DiffLine{Kind: LineAdded, Text: line[1:], NewLine: newNo})
+			newNo++
+			file.Added++
+		case '-':
+			hunk.Lines = append(hunk.Lines, DiffLine{Kind: LineRemoved, Text: line[1:], OldLine: oldNo})
+			oldNo++
+			file.Removed++
+		case ' ':
+			hunk.Lines = append(hunk.Lines, DiffLine{Kind: LineContext, Text: line[1:], OldLine: oldNo, NewLine: newNo})
+			oldNo++
+			newNo++
+		case '\\':
+			if n := len(hunk.Lines); n > 0 {
+				hunk.Lines[n-1].NoNewline = true
+			}
+		default:
+			// Anything else ends the hunk, e.g. a truncation marker.
+			hunk = nil
+		}
+	}
+	return files
+}
+
+// parseGitHeaderPaths extracts the paths from a "diff --git a/x b/y" line. It is a fallback for
+// diffs without ---/+++ lines, such as pure renames or mode changes.
+func parseGitHeaderPaths(line string) (string, string) {
+	rest := strings.TrimPrefix(line, "diff --git ")
+	if i := strings.Index(rest, " b/"); i >= 0 && strings.HasPrefix(rest, "a/") {
+		return unquotePath(rest[2:i]), unquotePath(rest[i+3:])
+	}
+	fields := strings.Fields(rest)
+	if len(fields) == 2 {
+		return parsePatchPath(fields[0], "a/"), parsePatchPath(fields[1], "b/")
+	}
+	return rest, rest
+}
+
+func parsePatchPath(p, prefix string) string {
+	// Timestamps may follow the path after a tab.
+	if i := strings.IndexByte(p, '\t'); i >= 0 {
+		p = p[:i]
+	}
+	p = unquotePath(p)
+	if p == "/dev/null" {
+		return ""
+	}
+	return strings.TrimPrefix(p, prefix)
+}
+
+func unquotePath(p string) string {
+	if len(p) >= 2 && p[0] == '"' && p[len(p)-1] == '"' {
+		if s, err := strconv.Unquote(p); err == nil {
+			return s
+		}
+	}
+	return p
+}
+
+// parseHunkHeader parses "@@ -a,b +c,d @@ section".
+func parseHunkHeader(line string) (Hunk, error) {
+	h := Hunk{Header: line}
+	end := strings.Index(line[2:], "@@")
+	if end < 0 {
+		return h, fmt.Errorf("malformed hunk header %q", line)
+	}
+	fields := strings.Fields(line[2 : end+2])
+	if len(fields) != 2 || fields[0][0] != '-' || fields[1][0] != '+' {
+		return h, fmt.Errorf("malformed hunk header %q", line)
+	}
+	var err error
+	if h.OldStart, h.OldCount, err = parseRange(fields[0][1:]); err != nil {
+		return h, err
+	}
+	if h.NewStart, h.NewCount, err = parseRange(fields[1][1:]); err != nil {
+		return h, err
+	}
+	return h, nil
+}
+
+func parseRange(r string) (start, count int, err error) {
+	count = 1
+	if i := strings.IndexByte(r, ','); i >= 0 {
+		if count, err = strconv.Atoi(r[i+1:]); err != nil {
+			return 0, 0, err
+		}
+		r = r[:i]
+	}
+	start, err = strconv.Atoi(r)
+	return start, count, err
+}
@@ -0,0 +1,83 @@
+package git
+
+import (
+	"strings"
+	"testing"
+)
+
+const sampleDiff = `diff --git a/main.go b/main.go
+index 1111111..2222222 100644
+--- a/main.go
++++ b/main.go
+@@ -1,4 +1,4 @@ package main
+ package main
+-func old() {}
++func renamed() {}
+ 
+ // end
+\ No newline at end of file
+diff --git a/new.txt b/new.txt
+new file mode 100644
+index 0000000..3333333
+--- /dev/null
++++ b/new.txt
+@@ -0,0 +1,2 @@
++one
++two
+diff --git a/gone.txt b/gone.txt
+deleted file mode 100644
+index 4444444..0000000
+--- a/gone.txt
++++ /dev/null
+@@ -1 +0,0 @@
+-bye
+`
+
+func TestParseDiff(t *testing.T) {
+	files := ParseDiff(sampleDiff)
+	if len(files) != 3 {
+		t.Fatalf("expected 3 files, got %d", len(files))
+	}
+
+	main := files[0]
+	if main.Path() != "main.go" || main.Added != 1 || main.Removed != 1 {
+		t.Fatalf("unexpected main.go diff: path=%q +%d -%d", main.Path(), main.Added, main.Removed)
+	}
+	if len(main.Hunks) != 1 || len(main.Hunks[0].Lines) != 5 {
+		t.Fatalf("expected 1 hunk with 5 lines, got %+v", main.Hunks)
+	}
+	lines := main.Hunks[0].Lines
+	if lines[1].Kind != LineRemoved || lines[1].OldLine != 2 || lines[1].NewLine != 0 {
+		t.Fatalf("unexpected removed line: %+v", lines[1])
+	}
+	if lines[2].Kind != LineAdded || lines[2].NewLine != 2 || lines[2].Text != "func renamed() {}" {
+		t.Fatalf("unexpected added line: %+v", lines[2])
+	}
+	if lines[3].Kind != LineContext || lines[3].Text != "" || lines[3].OldLine != 3 || lines[3].NewLine != 3 {
+		t.Fatalf("unexpected empty context line: %+v", lines[3])
+	}
+	if !lines[4].NoNewline {
+		t.Fatalf("expected the last line to have no trailing newline")
+	}
+
+	if !files[1].IsNew() || files[1].Path() != "new.txt" || files[1].Added != 2 {
+		t.Fatalf("unexpected new file diff: %+v", files[1])
+	}
+	if !files[2].IsDeleted() || files[2].Path() != "gone.txt" || files[2].Hunks[0].OldCount != 1 {
+		t.Fatalf("unexpected deleted file diff: %+v", files[2])
+	}
+}
+
+func TestFileDiffPatchRoundTrip(t *testing.T) {
+	files := ParseDiff(sampleDiff)
+	var b strings.Builder
+	for i := range files {
+		b.WriteString(files[i].Patch())
+	}
+	if b.String() != sampleDiff {
+		t.Fatalf("patch does not round trip:\n%s", b.String())
+	}
+	if files[0].Hash() == files[1].Hash() {
+		t.Fatalf("expected different hashes for different files")
+	}
+}
@@ -3,6 +3,7 @@ package git
 import (
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
 
 	"github.com/go-git/go-git/v5"
@@ -74,3 +75,91 @@ func TestDiffNumstatAndFull(t *testing.T) {
 		t.Fatalf("expected at least 3 added lines, got %d", fd.Added)
 	}
 }
+
+func TestDiffWithModes(t *testing.T) {
+	tmp := t.TempDir()
+	repoPath := filepath.Join(tmp, "repo")
+
+	repo, err := git.PlainInit(repoPath, false)
+	if err != nil {
+		t.Fatalf("init repo: %v", err)
+	}
+	wt, err := repo.Worktree()
+	if err != nil {
+		t.Fatalf("worktree: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(repoPath, "a.txt"), []byte("one\n"), 0644); err != nil {
+		t.Fatalf("write a.txt: %v", err)
+	}
+	if _, err := wt.Add("a.txt"); err != nil {
+		t.Fatalf("add a.txt: %v", err)
+	}
+	if _, err := wt.Commit("init", &git.CommitOptions{Author: &object.Signature{Name: "t", Email: "t@example.com"}}); err != nil {
+		t.Fatalf("commit: %v", err)
+	}
+
+	gw, _, err := NewGitWorktree(repoPath, "modes")
+	if err != nil {
+		t.Fatalf("NewGitWorktree: %v", err)
+	}
+	if err := gw.Setup(); err != nil {
+		t.Fatalf("setup worktree: %v", err)
+	}
+	defer func() { _ = gw.Cleanup() }()
+
+	// Commit a change to a.txt, then leave b.txt modified and c.txt untracked.
+	write := func(name, content string) {
+		if err := os.WriteFile(filepath.Join(gw.worktreePath, name), []byte(content), 0644); err != nil {
+			t.Fatalf("write %s: %v", name, err)
+		}
+	}
+	write("a.txt", "one\ntwo\n")
+	write("b.txt", "b\n")
+	if _, err := gw.runGitCommand(gw.worktreePath, "add", "a.txt", "b.txt"); err != nil {
+		t.Fatalf("stage: %v", err)
+	}
+	if _, err := gw.runGitCommand(gw.worktreePath, "-c", "user.name=t", "-c", "user.email=t@example.com", "commit", "-m", "agent"); err != nil {
+		t.Fatalf("commit: %v", err)
+	}
+	write("b.txt", "b\nb2\n")
+	write("c.txt", "c\n")
+
+	paths := func(spec DiffSpec) []string {
+		stats := gw.DiffWith(spec)
+		if stats.Error != nil {
+			t.Fatalf("%s: %v", spec, stats.Error)
+		}
+		var paths []string
+		for _, f := range ParseDiff(stats.Content) {
+			paths = append(paths, f.Path())
+		}
+		return paths
+	}
+	cases := []struct {
+		spec DiffSpec
+		want []string
+	}{
+		{DiffSpec{Mode: DiffSinceBase}, []string{"a.txt", "b.txt", "c.txt"}},
+		{DiffSpec{Mode: DiffUncommitted}, []string{"b.txt", "c.txt"}},
+		{DiffSpec{Mode: DiffLastCommit}, []string{"a.txt", "b.txt"}},
+		{DiffSpec{Mode: DiffAgainstRef, Ref: gw.GetBaseCommitSHA()}, []string{"a.txt", "b.txt", "c.txt"}},
+	}
+	for _, c := range cases {
+		if got := paths(c.spec); strings.Join(got, ",") != strings.Join(c.want, ",") {
+			t.Errorf("%s: expected %v, got %v", c.spec, c.want, got)
+		}
+	}
+
+	if stats := gw.DiffWith(DiffSpec{Mode: DiffAgainstRef, Ref: "no-such-branch"}); stats.Error == nil {
+		t.Errorf("expected an error for an unknown ref")
+	}
+
+	// The real index is left alone: c.txt is still untracked.
+	status, err := gw.runGitCommand(gw.worktreePath, "status", "--porcelain")
+	if err != nil {
+		t.Fatalf("status: %v", err)
+	}
+	if !strings.Contains(status, "?? c.txt") {
+		t.Errorf("expected c.txt to stay untracked, got %q", status)
+	}
+}
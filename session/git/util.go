@@ -35,22 +35,6 @@ func sanitizeBranchName(s string) string {
 	return s
 }
 
-// checkGHCLI checks if GitHub CLI is installed and configured
-func checkGHCLI() error {
-	// Check if gh is installed
-	if _, err := exec.LookPath("gh"); err != nil {
-		return fmt.Errorf("GitHub CLI (gh) is not installed. Please install it first")
-	}
-
-	// Check if gh is authenticated
-	cmd := exec.Command("gh", "auth", "status")
-	if err := cmd.Run(); err != nil {
-		return fmt.Errorf("GitHub CLI is not configured. Please run 'gh auth login' first")
-	}
-
-	return nil
-}
-
 // IsGitRepo checks if the given path is within a git repository
 func IsGitRepo(path string) bool {
 	for {
@@ -1,16 +1,28 @@
 package git
 
 import (
+	"claude-squad/forge"
 	"claude-squad/log"
+	"context"
 	"fmt"
+	"os"
 	"os/exec"
+	"path/filepath"
 	"strings"
 )
 
 // runGitCommand executes a git command and returns any error
 func (g *GitWorktree) runGitCommand(path string, args ...string) (string, error) {
+	return g.runGitCommandEnv(path, nil, args...)
+}
+
+// runGitCommandEnv executes a git command with additional environment variables
+func (g *GitWorktree) runGitCommandEnv(path string, env []string, args ...string) (string, error) {
 	baseArgs := []string{"-C", path}
 	cmd := exec.Command("git", append(baseArgs, args...)...)
+	if env != nil {
+		cmd.Env = append(os.Environ(), env...)
+	}
 
 	output, err := cmd.CombinedOutput()
 	if err != nil {
@@ -20,62 +32,31 @@ func (g *GitWorktree) runGitCommand(path string, args ...string) (string, error)
 	return string(output), nil
 }
 
-// PushChanges commits and pushes changes in the worktree to the remote branch
-func (g *GitWorktree) PushChanges(commitMessage string, open bool) error {
-	if err := checkGHCLI(); err != nil {
+// PushChanges commits any uncommitted changes and pushes the branch with the forge provider. The
+// push is canceled with ctx.
+func (g *GitWorktree) PushChanges(ctx context.Context, commitMessage string, provider forge.Provider) error {
+	if err := g.CommitChanges(commitMessage); err != nil {
 		return err
 	}
+	return provider.Push(ctx, g.worktreePath, g.branchName)
+}
 
-	// Check if there are any changes to commit
-	isDirty, err := g.IsDirty()
+// RemoteURL returns the URL of a git remote of the repository
+func (g *GitWorktree) RemoteURL(remote string) (string, error) {
+	output, err := g.runGitCommand(g.repoPath, "remote", "get-url", remote)
 	if err != nil {
-		return fmt.Errorf("failed to check for changes: %w", err)
-	}
-
-	if isDirty {
-		// Stage all changes
-		if _, err := g.runGitCommand(g.worktreePath, "add", "."); err != nil {
-			log.ErrorLog.Print(err)
-			return fmt.Errorf("failed to stage changes: %w", err)
-		}
-
-		// Create commit
-		if _, err := g.runGitCommand(g.worktreePath, "commit", "-m", commitMessage, "--no-verify"); err != nil {
-			log.ErrorLog.Print(err)
-			return fmt.Errorf("failed to commit changes: %w", err)
-		}
-	}
-
-	// First push the branch to remote to ensure it exists
-	pushCmd := exec.Command("gh", "repo", "sync", "--source", "-b", g.branchName)
-	pushCmd.Dir = g.worktreePath
-	if err := pushCmd.Run(); err != nil {
-		// If sync fails, try creating the branch on remote first
-		gitPushCmd := exec.Command("git", "push", "-u", "origin", g.branchName)
-		gitPushCmd.Dir = g.worktreePath
-		if pushOutput, pushErr := gitPushCmd.CombinedOutput(); pushErr != nil {
-			log.ErrorLog.Print(pushErr)
-			return fmt.Errorf("failed to push branch: %s (%w)", pushOutput, pushErr)
-		}
-	}
-
-	// Now sync with remote
-	syncCmd := exec.Command("gh", "repo", "sync", "-b", g.branchName)
-	syncCmd.Dir = g.worktreePath
-	if output, err := syncCmd.CombinedOutput(); err != nil {
-		log.ErrorLog.Print(err)
-		return fmt.Errorf("failed to sync changes: %s (%w)", output, err)
+		return "", fmt.Errorf("failed to get the URL of remote %s: %w", remote, err)
 	}
+	return strings.TrimSpace(output), nil
+}
 
-	// Open the branch in the browser
-	if open {
-		if err := g.OpenBranchURL(); err != nil {
-			// Just log the error but don't fail the push operation
-			log.ErrorLog.Printf("failed to open branch URL: %v", err)
-		}
+// DefaultBranch returns the branch the remote's HEAD points to, falling back to "main"
+func (g *GitWorktree) DefaultBranch(remote string) string {
+	output, err := g.runGitCommand(g.repoPath, "symbolic-ref", "--short", "refs/remotes/"+remote+"/HEAD")
+	if err != nil {
+		return "main"
 	}
-
-	return nil
+	return strings.TrimPrefix(strings.TrimSpace(output), remote+"/")
 }
 
 // CommitChanges commits changes locally without pushing to remote
@@ -121,17 +102,27 @@ func (g *GitWorktree) IsBranchCheckedOut() (bool, error) {
 	return strings.TrimSpace(string(output)) == g.branchName, nil
 }
 
-// OpenBranchURL opens the branch URL in the default browser
-func (g *GitWorktree) OpenBranchURL() error {
-	// Check if GitHub CLI is available
-	if err := checkGHCLI(); err != nil {
-		return err
+// RevertPatch undoes a patch in the worktree by applying it in reverse. The patch must be part of
+// the worktree's diff against the base commit, like a file or hunk patch from ParseDiff.
+func (g *GitWorktree) RevertPatch(patch string) error {
+	cmd := exec.Command("git", "-C", g.worktreePath, "apply", "-R", "--whitespace=nowarn", "-")
+	cmd.Stdin = strings.NewReader(patch)
+	if output, err := cmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("failed to revert changes: %s (%w)", output, err)
 	}
 
-	cmd := exec.Command("gh", "browse", "--branch", g.branchName)
-	cmd.Dir = g.worktreePath
-	if err := cmd.Run(); err != nil {
-		return fmt.Errorf("failed to open branch URL: %w", err)
+	// Reverting the creation of a file deletes it from the working tree. Drop it from the index
+	// too, where it may have been staged, so it doesn't linger as a deleted file.
+	for _, f := range ParseDiff(patch) {
+		if !f.IsNew() {
+			continue
+		}
+		if _, err := os.Stat(filepath.Join(g.worktreePath, f.NewPath)); !os.IsNotExist(err) {
+			continue
+		}
+		if _, err := g.runGitCommand(g.worktreePath, "update-index", "--force-remove", "--", f.NewPath); err != nil {
+			return fmt.Errorf("failed to remove %s from the index: %w", f.NewPath, err)
+		}
 	}
 	return nil
 }
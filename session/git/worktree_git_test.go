@@ -0,0 +1,90 @@
+package git
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing/object"
+)
+
+func TestRevertPatch(t *testing.T) {
+	tmp := t.TempDir()
+	repoPath := filepath.Join(tmp, "repo")
+
+	repo, err := git.PlainInit(repoPath, false)
+	if err != nil {
+		t.Fatalf("init repo: %v", err)
+	}
+	wt, err := repo.Worktree()
+	if err != nil {
+		t.Fatalf("worktree: %v", err)
+	}
+	var original []string
+	for i := 1; i <= 20; i++ {
+		original = append(original, strings.Repeat("x", i))
+	}
+	if err := os.WriteFile(filepath.Join(repoPath, "a.txt"), []byte(strings.Join(original, "\n")+"\n"), 0644); err != nil {
+		t.Fatalf("write a.txt: %v", err)
+	}
+	if _, err := wt.Add("a.txt"); err != nil {
+		t.Fatalf("add a.txt: %v", err)
+	}
+	if _, err := wt.Commit("init", &git.CommitOptions{Author: &object.Signature{Name: "t", Email: "t@example.com"}}); err != nil {
+		t.Fatalf("commit: %v", err)
+	}
+
+	gw, _, err := NewGitWorktree(repoPath, "review")
+	if err != nil {
+		t.Fatalf("NewGitWorktree: %v", err)
+	}
+	if err := gw.Setup(); err != nil {
+		t.Fatalf("setup worktree: %v", err)
+	}
+	defer func() { _ = gw.Cleanup() }()
+
+	// Change the first and the last line, which gives two hunks, and add a file.
+	changed := append([]string{"first"}, original[1:19]...)
+	changed = append(changed, "last")
+	if err := os.WriteFile(filepath.Join(gw.worktreePath, "a.txt"), []byte(strings.Join(changed, "\n")+"\n"), 0644); err != nil {
+		t.Fatalf("modify a.txt: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(gw.worktreePath, "new.txt"), []byte("hello\n"), 0644); err != nil {
+		t.Fatalf("write new.txt: %v", err)
+	}
+
+	files := ParseDiff(gw.DiffFull().Content)
+	if len(files) != 2 || len(files[0].Hunks) != 2 {
+		t.Fatalf("expected a.txt with two hunks and new.txt, got %+v", files)
+	}
+
+	// Revert only the second hunk of a.txt.
+	if err := gw.RevertPatch(files[0].HunkPatch(1)); err != nil {
+		t.Fatalf("revert hunk: %v", err)
+	}
+	content, err := os.ReadFile(filepath.Join(gw.worktreePath, "a.txt"))
+	if err != nil {
+		t.Fatalf("read a.txt: %v", err)
+	}
+	want := append([]string{"first"}, original[1:]...)
+	if string(content) != strings.Join(want, "\n")+"\n" {
+		t.Fatalf("unexpected a.txt after revert:\n%s", content)
+	}
+
+	// Revert the new file entirely.
+	if err := gw.RevertPatch(files[1].Patch()); err != nil {
+		t.Fatalf("revert file: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(gw.worktreePath, "new.txt")); !os.IsNotExist(err) {
+		t.Fatalf("expected new.txt to be removed, got %v", err)
+	}
+	status, err := gw.runGitCommand(gw.worktreePath, "status", "--porcelain")
+	if err != nil {
+		t.Fatalf("status: %v", err)
+	}
+	if strings.TrimSpace(status) != "M a.txt" {
+		t.Fatalf("expected only a.txt to be modified, got %q", status)
+	}
+}
@@ -219,6 +219,10 @@ func (g *GitWorktree) Cleanup() error {
 		errs = append(errs, fmt.Errorf("error checking branch %s existence: %w", g.branchName, err))
 	}
 
+	if err := g.DeleteCheckpoints(); err != nil {
+		errs = append(errs, err)
+	}
+
 	// Prune the worktree to clean up any remaining references
 	if err := g.Prune(); err != nil {
 		errs = append(errs, err)
@@ -245,7 +249,10 @@ func (g *GitWorktree) CleanupDirect() error {
 	}
 
 	// In direct mode, we don't remove branches or worktrees
-	// The user is responsible for managing their branches
+	// The user is responsible for managing their branches. Checkpoints are ours to remove.
+	if err := g.DeleteCheckpoints(); err != nil {
+		log.ErrorLog.Printf("failed to delete checkpoints of %s: %v", g.branchName, err)
+	}
 	return nil
 }
 
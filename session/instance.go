@@ -1,14 +1,20 @@
 package session
 
 import (
+	"claude-squad/forge"
 	"claude-squad/log"
+	"claude-squad/session/events"
 	"claude-squad/session/git"
+	"claude-squad/session/resources"
 	"claude-squad/session/tmux"
+	"claude-squad/session/usage"
 	"path/filepath"
 
 	"fmt"
 	"os"
 	"strings"
+	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/atotto/clipboard"
@@ -49,6 +55,14 @@ type Instance struct {
 	UpdatedAt time.Time
 	// AutoYes is true if the instance should automatically press enter when prompted.
 	AutoYes bool
+	// AutoYesSince is when AutoYes was turned on. It starts the AutoYes budget.
+	AutoYesSince time.Time
+	// AutoYesApprovals is the number of confirmations AutoYes approved since it was turned on.
+	AutoYesApprovals int
+	// Muted is true if the instance should not send notifications.
+	Muted bool
+	// Sandbox describes the sandbox the agent runs in, or is nil if it runs without one.
+	Sandbox *SandboxState
 	// Prompt is the initial prompt to pass to the instance on startup
 	Prompt string
 	// DirectMode indicates if the session works directly on an existing branch
@@ -56,8 +70,17 @@ type Instance struct {
 	// DirectBranch is the branch name used in direct mode
 	DirectBranch string
 
+	// DiffView holds the review state of the diff: viewed files, approved hunks and comments.
+	DiffView DiffViewState
+	// PullRequest is the last known status of the pull request opened for the instance, if any.
+	PullRequest *forge.PullRequestStatus
+	// FixLoop records the failed checks that were sent to the agent to fix.
+	FixLoop FixLoopState
+
 	// DiffStats stores the current git diff statistics
 	diffStats *git.DiffStats
+	// diffSpec selects the changes shown in the diff tab.
+	diffSpec git.DiffSpec
 
 	// The below fields are initialized upon calling Start().
 
@@ -66,6 +89,34 @@ type Instance struct {
 	tmuxSession *tmux.TmuxSession
 	// gitWorktree is the git worktree for the instance.
 	gitWorktree *git.GitWorktree
+	// shellSession is the tmux session of the shell opened in the worktree, if any.
+	shellSession *tmux.TmuxSession
+
+	// checkMu guards check, which is updated while the check command runs.
+	checkMu sync.Mutex
+	// check is the running or last finished check, if any.
+	check *checkRun
+	// prompting is true while the agent asks for a confirmation.
+	prompting atomic.Bool
+	// escalated is true while the current confirmation is left to the user by the AutoYes policy.
+	escalated atomic.Bool
+	// statusSince is when the status last changed. Timeouts are measured from it.
+	statusSince time.Time
+	// timedOut is true if CheckTimeout returned a timeout since the status last changed.
+	timedOut bool
+
+	// resourcesMu guards resourceGroup and usage, which are updated by SampleResources.
+	resourcesMu sync.Mutex
+	// resourceGroup is the process tree of the agent, which may be placed under resource limits.
+	resourceGroup *resources.Group
+	// usage is the last measured usage of resourceGroup.
+	usage *resources.Usage
+	// usageMu guards usageLedger, which is replaced by UpdateUsage.
+	usageMu sync.Mutex
+	// usageLedger holds the tokens and cost the agent used, or is nil until they were read.
+	usageLedger *usage.Ledger
+	// usageAlerted is the cost budget the session was last alerted about.
+	usageAlerted string
 }
 
 // ToInstanceData converts an Instance to its serializable form
@@ -81,8 +132,15 @@ func (i *Instance) ToInstanceData() InstanceData {
 		UpdatedAt:    time.Now(),
 		Program:      i.Program,
 		AutoYes:      i.AutoYes,
+		AutoYesSince: i.AutoYesSince,
+		Approvals:    i.AutoYesApprovals,
+		Muted:        i.Muted,
+		Sandbox:      i.Sandbox,
 		DirectMode:   i.DirectMode,
 		DirectBranch: i.DirectBranch,
+		DiffView:     i.DiffView,
+		PullRequest:  i.PullRequest,
+		FixLoop:      i.FixLoop,
 	}
 
 	// Only include worktree data if gitWorktree is initialized
@@ -129,6 +187,17 @@ func FromInstanceData(data InstanceData) (*Instance, error) {
 		DirectBranch: data.DirectBranch,
 		Program:      data.Program,
 		AutoYes:      data.AutoYes,
+		AutoYesSince: data.AutoYesSince,
+		Muted:        data.Muted,
+		Sandbox:      data.Sandbox,
+		DiffView:     data.DiffView,
+		PullRequest:  data.PullRequest,
+		FixLoop:      data.FixLoop,
+	}
+	instance.AutoYesApprovals = data.Approvals
+	if instance.AutoYes && instance.AutoYesSince.IsZero() {
+		// Saved before AutoYes had a budget.
+		instance.AutoYesSince = time.Now()
 	}
 
 	// Reconstruct GitWorktree based on mode
@@ -167,6 +236,7 @@ func FromInstanceData(data InstanceData) (*Instance, error) {
 		if err := instance.Start(false); err != nil {
 			return nil, err
 		}
+		instance.restoreShell()
 	}
 
 	return instance, nil
@@ -202,7 +272,7 @@ func NewInstance(opts InstanceOptions) (*Instance, error) {
 		return nil, fmt.Errorf("direct mode requires a branch name")
 	}
 
-	return &Instance{
+	instance := &Instance{
 		Title:        opts.Title,
 		Status:       Ready,
 		Path:         absPath,
@@ -211,10 +281,11 @@ func NewInstance(opts InstanceOptions) (*Instance, error) {
 		Width:        0,
 		CreatedAt:    t,
 		UpdatedAt:    t,
-		AutoYes:      opts.AutoYes,
 		DirectMode:   opts.DirectMode,
 		DirectBranch: opts.DirectBranch,
-	}, nil
+	}
+	instance.SetAutoYes(opts.AutoYes)
+	return instance, nil
 }
 
 func (i *Instance) RepoName() (string, error) {
@@ -225,7 +296,13 @@ func (i *Instance) RepoName() (string, error) {
 }
 
 func (i *Instance) SetStatus(status Status) {
+	previous := i.Status
 	i.Status = status
+	if status != previous {
+		i.statusSince = time.Now()
+		i.timedOut = false
+		i.publish(events.Event{Type: events.StatusChanged, PreviousStatus: previous.String()})
+	}
 }
 
 // firstTimeSetup is true if this is a new instance. Otherwise, it's one loaded from storage.
@@ -245,6 +322,7 @@ func (i *Instance) Start(firstTimeSetup bool) error {
 	i.tmuxSession = tmuxSession
 
 	if firstTimeSetup {
+		i.publish(events.Event{Type: events.Created})
 		var gitWorktree *git.GitWorktree
 		var branchName string
 		var err error
@@ -292,6 +370,14 @@ func (i *Instance) Start(firstTimeSetup bool) error {
 			return setupErr
 		}
 
+		if err := i.applySandbox(); err != nil {
+			if cleanupErr := i.gitWorktree.Cleanup(); cleanupErr != nil {
+				err = fmt.Errorf("%v (cleanup error: %v)", err, cleanupErr)
+			}
+			setupErr = err
+			return setupErr
+		}
+
 		// Create new session
 		if err := i.tmuxSession.Start(i.gitWorktree.GetWorktreePath()); err != nil {
 			// Cleanup git worktree if tmux session creation fails
@@ -304,6 +390,9 @@ func (i *Instance) Start(firstTimeSetup bool) error {
 	}
 
 	i.SetStatus(Running)
+	if firstTimeSetup {
+		i.publish(events.Event{Type: events.Started})
+	}
 
 	return nil
 }
@@ -318,12 +407,17 @@ func (i *Instance) Kill() error {
 	var errs []error
 
 	// Always try to cleanup both resources, even if one fails
-	// Clean up tmux session first since it's using the git worktree
+	// Clean up tmux sessions first since they're using the git worktree
+	i.CancelCheck()
+	if err := i.closeShell(); err != nil {
+		errs = append(errs, err)
+	}
 	if i.tmuxSession != nil {
 		if err := i.tmuxSession.Close(); err != nil {
 			errs = append(errs, fmt.Errorf("failed to close tmux session: %w", err))
 		}
 	}
+	i.closeResources()
 
 	// Then clean up git worktree
 	if i.gitWorktree != nil {
@@ -332,6 +426,8 @@ func (i *Instance) Kill() error {
 		}
 	}
 
+	i.publish(events.Event{Type: events.Killed})
+	log.CloseInstance(i.Title)
 	return i.combineErrors(errs)
 }
 
@@ -363,17 +459,15 @@ func (i *Instance) HasUpdated() (updated bool, hasPrompt bool) {
 	if !i.started {
 		return false, false
 	}
-	return i.tmuxSession.HasUpdated()
-}
-
-// TapEnter sends an enter key press to the tmux session if AutoYes is enabled.
-func (i *Instance) TapEnter() {
-	if !i.started || !i.AutoYes {
-		return
-	}
-	if err := i.tmuxSession.TapEnter(); err != nil {
-		log.ErrorLog.Printf("error tapping enter: %v", err)
+	updated, hasPrompt = i.tmuxSession.HasUpdated()
+	if i.prompting.Swap(hasPrompt) != hasPrompt {
+		if hasPrompt {
+			i.publish(events.Event{Type: events.PromptDetected})
+		} else {
+			i.escalated.Store(false)
+		}
 	}
+	return updated, hasPrompt
 }
 
 func (i *Instance) Attach() (chan struct{}, error) {
@@ -391,6 +485,11 @@ func (i *Instance) SetPreviewSize(width, height int) error {
 	// Persist the dimensions for viewport-bounded capture
 	i.Width = width
 	i.Height = height
+	if i.shellSession != nil {
+		if err := i.shellSession.SetDetachedSize(width, height); err != nil {
+			i.logger().Warn("failed to resize the shell", "err", err)
+		}
+	}
 	return i.tmuxSession.SetDetachedSize(width, height)
 }
 
@@ -425,8 +524,15 @@ func (i *Instance) TmuxAlive() bool {
 	return i.tmuxSession.DoesSessionExist()
 }
 
-// Pause stops the tmux session and removes the worktree, preserving the branch
+// Pause stops the tmux session and removes the worktree, preserving the branch. Uncommitted
+// changes are committed with a message describing the changed files.
 func (i *Instance) Pause() error {
+	return i.PauseWithMessage("")
+}
+
+// PauseWithMessage is like Pause but commits uncommitted changes with commitMsg. An empty
+// commitMsg uses the default message.
+func (i *Instance) PauseWithMessage(commitMsg string) error {
 	if !i.started {
 		return fmt.Errorf("cannot pause instance that has not been started")
 	}
@@ -439,22 +545,31 @@ func (i *Instance) Pause() error {
 	// Check if there are any changes to commit
 	if dirty, err := i.gitWorktree.IsDirty(); err != nil {
 		errs = append(errs, fmt.Errorf("failed to check if worktree is dirty: %w", err))
-		log.ErrorLog.Print(err)
+		i.logger().Error("failed to pause", "err", err)
 	} else if dirty {
 		// Commit changes locally (without pushing to GitHub)
-		commitMsg := fmt.Sprintf("[claudesquad] update from '%s' on %s (paused)", i.Title, time.Now().Format(time.RFC822))
+		if commitMsg == "" {
+			commitMsg = i.defaultCommitMessage()
+		}
 		if err := i.gitWorktree.CommitChanges(commitMsg); err != nil {
 			errs = append(errs, fmt.Errorf("failed to commit changes: %w", err))
-			log.ErrorLog.Print(err)
+			i.logger().Error("failed to pause", "err", err)
 			// Return early if we can't commit changes to avoid corrupted state
 			return i.combineErrors(errs)
 		}
 	}
 
+	// The working directory of the shell and the check is about to be removed.
+	i.CancelCheck()
+	if err := i.closeShell(); err != nil {
+		errs = append(errs, err)
+		i.logger().Error("failed to pause", "err", err)
+	}
+
 	// Detach from tmux session instead of closing to preserve session output
 	if err := i.tmuxSession.DetachSafely(); err != nil {
 		errs = append(errs, fmt.Errorf("failed to detach tmux session: %w", err))
-		log.ErrorLog.Print(err)
+		i.logger().Error("failed to pause", "err", err)
 		// Continue with pause process even if detach fails
 	}
 
@@ -463,24 +578,25 @@ func (i *Instance) Pause() error {
 		// Remove worktree but keep branch
 		if err := i.gitWorktree.Remove(); err != nil {
 			errs = append(errs, fmt.Errorf("failed to remove git worktree: %w", err))
-			log.ErrorLog.Print(err)
+			i.logger().Error("failed to pause", "err", err)
 			return i.combineErrors(errs)
 		}
 
 		// Only prune if remove was successful
 		if err := i.gitWorktree.Prune(); err != nil {
 			errs = append(errs, fmt.Errorf("failed to prune git worktrees: %w", err))
-			log.ErrorLog.Print(err)
+			i.logger().Error("failed to pause", "err", err)
 			return i.combineErrors(errs)
 		}
 	}
 
 	if err := i.combineErrors(errs); err != nil {
-		log.ErrorLog.Print(err)
+		i.logger().Error("failed to pause", "err", err)
 		return err
 	}
 
 	i.SetStatus(Paused)
+	i.publish(events.Event{Type: events.Paused})
 	_ = clipboard.WriteAll(i.gitWorktree.GetBranchName())
 	return nil
 }
@@ -496,7 +612,7 @@ func (i *Instance) Resume() error {
 
 	// Check if branch is checked out
 	if checked, err := i.gitWorktree.IsBranchCheckedOut(); err != nil {
-		log.ErrorLog.Print(err)
+		i.logger().Error("failed to resume", "err", err)
 		return fmt.Errorf("failed to check if branch is checked out: %w", err)
 	} else if checked {
 		return fmt.Errorf("cannot resume: branch is checked out, please switch to a different branch")
@@ -504,22 +620,34 @@ func (i *Instance) Resume() error {
 
 	// Setup git worktree
 	if err := i.gitWorktree.Setup(); err != nil {
-		log.ErrorLog.Print(err)
+		i.logger().Error("failed to resume", "err", err)
 		return fmt.Errorf("failed to setup git worktree: %w", err)
 	}
 
+	// A new agent is started in the sandbox that is configured now.
+	if !i.tmuxSession.DoesSessionExist() {
+		if err := i.applySandbox(); err != nil {
+			i.logger().Error("failed to resume", "err", err)
+			if cleanupErr := i.gitWorktree.Cleanup(); cleanupErr != nil {
+				err = fmt.Errorf("%v (cleanup error: %v)", err, cleanupErr)
+				i.logger().Error("failed to resume", "err", err)
+			}
+			return err
+		}
+	}
+
 	// Check if tmux session still exists from pause, otherwise create new one
 	if i.tmuxSession.DoesSessionExist() {
 		// Session exists, just restore PTY connection to it
 		if err := i.tmuxSession.Restore(); err != nil {
-			log.ErrorLog.Print(err)
+			i.logger().Error("failed to resume", "err", err)
 			// If restore fails, fall back to creating new session
 			if err := i.tmuxSession.Start(i.gitWorktree.GetWorktreePath()); err != nil {
-				log.ErrorLog.Print(err)
+				i.logger().Error("failed to resume", "err", err)
 				// Cleanup git worktree if tmux session creation fails
 				if cleanupErr := i.gitWorktree.Cleanup(); cleanupErr != nil {
 					err = fmt.Errorf("%v (cleanup error: %v)", err, cleanupErr)
-					log.ErrorLog.Print(err)
+					i.logger().Error("failed to resume", "err", err)
 				}
 				return fmt.Errorf("failed to start new session: %w", err)
 			}
@@ -527,17 +655,18 @@ func (i *Instance) Resume() error {
 	} else {
 		// Create new tmux session
 		if err := i.tmuxSession.Start(i.gitWorktree.GetWorktreePath()); err != nil {
-			log.ErrorLog.Print(err)
+			i.logger().Error("failed to resume", "err", err)
 			// Cleanup git worktree if tmux session creation fails
 			if cleanupErr := i.gitWorktree.Cleanup(); cleanupErr != nil {
 				err = fmt.Errorf("%v (cleanup error: %v)", err, cleanupErr)
-				log.ErrorLog.Print(err)
+				i.logger().Error("failed to resume", "err", err)
 			}
 			return fmt.Errorf("failed to start new session: %w", err)
 		}
 	}
 
 	i.SetStatus(Running)
+	i.publish(events.Event{Type: events.Resumed})
 	return nil
 }
 
@@ -554,7 +683,7 @@ func (i *Instance) UpdateDiffStats() error {
 	}
 
 	// When called (gated by Diff tab visibility), compute full diff including content
-	stats := i.gitWorktree.DiffFull()
+	stats := i.gitWorktree.DiffWith(i.diffSpec)
 	if stats.Error != nil {
 		if strings.Contains(stats.Error.Error(), "base commit SHA not set") {
 			// Worktree is not fully set up yet, not an error
@@ -578,6 +707,87 @@ func (i *Instance) SetDiffStats(stats *git.DiffStats) {
 	i.diffStats = stats
 }
 
+// DiffSpec returns what the diff tab compares.
+func (i *Instance) DiffSpec() git.DiffSpec {
+	return i.diffSpec
+}
+
+// SetDiffSpec selects what the diff tab compares. It takes effect on the next UpdateDiffStats.
+func (i *Instance) SetDiffSpec(spec git.DiffSpec) {
+	i.diffSpec = spec
+}
+
+// Branches returns the branches of the instance's repository, e.g. to compare the diff with.
+func (i *Instance) Branches() ([]string, error) {
+	if !i.started {
+		return nil, fmt.Errorf("instance not started")
+	}
+	return i.gitWorktree.Branches()
+}
+
+// worktreeForHistory returns the worktree of a started, running instance.
+func (i *Instance) worktreeForHistory() (*git.GitWorktree, error) {
+	if !i.started || i.Status == Paused {
+		return nil, fmt.Errorf("cannot access the history of an instance that has not been started or is paused")
+	}
+	return i.gitWorktree, nil
+}
+
+// Commits returns the commits made on the instance branch, newest first.
+func (i *Instance) Commits() ([]git.Commit, error) {
+	gw, err := i.worktreeForHistory()
+	if err != nil {
+		return nil, err
+	}
+	return gw.Commits()
+}
+
+// CommitDiff returns the changes of a commit on the instance branch.
+func (i *Instance) CommitDiff(sha string) (*git.DiffStats, error) {
+	gw, err := i.worktreeForHistory()
+	if err != nil {
+		return nil, err
+	}
+	stats := gw.DiffWith(git.DiffSpec{Mode: git.DiffCommit, Ref: sha})
+	return stats, stats.Error
+}
+
+// CommitMessage returns the full message of a commit on the instance branch.
+func (i *Instance) CommitMessage(sha string) (string, error) {
+	gw, err := i.worktreeForHistory()
+	if err != nil {
+		return "", err
+	}
+	return gw.CommitMessage(sha)
+}
+
+// SquashCommit melds a commit on the instance branch into its parent.
+func (i *Instance) SquashCommit(sha string) error {
+	gw, err := i.worktreeForHistory()
+	if err != nil {
+		return err
+	}
+	return gw.SquashCommit(sha)
+}
+
+// RewordCommit changes the message of a commit on the instance branch.
+func (i *Instance) RewordCommit(sha, message string) error {
+	gw, err := i.worktreeForHistory()
+	if err != nil {
+		return err
+	}
+	return gw.RewordCommit(sha, message)
+}
+
+// DropCommit removes a commit from the instance branch.
+func (i *Instance) DropCommit(sha string) error {
+	gw, err := i.worktreeForHistory()
+	if err != nil {
+		return err
+	}
+	return gw.DropCommit(sha)
+}
+
 // SendPrompt sends a prompt to the tmux session
 func (i *Instance) SendPrompt(prompt string) error {
 	if !i.started {
@@ -599,6 +809,28 @@ func (i *Instance) SendPrompt(prompt string) error {
 	return nil
 }
 
+// SendReview sends the pending review comments to the agent as one prompt and clears them.
+func (i *Instance) SendReview() error {
+	prompt := i.DiffView.ReviewPrompt()
+	if prompt == "" {
+		return fmt.Errorf("no review comments to send")
+	}
+	if err := i.SendPrompt(prompt); err != nil {
+		return err
+	}
+	i.Audit(AuditEntry{Source: AuditUser, Action: "review", Keys: prompt + "\r"})
+	i.DiffView.Comments = nil
+	return nil
+}
+
+// RevertPatch undoes a patch in the instance's worktree, e.g. a hunk rejected in review.
+func (i *Instance) RevertPatch(patch string) error {
+	if !i.started || i.Status == Paused {
+		return fmt.Errorf("cannot revert changes of an instance that has not been started or is paused")
+	}
+	return i.gitWorktree.RevertPatch(patch)
+}
+
 // PreviewFullHistory captures the entire tmux pane output including full scrollback history
 func (i *Instance) PreviewFullHistory() (string, error) {
 	if !i.started || i.Status == Paused {
@@ -0,0 +1,65 @@
+package session
+
+import (
+	"claude-squad/log"
+	"claude-squad/session/events"
+	"context"
+	"log/slog"
+)
+
+// eventBus receives the lifecycle events of all instances. Events are dropped while it is nil.
+var eventBus *events.Bus
+
+// SetEventBus sets the bus the instances publish their lifecycle events to.
+func SetEventBus(bus *events.Bus) {
+	eventBus = bus
+}
+
+// String returns the name of the status as used in events, e.g. "running".
+func (s Status) String() string {
+	switch s {
+	case Running:
+		return "running"
+	case Ready:
+		return "ready"
+	case Loading:
+		return "loading"
+	case Paused:
+		return "paused"
+	default:
+		return "unknown"
+	}
+}
+
+// logger returns the logger of the instance, which also writes to the instance's own log file if
+// that is turned on.
+func (i *Instance) logger() *slog.Logger {
+	return log.ForInstance(i.Title)
+}
+
+// publish fills in the instance's details, logs e and publishes it.
+func (i *Instance) publish(e events.Event) {
+	level := slog.LevelInfo
+	if e.Type == events.StatusChanged {
+		level = slog.LevelDebug
+	}
+	attrs := []any{"status", i.Status.String()}
+	for _, attr := range []struct{ key, value string }{
+		{"previous_status", e.PreviousStatus}, {"action", e.Action}, {"url", e.URL},
+	} {
+		if attr.value != "" {
+			attrs = append(attrs, attr.key, attr.value)
+		}
+	}
+	i.logger().Log(context.Background(), level, string(e.Type), attrs...)
+
+	if eventBus == nil {
+		return
+	}
+	e.Session = i.Title
+	e.Branch = i.Branch
+	e.Path = i.Path
+	e.Program = i.Program
+	e.Status = i.Status.String()
+	eventBus.Publish(e)
+}
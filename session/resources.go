@@ -0,0 +1,79 @@
+package session
+
+import (
+	"claude-squad/session/resources"
+	"net/url"
+	"time"
+)
+
+// resourceLimits are the limits agents are placed under.
+var resourceLimits resources.Limits
+
+// SetResourceLimits sets the CPU, memory and process limits of agents.
+func SetResourceLimits(limits resources.Limits) {
+	resourceLimits = limits
+}
+
+// SampleResources measures what the agent's processes use. The first time it sees the agent's
+// process, which is after every (re)start, it places the process tree under the resource limits.
+// It must not be called concurrently for the same instance.
+func (i *Instance) SampleResources() error {
+	if !i.started || i.Paused() || resources.Supported() != nil {
+		return nil
+	}
+	pid, err := i.tmuxSession.PanePID()
+	if err != nil {
+		return err
+	}
+
+	i.resourcesMu.Lock()
+	group := i.resourceGroup
+	i.resourcesMu.Unlock()
+	if group == nil || group.PID() != pid {
+		group = resources.New(url.PathEscape(i.Title), pid, resourceLimits)
+		if err := group.LimitErr(); err != nil {
+			i.logger().Warn("resource limits are not enforced", "err", err)
+		}
+		i.resourcesMu.Lock()
+		i.resourceGroup = group
+		i.resourcesMu.Unlock()
+	}
+
+	usage, err := group.Sample(time.Now())
+	if err != nil {
+		return err
+	}
+	i.resourcesMu.Lock()
+	i.usage = &usage
+	i.resourcesMu.Unlock()
+	return nil
+}
+
+// ResourceUsage returns the last measured usage of the agent's processes. It returns false if
+// nothing was measured yet.
+func (i *Instance) ResourceUsage() (resources.Usage, bool) {
+	i.resourcesMu.Lock()
+	defer i.resourcesMu.Unlock()
+	if i.usage == nil || i.Paused() {
+		return resources.Usage{}, false
+	}
+	return *i.usage, true
+}
+
+// ResourceGroup returns the process group of the agent, or nil if it was not measured yet.
+func (i *Instance) ResourceGroup() *resources.Group {
+	i.resourcesMu.Lock()
+	defer i.resourcesMu.Unlock()
+	return i.resourceGroup
+}
+
+// closeResources kills what is left of the agent's processes and forgets them.
+func (i *Instance) closeResources() {
+	i.resourcesMu.Lock()
+	group := i.resourceGroup
+	i.resourceGroup, i.usage = nil, nil
+	i.resourcesMu.Unlock()
+	if group != nil {
+		group.Close()
+	}
+}
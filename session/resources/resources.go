@@ -0,0 +1,488 @@
+// Package resources limits and measures the processes of agents. An agent's process tree is placed
+// in a cgroup v2 group with CPU, memory and process limits when the cgroup hierarchy is delegated
+// to the user. Otherwise the limits are not enforced and the usage is read from /proc.
+package resources
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Limits are the resources an agent and everything it starts may use. Zero values don't limit.
+type Limits struct {
+	// CPU is the number of CPU cores, e.g. 1.5.
+	CPU float64 `json:"cpu,omitempty"`
+	// MemoryMB is the memory in megabytes. Processes are killed when they need more.
+	MemoryMB int `json:"memory_mb,omitempty"`
+	// Processes is the number of processes and threads.
+	Processes int `json:"processes,omitempty"`
+}
+
+// Enabled returns true if any limit is set.
+func (l Limits) Enabled() bool {
+	return l.CPU > 0 || l.MemoryMB > 0 || l.Processes > 0
+}
+
+// String describes the limits, e.g. "1.5 CPUs, 2048 MB, 500 processes".
+func (l Limits) String() string {
+	var parts []string
+	if l.CPU > 0 {
+		parts = append(parts, strconv.FormatFloat(l.CPU, 'f', -1, 64)+" CPUs")
+	}
+	if l.MemoryMB > 0 {
+		parts = append(parts, fmt.Sprintf("%d MB", l.MemoryMB))
+	}
+	if l.Processes > 0 {
+		parts = append(parts, fmt.Sprintf("%d processes", l.Processes))
+	}
+	if len(parts) == 0 {
+		return "none"
+	}
+	return strings.Join(parts, ", ")
+}
+
+// Usage is what an agent's processes use at a point in time.
+type Usage struct {
+	Time time.Time
+	// CPU is the CPU time used since the previous sample, in percent of one core.
+	CPU float64
+	// Memory is the memory in bytes.
+	Memory uint64
+	// MemoryMax is the memory limit in bytes, or 0.
+	MemoryMax uint64
+	// Processes is the number of processes.
+	Processes int
+}
+
+// NearMemoryLimit returns true if the processes use 90% of their memory limit or more.
+func (u Usage) NearMemoryLimit() bool {
+	return u.MemoryMax > 0 && u.Memory >= u.MemoryMax/10*9
+}
+
+// Process is a process of an agent.
+type Process struct {
+	PID  int
+	PPID int
+	Name string
+	// RSS is the resident memory in bytes.
+	RSS uint64
+	// CPUTime is the CPU time the process used so far.
+	CPUTime time.Duration
+}
+
+// These are variables for testing.
+var (
+	procRoot   = "/proc"
+	cgroupRoot = "/sys/fs/cgroup"
+)
+
+// clockTick is the unit of CPU times in /proc. It is 100 Hz on all Linux architectures we run on.
+const clockTick = 10 * time.Millisecond
+
+// groupDir is the name of the cgroup that holds the groups of all agents.
+const groupDir = "claude-squad"
+
+// Supported returns an error if usage can't be measured on this system.
+func Supported() error {
+	if runtime.GOOS != "linux" {
+		return fmt.Errorf("resource usage is only measured on Linux")
+	}
+	return nil
+}
+
+// Group is the process tree of one agent, rooted at the process in its tmux pane.
+type Group struct {
+	pid    int
+	limits Limits
+	// cgroup is the directory of the agent's cgroup, or empty if the limits are not enforced.
+	cgroup string
+	// limitErr explains why the limits are not enforced.
+	limitErr error
+
+	lastCPU  time.Duration
+	lastTime time.Time
+}
+
+// New returns the group of the process tree rooted at pid. If limits are set, the tree is moved to
+// the cgroup named name. When that is not possible the group only measures the usage; LimitErr
+// tells why.
+func New(name string, pid int, limits Limits) *Group {
+	g := &Group{pid: pid, limits: limits}
+	if !limits.Enabled() {
+		return g
+	}
+	cgroup, err := place(name, pid, limits)
+	if err != nil {
+		g.limitErr = err
+		return g
+	}
+	g.cgroup = cgroup
+	return g
+}
+
+// PID returns the process at the root of the group.
+func (g *Group) PID() int { return g.pid }
+
+// Limits returns the configured limits.
+func (g *Group) Limits() Limits { return g.limits }
+
+// CgroupPath returns the cgroup the limits are enforced with, or "" if they are not.
+func (g *Group) CgroupPath() string { return g.cgroup }
+
+// LimitErr returns why the configured limits are not enforced, or nil.
+func (g *Group) LimitErr() error { return g.limitErr }
+
+// Sample measures the usage of the group. CPU is averaged since the previous sample and is zero in
+// the first one.
+func (g *Group) Sample(now time.Time) (Usage, error) {
+	if err := Supported(); err != nil {
+		return Usage{}, err
+	}
+	var (
+		usage   Usage
+		cpuTime time.Duration
+		err     error
+	)
+	if g.cgroup != "" {
+		usage, cpuTime, err = sampleCgroup(g.cgroup)
+	} else {
+		usage, cpuTime, err = sampleTree(g.pid)
+	}
+	if err != nil {
+		return Usage{}, err
+	}
+	usage.Time = now
+	if !g.lastTime.IsZero() && now.After(g.lastTime) && cpuTime >= g.lastCPU {
+		usage.CPU = float64(cpuTime-g.lastCPU) / float64(now.Sub(g.lastTime)) * 100
+	}
+	g.lastCPU, g.lastTime = cpuTime, now
+	return usage, nil
+}
+
+// Processes returns the processes of the group, the root first and then by memory.
+func (g *Group) Processes() ([]Process, error) {
+	procs, err := readProcs()
+	if err != nil {
+		return nil, err
+	}
+	tree := descendants(procs, g.pid)
+	sort.SliceStable(tree, func(a, b int) bool {
+		if tree[a].PID == g.pid || tree[b].PID == g.pid {
+			return tree[a].PID == g.pid
+		}
+		return tree[a].RSS > tree[b].RSS
+	})
+	return tree, nil
+}
+
+// Close kills what is left in the group's cgroup, e.g. servers the agent started in the
+// background, and removes the cgroup. The agent itself should have exited already.
+func (g *Group) Close() {
+	if g.cgroup == "" {
+		return
+	}
+	_ = writeFile(filepath.Join(g.cgroup, "cgroup.kill"), "1")
+	// The cgroup can only be removed once the killed processes are gone.
+	for range 10 {
+		if err := os.Remove(g.cgroup); err == nil || errors.Is(err, os.ErrNotExist) {
+			return
+		}
+		time.Sleep(50 * time.Millisecond)
+	}
+}
+
+// place creates the agent's cgroup with the limits and moves the process tree into it.
+func place(name string, pid int, limits Limits) (string, error) {
+	if err := Supported(); err != nil {
+		return "", err
+	}
+	if _, err := os.Stat(filepath.Join(cgroupRoot, "cgroup.controllers")); err != nil {
+		return "", fmt.Errorf("cgroup v2 is not mounted at %s", cgroupRoot)
+	}
+	parent, err := delegatedParent()
+	if err != nil {
+		return "", err
+	}
+	dir := filepath.Join(parent, name)
+	if err := os.Mkdir(dir, 0755); err != nil && !errors.Is(err, os.ErrExist) {
+		return "", fmt.Errorf("failed to create cgroup %s: %w", dir, err)
+	}
+	if err := applyLimits(dir, limits); err != nil {
+		_ = os.Remove(dir)
+		return "", err
+	}
+
+	procs, err := readProcs()
+	if err != nil {
+		_ = os.Remove(dir)
+		return "", err
+	}
+	tree := descendants(procs, pid)
+	if len(tree) == 0 {
+		_ = os.Remove(dir)
+		return "", fmt.Errorf("process %d is not running", pid)
+	}
+	for _, p := range tree {
+		err := writeFile(filepath.Join(dir, "cgroup.procs"), strconv.Itoa(p.PID))
+		if err == nil || errors.Is(err, os.ErrNotExist) || p.PID != pid {
+			// Processes may exit while we move them.
+			continue
+		}
+		_ = os.Remove(dir)
+		return "", fmt.Errorf("failed to move process %d to cgroup %s: %w", pid, dir, err)
+	}
+	return dir, nil
+}
+
+// delegatedParent returns the cgroup that holds the cgroups of all agents. It is created next to
+// the cgroup of this process, in the closest ancestor we may write to.
+func delegatedParent() (string, error) {
+	own, err := ownCgroup()
+	if err != nil {
+		return "", err
+	}
+	// The own cgroup contains processes, so no controllers can be enabled for its children.
+	for dir := filepath.Dir(own); ; dir = filepath.Dir(dir) {
+		parent := filepath.Join(cgroupRoot, dir, groupDir)
+		if err := os.Mkdir(parent, 0755); err == nil || errors.Is(err, os.ErrExist) {
+			enableControllers(filepath.Join(cgroupRoot, dir))
+			enableControllers(parent)
+			return parent, nil
+		}
+		if dir == "/" {
+			return "", fmt.Errorf("the cgroup hierarchy is not delegated to this user")
+		}
+	}
+}
+
+// ownCgroup returns the cgroup v2 path of this process, e.g. "/user.slice/app.slice/x.scope".
+func ownCgroup() (string, error) {
+	data, err := os.ReadFile(filepath.Join(procRoot, "self", "cgroup"))
+	if err != nil {
+		return "", err
+	}
+	return parseCgroup(string(data))
+}
+
+// parseCgroup returns the cgroup v2 path in the content of /proc/<pid>/cgroup.
+func parseCgroup(content string) (string, error) {
+	for _, line := range strings.Split(content, "\n") {
+		if path, ok := strings.CutPrefix(line, "0::"); ok {
+			return filepath.Clean("/" + path), nil
+		}
+	}
+	return "", fmt.Errorf("this process is not in a cgroup v2 hierarchy")
+}
+
+// enableControllers enables the controllers that limits need for the children of dir, as far as
+// they are available. Controllers that can't be enabled show up as missing limit files.
+func enableControllers(dir string) {
+	available, err := os.ReadFile(filepath.Join(dir, "cgroup.controllers"))
+	if err != nil {
+		return
+	}
+	for _, controller := range []string{"cpu", "memory", "pids"} {
+		if strings.Contains(" "+strings.TrimSpace(string(available))+" ", " "+controller+" ") {
+			_ = writeFile(filepath.Join(dir, "cgroup.subtree_control"), "+"+controller)
+		}
+	}
+}
+
+// cpuPeriod is the period of the CPU quota in microseconds.
+const cpuPeriod = 100000
+
+// applyLimits writes the limits to the cgroup dir.
+func applyLimits(dir string, limits Limits) error {
+	type limit struct {
+		file, value, controller string
+	}
+	var writes []limit
+	if limits.CPU > 0 {
+		writes = append(writes, limit{"cpu.max", fmt.Sprintf("%d %d", int(limits.CPU*cpuPeriod), cpuPeriod), "cpu"})
+	}
+	if limits.MemoryMB > 0 {
+		writes = append(writes, limit{"memory.max", strconv.Itoa(limits.MemoryMB * 1024 * 1024), "memory"})
+	}
+	if limits.Processes > 0 {
+		writes = append(writes, limit{"pids.max", strconv.Itoa(limits.Processes), "pids"})
+	}
+	for _, w := range writes {
+		if err := writeFile(filepath.Join(dir, w.file), w.value); err != nil {
+			if errors.Is(err, os.ErrNotExist) {
+				return fmt.Errorf("the %s controller is not delegated to this user", w.controller)
+			}
+			return fmt.Errorf("failed to set %s: %w", w.file, err)
+		}
+	}
+	return nil
+}
+
+// sampleCgroup reads the usage of all processes in the cgroup dir.
+func sampleCgroup(dir string) (Usage, time.Duration, error) {
+	var usage Usage
+	stat, err := os.ReadFile(filepath.Join(dir, "cpu.stat"))
+	if err != nil {
+		return Usage{}, 0, err
+	}
+	var cpuTime time.Duration
+	for _, line := range strings.Split(string(stat), "\n") {
+		if v, ok := strings.CutPrefix(line, "usage_usec "); ok {
+			usec, _ := strconv.ParseInt(strings.TrimSpace(v), 10, 64)
+			cpuTime = time.Duration(usec) * time.Microsecond
+		}
+	}
+	usage.Memory, _ = readUint(filepath.Join(dir, "memory.current"))
+	usage.MemoryMax, _ = readUint(filepath.Join(dir, "memory.max"))
+	procs, err := os.ReadFile(filepath.Join(dir, "cgroup.procs"))
+	if err != nil {
+		return Usage{}, 0, err
+	}
+	usage.Processes = len(strings.Fields(string(procs)))
+	return usage, cpuTime, nil
+}
+
+// sampleTree adds up the usage of the process tree rooted at pid.
+func sampleTree(pid int) (Usage, time.Duration, error) {
+	procs, err := readProcs()
+	if err != nil {
+		return Usage{}, 0, err
+	}
+	tree := descendants(procs, pid)
+	if len(tree) == 0 {
+		return Usage{}, 0, fmt.Errorf("process %d is not running", pid)
+	}
+	var usage Usage
+	var cpuTime time.Duration
+	for _, p := range tree {
+		usage.Memory += p.RSS
+		cpuTime += p.CPUTime
+	}
+	usage.Processes = len(tree)
+	return usage, cpuTime, nil
+}
+
+// readProcs reads all processes in /proc.
+func readProcs() ([]Process, error) {
+	entries, err := os.ReadDir(procRoot)
+	if err != nil {
+		return nil, err
+	}
+	pageSize := uint64(os.Getpagesize())
+	var procs []Process
+	for _, entry := range entries {
+		pid, err := strconv.Atoi(entry.Name())
+		if err != nil {
+			continue
+		}
+		data, err := os.ReadFile(filepath.Join(procRoot, entry.Name(), "stat"))
+		if err != nil {
+			// The process exited.
+			continue
+		}
+		p, err := parseStat(string(data), pageSize)
+		if err != nil || p.PID != pid {
+			continue
+		}
+		procs = append(procs, p)
+	}
+	return procs, nil
+}
+
+// parseStat parses /proc/<pid>/stat. The name is in parentheses and may contain spaces and
+// parentheses itself.
+func parseStat(stat string, pageSize uint64) (Process, error) {
+	open := strings.IndexByte(stat, '(')
+	closing := strings.LastIndexByte(stat, ')')
+	if open < 0 || closing < open {
+		return Process{}, fmt.Errorf("malformed stat %q", stat)
+	}
+	pid, err := strconv.Atoi(strings.TrimSpace(stat[:open]))
+	if err != nil {
+		return Process{}, err
+	}
+	// Fields from the state on, so field n of proc(5) is at n-3.
+	fields := strings.Fields(stat[closing+1:])
+	if len(fields) < 22 {
+		return Process{}, fmt.Errorf("malformed stat %q", stat)
+	}
+	field := func(n int) uint64 {
+		v, _ := strconv.ParseUint(fields[n-3], 10, 64)
+		return v
+	}
+	return Process{
+		PID:     pid,
+		PPID:    int(field(4)),
+		Name:    stat[open+1 : closing],
+		CPUTime: time.Duration(field(14)+field(15)) * clockTick,
+		RSS:     field(24) * pageSize,
+	}, nil
+}
+
+// descendants returns the process pid and all processes it started, directly or not.
+func descendants(procs []Process, pid int) []Process {
+	children := make(map[int][]Process)
+	var root *Process
+	for i, p := range procs {
+		children[p.PPID] = append(children[p.PPID], p)
+		if p.PID == pid {
+			root = &procs[i]
+		}
+	}
+	if root == nil {
+		return nil
+	}
+	tree := []Process{*root}
+	for i := 0; i < len(tree); i++ {
+		tree = append(tree, children[tree[i].PID]...)
+	}
+	return tree
+}
+
+// readUint reads a number from a cgroup file. "max" is returned as 0.
+func readUint(path string) (uint64, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return 0, err
+	}
+	s := strings.TrimSpace(string(data))
+	if s == "max" {
+		return 0, nil
+	}
+	return strconv.ParseUint(s, 10, 64)
+}
+
+// writeFile writes to an existing cgroup file. Unlike os.WriteFile it doesn't create the file:
+// missing files mean that a controller is not enabled.
+func writeFile(path, value string) error {
+	f, err := os.OpenFile(path, os.O_WRONLY, 0)
+	if err != nil {
+		return err
+	}
+	if _, err := f.WriteString(value); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// FormatBytes formats a number of bytes compactly, e.g. "340M" or "1.2G".
+func FormatBytes(n uint64) string {
+	const unit = 1024
+	switch {
+	case n >= unit*unit*unit:
+		return strconv.FormatFloat(float64(n)/(unit*unit*unit), 'f', 1, 64) + "G"
+	case n >= unit*unit:
+		return fmt.Sprintf("%dM", n/(unit*unit))
+	case n >= unit:
+		return fmt.Sprintf("%dK", n/unit)
+	default:
+		return fmt.Sprintf("%dB", n)
+	}
+}
@@ -0,0 +1,149 @@
+package resources
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeProc fills a fake /proc with processes given as pid: {ppid, utime ticks, rss pages}.
+func fakeProc(t *testing.T, procs map[int][3]int) {
+	t.Helper()
+	procRoot = t.TempDir()
+	t.Cleanup(func() { procRoot = "/proc" })
+	for pid, p := range procs {
+		dir := filepath.Join(procRoot, strconv.Itoa(pid))
+		require.NoError(t, os.MkdirAll(dir, 0755))
+		stat := strconv.Itoa(pid) + " (go (test)) S " + strconv.Itoa(p[0]) +
+			" 1 1 0 -1 4194560 100 0 0 0 " + strconv.Itoa(p[1]) + " 0 0 0 20 0 1 0 100 1000 " +
+			strconv.Itoa(p[2]) + " 0"
+		require.NoError(t, os.WriteFile(filepath.Join(dir, "stat"), []byte(stat), 0644))
+	}
+}
+
+func TestParseStat(t *testing.T) {
+	p, err := parseStat("42 (tmux: server (1)) S 7 42 42 0 -1 4194560 100 0 0 0 250 50 0 0 20 0 1 0 100 1000 3 0", 4096)
+	require.NoError(t, err)
+	assert.Equal(t, Process{PID: 42, PPID: 7, Name: "tmux: server (1)", CPUTime: 3 * time.Second, RSS: 3 * 4096}, p)
+
+	_, err = parseStat("42 (broken", 4096)
+	assert.Error(t, err)
+}
+
+func TestParseCgroup(t *testing.T) {
+	path, err := parseCgroup("0::/user.slice/user-1000.slice/session-2.scope\n")
+	require.NoError(t, err)
+	assert.Equal(t, "/user.slice/user-1000.slice/session-2.scope", path)
+
+	_, err = parseCgroup("1:name=systemd:/user.slice\n")
+	assert.Error(t, err)
+}
+
+func TestSampleTree(t *testing.T) {
+	if runtime.GOOS != "linux" {
+		t.Skip("usage is only measured on Linux")
+	}
+	// 10 is the agent, 11 and 12 its children, 13 a grandchild and 20 unrelated.
+	fakeProc(t, map[int][3]int{
+		10: {1, 100, 10},
+		11: {10, 50, 20},
+		12: {10, 0, 30},
+		13: {11, 0, 40},
+		20: {1, 900, 1000},
+	})
+
+	g := New("agent", 10, Limits{})
+	assert.Empty(t, g.CgroupPath())
+	assert.NoError(t, g.LimitErr())
+
+	start := time.Now()
+	usage, err := g.Sample(start)
+	require.NoError(t, err)
+	assert.Equal(t, 4, usage.Processes)
+	assert.Equal(t, uint64(100*os.Getpagesize()), usage.Memory)
+	assert.Zero(t, usage.CPU)
+
+	// The agent used another 1.5s of CPU in 3s.
+	fakeProc(t, map[int][3]int{10: {1, 250, 10}, 11: {10, 50, 20}})
+	usage, err = g.Sample(start.Add(3 * time.Second))
+	require.NoError(t, err)
+	assert.Equal(t, 2, usage.Processes)
+	assert.InDelta(t, 50, usage.CPU, 0.01)
+
+	procs, err := g.Processes()
+	require.NoError(t, err)
+	require.Len(t, procs, 2)
+	assert.Equal(t, 10, procs[0].PID)
+}
+
+func TestLimitsInCgroup(t *testing.T) {
+	if runtime.GOOS != "linux" {
+		t.Skip("cgroups only exist on Linux")
+	}
+	fakeProc(t, map[int][3]int{10: {1, 0, 10}, 11: {10, 0, 10}})
+	require.NoError(t, os.MkdirAll(filepath.Join(procRoot, "self"), 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(procRoot, "self", "cgroup"), []byte("0::/user.slice/app.slice/term.scope\n"), 0644))
+
+	cgroupRoot = t.TempDir()
+	defer func() { cgroupRoot = "/sys/fs/cgroup" }()
+	require.NoError(t, os.WriteFile(filepath.Join(cgroupRoot, "cgroup.controllers"), []byte("cpu memory pids\n"), 0644))
+	// A real cgroup directory comes with its files; the fake one gets them up front.
+	dir := filepath.Join(cgroupRoot, "user.slice", "app.slice", "claude-squad", "agent")
+	require.NoError(t, os.MkdirAll(dir, 0755))
+	for _, file := range []string{"cpu.max", "memory.max", "pids.max", "cgroup.procs"} {
+		require.NoError(t, os.WriteFile(filepath.Join(dir, file), nil, 0644))
+	}
+
+	g := New("agent", 10, Limits{CPU: 1.5, MemoryMB: 512, Processes: 100})
+	require.NoError(t, g.LimitErr())
+	assert.Equal(t, dir, g.CgroupPath())
+
+	read := func(file string) string {
+		data, err := os.ReadFile(filepath.Join(dir, file))
+		require.NoError(t, err)
+		return string(data)
+	}
+	assert.Equal(t, "150000 100000", read("cpu.max"))
+	assert.Equal(t, strconv.Itoa(512*1024*1024), read("memory.max"))
+	assert.Equal(t, "100", read("pids.max"))
+
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "cpu.stat"), []byte("usage_usec 2000000\nuser_usec 1500000\n"), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "memory.current"), []byte("500000000\n"), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "cgroup.procs"), []byte("10\n11\n"), 0644))
+	usage, err := g.Sample(time.Now())
+	require.NoError(t, err)
+	assert.Equal(t, 2, usage.Processes)
+	assert.Equal(t, uint64(500000000), usage.Memory)
+	assert.Equal(t, uint64(512*1024*1024), usage.MemoryMax)
+	assert.True(t, usage.NearMemoryLimit())
+}
+
+func TestLimitsWithoutDelegation(t *testing.T) {
+	if runtime.GOOS != "linux" {
+		t.Skip("cgroups only exist on Linux")
+	}
+	fakeProc(t, map[int][3]int{10: {1, 0, 10}})
+	cgroupRoot = filepath.Join(t.TempDir(), "missing")
+	defer func() { cgroupRoot = "/sys/fs/cgroup" }()
+
+	g := New("agent", 10, Limits{MemoryMB: 512})
+	assert.Error(t, g.LimitErr())
+	assert.Empty(t, g.CgroupPath())
+
+	// The usage is still measured.
+	usage, err := g.Sample(time.Now())
+	require.NoError(t, err)
+	assert.Equal(t, 1, usage.Processes)
+}
+
+func TestFormatBytes(t *testing.T) {
+	assert.Equal(t, "512B", FormatBytes(512))
+	assert.Equal(t, "340M", FormatBytes(340*1024*1024))
+	assert.Equal(t, "1.5G", FormatBytes(1536*1024*1024))
+}
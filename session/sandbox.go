@@ -0,0 +1,52 @@
+package session
+
+import (
+	"claude-squad/config"
+	"claude-squad/session/sandbox"
+	"fmt"
+	"path/filepath"
+)
+
+// sandboxConfig decides which agents run in a sandbox.
+var sandboxConfig config.SandboxConfig
+
+// SetSandbox sets the sandbox configuration that agents are started with.
+func SetSandbox(cfg config.SandboxConfig) {
+	sandboxConfig = cfg
+}
+
+// SandboxState describes the sandbox an agent runs in.
+type SandboxState struct {
+	// Network is true if the agent can reach the network.
+	Network bool `json:"network"`
+}
+
+// applySandbox prepares the next start of the agent for the sandbox configuration of the
+// instance's repository. It fails rather than starting an agent that should be contained without
+// a sandbox.
+func (i *Instance) applySandbox() error {
+	cfg := sandboxConfig.For(i.Path)
+	if !cfg.Enabled {
+		i.Sandbox = nil
+		i.tmuxSession.SetWrapper("")
+		return nil
+	}
+
+	opts := sandbox.Options{
+		Worktree: i.gitWorktree.GetWorktreePath(),
+		Writable: cfg.Writable,
+		Hidden:   cfg.Hidden,
+		Network:  !cfg.NoNetwork,
+	}
+	if !i.DirectMode {
+		// Commits of a worktree are written to the repository's git directory.
+		opts.GitDir = filepath.Join(i.gitWorktree.GetRepoPath(), ".git")
+	}
+	wrapper, err := sandbox.Wrapper(opts)
+	if err != nil {
+		return fmt.Errorf("failed to set up the sandbox: %w", err)
+	}
+	i.tmuxSession.SetWrapper(wrapper)
+	i.Sandbox = &SandboxState{Network: opts.Network}
+	return nil
+}
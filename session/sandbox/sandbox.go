@@ -0,0 +1,155 @@
+// Package sandbox contains agents with bubblewrap on Linux. The agent sees the filesystem read-only
+// except for its worktree, the repository's git directory (but not its config and hooks) and the
+// paths it keeps its own state in.
+// Secrets can be hidden and the network turned off.
+package sandbox
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+)
+
+// Options describe the sandbox of one agent.
+type Options struct {
+	// Worktree is the directory the agent works in. It is writable.
+	Worktree string
+	// GitDir is the git directory of the repository. It is writable so the agent can commit, except
+	// for its config, hooks and info, which git also reads outside the sandbox.
+	GitDir string
+	// Writable are further paths the agent may write to, e.g. its config directory. Paths that
+	// don't exist are skipped.
+	Writable []string
+	// Hidden are paths replaced by empty ones, e.g. ~/.ssh. Paths that don't exist are skipped.
+	Hidden []string
+	// Network keeps network access.
+	Network bool
+}
+
+// bwrap is the bubblewrap binary. It's a variable for testing.
+var bwrap = "bwrap"
+
+// Available returns an error if agents can't be sandboxed on this system.
+func Available() error {
+	if runtime.GOOS != "linux" {
+		return fmt.Errorf("the sandbox is only supported on Linux")
+	}
+	if _, err := exec.LookPath(bwrap); err != nil {
+		return fmt.Errorf("the sandbox needs bubblewrap (bwrap): %w", err)
+	}
+	return nil
+}
+
+// Wrapper returns the command line that runs a program in the sandbox when the program is appended
+// to it. The arguments are quoted for the shell.
+func Wrapper(opts Options) (string, error) {
+	if err := Available(); err != nil {
+		return "", err
+	}
+	args, err := Args(opts)
+	if err != nil {
+		return "", err
+	}
+	quoted := make([]string, 0, len(args)+1)
+	quoted = append(quoted, shellQuote(bwrap))
+	for _, arg := range args {
+		quoted = append(quoted, shellQuote(arg))
+	}
+	return strings.Join(quoted, " "), nil
+}
+
+// Args returns the arguments of bwrap for the options, ending with "--" before the program.
+func Args(opts Options) ([]string, error) {
+	if opts.Worktree == "" {
+		return nil, fmt.Errorf("the sandbox needs a worktree")
+	}
+	args := []string{
+		"--die-with-parent",
+		"--ro-bind", "/", "/",
+		"--dev", "/dev",
+		"--proc", "/proc",
+		"--tmpfs", "/tmp",
+	}
+	for _, path := range opts.Writable {
+		path = expandHome(path)
+		if _, err := os.Stat(path); err != nil {
+			continue
+		}
+		args = append(args, "--bind", path, path)
+	}
+	for _, path := range opts.Hidden {
+		path = expandHome(path)
+		info, err := os.Stat(path)
+		if err != nil {
+			continue
+		}
+		if info.IsDir() {
+			args = append(args, "--tmpfs", path)
+		} else {
+			args = append(args, "--ro-bind", os.DevNull, path)
+		}
+	}
+	if opts.GitDir != "" {
+		gitArgs, err := gitDirArgs(opts.GitDir)
+		if err != nil {
+			return nil, err
+		}
+		args = append(args, gitArgs...)
+	}
+	// The worktree goes last so that nothing above can hide it.
+	args = append(args, "--bind", opts.Worktree, opts.Worktree, "--chdir", opts.Worktree)
+	if !opts.Network {
+		args = append(args, "--unshare-net")
+	}
+	return append(args, "--"), nil
+}
+
+// protectedGitPaths are the parts of the git directory that make git run commands, e.g.
+// core.fsmonitor or core.hooksPath in the config and hooks in hooks. Git runs them outside the
+// sandbox too, for Claude Squad's own git commands and the user's, so they are read-only.
+var protectedGitPaths = []string{"config", "hooks", "info"}
+
+// gitDirArgs binds the git directory writable and protectedGitPaths read-only over it.
+// Directories that don't exist yet are created, so that the agent can't create them instead.
+func gitDirArgs(gitDir string) ([]string, error) {
+	args := []string{"--bind", gitDir, gitDir}
+	for _, name := range protectedGitPaths {
+		path := filepath.Join(gitDir, name)
+		if _, err := os.Stat(path); err != nil {
+			if name == "config" || !errors.Is(err, os.ErrNotExist) {
+				return nil, fmt.Errorf("failed to protect %s in the sandbox: %w", path, err)
+			}
+			if err := os.Mkdir(path, 0755); err != nil {
+				return nil, fmt.Errorf("failed to protect %s in the sandbox: %w", path, err)
+			}
+		}
+		args = append(args, "--ro-bind", path, path)
+	}
+	return args, nil
+}
+
+// expandHome replaces a leading ~ with the home directory.
+func expandHome(path string) string {
+	if path != "~" && !strings.HasPrefix(path, "~/") {
+		return filepath.Clean(path)
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return path
+	}
+	return filepath.Join(home, strings.TrimPrefix(path, "~"))
+}
+
+// shellQuote quotes s for a POSIX shell.
+func shellQuote(s string) string {
+	if s != "" && strings.IndexFunc(s, func(r rune) bool {
+		return !(r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || strings.ContainsRune("-_./=:@+,", r))
+	}) < 0 {
+		return s
+	}
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
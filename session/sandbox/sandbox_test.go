@@ -0,0 +1,62 @@
+package sandbox
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestArgs(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	require.NoError(t, os.Mkdir(filepath.Join(home, ".claude"), 0755))
+	require.NoError(t, os.Mkdir(filepath.Join(home, ".ssh"), 0700))
+	require.NoError(t, os.WriteFile(filepath.Join(home, ".netrc"), nil, 0600))
+	gitDir := filepath.Join(t.TempDir(), ".git")
+	require.NoError(t, os.MkdirAll(filepath.Join(gitDir, "hooks"), 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(gitDir, "config"), nil, 0644))
+
+	args, err := Args(Options{
+		Worktree: "/work/tree",
+		GitDir:   gitDir,
+		Writable: []string{"~/.claude", "~/.gemini"},
+		Hidden:   []string{"~/.ssh", "~/.netrc", "~/.aws"},
+	})
+	require.NoError(t, err)
+	got := strings.Join(args, " ")
+	require.True(t, strings.HasPrefix(got, "--die-with-parent --ro-bind / / --dev /dev --proc /proc --tmpfs /tmp "))
+	// Missing paths are skipped.
+	require.Contains(t, got, "--bind "+home+"/.claude "+home+"/.claude ")
+	require.NotContains(t, got, ".gemini")
+	require.NotContains(t, got, ".aws")
+	require.Contains(t, got, "--tmpfs "+home+"/.ssh ")
+	require.Contains(t, got, "--ro-bind /dev/null "+home+"/.netrc ")
+	// The agent may commit, but git's config and hooks are read-only. The missing info is created so
+	// that the agent can't create it.
+	require.True(t, strings.HasSuffix(got, "--bind "+gitDir+" "+gitDir+
+		" --ro-bind "+gitDir+"/config "+gitDir+"/config"+
+		" --ro-bind "+gitDir+"/hooks "+gitDir+"/hooks"+
+		" --ro-bind "+gitDir+"/info "+gitDir+"/info"+
+		" --bind /work/tree /work/tree --chdir /work/tree --unshare-net --"))
+	require.DirExists(t, filepath.Join(gitDir, "info"))
+
+	_, err = Args(Options{Worktree: "/work/tree", GitDir: filepath.Join(t.TempDir(), ".git")})
+	require.Error(t, err, "a git directory without a config can't be protected")
+
+	args, err = Args(Options{Worktree: "/work/tree", Network: true})
+	require.NoError(t, err)
+	require.NotContains(t, args, "--unshare-net")
+
+	_, err = Args(Options{})
+	require.Error(t, err)
+}
+
+func TestShellQuote(t *testing.T) {
+	require.Equal(t, "/usr/bin/bwrap", shellQuote("/usr/bin/bwrap"))
+	require.Equal(t, "'/home/me/my repo'", shellQuote("/home/me/my repo"))
+	require.Equal(t, `'it'\''s'`, shellQuote("it's"))
+	require.Equal(t, "''", shellQuote(""))
+}
@@ -0,0 +1,112 @@
+package session
+
+import (
+	"claude-squad/session/tmux"
+	"fmt"
+	"os"
+)
+
+// shellProgram returns the user's shell.
+func shellProgram() string {
+	if shell := os.Getenv("SHELL"); shell != "" {
+		return shell
+	}
+	return "/bin/sh"
+}
+
+// OpenShell starts a shell in the instance's worktree, or reuses the one that is already running.
+func (i *Instance) OpenShell() error {
+	if !i.started || i.Status == Paused {
+		return fmt.Errorf("cannot open a shell for an instance that has not been started or is paused")
+	}
+	if i.shellSession != nil {
+		if i.shellSession.DoesSessionExist() {
+			return nil
+		}
+		// The shell was exited; release its PTY and start a new one.
+		_ = i.shellSession.Close()
+		i.shellSession = nil
+	}
+
+	shell := tmux.NewShellSession(i.Title, shellProgram())
+	if shell.DoesSessionExist() {
+		if err := shell.Restore(); err != nil {
+			return fmt.Errorf("failed to restore shell: %w", err)
+		}
+	} else if err := shell.Start(i.gitWorktree.GetWorktreePath()); err != nil {
+		return fmt.Errorf("failed to start shell: %w", err)
+	}
+	if i.Width > 0 && i.Height > 0 {
+		if err := shell.SetDetachedSize(i.Width, i.Height); err != nil {
+			i.logger().Warn("failed to size the shell", "err", err)
+		}
+	}
+	i.shellSession = shell
+	return nil
+}
+
+// restoreShell reconnects to the instance's shell if it is still running, e.g. after a restart.
+func (i *Instance) restoreShell() {
+	shell := tmux.NewShellSession(i.Title, shellProgram())
+	if !shell.DoesSessionExist() {
+		return
+	}
+	if err := shell.Restore(); err != nil {
+		i.logger().Warn("failed to restore the shell", "err", err)
+		return
+	}
+	i.shellSession = shell
+}
+
+// HasShell returns true if the instance has a shell open.
+func (i *Instance) HasShell() bool {
+	return i.started && i.Status != Paused && i.shellSession != nil
+}
+
+// ShellPreview captures the visible part of the shell. It returns an empty string if there is no
+// shell.
+func (i *Instance) ShellPreview() (string, error) {
+	return i.captureShell(false)
+}
+
+// ShellPreviewFullHistory captures the shell's output including its scrollback history.
+func (i *Instance) ShellPreviewFullHistory() (string, error) {
+	return i.captureShell(true)
+}
+
+func (i *Instance) captureShell(fullHistory bool) (string, error) {
+	if !i.HasShell() {
+		return "", nil
+	}
+	content, _, _, err := i.shellSession.CaptureUnified(fullHistory, i.Height)
+	if err != nil && !i.shellSession.DoesSessionExist() {
+		// The shell was exited.
+		i.shellSession = nil
+		return "", nil
+	}
+	return content, err
+}
+
+// AttachShell attaches to the instance's shell.
+func (i *Instance) AttachShell() (chan struct{}, error) {
+	if !i.HasShell() {
+		return nil, fmt.Errorf("instance '%s' has no shell open", i.Title)
+	}
+	return i.shellSession.Attach()
+}
+
+// closeShell terminates the shell, if any.
+func (i *Instance) closeShell() error {
+	if i.shellSession == nil {
+		return nil
+	}
+	shell := i.shellSession
+	i.shellSession = nil
+	if !shell.DoesSessionExist() {
+		return nil
+	}
+	if err := shell.Close(); err != nil {
+		return fmt.Errorf("failed to close shell: %w", err)
+	}
+	return nil
+}
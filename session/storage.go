@@ -2,6 +2,7 @@ package session
 
 import (
 	"claude-squad/config"
+	"claude-squad/forge"
 	"encoding/json"
 	"fmt"
 	"time"
@@ -18,12 +19,20 @@ type InstanceData struct {
 	CreatedAt    time.Time `json:"created_at"`
 	UpdatedAt    time.Time `json:"updated_at"`
 	AutoYes      bool      `json:"auto_yes"`
+	AutoYesSince time.Time `json:"auto_yes_since"`
+	Approvals    int       `json:"auto_yes_approvals,omitempty"`
+	Muted        bool      `json:"muted,omitempty"`
 	DirectMode   bool      `json:"direct_mode"`
 	DirectBranch string    `json:"direct_branch"`
 
 	Program   string          `json:"program"`
 	Worktree  GitWorktreeData `json:"worktree"`
 	DiffStats DiffStatsData   `json:"diff_stats"`
+	DiffView  DiffViewState   `json:"diff_view"`
+
+	PullRequest *forge.PullRequestStatus `json:"pull_request,omitempty"`
+	FixLoop     FixLoopState             `json:"fix_loop"`
+	Sandbox     *SandboxState            `json:"sandbox,omitempty"`
 }
 
 // GitWorktreeData represents the serializable data of a GitWorktree
@@ -94,6 +103,21 @@ func (s *Storage) LoadInstances() ([]*Instance, error) {
 	return instances, nil
 }
 
+// HasAutoYes returns true if AutoYes is on for any stored instance. Unlike LoadInstances, it
+// doesn't restore the instances.
+func (s *Storage) HasAutoYes() (bool, error) {
+	var instancesData []InstanceData
+	if err := json.Unmarshal(s.state.GetInstances(), &instancesData); err != nil {
+		return false, fmt.Errorf("failed to unmarshal instances: %w", err)
+	}
+	for _, data := range instancesData {
+		if data.AutoYes {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
 // DeleteInstance removes an instance from storage
 func (s *Storage) DeleteInstance(title string) error {
 	instances, err := s.LoadInstances()
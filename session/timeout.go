@@ -0,0 +1,69 @@
+package session
+
+import (
+	"claude-squad/config"
+	"time"
+)
+
+// timeouts are the timeouts of sessions.
+var timeouts config.TimeoutConfig
+
+// SetTimeouts sets the idle and running timeouts of sessions.
+func SetTimeouts(cfg config.TimeoutConfig) {
+	timeouts = cfg
+}
+
+// TimeoutAction is what should happen to a session that ran into a timeout.
+type TimeoutAction int
+
+const (
+	// TimeoutNone means no timeout expired.
+	TimeoutNone TimeoutAction = iota
+	// TimeoutPause means the session was ready for too long and should be paused.
+	TimeoutPause
+	// TimeoutNotify means the session was running for too long and the user should be told.
+	TimeoutNotify
+	// TimeoutKill means the session was running for too long and should be killed.
+	TimeoutKill
+)
+
+// HasTimeouts returns true if timeouts are set for the instance's repository. The status of such
+// instances has to be watched, even in the background.
+func (i *Instance) HasTimeouts() bool {
+	return timeouts.For(i.Path).Enabled()
+}
+
+// CheckTimeout returns what should happen to the instance because it was ready or running for too
+// long. A timeout is returned once; it starts over when the status changes. Running sessions that
+// wait for a confirmation are left alone until the confirmation is answered. Instances restored
+// with their status are timed from the first check.
+func (i *Instance) CheckTimeout(now time.Time) TimeoutAction {
+	if !i.started || i.Paused() || i.timedOut {
+		return TimeoutNone
+	}
+	if i.statusSince.IsZero() {
+		i.statusSince = now
+		return TimeoutNone
+	}
+	cfg := timeouts.For(i.Path)
+	elapsed := now.Sub(i.statusSince)
+	switch i.Status {
+	case Ready:
+		if cfg.IdleMinutes > 0 && elapsed >= time.Duration(cfg.IdleMinutes)*time.Minute {
+			i.timedOut = true
+			return TimeoutPause
+		}
+	case Running:
+		if i.prompting.Load() {
+			return TimeoutNone
+		}
+		if cfg.RunningMinutes > 0 && elapsed >= time.Duration(cfg.RunningMinutes)*time.Minute {
+			i.timedOut = true
+			if cfg.OnRunning == config.TimeoutKill {
+				return TimeoutKill
+			}
+			return TimeoutNotify
+		}
+	}
+	return TimeoutNone
+}
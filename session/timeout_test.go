@@ -0,0 +1,47 @@
+package session
+
+import (
+	"claude-squad/config"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestCheckTimeout(t *testing.T) {
+	defer SetTimeouts(config.TimeoutConfig{})
+	SetTimeouts(config.TimeoutConfig{
+		IdleMinutes:    30,
+		RunningMinutes: 60,
+		Repos: map[string]config.TimeoutConfig{
+			"infra": {RunningMinutes: 10, OnRunning: config.TimeoutKill},
+		},
+	})
+
+	instance := &Instance{Title: "api", Path: "/src/api", started: true}
+	require.True(t, instance.HasTimeouts())
+	instance.SetStatus(Ready)
+	start := instance.statusSince
+
+	require.Equal(t, TimeoutNone, instance.CheckTimeout(start.Add(29*time.Minute)))
+	require.Equal(t, TimeoutPause, instance.CheckTimeout(start.Add(30*time.Minute)))
+	require.Equal(t, TimeoutNone, instance.CheckTimeout(start.Add(31*time.Minute)), "a timeout fires once")
+
+	// A status change starts over.
+	instance.SetStatus(Running)
+	start = instance.statusSince
+	require.Equal(t, TimeoutNone, instance.CheckTimeout(start.Add(59*time.Minute)))
+	instance.prompting.Store(true)
+	require.Equal(t, TimeoutNone, instance.CheckTimeout(start.Add(61*time.Minute)), "waiting for a confirmation")
+	instance.prompting.Store(false)
+	require.Equal(t, TimeoutNotify, instance.CheckTimeout(start.Add(61*time.Minute)))
+
+	infra := &Instance{Title: "infra", Path: "/src/infra", started: true, Status: Running}
+	// Restored instances are timed from the first check.
+	require.Equal(t, TimeoutNone, infra.CheckTimeout(start))
+	require.Equal(t, TimeoutNone, infra.CheckTimeout(start.Add(9*time.Minute)))
+	require.Equal(t, TimeoutKill, infra.CheckTimeout(start.Add(10*time.Minute)))
+
+	SetTimeouts(config.TimeoutConfig{})
+	require.False(t, instance.HasTimeouts())
+}
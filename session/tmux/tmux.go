@@ -3,6 +3,7 @@ package tmux
 import (
     "bytes"
     "claude-squad/cmd"
+    "claude-squad/keys"
     "claude-squad/log"
     "context"
     "errors"
@@ -32,6 +33,8 @@ type TmuxSession struct {
 	// The name of the tmux session and the sanitized name used for tmux commands.
 	sanitizedName string
 	program       string
+	// wrapper is put in front of the program when the session starts, e.g. to run it in a sandbox.
+	wrapper string
 	// ptyFactory is used to create a PTY for the tmux session.
 	ptyFactory PtyFactory
 	// cmdExec is used to execute commands in the tmux session.
@@ -76,6 +79,15 @@ func NewTmuxSession(name string, program string) *TmuxSession {
 	return newTmuxSession(name, program, MakePtyFactory(), cmd.MakeExecutor())
 }
 
+// shellSuffix is appended to the name of an instance's tmux session to name the session of its
+// shell. The shell gets its own tmux session so it never changes what the agent's session shows.
+const shellSuffix = "__shell"
+
+// NewShellSession creates a TmuxSession that runs shell next to the session with the given name.
+func NewShellSession(name string, shell string) *TmuxSession {
+	return NewTmuxSession(name+shellSuffix, shell)
+}
+
 // NewTmuxSessionWithDeps creates a new TmuxSession with provided dependencies for testing.
 func NewTmuxSessionWithDeps(name string, program string, ptyFactory PtyFactory, cmdExec cmd.Executor) *TmuxSession {
 	return newTmuxSession(name, program, ptyFactory, cmdExec)
@@ -90,6 +102,12 @@ func newTmuxSession(name string, program string, ptyFactory PtyFactory, cmdExec
 	}
 }
 
+// SetWrapper sets a command line that runs the program when the session is started, e.g. a
+// sandbox. The program is appended to it.
+func (t *TmuxSession) SetWrapper(wrapper string) {
+	t.wrapper = wrapper
+}
+
 // Start creates and starts a new tmux session, then attaches to it. Program is the command to run in
 // the session (ex. claude). workdir is the git worktree directory.
 func (t *TmuxSession) Start(workDir string) error {
@@ -99,7 +117,11 @@ func (t *TmuxSession) Start(workDir string) error {
 	}
 
 	// Create a new detached tmux session and start claude in it
-	cmd := exec.Command("tmux", "new-session", "-d", "-s", t.sanitizedName, "-c", workDir, t.program)
+	command := t.program
+	if t.wrapper != "" {
+		command = t.wrapper + " " + t.program
+	}
+	cmd := exec.Command("tmux", "new-session", "-d", "-s", t.sanitizedName, "-c", workDir, command)
 
 	ptmx, err := t.ptyFactory.Start(cmd)
 	if err != nil {
@@ -344,7 +366,7 @@ func (t *TmuxSession) Attach() (chan struct{}, error) {
 		default:
 			// If context is not done, it was likely an abnormal termination (Ctrl-D)
 			// Print warning message
-			fmt.Fprintf(os.Stderr, "\n\033[31mError: Session terminated without detaching. Use Ctrl-Q to properly detach from tmux sessions.\033[0m\n")
+			fmt.Fprintf(os.Stderr, "\n\033[31mError: Session terminated without detaching. Use %s to properly detach from tmux sessions.\033[0m\n", keys.AttachHelp(keys.AttachDetach))
 		}
 	}()
 
@@ -356,7 +378,7 @@ func (t *TmuxSession) Attach() (chan struct{}, error) {
             close(timeoutCh)
         }()
 
-        // Read input from stdin and check for Ctrl+q
+        // Read input from stdin and check for the detach key
         buf := make([]byte, 32)
         for {
             nr, err := os.Stdin.Read(buf)
@@ -381,8 +403,8 @@ func (t *TmuxSession) Attach() (chan struct{}, error) {
 				continue
 			}
 
-            // Check for Ctrl+q (ASCII 17)
-            if nr == 1 && buf[0] == 17 {
+            // Check for the detach key (Ctrl+q by default)
+            if matchesKey(buf[:nr], keys.AttachSequences(keys.AttachDetach)) {
                 // Detach from the session
                 t.Detach()
                 return
@@ -403,53 +425,48 @@ func (t *TmuxSession) Attach() (chan struct{}, error) {
     return t.attachCh, nil
 }
 
-// handleCopyModeKeys detects common navigation key sequences and controls tmux copy-mode scrolling.
-// Returns true if the input was handled and should not be forwarded to tmux.
-func (t *TmuxSession) handleCopyModeKeys(b []byte) bool {
-    // Common escape sequences
-    seq := func(bytes ...byte) bool { return len(b) >= len(bytes) && bytesEqualSuffix(b, bytes) }
-
-    // Page Up / Page Down
-    if seq(27, 91, 53, 126) { // ESC [ 5 ~
-        _ = t.copyModeCommand("page-up")
-        return true
-    }
-    if seq(27, 91, 54, 126) { // ESC [ 6 ~
-        _ = t.copyModeCommand("page-down")
-        return true
-    }
-
-    // Home variants
-    if seq(27, 91, 72) || seq(27, 91, 49, 126) || seq(27, 79, 72) { // ESC [ H | ESC [ 1 ~ | ESC O H
-        _ = t.copyModeCommand("history-top")
-        return true
-    }
-    // End variants
-    if seq(27, 91, 70) || seq(27, 91, 52, 126) || seq(27, 79, 70) { // ESC [ F | ESC [ 4 ~ | ESC O F
-        _ = t.copyModeCommand("history-bottom")
-        return true
-    }
+// copyModeCommands maps attach-mode actions to the tmux copy-mode commands they run.
+var copyModeCommands = []struct {
+    action  keys.AttachAction
+    command string
+}{
+    {keys.AttachPageUp, "page-up"},
+    {keys.AttachPageDown, "page-down"},
+    {keys.AttachTop, "history-top"},
+    {keys.AttachBottom, "history-bottom"},
+    {keys.AttachScrollUp, "scroll-up"},
+    {keys.AttachScrollDown, "scroll-down"},
+    {keys.AttachHalfUp, "halfpage-up"},
+    {keys.AttachHalfDown, "halfpage-down"},
+}
 
-    // Shift+Up / Shift+Down (xterm): ESC [ 1 ; 2 A / ESC [ 1 ; 2 B
-    if seq(27, 91, 49, 59, 50, 65) { // S-Up
-        _ = t.copyModeCommand("scroll-up")
-        return true
-    }
-    if seq(27, 91, 49, 59, 50, 66) { // S-Down
-        _ = t.copyModeCommand("scroll-down")
-        return true
+// handleCopyModeKeys detects the configured navigation keys and controls tmux copy-mode scrolling.
+// Returns true if the input was handled and should not be forwarded to the session.
+func (t *TmuxSession) handleCopyModeKeys(b []byte) bool {
+    for _, c := range copyModeCommands {
+        if matchesKey(b, keys.AttachSequences(c.action)) {
+            _ = t.copyModeCommand(c.command)
+            return true
+        }
     }
+    return false
+}
 
-    // Ctrl-U / Ctrl-D for half-page scroll (C-u = 0x15, C-d = 0x04)
-    if len(b) == 1 && b[0] == 0x15 { // C-u
-        _ = t.copyModeCommand("halfpage-up")
-        return true
-    }
-    if len(b) == 1 && b[0] == 0x04 { // C-d
-        _ = t.copyModeCommand("halfpage-down")
-        return true
+// matchesKey reports whether the input b is one of the key sequences. Multi-byte escape sequences
+// may arrive at the end of a larger read; single bytes must match exactly so that typed text
+// containing them is still forwarded.
+func matchesKey(b []byte, seqs [][]byte) bool {
+    for _, seq := range seqs {
+        if len(seq) == 1 {
+            if len(b) == 1 && b[0] == seq[0] {
+                return true
+            }
+            continue
+        }
+        if bytesEqualSuffix(b, seq) {
+            return true
+        }
     }
-
     return false
 }
 
@@ -633,6 +650,20 @@ func (t *TmuxSession) DoesSessionExist() bool {
 	return t.cmdExec.Run(existsCmd) == nil
 }
 
+// PanePID returns the process ID of the program in the session's pane.
+func (t *TmuxSession) PanePID() (int, error) {
+	cmd := exec.Command("tmux", "display-message", "-p", "-t", t.sanitizedName, "#{pane_pid}")
+	output, err := t.cmdExec.Output(cmd)
+	if err != nil {
+		return 0, fmt.Errorf("failed to get the pane pid of %s: %v", t.sanitizedName, err)
+	}
+	pid, err := strconv.Atoi(strings.TrimSpace(string(output)))
+	if err != nil {
+		return 0, fmt.Errorf("unexpected pane pid %q of %s", strings.TrimSpace(string(output)), t.sanitizedName)
+	}
+	return pid, nil
+}
+
 // CapturePaneContent captures the content of the tmux pane
 func (t *TmuxSession) CapturePaneContent() (string, error) {
 	content, _, _, err := t.CaptureUnified(false, 0)
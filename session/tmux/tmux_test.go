@@ -48,6 +48,10 @@ func TestSanitizeName(t *testing.T) {
 
 	session = NewTmuxSession("a sd f . . asdf", "program")
 	require.Equal(t, TmuxPrefix+"asdf__asdf", session.sanitizedName)
+
+	// The shell of a session is a separate tmux session next to it.
+	session = NewShellSession("a sd f", "/bin/sh")
+	require.Equal(t, TmuxPrefix+"asdf__shell", session.sanitizedName)
 }
 
 func TestStartTmuxSession(t *testing.T) {
@@ -191,3 +195,18 @@ func TestCaptureUnifiedCaching(t *testing.T) {
 
 	require.Equal(t, 1, callCount, "expected capture-pane to be called once due to caching")
 }
+
+func TestPanePID(t *testing.T) {
+	cmdExec := cmd_test.MockCmdExec{
+		RunFunc: func(cmd *exec.Cmd) error { return nil },
+		OutputFunc: func(cmd *exec.Cmd) ([]byte, error) {
+			require.Equal(t, "tmux display-message -p -t claudesquad_pid #{pane_pid}", cmd2.ToString(cmd))
+			return []byte("4242\n"), nil
+		},
+	}
+	session := newTmuxSession("pid", ProgramClaude, NewMockPtyFactory(t), cmdExec)
+
+	pid, err := session.PanePID()
+	require.NoError(t, err)
+	require.Equal(t, 4242, pid)
+}
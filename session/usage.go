@@ -0,0 +1,157 @@
+package session
+
+import (
+	"claude-squad/config"
+	"claude-squad/session/policy"
+	"claude-squad/session/usage"
+	"fmt"
+	"maps"
+	"path/filepath"
+	"time"
+)
+
+// usageConfig prices the usage of agents and sets their cost budgets.
+var usageConfig config.UsageConfig
+
+// SetUsageConfig sets the prices and cost budgets of agents.
+func SetUsageConfig(cfg config.UsageConfig) {
+	usageConfig = cfg
+}
+
+// UsageDir returns the directory the usage ledgers of sessions are kept in.
+func UsageDir() (string, error) {
+	configDir, err := config.GetConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(configDir, "usage"), nil
+}
+
+// UsageAlert tells that a session spent more than a cost budget allows.
+type UsageAlert struct {
+	// Action is config.BudgetWarn or config.BudgetPause.
+	Action string
+	// Reason describes the budget, e.g. "its budget of $5.00".
+	Reason string
+}
+
+// UpdateUsage reads what the agent used since the last update and adds it to the session's
+// ledger: Claude Code's usage from its transcripts, other agents' from what they print. If the
+// session went over a cost budget it returns an alert, once per budget and day. It must not be
+// called concurrently for the same instance.
+func (i *Instance) UpdateUsage(now time.Time) (*UsageAlert, error) {
+	if !i.started || i.Paused() {
+		return nil, nil
+	}
+	dir, err := UsageDir()
+	if err != nil {
+		return nil, err
+	}
+	i.usageMu.Lock()
+	ledger := i.usageLedger
+	i.usageMu.Unlock()
+	if ledger == nil {
+		if ledger, err = usage.LoadLedger(dir, i.Title); err != nil {
+			return nil, err
+		}
+		ledger.Repo = i.gitWorktree.GetRepoName()
+		if ledger.Transcripts == nil {
+			ledger.Transcripts = make(map[string]usage.Cursor)
+		}
+	}
+	// The ledger is only shared once it's updated, so that the list never sees it half done.
+	ledger = cloneLedger(ledger)
+	before, cursors := ledger.Total(), maps.Clone(ledger.Transcripts)
+
+	projectDir, err := usage.ClaudeProjectDir(i.gitWorktree.GetWorktreePath())
+	if err != nil {
+		return nil, err
+	}
+	records, err := usage.ReadClaudeTranscripts(projectDir, ledger.Transcripts, i.CreatedAt)
+	if err != nil {
+		return nil, err
+	}
+	for _, record := range records {
+		if !record.Priced {
+			if price, ok := usage.PriceFor(usageConfig.Prices, record.Model); ok {
+				record.Cost = price.Cost(record.Totals)
+			}
+		}
+		ledger.Add(usage.Day(record.Time), record.Totals)
+	}
+	if adapter := policy.AdapterFor(i.Program); adapter != nil {
+		if content, _, _, err := i.tmuxSession.CaptureUnified(false, 0); err == nil {
+			if report, ok := adapter.PaneUsage(content); ok {
+				ledger.AddPane(usage.Day(now), report)
+			}
+		}
+	}
+
+	spent := ledger.Total() != before
+	if !spent && maps.Equal(cursors, ledger.Transcripts) {
+		i.setUsageLedger(ledger)
+		return nil, nil
+	}
+	if err := ledger.Save(dir); err != nil {
+		return nil, err
+	}
+	i.setUsageLedger(ledger)
+	if !spent {
+		return nil, nil
+	}
+	return i.checkCostBudget(dir, ledger, now)
+}
+
+func (i *Instance) setUsageLedger(ledger *usage.Ledger) {
+	i.usageMu.Lock()
+	defer i.usageMu.Unlock()
+	i.usageLedger = ledger
+}
+
+// cloneLedger copies the ledger so that it can be updated while the original is read.
+func cloneLedger(ledger *usage.Ledger) *usage.Ledger {
+	clone := *ledger
+	clone.Days = maps.Clone(ledger.Days)
+	clone.Transcripts = maps.Clone(ledger.Transcripts)
+	if ledger.Pane != nil {
+		pane := *ledger.Pane
+		clone.Pane = &pane
+	}
+	return &clone
+}
+
+// checkCostBudget returns an alert if the session that just spent went over its budget, or all
+// sessions together over the daily budget.
+func (i *Instance) checkCostBudget(dir string, ledger *usage.Ledger, now time.Time) (*UsageAlert, error) {
+	action := usageConfig.OnBudget
+	if action == "" {
+		action = config.BudgetWarn
+	}
+	if budget := usageConfig.SessionBudget; budget > 0 && ledger.Total().Cost >= budget && i.usageAlerted != "session" {
+		i.usageAlerted = "session"
+		return &UsageAlert{Action: action, Reason: fmt.Sprintf("its budget of %s", usage.FormatCost(budget))}, nil
+	}
+	day := usage.Day(now)
+	if budget := usageConfig.DailyBudget; budget > 0 && i.usageAlerted != "day "+day {
+		ledgers, err := usage.LoadLedgers(dir)
+		if err != nil {
+			return nil, err
+		}
+		if usage.DayTotal(ledgers, day).Cost >= budget {
+			i.usageAlerted = "day " + day
+			return &UsageAlert{Action: action, Reason: fmt.Sprintf("the daily budget of %s", usage.FormatCost(budget))}, nil
+		}
+	}
+	return nil, nil
+}
+
+// UsageTotal returns what the session used so far. It returns false if nothing was measured.
+func (i *Instance) UsageTotal() (usage.Totals, bool) {
+	i.usageMu.Lock()
+	defer i.usageMu.Unlock()
+	if i.usageLedger == nil {
+		return usage.Totals{}, false
+	}
+	total := i.usageLedger.Total()
+	return total, !total.IsZero()
+}
@@ -0,0 +1,148 @@
+package usage
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// ClaudeProjectDir returns the directory Claude Code keeps the transcripts of sessions started in
+// dir in. Claude Code names it after the path, with everything but letters and digits replaced
+// by dashes.
+func ClaudeProjectDir(dir string) (string, error) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+	name := []byte(filepath.Clean(dir))
+	for i, c := range name {
+		if !(c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9') {
+			name[i] = '-'
+		}
+	}
+	return filepath.Join(home, ".claude", "projects", string(name)), nil
+}
+
+// Cursor is how far a transcript was read.
+type Cursor struct {
+	// Offset is the end of the last complete line read.
+	Offset int64 `json:"offset"`
+	// LastID is the ID of the last message read. Claude Code writes a line per content block of a
+	// message, each with the usage of the whole message.
+	LastID string `json:"last_id,omitempty"`
+}
+
+// transcriptLine is the part of a transcript line that carries usage.
+type transcriptLine struct {
+	Type      string    `json:"type"`
+	Timestamp time.Time `json:"timestamp"`
+	CostUSD   float64   `json:"costUSD"`
+	Message   struct {
+		ID    string `json:"id"`
+		Model string `json:"model"`
+		Usage *struct {
+			InputTokens              int64 `json:"input_tokens"`
+			OutputTokens             int64 `json:"output_tokens"`
+			CacheCreationInputTokens int64 `json:"cache_creation_input_tokens"`
+			CacheReadInputTokens     int64 `json:"cache_read_input_tokens"`
+		} `json:"usage"`
+	} `json:"message"`
+}
+
+// ReadClaudeTranscripts reads the usage added to the transcripts in dir since the cursors, which
+// are updated. Responses before since are skipped, e.g. those of sessions started in the same
+// directory before Claude Squad. A missing directory has no usage.
+func ReadClaudeTranscripts(dir string, cursors map[string]Cursor, since time.Time) ([]Record, error) {
+	paths, err := filepath.Glob(filepath.Join(dir, "*.jsonl"))
+	if err != nil {
+		return nil, err
+	}
+	var records []Record
+	for _, path := range paths {
+		name := filepath.Base(path)
+		cursor := cursors[name]
+		read, err := readTranscript(path, &cursor, since)
+		if err != nil {
+			return records, err
+		}
+		cursors[name] = cursor
+		records = append(records, read...)
+	}
+	return records, nil
+}
+
+func readTranscript(path string, cursor *Cursor, since time.Time) ([]Record, error) {
+	f, err := os.Open(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	info, err := f.Stat()
+	if err != nil {
+		return nil, err
+	}
+	if info.Size() < cursor.Offset {
+		// The transcript was rewritten.
+		*cursor = Cursor{}
+	}
+	if info.Size() == cursor.Offset {
+		return nil, nil
+	}
+	if _, err := f.Seek(cursor.Offset, io.SeekStart); err != nil {
+		return nil, err
+	}
+
+	var records []Record
+	reader := bufio.NewReaderSize(f, 64*1024)
+	for {
+		line, err := reader.ReadBytes('\n')
+		if err != nil {
+			// An incomplete last line is read again once it's complete.
+			if errors.Is(err, io.EOF) {
+				return records, nil
+			}
+			return records, err
+		}
+		cursor.Offset += int64(len(line))
+		if record, ok := parseTranscriptLine(bytes.TrimSpace(line), cursor); ok && !record.Time.Before(since) {
+			records = append(records, record)
+		}
+	}
+}
+
+func parseTranscriptLine(line []byte, cursor *Cursor) (Record, bool) {
+	if !bytes.Contains(line, []byte(`"usage"`)) {
+		return Record{}, false
+	}
+	var entry transcriptLine
+	if err := json.Unmarshal(line, &entry); err != nil || entry.Type != "assistant" || entry.Message.Usage == nil {
+		return Record{}, false
+	}
+	if entry.Message.ID != "" {
+		if entry.Message.ID == cursor.LastID {
+			return Record{}, false
+		}
+		cursor.LastID = entry.Message.ID
+	}
+	u := entry.Message.Usage
+	return Record{
+		Time:  entry.Timestamp,
+		Model: entry.Message.Model,
+		Totals: Totals{
+			InputTokens:      u.InputTokens,
+			OutputTokens:     u.OutputTokens,
+			CacheWriteTokens: u.CacheCreationInputTokens,
+			CacheReadTokens:  u.CacheReadInputTokens,
+			Cost:             entry.CostUSD,
+		},
+		Priced: entry.CostUSD > 0,
+	}, true
+}
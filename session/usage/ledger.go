@@ -0,0 +1,212 @@
+package usage
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"text/tabwriter"
+)
+
+// PaneReport is the usage an agent printed in its pane: the tokens of its last response and the
+// cost of its whole session.
+type PaneReport struct {
+	Last        Totals  `json:"last"`
+	SessionCost float64 `json:"session_cost"`
+}
+
+// Ledger is the usage of one session per day. It also remembers how far the usage was read.
+type Ledger struct {
+	Session string `json:"session"`
+	// Repo is the name of the session's repository.
+	Repo string `json:"repo"`
+	// Days maps days, e.g. "2026-10-18", to the usage on that day.
+	Days map[string]Totals `json:"days"`
+
+	// Transcripts are the cursors of Claude Code's transcripts, by file name.
+	Transcripts map[string]Cursor `json:"transcripts,omitempty"`
+	// Pane is the last usage the agent printed, to tell new responses from ones already counted.
+	Pane *PaneReport `json:"pane,omitempty"`
+}
+
+// Add adds the usage of a response on day.
+func (l *Ledger) Add(day string, t Totals) {
+	if l.Days == nil {
+		l.Days = make(map[string]Totals)
+	}
+	totals := l.Days[day]
+	totals.Add(t)
+	l.Days[day] = totals
+}
+
+// Total returns the usage on all days.
+func (l *Ledger) Total() Totals {
+	var total Totals
+	for _, t := range l.Days {
+		total.Add(t)
+	}
+	return total
+}
+
+// AddPane counts the usage the agent printed in its pane if it belongs to a new response. The
+// session cost goes back to zero when the agent is restarted.
+func (l *Ledger) AddPane(day string, report PaneReport) {
+	if l.Pane != nil && *l.Pane == report {
+		return
+	}
+	cost := report.SessionCost
+	if l.Pane != nil && report.SessionCost >= l.Pane.SessionCost {
+		cost -= l.Pane.SessionCost
+	}
+	t := report.Last
+	t.Cost = cost
+	l.Add(day, t)
+	l.Pane = &report
+}
+
+// LedgerPath returns the path of the ledger of the session with the title in dir.
+func LedgerPath(dir, title string) string {
+	return filepath.Join(dir, url.PathEscape(title)+".json")
+}
+
+// LoadLedger reads the ledger of the session with the title from dir. A missing ledger is empty.
+func LoadLedger(dir, title string) (*Ledger, error) {
+	data, err := os.ReadFile(LedgerPath(dir, title))
+	if errors.Is(err, os.ErrNotExist) {
+		return &Ledger{Session: title}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var ledger Ledger
+	if err := json.Unmarshal(data, &ledger); err != nil {
+		return nil, fmt.Errorf("%s: %w", LedgerPath(dir, title), err)
+	}
+	return &ledger, nil
+}
+
+// Save writes the ledger to dir.
+func (l *Ledger) Save(dir string) error {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return err
+	}
+	data, err := json.Marshal(l)
+	if err != nil {
+		return err
+	}
+	path := LedgerPath(dir, l.Session)
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0600); err != nil {
+		return err
+	}
+	return os.Rename(tmp, path)
+}
+
+// LoadLedgers reads all ledgers in dir, including those of killed sessions.
+func LoadLedgers(dir string) ([]*Ledger, error) {
+	paths, err := filepath.Glob(filepath.Join(dir, "*.json"))
+	if err != nil {
+		return nil, err
+	}
+	ledgers := make([]*Ledger, 0, len(paths))
+	for _, path := range paths {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, err
+		}
+		var ledger Ledger
+		if err := json.Unmarshal(data, &ledger); err != nil {
+			return nil, fmt.Errorf("%s: %w", path, err)
+		}
+		ledgers = append(ledgers, &ledger)
+	}
+	return ledgers, nil
+}
+
+// DayTotal returns the usage of all ledgers on day.
+func DayTotal(ledgers []*Ledger, day string) Totals {
+	var total Totals
+	for _, ledger := range ledgers {
+		total.Add(ledger.Days[day])
+	}
+	return total
+}
+
+// Groupings of a report.
+const (
+	BySession = "session"
+	ByRepo    = "repo"
+	ByDay     = "day"
+)
+
+// ReportRow is a line of a report.
+type ReportRow struct {
+	Key string
+	Totals
+}
+
+// Report adds up the usage of the ledgers by session, repo or day, on the days from since on
+// ("" for all days). Rows are sorted by cost, or by day for ByDay.
+func Report(ledgers []*Ledger, by, since string) ([]ReportRow, error) {
+	if by != BySession && by != ByRepo && by != ByDay {
+		return nil, fmt.Errorf("unknown grouping %q, use %s, %s or %s", by, BySession, ByRepo, ByDay)
+	}
+	rows := make(map[string]*ReportRow)
+	for _, ledger := range ledgers {
+		for day, totals := range ledger.Days {
+			if day < since {
+				continue
+			}
+			key := ledger.Session
+			switch by {
+			case ByRepo:
+				key = ledger.Repo
+			case ByDay:
+				key = day
+			}
+			row, ok := rows[key]
+			if !ok {
+				row = &ReportRow{Key: key}
+				rows[key] = row
+			}
+			row.Add(totals)
+		}
+	}
+	report := make([]ReportRow, 0, len(rows))
+	for _, row := range rows {
+		report = append(report, *row)
+	}
+	sort.Slice(report, func(a, b int) bool {
+		if by == ByDay {
+			return report[a].Key < report[b].Key
+		}
+		if report[a].Cost != report[b].Cost {
+			return report[a].Cost > report[b].Cost
+		}
+		return report[a].Key < report[b].Key
+	})
+	return report, nil
+}
+
+// WriteReport writes the report as a table with a total.
+func WriteReport(w io.Writer, by string, rows []ReportRow) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintf(tw, "%s\tINPUT\tOUTPUT\tCACHE WRITE\tCACHE READ\tCOST\n", strings.ToUpper(by))
+	var total Totals
+	for _, row := range rows {
+		writeRow(tw, row.Key, row.Totals)
+		total.Add(row.Totals)
+	}
+	writeRow(tw, "total", total)
+	return tw.Flush()
+}
+
+func writeRow(w io.Writer, key string, t Totals) {
+	fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\n", key, FormatTokens(t.InputTokens), FormatTokens(t.OutputTokens),
+		FormatTokens(t.CacheWriteTokens), FormatTokens(t.CacheReadTokens), FormatCost(t.Cost))
+}
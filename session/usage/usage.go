@@ -0,0 +1,114 @@
+// Package usage measures the tokens agents use and what they cost. Claude Code's usage is read
+// from its transcripts, other agents' from what they print. Usage is kept in a ledger per session
+// and added up per session, repository and day.
+package usage
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Totals are tokens used and their cost.
+type Totals struct {
+	InputTokens      int64 `json:"input_tokens,omitempty"`
+	OutputTokens     int64 `json:"output_tokens,omitempty"`
+	CacheWriteTokens int64 `json:"cache_write_tokens,omitempty"`
+	CacheReadTokens  int64 `json:"cache_read_tokens,omitempty"`
+	// Cost is in USD.
+	Cost float64 `json:"cost,omitempty"`
+}
+
+// Add adds o to t.
+func (t *Totals) Add(o Totals) {
+	t.InputTokens += o.InputTokens
+	t.OutputTokens += o.OutputTokens
+	t.CacheWriteTokens += o.CacheWriteTokens
+	t.CacheReadTokens += o.CacheReadTokens
+	t.Cost += o.Cost
+}
+
+// Tokens returns all tokens, including cached ones.
+func (t Totals) Tokens() int64 {
+	return t.InputTokens + t.OutputTokens + t.CacheWriteTokens + t.CacheReadTokens
+}
+
+// IsZero returns true if nothing was used.
+func (t Totals) IsZero() bool {
+	return t == Totals{}
+}
+
+// Record is the usage of one response of the agent.
+type Record struct {
+	Time  time.Time
+	Model string
+	Totals
+	// Priced is true if the agent reported the cost itself.
+	Priced bool
+}
+
+// Price is what a model costs in USD per million tokens.
+type Price struct {
+	Input      float64 `json:"input"`
+	Output     float64 `json:"output"`
+	CacheWrite float64 `json:"cache_write"`
+	CacheRead  float64 `json:"cache_read"`
+}
+
+// Cost returns what the tokens cost at price p.
+func (p Price) Cost(t Totals) float64 {
+	return (float64(t.InputTokens)*p.Input + float64(t.OutputTokens)*p.Output +
+		float64(t.CacheWriteTokens)*p.CacheWrite + float64(t.CacheReadTokens)*p.CacheRead) / 1e6
+}
+
+// DefaultPrices are the list prices of Claude models. Keys match model names containing them,
+// the longest key first.
+var DefaultPrices = map[string]Price{
+	"opus-4-5": {Input: 5, Output: 25, CacheWrite: 6.25, CacheRead: 0.5},
+	"opus":     {Input: 15, Output: 75, CacheWrite: 18.75, CacheRead: 1.5},
+	"sonnet":   {Input: 3, Output: 15, CacheWrite: 3.75, CacheRead: 0.3},
+	"haiku":    {Input: 0.8, Output: 4, CacheWrite: 1, CacheRead: 0.08},
+}
+
+// PriceFor returns the price of model: the one with the longest key the model name contains,
+// looking at prices first and then at DefaultPrices.
+func PriceFor(prices map[string]Price, model string) (Price, bool) {
+	for _, table := range []map[string]Price{prices, DefaultPrices} {
+		best := ""
+		for key := range table {
+			if strings.Contains(model, key) && len(key) > len(best) {
+				best = key
+			}
+		}
+		if best != "" {
+			return table[best], true
+		}
+	}
+	return Price{}, false
+}
+
+// Day returns the day of t in the local time zone, e.g. "2026-10-18".
+func Day(t time.Time) string {
+	return t.Local().Format(time.DateOnly)
+}
+
+// FormatTokens formats a number of tokens compactly, e.g. "950", "12k" or "3.4M".
+func FormatTokens(n int64) string {
+	switch {
+	case n >= 10_000_000:
+		return fmt.Sprintf("%dM", n/1_000_000)
+	case n >= 1_000_000:
+		return fmt.Sprintf("%.1fM", float64(n)/1e6)
+	case n >= 10_000:
+		return fmt.Sprintf("%dk", n/1000)
+	case n >= 1000:
+		return fmt.Sprintf("%.1fk", float64(n)/1e3)
+	default:
+		return fmt.Sprintf("%d", n)
+	}
+}
+
+// FormatCost formats a cost in USD, e.g. "$0.42".
+func FormatCost(cost float64) string {
+	return fmt.Sprintf("$%.2f", cost)
+}
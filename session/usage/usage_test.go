@@ -0,0 +1,177 @@
+package usage
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func transcriptEntry(id, timestamp string, input, output int) string {
+	return `{"type":"assistant","timestamp":"` + timestamp + `","message":{"id":"` + id +
+		`","model":"claude-sonnet-4-5","usage":{"input_tokens":` + strconv.Itoa(input) +
+		`,"output_tokens":` + strconv.Itoa(output) + `,"cache_read_input_tokens":100}}}` + "\n"
+}
+
+func TestReadClaudeTranscripts(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "session.jsonl")
+	content := `{"type":"user","message":{"content":"hi"}}` + "\n" +
+		transcriptEntry("msg_old", "2026-10-17T09:00:00Z", 1, 1) +
+		transcriptEntry("msg_1", "2026-10-18T10:00:00Z", 10, 5) +
+		// Claude Code writes a line per content block, all with the same usage.
+		transcriptEntry("msg_1", "2026-10-18T10:00:01Z", 10, 5) +
+		transcriptEntry("msg_2", "2026-10-18T10:01:00Z", 20, 7)
+	require.NoError(t, os.WriteFile(path, []byte(content), 0644))
+
+	since := time.Date(2026, 10, 18, 0, 0, 0, 0, time.UTC)
+	cursors := make(map[string]Cursor)
+	records, err := ReadClaudeTranscripts(dir, cursors, since)
+	require.NoError(t, err)
+	require.Len(t, records, 2)
+	assert.Equal(t, "claude-sonnet-4-5", records[0].Model)
+	assert.Equal(t, Totals{InputTokens: 10, OutputTokens: 5, CacheReadTokens: 100}, records[0].Totals)
+	assert.False(t, records[0].Priced)
+	assert.Equal(t, Totals{InputTokens: 20, OutputTokens: 7, CacheReadTokens: 100}, records[1].Totals)
+	assert.Equal(t, Cursor{Offset: int64(len(content)), LastID: "msg_2"}, cursors["session.jsonl"])
+
+	// Nothing new, and an incomplete line, are not read.
+	records, err = ReadClaudeTranscripts(dir, cursors, since)
+	require.NoError(t, err)
+	assert.Empty(t, records)
+
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_WRONLY, 0644)
+	require.NoError(t, err)
+	line := strings.Replace(transcriptEntry("msg_3", "2026-10-18T10:02:00Z", 30, 9), `"type"`, `"costUSD":0.5,"type"`, 1)
+	_, err = f.WriteString(line[:20])
+	require.NoError(t, err)
+	records, err = ReadClaudeTranscripts(dir, cursors, since)
+	require.NoError(t, err)
+	assert.Empty(t, records)
+
+	_, err = f.WriteString(line[20:])
+	require.NoError(t, err)
+	require.NoError(t, f.Close())
+	records, err = ReadClaudeTranscripts(dir, cursors, since)
+	require.NoError(t, err)
+	require.Len(t, records, 1)
+	assert.True(t, records[0].Priced)
+	assert.Equal(t, 0.5, records[0].Cost)
+
+	records, err = ReadClaudeTranscripts(filepath.Join(dir, "missing"), cursors, since)
+	require.NoError(t, err)
+	assert.Empty(t, records)
+}
+
+func TestClaudeProjectDir(t *testing.T) {
+	t.Setenv("HOME", "/home/me")
+	dir, err := ClaudeProjectDir("/src/my_repo/.worktrees/fix-1/")
+	require.NoError(t, err)
+	assert.Equal(t, "/home/me/.claude/projects/-src-my-repo--worktrees-fix-1", dir)
+}
+
+func TestPriceFor(t *testing.T) {
+	price, ok := PriceFor(nil, "claude-opus-4-5-20251101")
+	require.True(t, ok)
+	assert.Equal(t, DefaultPrices["opus-4-5"], price)
+
+	price, ok = PriceFor(nil, "claude-opus-4-1-20250805")
+	require.True(t, ok)
+	assert.Equal(t, DefaultPrices["opus"], price)
+
+	custom := map[string]Price{"sonnet": {Input: 1, Output: 2}}
+	price, ok = PriceFor(custom, "claude-sonnet-4-5")
+	require.True(t, ok)
+	assert.Equal(t, custom["sonnet"], price)
+	assert.InDelta(t, 3.0, price.Cost(Totals{InputTokens: 1_000_000, OutputTokens: 1_000_000}), 1e-9)
+
+	_, ok = PriceFor(nil, "gpt-5")
+	assert.False(t, ok)
+}
+
+func TestLedgerAddPane(t *testing.T) {
+	var ledger Ledger
+	first := PaneReport{Last: Totals{InputTokens: 100, OutputTokens: 10}, SessionCost: 0.2}
+	ledger.AddPane("2026-10-18", first)
+	// The same report is still on screen.
+	ledger.AddPane("2026-10-18", first)
+	ledger.AddPane("2026-10-18", PaneReport{Last: Totals{InputTokens: 50, OutputTokens: 5}, SessionCost: 0.3})
+	// The agent was restarted.
+	ledger.AddPane("2026-10-19", PaneReport{Last: Totals{InputTokens: 10, OutputTokens: 1}, SessionCost: 0.05})
+
+	assert.Equal(t, int64(150), ledger.Days["2026-10-18"].InputTokens)
+	assert.InDelta(t, 0.3, ledger.Days["2026-10-18"].Cost, 1e-9)
+	assert.InDelta(t, 0.05, ledger.Days["2026-10-19"].Cost, 1e-9)
+	assert.InDelta(t, 0.35, ledger.Total().Cost, 1e-9)
+}
+
+func TestLedgerSaveAndReport(t *testing.T) {
+	dir := t.TempDir()
+	ledgers := []*Ledger{
+		{Session: "fix/login", Repo: "api", Days: map[string]Totals{
+			"2026-10-17": {InputTokens: 1000, Cost: 1},
+			"2026-10-18": {InputTokens: 2000, Cost: 2},
+		}},
+		{Session: "docs", Repo: "web", Days: map[string]Totals{
+			"2026-10-18": {InputTokens: 500, Cost: 0.5},
+		}},
+		{Session: "tests", Repo: "api", Days: map[string]Totals{
+			"2026-10-18": {InputTokens: 4000, Cost: 4},
+		}},
+	}
+	for _, ledger := range ledgers {
+		require.NoError(t, ledger.Save(dir))
+	}
+	loaded, err := LoadLedger(dir, "fix/login")
+	require.NoError(t, err)
+	assert.Equal(t, ledgers[0], loaded)
+	missing, err := LoadLedger(dir, "new")
+	require.NoError(t, err)
+	assert.Equal(t, &Ledger{Session: "new"}, missing)
+
+	all, err := LoadLedgers(dir)
+	require.NoError(t, err)
+	require.Len(t, all, 3)
+	assert.InDelta(t, 6.5, DayTotal(all, "2026-10-18").Cost, 1e-9)
+
+	rows, err := Report(all, ByRepo, "")
+	require.NoError(t, err)
+	assert.Equal(t, []ReportRow{
+		{Key: "api", Totals: Totals{InputTokens: 7000, Cost: 7}},
+		{Key: "web", Totals: Totals{InputTokens: 500, Cost: 0.5}},
+	}, rows)
+
+	rows, err = Report(all, BySession, "2026-10-18")
+	require.NoError(t, err)
+	require.Len(t, rows, 3)
+	assert.Equal(t, "tests", rows[0].Key)
+	assert.Equal(t, Totals{InputTokens: 2000, Cost: 2}, rows[1].Totals)
+
+	rows, err = Report(all, ByDay, "")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"2026-10-17", "2026-10-18"}, []string{rows[0].Key, rows[1].Key})
+
+	_, err = Report(all, "model", "")
+	assert.Error(t, err)
+
+	var out bytes.Buffer
+	require.NoError(t, WriteReport(&out, ByDay, rows))
+	assert.Equal(t, "DAY         INPUT  OUTPUT  CACHE WRITE  CACHE READ  COST\n"+
+		"2026-10-17  1.0k   0       0            0           $1.00\n"+
+		"2026-10-18  6.5k   0       0            0           $6.50\n"+
+		"total       7.5k   0       0            0           $7.50\n", out.String())
+}
+
+func TestFormatTokens(t *testing.T) {
+	assert.Equal(t, "950", FormatTokens(950))
+	assert.Equal(t, "1.5k", FormatTokens(1500))
+	assert.Equal(t, "48k", FormatTokens(48_200))
+	assert.Equal(t, "3.4M", FormatTokens(3_400_000))
+	assert.Equal(t, "12M", FormatTokens(12_000_000))
+}
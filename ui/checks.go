@@ -0,0 +1,189 @@
+package ui
+
+import (
+	"claude-squad/session"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+// ChecksPane shows the output of the running or last finished check of an instance.
+type ChecksPane struct {
+	width  int
+	height int
+
+	instance *session.Instance
+	run      session.CheckRun
+	hasRun   bool
+	lines    []string
+	// offset is the index of the first visible output line.
+	offset int
+	// follow keeps the end of the output in view while it grows. Scrolling up turns it off.
+	follow bool
+}
+
+func NewChecksPane() *ChecksPane {
+	return &ChecksPane{follow: true}
+}
+
+func (c *ChecksPane) SetSize(width, height int) {
+	c.width = max(1, width)
+	c.height = max(1, height)
+	c.clampOffset()
+}
+
+// SetCheck shows the check of instance, which may be nil.
+func (c *ChecksPane) SetCheck(instance *session.Instance) {
+	var run session.CheckRun
+	var hasRun bool
+	if instance != nil {
+		run, hasRun = instance.LastCheck()
+	}
+	if instance != c.instance || !run.StartedAt.Equal(c.run.StartedAt) {
+		c.offset = 0
+		c.follow = true
+	}
+	c.instance = instance
+	c.run = run
+	c.hasRun = hasRun
+
+	c.lines = nil
+	if hasRun {
+		c.lines = checkOutputLines(instance.CheckOutput())
+	}
+	c.clampOffset()
+}
+
+// checkOutputLines splits command output into lines for display. Carriage returns overwrite the
+// line like in a terminal, and tabs are expanded.
+func checkOutputLines(output string) []string {
+	output = strings.TrimRight(output, "\n")
+	if output == "" {
+		return nil
+	}
+	lines := strings.Split(output, "\n")
+	for i, line := range lines {
+		line = strings.TrimRight(line, "\r")
+		if cr := strings.LastIndexByte(line, '\r'); cr >= 0 {
+			line = line[cr+1:]
+		}
+		lines[i] = strings.ReplaceAll(line, "\t", "    ")
+	}
+	return lines
+}
+
+// bodyHeight is the number of output lines that fit below the header.
+func (c *ChecksPane) bodyHeight() int {
+	return max(1, c.height-2)
+}
+
+func (c *ChecksPane) maxOffset() int {
+	return max(0, len(c.lines)-c.bodyHeight())
+}
+
+func (c *ChecksPane) clampOffset() {
+	if c.follow {
+		c.offset = c.maxOffset()
+	}
+	c.offset = max(0, min(c.offset, c.maxOffset()))
+}
+
+func (c *ChecksPane) scrollBy(delta int) {
+	c.offset = max(0, min(c.offset+delta, c.maxOffset()))
+	c.follow = c.offset == c.maxOffset()
+}
+
+// ScrollUp scrolls the output up one line
+func (c *ChecksPane) ScrollUp() { c.scrollBy(-1) }
+
+// ScrollDown scrolls the output down one line
+func (c *ChecksPane) ScrollDown() { c.scrollBy(1) }
+
+// PageUp scrolls the output up one page
+func (c *ChecksPane) PageUp() { c.scrollBy(-max(1, c.bodyHeight()-1)) }
+
+// PageDown scrolls the output down one page
+func (c *ChecksPane) PageDown() { c.scrollBy(max(1, c.bodyHeight()-1)) }
+
+// HalfPageUp scrolls the output up half a page
+func (c *ChecksPane) HalfPageUp() { c.scrollBy(-max(1, c.bodyHeight()/2)) }
+
+// HalfPageDown scrolls the output down half a page
+func (c *ChecksPane) HalfPageDown() { c.scrollBy(max(1, c.bodyHeight()/2)) }
+
+// GotoTop scrolls to the start of the output
+func (c *ChecksPane) GotoTop() { c.scrollBy(-len(c.lines)) }
+
+// GotoBottom scrolls to the end of the output and follows it from there
+func (c *ChecksPane) GotoBottom() { c.scrollBy(len(c.lines)) }
+
+func (c *ChecksPane) String() string {
+	if c.instance == nil {
+		return lipgloss.Place(c.width, c.height, lipgloss.Center, lipgloss.Center, "No instance selected")
+	}
+	if !c.hasRun {
+		return lipgloss.Place(c.width, c.height, lipgloss.Center, lipgloss.Center,
+			"No checks have run. Press 'e' to run the check command in the session's worktree.")
+	}
+
+	label := checkBadgeStyle(c.run).Render(checkLabel(c.run))
+	header := label + "  " + StyleMuted().Render(c.run.Command)
+	if !c.run.Running && !c.run.Canceled && !c.run.Passed() {
+		header += StyleMuted().Render(fmt.Sprintf("  (exit %d)", c.run.ExitCode))
+	}
+	if attempts := c.instance.FixLoop.Attempts; attempts > 0 {
+		header += StyleWarn().Render(fmt.Sprintf("  fix loop: %d sent to the agent", attempts))
+	}
+	lines := make([]string, 0, c.bodyHeight()+2)
+	lines = append(lines, fitWidth(header, c.width), "")
+	end := min(len(c.lines), c.offset+c.bodyHeight())
+	for _, line := range c.lines[c.offset:end] {
+		lines = append(lines, fitWidth(line, c.width))
+	}
+	if len(c.lines) == 0 && !c.run.Running {
+		lines = append(lines, StyleMuted().Render("(no output)"))
+	}
+	return strings.Join(lines, "\n")
+}
+
+// checkLabel describes a check run in a few characters, e.g. "check ✓ 3.2s".
+func checkLabel(run session.CheckRun) string {
+	switch {
+	case run.Running:
+		return "check ● " + formatCheckDuration(run.Elapsed())
+	case run.Canceled:
+		return "check canceled"
+	case run.Passed():
+		return "check ✓ " + formatCheckDuration(run.Duration)
+	default:
+		return "check ✗ " + formatCheckDuration(run.Duration)
+	}
+}
+
+// checkBadgeStyle colors a check label by its outcome.
+func checkBadgeStyle(run session.CheckRun) lipgloss.Style {
+	switch {
+	case run.Running:
+		return StyleBadge()
+	case run.Canceled:
+		return StyleBadge().Foreground(StyleMuted().GetForeground())
+	case run.Passed():
+		return StyleBadge().Foreground(StyleOk().GetForeground())
+	default:
+		return StyleBadge().Foreground(StyleDanger().GetForeground())
+	}
+}
+
+// formatCheckDuration formats d with a precision that fits its length, e.g. "3.2s" or "1m05s".
+func formatCheckDuration(d time.Duration) string {
+	switch {
+	case d < 10*time.Second:
+		return fmt.Sprintf("%.1fs", d.Seconds())
+	case d < time.Minute:
+		return fmt.Sprintf("%ds", int(d.Seconds()))
+	default:
+		return fmt.Sprintf("%dm%02ds", int(d.Minutes()), int(d.Seconds())%60)
+	}
+}
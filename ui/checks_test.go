@@ -0,0 +1,30 @@
+package ui
+
+import (
+	"claude-squad/session"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestCheckLabel(t *testing.T) {
+	cases := []struct {
+		run  session.CheckRun
+		want string
+	}{
+		{session.CheckRun{Duration: 3200 * time.Millisecond}, "check ✓ 3.2s"},
+		{session.CheckRun{Duration: 42 * time.Second, ExitCode: 1}, "check ✗ 42s"},
+		{session.CheckRun{Duration: 65 * time.Second, ExitCode: -1, Canceled: true}, "check canceled"},
+		{session.CheckRun{Duration: 125 * time.Second}, "check ✓ 2m05s"},
+	}
+	for _, c := range cases {
+		require.Equal(t, c.want, checkLabel(c.run))
+	}
+	require.Contains(t, checkLabel(session.CheckRun{Running: true, StartedAt: time.Now()}), "check ● ")
+}
+
+func TestCheckOutputLines(t *testing.T) {
+	require.Nil(t, checkOutputLines("\n"))
+	require.Equal(t, []string{"ok", "    two", "done"}, checkOutputLines("ok\r\n\ttwo\n10%\r50%\rdone\n"))
+}
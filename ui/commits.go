@@ -0,0 +1,212 @@
+package ui
+
+import (
+	"claude-squad/session"
+	"claude-squad/session/git"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+var (
+	commitSHAStyle  lipgloss.Style
+	commitMetaStyle lipgloss.Style
+)
+
+func init() {
+	OnThemeChange(func() {
+		commitSHAStyle = StyleWarn()
+		commitMetaStyle = StyleMuted()
+	})
+}
+
+// CommitsPane lists the commits on an instance's branch above the diff of the selected commit.
+type CommitsPane struct {
+	width  int
+	height int
+
+	instance *session.Instance
+	commits  []git.Commit
+	selected int
+	// listOffset is the index of the first visible commit.
+	listOffset int
+	// message replaces the pane when there are no commits to show.
+	message string
+
+	diff *DiffPane
+	// diffSHA is the commit whose diff is shown.
+	diffSHA string
+}
+
+func NewCommitsPane() *CommitsPane {
+	return &CommitsPane{diff: NewDiffPane(), message: "No commits"}
+}
+
+func (c *CommitsPane) SetSize(width, height int) {
+	c.width = max(1, width)
+	c.height = max(1, height)
+	c.diff.SetSize(c.width, max(1, c.height-c.listHeight()-1))
+	c.clampList()
+}
+
+// listHeight is the number of rows of the commit list. The rest of the pane shows the diff.
+func (c *CommitsPane) listHeight() int {
+	return max(1, min(len(c.commits), max(3, c.height/3)))
+}
+
+// SetCommits updates the list of commits. The selection is kept on the same commit if it still
+// exists. err is shown instead of the list.
+func (c *CommitsPane) SetCommits(instance *session.Instance, commits []git.Commit, err error) {
+	previous, hadSelection := c.Selected()
+	if instance != c.instance {
+		hadSelection = false
+		c.selected = 0
+		c.listOffset = 0
+		c.diffSHA = ""
+	}
+	c.instance = instance
+	c.commits = commits
+
+	switch {
+	case err != nil:
+		c.message = fmt.Sprintf("Error: %v", err)
+	case len(commits) == 0:
+		c.message = "No commits since the session started"
+	default:
+		c.message = ""
+	}
+	if hadSelection {
+		for i := range commits {
+			if commits[i].SHA == previous.SHA {
+				c.selected = i
+			}
+		}
+	}
+	c.SetSize(c.width, c.height)
+}
+
+// Selected returns the selected commit.
+func (c *CommitsPane) Selected() (git.Commit, bool) {
+	if c.selected >= len(c.commits) {
+		return git.Commit{}, false
+	}
+	return c.commits[c.selected], true
+}
+
+// PendingDiff returns the selected commit if its diff hasn't been loaded yet.
+func (c *CommitsPane) PendingDiff() (string, bool) {
+	commit, ok := c.Selected()
+	if !ok || commit.SHA == c.diffSHA {
+		return "", false
+	}
+	return commit.SHA, true
+}
+
+// SetCommitDiff shows the diff of a commit. Diffs of commits that are no longer selected are
+// ignored.
+func (c *CommitsPane) SetCommitDiff(sha string, stats *git.DiffStats) {
+	commit, ok := c.Selected()
+	if !ok || commit.SHA != sha {
+		return
+	}
+	c.diffSHA = sha
+	c.diff.setStats(stats)
+}
+
+func (c *CommitsPane) clampList() {
+	c.selected = max(0, min(c.selected, len(c.commits)-1))
+	if c.selected < c.listOffset {
+		c.listOffset = c.selected
+	}
+	if c.selected >= c.listOffset+c.listHeight() {
+		c.listOffset = c.selected - c.listHeight() + 1
+	}
+}
+
+// SelectPrev selects the next newer commit.
+func (c *CommitsPane) SelectPrev() {
+	c.selected--
+	c.clampList()
+}
+
+// SelectNext selects the next older commit.
+func (c *CommitsPane) SelectNext() {
+	c.selected++
+	c.clampList()
+}
+
+func (c *CommitsPane) String() string {
+	if c.message != "" {
+		return lipgloss.Place(c.width, c.height, lipgloss.Center, lipgloss.Center, c.message)
+	}
+
+	lines := make([]string, 0, c.listHeight()+2)
+	end := min(len(c.commits), c.listOffset+c.listHeight())
+	for i := c.listOffset; i < end; i++ {
+		lines = append(lines, c.renderCommit(&c.commits[i], i == c.selected))
+	}
+	lines = append(lines, StyleMuted().Render(strings.Repeat("─", c.width)))
+	if c.diffSHA == "" {
+		lines = append(lines, "Loading...")
+	} else {
+		lines = append(lines, c.diff.String())
+	}
+	return strings.Join(lines, "\n")
+}
+
+// renderCommit renders one row of the commit list.
+func (c *CommitsPane) renderCommit(commit *git.Commit, selected bool) string {
+	meta := commitMetaStyle.Render(fmt.Sprintf("  %s, %s  ", commit.Author, relativeTime(commit.Date))) +
+		AdditionStyle.Render(fmt.Sprintf("+%d", commit.Added)) + " " +
+		DeletionStyle.Render(fmt.Sprintf("-%d", commit.Removed)) +
+		commitMetaStyle.Render(fmt.Sprintf(" (%d files)", commit.Files))
+	subjectWidth := max(10, c.width-len(commit.ShortSHA)-1-lipgloss.Width(meta))
+	subject := padWidth(truncateRight(commit.Subject, subjectWidth), subjectWidth)
+	if selected {
+		subject = StyleSelected().Render(subject)
+	}
+	return fitWidth(commitSHAStyle.Render(commit.ShortSHA)+" "+subject+meta, c.width)
+}
+
+// truncateRight shortens s to width cells, marking the cut with an ellipsis.
+func truncateRight(s string, width int) string {
+	if lipgloss.Width(s) <= width {
+		return s
+	}
+	return fitWidth(s, max(0, width-1)) + "…"
+}
+
+// relativeTime formats t as a short age like "5m ago".
+func relativeTime(t time.Time) string {
+	d := time.Since(t)
+	switch {
+	case d < time.Minute:
+		return "just now"
+	case d < time.Hour:
+		return fmt.Sprintf("%dm ago", int(d.Minutes()))
+	case d < 24*time.Hour:
+		return fmt.Sprintf("%dh ago", int(d.Hours()))
+	default:
+		return fmt.Sprintf("%dd ago", int(d.Hours()/24))
+	}
+}
+
+// PageUp scrolls the commit diff up one page
+func (c *CommitsPane) PageUp() { c.diff.PageUp() }
+
+// PageDown scrolls the commit diff down one page
+func (c *CommitsPane) PageDown() { c.diff.PageDown() }
+
+// HalfPageUp scrolls the commit diff up half a page
+func (c *CommitsPane) HalfPageUp() { c.diff.HalfPageUp() }
+
+// HalfPageDown scrolls the commit diff down half a page
+func (c *CommitsPane) HalfPageDown() { c.diff.HalfPageDown() }
+
+// GotoTop moves to the top of the commit diff
+func (c *CommitsPane) GotoTop() { c.diff.GotoTop() }
+
+// GotoBottom moves to the bottom of the commit diff
+func (c *CommitsPane) GotoBottom() { c.diff.GotoBottom() }
@@ -0,0 +1,77 @@
+package ui
+
+import (
+	"claude-squad/session/git"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testCommits(shas ...string) []git.Commit {
+	commits := make([]git.Commit, 0, len(shas))
+	for _, sha := range shas {
+		commits = append(commits, git.Commit{
+			SHA:      sha + "0000",
+			ShortSHA: sha,
+			Author:   "t",
+			Date:     time.Now().Add(-2 * time.Hour),
+			Subject:  "commit " + sha,
+			Files:    1,
+			Added:    2,
+			Removed:  1,
+		})
+	}
+	return commits
+}
+
+func TestCommitsPaneSelection(t *testing.T) {
+	instance := newDiffInstance(t, testDiff)
+	c := NewCommitsPane()
+	c.SetSize(100, 30)
+	c.SetCommits(instance, testCommits("ccc", "bbb", "aaa"), nil)
+
+	commit, ok := c.Selected()
+	require.True(t, ok)
+	assert.Equal(t, "ccc", commit.ShortSHA)
+	sha, pending := c.PendingDiff()
+	require.True(t, pending)
+	assert.Equal(t, "ccc0000", sha)
+	assert.Contains(t, c.String(), "Loading...")
+
+	c.SelectNext()
+	c.SelectNext()
+	c.SelectNext()
+	commit, _ = c.Selected()
+	assert.Equal(t, "aaa", commit.ShortSHA)
+
+	// Diffs of commits that are no longer selected are dropped.
+	c.SetCommitDiff("ccc0000", &git.DiffStats{Content: testDiff})
+	_, pending = c.PendingDiff()
+	assert.True(t, pending)
+	c.SetCommitDiff("aaa0000", &git.DiffStats{Content: testDiff})
+	_, pending = c.PendingDiff()
+	assert.False(t, pending)
+	out := c.String()
+	assert.Contains(t, out, "commit aaa")
+	assert.Contains(t, out, "2h ago")
+	assert.Contains(t, out, `var name = "new"`)
+
+	// A new commit on top keeps the selection on the same commit.
+	c.SetCommits(instance, testCommits("ddd", "ccc", "bbb", "aaa"), nil)
+	commit, _ = c.Selected()
+	assert.Equal(t, "aaa", commit.ShortSHA)
+	_, pending = c.PendingDiff()
+	assert.False(t, pending)
+
+	// When the commit is gone, e.g. after a drop, the selection stays in range.
+	c.SetCommits(instance, testCommits("ddd", "ccc"), nil)
+	commit, _ = c.Selected()
+	assert.Equal(t, "ccc", commit.ShortSHA)
+
+	c.SetCommits(instance, nil, nil)
+	_, ok = c.Selected()
+	assert.False(t, ok)
+	assert.Contains(t, c.String(), "No commits since the session started")
+}
@@ -2,274 +2,723 @@ package ui
 
 import (
 	"claude-squad/session"
+	"claude-squad/session/git"
 	"fmt"
 	"strings"
 
-	"github.com/charmbracelet/bubbles/viewport"
 	"github.com/charmbracelet/lipgloss"
 )
 
 var (
-    AdditionStyle = StyleOk()
-    DeletionStyle = StyleDanger()
-    HunkStyle     = lipgloss.NewStyle().Foreground(Theme.Accent)
+	AdditionStyle lipgloss.Style
+	DeletionStyle lipgloss.Style
+	HunkStyle     lipgloss.Style
+	// AdditionWordStyle and DeletionWordStyle emphasize the changed words of a modified line.
+	AdditionWordStyle lipgloss.Style
+	DeletionWordStyle lipgloss.Style
+
+	diffFileStyle    lipgloss.Style
+	diffGutterStyle  lipgloss.Style
+	diffCommentStyle lipgloss.Style
+	// diffAddLineStyle and diffDelLineStyle are the base styles of added and removed lines.
+	diffAddLineStyle lipgloss.Style
+	diffDelLineStyle lipgloss.Style
+	// syntaxStyles holds the style of each token kind. Unset attributes are taken from the line.
+	syntaxStyles [tokComment + 1]lipgloss.Style
+)
+
+func init() {
+	OnThemeChange(func() {
+		AdditionStyle = StyleOk()
+		DeletionStyle = StyleDanger()
+		HunkStyle = lipgloss.NewStyle().Foreground(Theme.Accent)
+		diffAddLineStyle = StyleOk().Background(Theme.DiffAddBg)
+		diffDelLineStyle = StyleDanger().Background(Theme.DiffDelBg)
+		AdditionWordStyle = diffAddLineStyle.Bold(true).Underline(true)
+		DeletionWordStyle = diffDelLineStyle.Bold(true).Underline(true)
+		diffFileStyle = lipgloss.NewStyle().Bold(true).Foreground(Theme.Fg)
+		diffGutterStyle = StyleMuted()
+		diffCommentStyle = StyleWarn().Italic(true)
+		syntaxStyles = [...]lipgloss.Style{
+			tokPlain:   lipgloss.NewStyle(),
+			tokKeyword: lipgloss.NewStyle().Foreground(Theme.AccentAlt),
+			tokType:    lipgloss.NewStyle().Foreground(Theme.Accent),
+			tokString:  lipgloss.NewStyle().Foreground(Theme.Warn),
+			tokNumber:  lipgloss.NewStyle().Foreground(Theme.Accent),
+			tokComment: lipgloss.NewStyle().Foreground(Theme.FgMuted).Italic(true),
+		}
+	})
+}
+
+// DiffLayout selects how hunks are rendered.
+type DiffLayout int
+
+const (
+	DiffUnified DiffLayout = iota
+	DiffSideBySide
+)
+
+// minSidebarWidth is the pane width below which the file sidebar is hidden.
+const minSidebarWidth = 70
+
+type diffRowKind int
+
+const (
+	rowFile diffRowKind = iota
+	rowHunk
+	// rowLine is a single line in the unified layout and a pair of lines side by side.
+	rowLine
+	// rowComment shows a review comment below the line it belongs to.
+	rowComment
 )
 
+// diffRow is one display row of the diff. Rows only index into the parsed diff; they are styled
+// when they become visible, so large diffs stay cheap to scroll.
+type diffRow struct {
+	kind diffRowKind
+	file int
+	hunk int
+	// left and right are line indexes in the hunk, or -1. Side by side they are the old and new
+	// line. In the unified layout left is the line and right is the line it is compared with for
+	// word highlighting.
+	left  int
+	right int
+	// comment is the index of the comment in the review state for rowComment rows.
+	comment int
+}
+
 type DiffPane struct {
-    viewport viewport.Model
-    diff     string
-    stats    string
-    width    int
-    height   int
+	width  int
+	height int
+	layout DiffLayout
+
+	// content is the raw diff the files were parsed from.
+	content string
+	files   []git.FileDiff
+	hashes  []string
+	// highlights caches the syntax tokens of each file, parallel to files.
+	highlights []fileHighlight
+	rows       []diffRow
+	// offset is the index of the first visible row.
+	offset int
+	// cursor is the index of the selected row. Review actions apply to it.
+	cursor int
 
-    // navigation markers
-    fileOffsets []int // line indexes within diff (not including stats header)
-    hunkOffsets []int // line indexes within diff (not including stats header)
+	stats string
+	// spec is what the shown diff compares.
+	spec git.DiffSpec
+	// message replaces the diff when there is nothing to show.
+	message string
+	// fallback holds colorized lines for diffs that couldn't be parsed.
+	fallback []string
+
+	instance *session.Instance
 }
 
 func NewDiffPane() *DiffPane {
-	return &DiffPane{
-		viewport: viewport.New(0, 0),
-	}
+	return &DiffPane{}
 }
 
 func (d *DiffPane) SetSize(width, height int) {
-    if width < 1 { width = 1 }
-    if height < 1 { height = 1 }
-    d.width = width
-    d.height = height
-    d.viewport.Width = width
-    d.viewport.Height = height
-    // Update viewport content if diff exists
-    if d.diff != "" || d.stats != "" {
-        d.viewport.SetContent(lipgloss.JoinVertical(lipgloss.Left, d.stats, d.diff))
-    }
+	d.width = max(1, width)
+	d.height = max(1, height)
+	d.clampCursor()
 }
 
 func (d *DiffPane) SetDiff(instance *session.Instance) {
-	centeredFallbackMessage := lipgloss.Place(
-		d.width,
-		d.height,
-		lipgloss.Center,
-		lipgloss.Center,
-		"No changes",
-	)
+	if instance != d.instance {
+		d.offset = 0
+		d.cursor = 0
+	}
+	d.instance = instance
 
 	if instance == nil || !instance.Started() {
-		d.viewport.SetContent(centeredFallbackMessage)
+		d.clear("No changes")
 		return
 	}
+	d.setStats(instance.GetDiffStats())
+}
 
-	stats := instance.GetDiffStats()
+// setStats shows the given diff. stats is nil while the worktree is being set up.
+func (d *DiffPane) setStats(stats *git.DiffStats) {
 	if stats == nil {
 		// Show loading message if worktree is not ready
-		centeredMessage := lipgloss.Place(
-			d.width,
-			d.height,
-			lipgloss.Center,
-			lipgloss.Center,
-			"Setting up worktree...",
-		)
-		d.viewport.SetContent(centeredMessage)
+		d.clear("Setting up worktree...")
 		return
 	}
-
 	if stats.Error != nil {
-		// Show error message
-		centeredMessage := lipgloss.Place(
-			d.width,
-			d.height,
-			lipgloss.Center,
-			lipgloss.Center,
-			fmt.Sprintf("Error: %v", stats.Error),
-		)
-		d.viewport.SetContent(centeredMessage)
+		d.clear(fmt.Sprintf("Error: %v", stats.Error))
+		return
+	}
+	if stats.IsEmpty() {
+		if stats.Spec.Mode != git.DiffSinceBase {
+			d.clear(fmt.Sprintf("No changes (%s)", stats.Spec))
+			return
+		}
+		d.clear("No changes")
 		return
 	}
 
-    if stats.IsEmpty() {
-        d.stats = ""
-        d.diff = ""
-        d.viewport.SetContent(centeredFallbackMessage)
-    } else {
-        additions := AdditionStyle.Render(fmt.Sprintf("%d additions(+)", stats.Added))
-        deletions := DeletionStyle.Render(fmt.Sprintf("%d deletions(-)", stats.Removed))
-        d.stats = lipgloss.JoinHorizontal(lipgloss.Center, additions, " ", deletions)
-        d.diff = colorizeDiff(stats.Content)
-        d.viewport.SetContent(lipgloss.JoinVertical(lipgloss.Left, d.stats, d.diff))
-        d.parseMarkers()
-    }
+	d.message = ""
+	if stats.Spec != d.spec {
+		d.spec = stats.Spec
+		d.offset = 0
+		d.cursor = 0
+	}
+	additions := AdditionStyle.Render(fmt.Sprintf("%d additions(+)", stats.Added))
+	deletions := DeletionStyle.Render(fmt.Sprintf("%d deletions(-)", stats.Removed))
+	d.stats = lipgloss.JoinHorizontal(lipgloss.Center, additions, " ", deletions)
+
+	if stats.Content != d.content {
+		d.content = stats.Content
+		d.files = git.ParseDiff(stats.Content)
+		d.hashes = d.hashes[:0]
+		for i := range d.files {
+			d.hashes = append(d.hashes, d.files[i].Hash())
+		}
+		d.highlights = make([]fileHighlight, len(d.files))
+		for i := range d.files {
+			d.highlights[i].lang = languageFor(d.files[i].Path())
+		}
+		d.fallback = nil
+		if len(d.files) == 0 {
+			d.fallback = strings.Split(strings.TrimSuffix(colorizeDiff(stats.Content), "\n"), "\n")
+		}
+	}
+	d.buildRows()
 }
 
-func (d *DiffPane) String() string {
-    return d.viewport.View()
+func (d *DiffPane) clear(message string) {
+	d.message = message
+	d.stats = ""
+	d.content = ""
+	d.files = nil
+	d.hashes = nil
+	d.highlights = nil
+	d.rows = d.rows[:0]
+	d.fallback = nil
+	d.offset = 0
+	d.cursor = 0
+}
+
+// state returns the persisted viewer state of the current instance.
+func (d *DiffPane) state() *session.DiffViewState {
+	if d.instance == nil {
+		return &session.DiffViewState{}
+	}
+	return &d.instance.DiffView
+}
+
+// collapsed returns true if the file's hunks are hidden. Files marked viewed that have changed
+// since are shown again.
+func (d *DiffPane) collapsed(file int) bool {
+	path := d.files[file].Path()
+	return d.state().IsCollapsed(path) && !d.state().ViewedStale(path, d.hashes[file])
+}
+
+// fileHighlight holds the syntax tokens of a file's lines. Lines are lexed on first display, so
+// only the parts of a large diff that are scrolled to are ever highlighted.
+type fileHighlight struct {
+	// lang is nil for files that aren't highlighted.
+	lang  *language
+	hunks []hunkHighlight
+}
+
+// hunkHighlight holds the tokens of the first len(lines) lines of a hunk. The old and new side of
+// the hunk are lexed separately so that a comment opened on one side doesn't leak into the other.
+type hunkHighlight struct {
+	lines                      [][]synToken
+	oldInComment, newInComment bool
+}
+
+// lineTokens returns the syntax tokens of a line, or nil if the file isn't highlighted. Hunks
+// start in the middle of a file, so a block comment opened before the hunk is not detected.
+func (d *DiffPane) lineTokens(fi, hi, li int) []synToken {
+	fh := &d.highlights[fi]
+	if fh.lang == nil {
+		return nil
+	}
+	if fh.hunks == nil {
+		fh.hunks = make([]hunkHighlight, len(d.files[fi].Hunks))
+	}
+	hh := &fh.hunks[hi]
+	lines := d.files[fi].Hunks[hi].Lines
+	for n := len(hh.lines); n <= li; n++ {
+		var tokens []synToken
+		switch lines[n].Kind {
+		case git.LineAdded:
+			tokens, hh.newInComment = fh.lang.lex(lines[n].Text, hh.newInComment)
+		case git.LineRemoved:
+			tokens, hh.oldInComment = fh.lang.lex(lines[n].Text, hh.oldInComment)
+		default:
+			tokens, hh.newInComment = fh.lang.lex(lines[n].Text, hh.newInComment)
+			hh.oldInComment = hh.newInComment
+		}
+		hh.lines = append(hh.lines, tokens)
+	}
+	return hh.lines[li]
 }
 
-// ScrollUp scrolls the viewport up
-func (d *DiffPane) ScrollUp() {
-	d.viewport.LineUp(1)
+// commentKey identifies the line a review comment belongs to.
+type commentKey struct {
+	path    string
+	line    int
+	removed bool
 }
 
-// ScrollDown scrolls the viewport down
-func (d *DiffPane) ScrollDown() {
-    d.viewport.LineDown(1)
+// buildRows lays out the parsed diff for the current layout, collapsed files and comments.
+func (d *DiffPane) buildRows() {
+	d.rows = d.rows[:0]
+	comments := make(map[commentKey][]int)
+	for i, c := range d.state().Comments {
+		k := commentKey{c.Path, c.Line, c.Removed}
+		comments[k] = append(comments[k], i)
+	}
+	for fi := range d.files {
+		d.rows = append(d.rows, diffRow{kind: rowFile, file: fi, left: -1, right: -1})
+		if d.collapsed(fi) {
+			continue
+		}
+		for hi := range d.files[fi].Hunks {
+			d.rows = append(d.rows, diffRow{kind: rowHunk, file: fi, hunk: hi, left: -1, right: -1})
+			d.appendLineRows(fi, hi, comments)
+		}
+	}
+	d.clampCursor()
+}
+
+// appendLineRows adds the rows for a hunk's lines and their comments. Each run of removed lines
+// followed by added lines is paired up line by line, which drives both the side-by-side layout and
+// word highlighting.
+func (d *DiffPane) appendLineRows(fi, hi int, comments map[commentKey][]int) {
+	path := d.files[fi].Path()
+	lines := d.files[fi].Hunks[hi].Lines
+	addComments := func(li int) {
+		if li < 0 {
+			return
+		}
+		for _, ci := range comments[lineCommentKey(path, &lines[li])] {
+			d.rows = append(d.rows, diffRow{kind: rowComment, file: fi, hunk: hi, left: li, right: -1, comment: ci})
+		}
+	}
+	row := func(left, right int) {
+		d.rows = append(d.rows, diffRow{kind: rowLine, file: fi, hunk: hi, left: left, right: right})
+		addComments(left)
+		// In the unified layout right is only the word diff partner, which has a row of its own.
+		if d.layout == DiffSideBySide && right != left {
+			addComments(right)
+		}
+	}
+	for i := 0; i < len(lines); {
+		if lines[i].Kind == git.LineContext {
+			if d.layout == DiffSideBySide {
+				row(i, i)
+			} else {
+				row(i, -1)
+			}
+			i++
+			continue
+		}
+
+		delStart := i
+		for i < len(lines) && lines[i].Kind == git.LineRemoved {
+			i++
+		}
+		addStart := i
+		for i < len(lines) && lines[i].Kind == git.LineAdded {
+			i++
+		}
+		dels, adds := addStart-delStart, i-addStart
+
+		partner := func(start, k, n int) int {
+			if k < n {
+				return start + k
+			}
+			return -1
+		}
+		if d.layout == DiffSideBySide {
+			for k := 0; k < max(dels, adds); k++ {
+				row(partner(delStart, k, dels), partner(addStart, k, adds))
+			}
+			continue
+		}
+		for k := 0; k < dels; k++ {
+			row(delStart+k, partner(addStart, k, adds))
+		}
+		for k := 0; k < adds; k++ {
+			row(addStart+k, partner(delStart, k, dels))
+		}
+	}
+}
+
+// lineCommentKey returns the key of comments on the given line.
+func lineCommentKey(path string, line *git.DiffLine) commentKey {
+	if line.Kind == git.LineRemoved {
+		return commentKey{path, line.OldLine, true}
+	}
+	return commentKey{path, line.NewLine, false}
+}
+
+// bodyHeight is the number of rows available below the stats header.
+func (d *DiffPane) bodyHeight() int {
+	return max(1, d.height-1)
+}
+
+func (d *DiffPane) numRows() int {
+	if d.fallback != nil {
+		return len(d.fallback)
+	}
+	return len(d.rows)
 }
 
+func (d *DiffPane) clampOffset() {
+	d.offset = max(0, min(d.offset, d.numRows()-d.bodyHeight()))
+}
+
+// clampCursor keeps the cursor on a row and scrolls it into view.
+func (d *DiffPane) clampCursor() {
+	d.cursor = max(0, min(d.cursor, len(d.rows)-1))
+	if d.cursor < d.offset {
+		d.offset = d.cursor
+	}
+	if d.cursor >= d.offset+d.bodyHeight() {
+		d.offset = d.cursor - d.bodyHeight() + 1
+	}
+	d.clampOffset()
+}
+
+// moveCursor moves the cursor by delta rows.
+func (d *DiffPane) moveCursor(delta int) {
+	if d.fallback != nil {
+		d.offset += delta
+		d.clampOffset()
+		return
+	}
+	d.cursor += delta
+	d.clampCursor()
+}
+
+// scrollBy moves the viewport and the cursor together by delta rows.
+func (d *DiffPane) scrollBy(delta int) {
+	d.offset += delta
+	d.clampOffset()
+	d.cursor += delta
+	d.clampCursor()
+}
+
+// ScrollUp moves the cursor up one row
+func (d *DiffPane) ScrollUp() { d.moveCursor(-1) }
+
+// ScrollDown moves the cursor down one row
+func (d *DiffPane) ScrollDown() { d.moveCursor(1) }
+
 // PageUp scrolls up one page
-func (d *DiffPane) PageUp() { d.viewport.LineUp(max(1, d.viewport.Height-1)) }
+func (d *DiffPane) PageUp() { d.scrollBy(-max(1, d.bodyHeight()-1)) }
 
 // PageDown scrolls down one page
-func (d *DiffPane) PageDown() { d.viewport.LineDown(max(1, d.viewport.Height-1)) }
+func (d *DiffPane) PageDown() { d.scrollBy(max(1, d.bodyHeight()-1)) }
 
 // HalfPageUp scrolls up half a page
-func (d *DiffPane) HalfPageUp() { d.viewport.LineUp(max(1, d.viewport.Height/2)) }
+func (d *DiffPane) HalfPageUp() { d.scrollBy(-max(1, d.bodyHeight()/2)) }
 
 // HalfPageDown scrolls down half a page
-func (d *DiffPane) HalfPageDown() { d.viewport.LineDown(max(1, d.viewport.Height/2)) }
+func (d *DiffPane) HalfPageDown() { d.scrollBy(max(1, d.bodyHeight()/2)) }
 
 // GotoTop moves to the top of the diff content area
-func (d *DiffPane) GotoTop() { d.viewport.GotoTop() }
+func (d *DiffPane) GotoTop() {
+	d.offset = 0
+	d.cursor = 0
+}
 
 // GotoBottom moves to the bottom of the diff content area
-func (d *DiffPane) GotoBottom() { d.viewport.GotoBottom() }
-
-// parseMarkers builds file and hunk line indices for navigation
-func (d *DiffPane) parseMarkers() {
-    d.fileOffsets = d.fileOffsets[:0]
-    d.hunkOffsets = d.hunkOffsets[:0]
-    if d.diff == "" {
-        return
-    }
-    lines := strings.Split(d.diff, "\n")
-    for i, line := range lines {
-        if strings.HasPrefix(line, "diff --git ") {
-            d.fileOffsets = append(d.fileOffsets, i)
-            continue
-        }
-        if strings.HasPrefix(line, "@@") {
-            d.hunkOffsets = append(d.hunkOffsets, i)
-        }
-    }
+func (d *DiffPane) GotoBottom() {
+	d.offset = d.numRows()
+	d.clampOffset()
+	d.cursor = len(d.rows) - 1
+	d.clampCursor()
 }
 
-// JumpNextHunk moves the viewport to the next hunk header if present
-func (d *DiffPane) JumpNextHunk() {
-    if len(d.hunkOffsets) == 0 {
-        return
-    }
-    headerLines := 0
-    if d.stats != "" {
-        headerLines = 1
-    }
-    cur := d.viewport.YOffset - headerLines
-    if cur < 0 {
-        cur = 0
-    }
-    for _, off := range d.hunkOffsets {
-        if off > cur {
-            d.viewport.SetYOffset(off + headerLines)
-            return
-        }
-    }
-    // wrap to last
-    d.viewport.SetYOffset(d.hunkOffsets[len(d.hunkOffsets)-1] + headerLines)
+// selectRow moves the cursor to row i and scrolls it to the top of the viewport.
+func (d *DiffPane) selectRow(i int) {
+	d.cursor = i
+	d.offset = i
+	d.clampOffset()
+	d.clampCursor()
 }
 
-// JumpPrevHunk moves the viewport to the previous hunk header if present
-func (d *DiffPane) JumpPrevHunk() {
-    if len(d.hunkOffsets) == 0 {
-        return
-    }
-    headerLines := 0
-    if d.stats != "" {
-        headerLines = 1
-    }
-    cur := d.viewport.YOffset - headerLines
-    if cur < 0 {
-        cur = 0
-    }
-    for i := len(d.hunkOffsets) - 1; i >= 0; i-- {
-        if d.hunkOffsets[i] < cur {
-            d.viewport.SetYOffset(d.hunkOffsets[i] + headerLines)
-            return
-        }
-    }
-    // wrap to first
-    d.viewport.SetYOffset(d.hunkOffsets[0] + headerLines)
+// jumpNext moves to the next row of the given kind, or the last one if there is none.
+func (d *DiffPane) jumpNext(kind diffRowKind) {
+	last := -1
+	for i, r := range d.rows {
+		if r.kind != kind {
+			continue
+		}
+		if i > d.cursor {
+			d.selectRow(i)
+			return
+		}
+		last = i
+	}
+	if last >= 0 {
+		d.selectRow(last)
+	}
 }
 
-// JumpNextFile moves to next file boundary
-func (d *DiffPane) JumpNextFile() {
-    if len(d.fileOffsets) == 0 {
-        return
-    }
-    headerLines := 0
-    if d.stats != "" {
-        headerLines = 1
-    }
-    cur := d.viewport.YOffset - headerLines
-    if cur < 0 {
-        cur = 0
-    }
-    for _, off := range d.fileOffsets {
-        if off > cur {
-            d.viewport.SetYOffset(off + headerLines)
-            return
-        }
-    }
-    d.viewport.SetYOffset(d.fileOffsets[len(d.fileOffsets)-1] + headerLines)
+// jumpPrev moves to the previous row of the given kind, or the first one if there is none.
+func (d *DiffPane) jumpPrev(kind diffRowKind) {
+	first := -1
+	for i := len(d.rows) - 1; i >= 0; i-- {
+		if d.rows[i].kind != kind {
+			continue
+		}
+		if i < d.cursor {
+			d.selectRow(i)
+			return
+		}
+		first = i
+	}
+	if first >= 0 {
+		d.selectRow(first)
+	}
 }
 
+// JumpNextHunk moves the viewport to the next hunk header if present
+func (d *DiffPane) JumpNextHunk() { d.jumpNext(rowHunk) }
+
+// JumpPrevHunk moves the viewport to the previous hunk header if present
+func (d *DiffPane) JumpPrevHunk() { d.jumpPrev(rowHunk) }
+
+// JumpNextFile moves to next file boundary
+func (d *DiffPane) JumpNextFile() { d.jumpNext(rowFile) }
+
 // JumpPrevFile moves to previous file boundary
-func (d *DiffPane) JumpPrevFile() {
-    if len(d.fileOffsets) == 0 {
-        return
-    }
-    headerLines := 0
-    if d.stats != "" {
-        headerLines = 1
-    }
-    cur := d.viewport.YOffset - headerLines
-    if cur < 0 {
-        cur = 0
-    }
-    for i := len(d.fileOffsets) - 1; i >= 0; i-- {
-        if d.fileOffsets[i] < cur {
-            d.viewport.SetYOffset(d.fileOffsets[i] + headerLines)
-            return
-        }
-    }
-    d.viewport.SetYOffset(d.fileOffsets[0] + headerLines)
-}
-func colorizeDiff(diff string) string {
+func (d *DiffPane) JumpPrevFile() { d.jumpPrev(rowFile) }
+
+// currentFile returns the index of the file under the cursor, or -1.
+func (d *DiffPane) currentFile() int {
+	if d.cursor >= len(d.rows) {
+		return -1
+	}
+	return d.rows[d.cursor].file
+}
+
+// scrollToFile selects the header of the given file and puts it at the top of the viewport.
+func (d *DiffPane) scrollToFile(file int) {
+	for i, r := range d.rows {
+		if r.kind == rowFile && r.file == file {
+			d.selectRow(i)
+			return
+		}
+	}
+}
+
+// Layout returns the current layout.
+func (d *DiffPane) Layout() DiffLayout { return d.layout }
+
+// ToggleLayout switches between the unified and side-by-side layouts, keeping the current file
+// in view.
+func (d *DiffPane) ToggleLayout() {
+	file := d.currentFile()
+	if d.layout == DiffUnified {
+		d.layout = DiffSideBySide
+	} else {
+		d.layout = DiffUnified
+	}
+	d.buildRows()
+	if file >= 0 {
+		d.scrollToFile(file)
+	}
+}
+
+// ToggleCollapsed collapses or expands the file under the cursor.
+func (d *DiffPane) ToggleCollapsed() {
+	file := d.currentFile()
+	if file < 0 {
+		return
+	}
+	d.state().SetCollapsed(d.files[file].Path(), !d.collapsed(file))
+	d.buildRows()
+	d.scrollToFile(file)
+}
+
+// ToggleViewed marks the file under the cursor as viewed, which also collapses it, or clears the
+// mark.
+func (d *DiffPane) ToggleViewed() {
+	file := d.currentFile()
+	if file < 0 {
+		return
+	}
+	path, hash := d.files[file].Path(), d.hashes[file]
+	d.state().SetViewed(path, hash, !d.state().IsViewed(path, hash))
+	d.buildRows()
+	d.scrollToFile(file)
+}
+
+// selectedHunk returns the file and hunk under the cursor. ok is false on file headers.
+func (d *DiffPane) selectedHunk() (file, hunk int, ok bool) {
+	if d.cursor >= len(d.rows) || d.rows[d.cursor].kind == rowFile {
+		return 0, 0, false
+	}
+	r := d.rows[d.cursor]
+	return r.file, r.hunk, true
+}
+
+// Revertible returns true if the shown diff is the session's changes in its worktree, which is
+// what hunks can be reverted and approved in. Reverting a patch of a commit or of a comparison with
+// another ref would undo changes the session never made.
+func (d *DiffPane) Revertible() bool {
+	return d.spec.Mode == git.DiffSinceBase || d.spec.Mode == git.DiffUncommitted
+}
+
+// SelectedHunkPatch returns a patch of the hunk under the cursor. ok is false unless the diff is
+// Revertible.
+func (d *DiffPane) SelectedHunkPatch() (string, bool) {
+	fi, hi, ok := d.selectedHunk()
+	if !ok || !d.Revertible() {
+		return "", false
+	}
+	return d.files[fi].HunkPatch(hi), true
+}
+
+// SelectedFilePatch returns the path and patch of the file under the cursor. ok is false unless
+// the diff is Revertible.
+func (d *DiffPane) SelectedFilePatch() (path, patch string, ok bool) {
+	fi := d.currentFile()
+	if fi < 0 || !d.Revertible() {
+		return "", "", false
+	}
+	return d.files[fi].Path(), d.files[fi].Patch(), true
+}
+
+// ToggleApproved marks the hunk under the cursor as approved or clears the mark. It returns false
+// if the cursor is not on a hunk or the diff is not Revertible.
+func (d *DiffPane) ToggleApproved() bool {
+	fi, hi, ok := d.selectedHunk()
+	if !ok || !d.Revertible() {
+		return false
+	}
+	path, hash := d.files[fi].Path(), d.files[fi].Hunks[hi].Hash()
+	d.state().SetApproved(path, hash, !d.state().IsApproved(path, hash))
+	return true
+}
+
+// SelectedLine returns a comment on the line under the cursor, without text. Side by side, the
+// new line of a pair is preferred.
+func (d *DiffPane) SelectedLine() (session.ReviewComment, bool) {
+	if d.cursor >= len(d.rows) {
+		return session.ReviewComment{}, false
+	}
+	r := d.rows[d.cursor]
+	if r.kind != rowLine && r.kind != rowComment {
+		return session.ReviewComment{}, false
+	}
+	li := r.left
+	if r.kind == rowLine && d.layout == DiffSideBySide && r.right >= 0 {
+		li = r.right
+	}
+	line := &d.files[r.file].Hunks[r.hunk].Lines[li]
+	k := lineCommentKey(d.files[r.file].Path(), line)
+	return session.ReviewComment{Path: k.path, Line: k.line, Removed: k.removed, Code: line.Text}, true
+}
+
+// AddComment adds a review comment and shows it below its line.
+func (d *DiffPane) AddComment(c session.ReviewComment) {
+	d.state().AddComment(c)
+	d.buildRows()
+}
+
+func (d *DiffPane) String() string {
+	if d.message != "" {
+		return lipgloss.Place(d.width, d.height, lipgloss.Center, lipgloss.Center, d.message)
+	}
+
+	bodyHeight := d.bodyHeight()
+	mainWidth := d.width
+	var sidebar []string
+	if d.width >= minSidebarWidth && len(d.files) > 0 {
+		sidebarWidth := max(20, min(36, d.width/4))
+		mainWidth = d.width - sidebarWidth - 1
+		sidebar = d.renderSidebar(sidebarWidth, bodyHeight)
+	}
+
 	b := getBuilder()
 	defer putBuilder(b)
-
-	lines := strings.Split(diff, "\n")
-	for _, line := range lines {
-		if len(line) > 0 {
-			if strings.HasPrefix(line, "@@") {
-				// Color hunk headers cyan
-				b.WriteString(HunkStyle.Render(line))
-				b.WriteByte('\n')
-			} else if line[0] == '+' && (len(line) == 1 || line[1] != '+') {
-				// Color added lines green, excluding metadata like '+++'
-				b.WriteString(AdditionStyle.Render(line))
-				b.WriteByte('\n')
-			} else if line[0] == '-' && (len(line) == 1 || line[1] != '-') {
-				// Color removed lines red, excluding metadata like '---'
-				b.WriteString(DeletionStyle.Render(line))
-				b.WriteByte('\n')
+	b.WriteString(d.renderHeader())
+	end := min(d.numRows(), d.offset+bodyHeight)
+	for i := 0; i < bodyHeight; i++ {
+		b.WriteByte('\n')
+		if sidebar != nil {
+			b.WriteString(sidebar[i])
+			b.WriteString(diffGutterStyle.Render("│"))
+		}
+		if d.offset+i < end {
+			if d.fallback != nil {
+				b.WriteString(fitWidth(d.fallback[d.offset+i], mainWidth))
 			} else {
-				// Print metadata and unchanged lines without color
-				b.WriteString(line)
-				b.WriteByte('\n')
+				b.WriteString(d.renderRow(d.rows[d.offset+i], mainWidth, d.offset+i == d.cursor))
 			}
-		} else {
-			// Preserve empty lines
-			b.WriteByte('\n')
 		}
 	}
-
 	return b.String()
 }
+
+// renderHeader renders the stats line with the layout and review progress.
+func (d *DiffPane) renderHeader() string {
+	layout := "unified"
+	if d.layout == DiffSideBySide {
+		layout = "side-by-side"
+	}
+	header := d.stats + StyleMuted().Render("  "+d.spec.String()+" • "+layout)
+	// Diffs without an instance, like a single commit, have no review state.
+	if len(d.files) > 0 && d.instance != nil {
+		viewed := 0
+		for i := range d.files {
+			if d.state().IsViewed(d.files[i].Path(), d.hashes[i]) {
+				viewed++
+			}
+		}
+		header += StyleMuted().Render(fmt.Sprintf("  %d/%d files viewed", viewed, len(d.files)))
+	}
+	if n := len(d.state().Comments); n > 0 {
+		header += StyleWarn().Render(fmt.Sprintf("  %d unsent comment(s)", n))
+	}
+	return fitWidth(header, d.width)
+}
+
+// renderSidebar renders exactly height lines of the file navigator, each exactly width wide.
+func (d *DiffPane) renderSidebar(width, height int) []string {
+	current := d.currentFile()
+	lines := make([]string, 0, height)
+	lines = append(lines, padWidth(StyleTitle().Render(fmt.Sprintf("Files (%d)", len(d.files))), width))
+
+	// Keep the current file visible.
+	visible := height - 1
+	start := 0
+	if current >= visible {
+		start = current - visible + 1
+	}
+	for fi := start; fi < len(d.files) && len(lines) < height; fi++ {
+		f := &d.files[fi]
+		mark := "  "
+		switch {
+		case d.state().IsViewed(f.Path(), d.hashes[fi]):
+			mark = StyleOk().Render("✓ ")
+		case d.state().ViewedStale(f.Path(), d.hashes[fi]):
+			mark = StyleWarn().Render("• ")
+		}
+		counts := AdditionStyle.Render(fmt.Sprintf("+%d", f.Added)) + " " + DeletionStyle.Render(fmt.Sprintf("-%d", f.Removed))
+		nameWidth := width - 2 - lipgloss.Width(counts) - 1
+		name := padWidth(truncateLeft(f.Path(), nameWidth), nameWidth)
+		if fi == current {
+			name = StyleSelected().Render(name)
+		}
+		lines = append(lines, mark+name+" "+counts)
+	}
+	for len(lines) < height {
+		lines = append(lines, strings.Repeat(" ", width))
+	}
+	for i := range lines {
+		lines[i] = padWidth(fitWidth(lines[i], width), width)
+	}
+	return lines
+}
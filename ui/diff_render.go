@@ -0,0 +1,369 @@
+package ui
+
+import (
+	"claude-squad/session/git"
+	"fmt"
+	"strings"
+	"unicode"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/mattn/go-runewidth"
+	"github.com/muesli/reflow/truncate"
+)
+
+// segment is a run of text rendered with a single style.
+type segment struct {
+	text  string
+	style lipgloss.Style
+}
+
+func (d *DiffPane) renderRow(r diffRow, width int, selected bool) string {
+	f := &d.files[r.file]
+	gutterStyle := diffGutterStyle
+	if selected {
+		gutterStyle = StyleSelected()
+	}
+	switch r.kind {
+	case rowFile:
+		return d.renderFileRow(r.file, width, selected)
+	case rowHunk:
+		h := &f.Hunks[r.hunk]
+		row := HunkStyle.Render(h.Header)
+		if selected {
+			row = HunkStyle.Inherit(StyleSelected()).Render(h.Header)
+		}
+		if d.state().IsApproved(f.Path(), h.Hash()) {
+			row += StyleOk().Render("  ✓ approved")
+		}
+		return fitWidth(row, width)
+	case rowComment:
+		c := d.state().Comments[r.comment]
+		text := strings.ReplaceAll(strings.TrimSpace(c.Text), "\n", " ")
+		return renderSegments([]segment{
+			{text: strings.Repeat(" ", 10), style: gutterStyle},
+			{text: "↳ " + text, style: diffCommentStyle},
+		}, width)
+	}
+
+	h := &f.Hunks[r.hunk]
+	if d.layout == DiffSideBySide {
+		half := (width - 1) / 2
+		return d.renderCell(r, r.left, r.right, true, selected, half) +
+			diffGutterStyle.Render("│") +
+			d.renderCell(r, r.right, r.left, false, selected, width-1-half)
+	}
+
+	line := &h.Lines[r.left]
+	gutter := fmt.Sprintf("%4s %4s ", lineNumber(line.OldLine), lineNumber(line.NewLine))
+	segs := append([]segment{{text: gutter, style: gutterStyle}}, d.lineSegments(r.file, r.hunk, r.left, r.right)...)
+	return renderSegments(segs, width)
+}
+
+// renderCell renders one side of a side-by-side row. old selects the line number to show.
+func (d *DiffPane) renderCell(r diffRow, idx, partner int, old, selected bool, width int) string {
+	if idx < 0 {
+		return strings.Repeat(" ", max(0, width))
+	}
+	line := &d.files[r.file].Hunks[r.hunk].Lines[idx]
+	n := line.NewLine
+	if old {
+		n = line.OldLine
+	}
+	gutterStyle := diffGutterStyle
+	if selected {
+		gutterStyle = StyleSelected()
+	}
+	segs := append([]segment{{text: fmt.Sprintf("%4s ", lineNumber(n)), style: gutterStyle}}, d.lineSegments(r.file, r.hunk, idx, partner)...)
+	return renderSegments(segs, width)
+}
+
+func lineNumber(n int) string {
+	if n == 0 {
+		return ""
+	}
+	return fmt.Sprint(n)
+}
+
+// lineSegments returns the styled prefix and text of a line. The text is syntax highlighted, and
+// when the line is paired with a line of the opposite kind, the words that differ between them
+// are emphasized.
+func (d *DiffPane) lineSegments(fi, hi, idx, partner int) []segment {
+	h := &d.files[fi].Hunks[hi]
+	line := &h.Lines[idx]
+	prefix, style, wordStyle := " ", lipgloss.NewStyle(), lipgloss.NewStyle()
+	switch line.Kind {
+	case git.LineAdded:
+		prefix, style, wordStyle = "+", diffAddLineStyle, AdditionWordStyle
+	case git.LineRemoved:
+		prefix, style, wordStyle = "-", diffDelLineStyle, DeletionWordStyle
+	}
+	segs := []segment{{text: prefix, style: style}}
+
+	syntax := d.lineTokens(fi, hi, idx)
+	if syntax == nil {
+		syntax = []synToken{{text: line.Text}}
+	}
+	var words []string
+	var changed []bool
+	if partner >= 0 && line.Kind != git.LineContext && h.Lines[partner].Kind != line.Kind {
+		words, changed = wordDiff(line.Text, h.Lines[partner].Text)
+	}
+
+	// Split the syntax tokens at the boundaries of the emphasized words. Both cover the whole
+	// line, so they are walked in step by byte offset.
+	lastKind, lastEmph := tokPlain, false
+	add := func(text string, kind tokenKind, emph bool) {
+		// Merge runs with the same style to keep the number of escape sequences down.
+		if len(segs) > 1 && kind == lastKind && emph == lastEmph {
+			segs[len(segs)-1].text += text
+			return
+		}
+		base := style
+		if emph {
+			base = wordStyle
+		}
+		segs = append(segs, segment{text: text, style: syntaxStyles[kind].Inherit(base)})
+		lastKind, lastEmph = kind, emph
+	}
+	pos, word, wordEnd := 0, 0, 0
+	if words != nil {
+		wordEnd = len(words[0])
+	}
+	for _, tok := range syntax {
+		for text := tok.text; text != ""; {
+			n, emph := len(text), false
+			if words != nil {
+				for wordEnd <= pos && word < len(words)-1 {
+					word++
+					wordEnd += len(words[word])
+				}
+				n, emph = min(n, max(1, wordEnd-pos)), changed[word]
+			}
+			add(text[:n], tok.kind, emph)
+			text = text[n:]
+			pos += n
+		}
+	}
+	return segs
+}
+
+// maxWordDiffCells bounds the size of the LCS table so that very long lines don't stall rendering.
+const maxWordDiffCells = 200 * 200
+
+// wordDiff splits a into tokens and marks the tokens that are not part of the longest common
+// token subsequence with b. It returns nil if the lines are too long or share no words, in which
+// case emphasis would only add noise.
+func wordDiff(a, b string) ([]string, []bool) {
+	ta, tb := tokenize(a), tokenize(b)
+	if len(ta)*len(tb) > maxWordDiffCells || len(ta) == 0 || len(tb) == 0 {
+		return nil, nil
+	}
+
+	// lcs[i][j] is the LCS length of ta[i:] and tb[j:].
+	lcs := make([][]int, len(ta)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(tb)+1)
+	}
+	for i := len(ta) - 1; i >= 0; i-- {
+		for j := len(tb) - 1; j >= 0; j-- {
+			if ta[i] == tb[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else {
+				lcs[i][j] = max(lcs[i+1][j], lcs[i][j+1])
+			}
+		}
+	}
+
+	changed := make([]bool, len(ta))
+	commonWords := 0
+	i, j := 0, 0
+	for i < len(ta) {
+		switch {
+		case j < len(tb) && ta[i] == tb[j]:
+			if strings.TrimSpace(ta[i]) != "" {
+				commonWords++
+			}
+			i++
+			j++
+		case j < len(tb) && lcs[i][j+1] >= lcs[i+1][j]:
+			j++
+		default:
+			changed[i] = true
+			i++
+		}
+	}
+	if commonWords == 0 {
+		return nil, nil
+	}
+	return ta, changed
+}
+
+// tokenize splits s into words, runs of whitespace and single punctuation characters.
+func tokenize(s string) []string {
+	var tokens []string
+	runes := []rune(s)
+	isWord := func(r rune) bool { return r == '_' || unicode.IsLetter(r) || unicode.IsDigit(r) }
+	for i := 0; i < len(runes); {
+		j := i + 1
+		switch {
+		case isWord(runes[i]):
+			for j < len(runes) && isWord(runes[j]) {
+				j++
+			}
+		case unicode.IsSpace(runes[i]):
+			for j < len(runes) && unicode.IsSpace(runes[j]) {
+				j++
+			}
+		}
+		tokens = append(tokens, string(runes[i:j]))
+		i = j
+	}
+	return tokens
+}
+
+// renderFileRow renders the header row of a file.
+func (d *DiffPane) renderFileRow(fi, width int, selected bool) string {
+	f := &d.files[fi]
+	marker := "▾ "
+	if d.collapsed(fi) {
+		marker = "▸ "
+	}
+	title := marker + f.Path()
+	switch {
+	case f.IsNew():
+		title += " (new)"
+	case f.IsDeleted():
+		title += " (deleted)"
+	case f.OldPath != f.NewPath:
+		title += fmt.Sprintf(" (renamed from %s)", f.OldPath)
+	}
+	if f.Binary {
+		title += " (binary)"
+	}
+
+	titleStyle := diffFileStyle
+	if selected {
+		titleStyle = diffFileStyle.Inherit(StyleSelected())
+	}
+	row := titleStyle.Render(title) + " " +
+		AdditionStyle.Render(fmt.Sprintf("+%d", f.Added)) + " " +
+		DeletionStyle.Render(fmt.Sprintf("-%d", f.Removed))
+	switch {
+	case d.state().IsViewed(f.Path(), d.hashes[fi]):
+		row += StyleOk().Render("  ✓ viewed")
+	case d.state().ViewedStale(f.Path(), d.hashes[fi]):
+		row += StyleWarn().Render("  changed since viewed")
+	}
+	return fitWidth(row, width)
+}
+
+// renderSegments renders segments into exactly width cells, truncating or padding as needed.
+func renderSegments(segs []segment, width int) string {
+	b := getBuilder()
+	defer putBuilder(b)
+	used := 0
+	for _, s := range segs {
+		text := sanitizeLine(s.text)
+		w := runewidth.StringWidth(text)
+		if used+w > width {
+			text = runewidth.Truncate(text, width-used, "")
+			w = runewidth.StringWidth(text)
+		}
+		if text != "" {
+			b.WriteString(s.style.Render(text))
+		}
+		used += w
+		if used >= width {
+			break
+		}
+	}
+	if used < width {
+		b.WriteString(strings.Repeat(" ", width-used))
+	}
+	return b.String()
+}
+
+// sanitizeLine expands tabs and drops control characters that would break the layout.
+func sanitizeLine(s string) string {
+	if !strings.ContainsFunc(s, unicode.IsControl) {
+		return s
+	}
+	var b strings.Builder
+	for _, r := range s {
+		switch {
+		case r == '\t':
+			b.WriteString("    ")
+		case unicode.IsControl(r):
+		default:
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
+
+// fitWidth truncates a styled string to at most width cells.
+func fitWidth(s string, width int) string {
+	if lipgloss.Width(s) <= width {
+		return s
+	}
+	return truncate.String(s, uint(max(0, width)))
+}
+
+// padWidth pads a styled string with spaces to width cells.
+func padWidth(s string, width int) string {
+	if w := lipgloss.Width(s); w < width {
+		return s + strings.Repeat(" ", width-w)
+	}
+	return s
+}
+
+// truncateLeft shortens s to width cells by cutting from the left, which keeps the file name
+// of a long path visible.
+func truncateLeft(s string, width int) string {
+	if width <= 0 {
+		return ""
+	}
+	if runewidth.StringWidth(s) <= width {
+		return s
+	}
+	runes := []rune(s)
+	for i := range runes {
+		if runewidth.StringWidth(string(runes[i:])) <= width-1 {
+			return "…" + string(runes[i:])
+		}
+	}
+	return "…"
+}
+
+func colorizeDiff(diff string) string {
+	b := getBuilder()
+	defer putBuilder(b)
+
+	lines := strings.Split(diff, "\n")
+	for _, line := range lines {
+		if len(line) > 0 {
+			if strings.HasPrefix(line, "@@") {
+				// Color hunk headers cyan
+				b.WriteString(HunkStyle.Render(line))
+				b.WriteByte('\n')
+			} else if line[0] == '+' && (len(line) == 1 || line[1] != '+') {
+				// Color added lines green, excluding metadata like '+++'
+				b.WriteString(AdditionStyle.Render(line))
+				b.WriteByte('\n')
+			} else if line[0] == '-' && (len(line) == 1 || line[1] != '-') {
+				// Color removed lines red, excluding metadata like '---'
+				b.WriteString(DeletionStyle.Render(line))
+				b.WriteByte('\n')
+			} else {
+				// Print metadata and unchanged lines without color
+				b.WriteString(line)
+				b.WriteByte('\n')
+			}
+		} else {
+			// Preserve empty lines
+			b.WriteByte('\n')
+		}
+	}
+
+	return b.String()
+}
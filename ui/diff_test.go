@@ -0,0 +1,181 @@
+package ui
+
+import (
+	"claude-squad/session"
+	"claude-squad/session/git"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testDiff = `diff --git a/a.go b/a.go
+index 1111111..2222222 100644
+--- a/a.go
++++ b/a.go
+@@ -1,3 +1,3 @@
+ package a
+-var name = "old"
++var name = "new"
+ // end
+diff --git a/b.txt b/b.txt
+new file mode 100644
+--- /dev/null
++++ b/b.txt
+@@ -0,0 +1,2 @@
++one
++two
+`
+
+// newDiffInstance returns a started (paused) instance whose diff is content.
+func newDiffInstance(t *testing.T, content string) *session.Instance {
+	t.Helper()
+	instance, err := session.FromInstanceData(session.InstanceData{
+		Title:     "diff",
+		Path:      t.TempDir(),
+		Status:    session.Paused,
+		Program:   "claude",
+		DiffStats: session.DiffStatsData{Added: 3, Removed: 1, Content: content},
+	})
+	require.NoError(t, err)
+	return instance
+}
+
+func TestDiffPaneLayouts(t *testing.T) {
+	d := NewDiffPane()
+	d.SetSize(100, 20)
+	d.SetDiff(newDiffInstance(t, testDiff))
+
+	// file, hunk, 4 lines, file, hunk, 2 lines
+	require.Len(t, d.rows, 10)
+	out := d.String()
+	assert.Contains(t, out, "Files (2)")
+	assert.Contains(t, out, `var name = "old"`)
+	assert.Contains(t, out, `var name = "new"`)
+
+	d.ToggleLayout()
+	assert.Equal(t, DiffSideBySide, d.Layout())
+	// The changed line pair shares a row side by side.
+	require.Len(t, d.rows, 9)
+	var paired bool
+	for _, line := range strings.Split(d.String(), "\n") {
+		if strings.Contains(line, `"old"`) && strings.Contains(line, `"new"`) {
+			paired = true
+		}
+	}
+	assert.True(t, paired, "expected old and new line on the same row")
+}
+
+func TestDiffPaneViewedAndCollapsed(t *testing.T) {
+	instance := newDiffInstance(t, testDiff)
+	d := NewDiffPane()
+	d.SetSize(100, 20)
+	d.SetDiff(instance)
+
+	d.ToggleViewed()
+	assert.True(t, instance.DiffView.IsCollapsed("a.go"))
+	// a.go is reduced to its header.
+	require.Len(t, d.rows, 5)
+	assert.Contains(t, d.String(), "1/2 files viewed")
+
+	// A change to the file brings it back.
+	d.SetDiff(newDiffInstanceWithState(t, strings.Replace(testDiff, `"new"`, `"newer"`, 1), instance.DiffView))
+	require.Len(t, d.rows, 10)
+	assert.Contains(t, d.String(), "changed since viewed")
+
+	// The state survives a save and load.
+	data := instance.ToInstanceData()
+	assert.Equal(t, instance.DiffView, data.DiffView)
+}
+
+func newDiffInstanceWithState(t *testing.T, content string, state session.DiffViewState) *session.Instance {
+	instance := newDiffInstance(t, content)
+	instance.DiffView = state
+	return instance
+}
+
+func TestWordDiff(t *testing.T) {
+	tokens, changed := wordDiff(`var name = "old"`, `var name = "new"`)
+	require.NotNil(t, tokens)
+	var marked []string
+	for i, tok := range tokens {
+		if changed[i] {
+			marked = append(marked, tok)
+		}
+	}
+	assert.Equal(t, []string{"old"}, marked)
+
+	// Lines without words in common aren't emphasized.
+	tokens, _ = wordDiff("alpha", "beta")
+	assert.Nil(t, tokens)
+}
+
+func TestDiffPaneReview(t *testing.T) {
+	instance := newDiffInstance(t, testDiff)
+	d := NewDiffPane()
+	d.SetSize(100, 20)
+	d.SetDiff(instance)
+
+	// The cursor starts on the file header, which has no hunk or line.
+	_, ok := d.SelectedHunkPatch()
+	assert.False(t, ok)
+	_, ok = d.SelectedLine()
+	assert.False(t, ok)
+	path, patch, ok := d.SelectedFilePatch()
+	require.True(t, ok)
+	assert.Equal(t, "a.go", path)
+	assert.Contains(t, patch, "+++ b/a.go")
+
+	d.JumpNextHunk()
+	patch, ok = d.SelectedHunkPatch()
+	require.True(t, ok)
+	assert.Contains(t, patch, `+var name = "new"`)
+	assert.NotContains(t, patch, "b.txt")
+
+	require.True(t, d.ToggleApproved())
+	assert.Contains(t, d.String(), "✓ approved")
+
+	// Comment on the added line.
+	d.ScrollDown()
+	d.ScrollDown()
+	d.ScrollDown()
+	comment, ok := d.SelectedLine()
+	require.True(t, ok)
+	assert.Equal(t, session.ReviewComment{Path: "a.go", Line: 2, Code: `var name = "new"`}, comment)
+	comment.Text = "Keep the old name"
+	d.AddComment(comment)
+	require.Len(t, d.rows, 11)
+	assert.Equal(t, rowComment, d.rows[5].kind)
+	out := d.String()
+	assert.Contains(t, out, "↳ Keep the old name")
+	assert.Contains(t, out, "1 unsent comment(s)")
+	assert.Contains(t, instance.DiffView.ReviewPrompt(), "a.go:2")
+}
+
+func TestDiffPaneRevertOnlyInWorktreeDiffs(t *testing.T) {
+	d := NewDiffPane()
+	d.SetSize(100, 20)
+	d.setStats(&git.DiffStats{Content: testDiff, Added: 3, Removed: 1, Spec: git.DiffSpec{Mode: git.DiffUncommitted}})
+	d.JumpNextHunk()
+	require.True(t, d.Revertible())
+	_, ok := d.SelectedHunkPatch()
+	require.True(t, ok)
+
+	// A hunk of a commit, or of a comparison with main, is not the worktree's to revert.
+	for _, spec := range []git.DiffSpec{
+		{Mode: git.DiffLastCommit},
+		{Mode: git.DiffAgainstRef, Ref: "main"},
+		{Mode: git.DiffCommit, Ref: "abc1234"},
+		{Mode: git.DiffCheckpoint, Ref: "def5678"},
+	} {
+		d.setStats(&git.DiffStats{Content: testDiff, Added: 3, Removed: 1, Spec: spec})
+		d.JumpNextHunk()
+		assert.False(t, d.Revertible(), spec.String())
+		_, ok := d.SelectedHunkPatch()
+		assert.False(t, ok, spec.String())
+		_, _, ok = d.SelectedFilePatch()
+		assert.False(t, ok, spec.String())
+		assert.False(t, d.ToggleApproved(), spec.String())
+	}
+}
@@ -10,7 +10,13 @@ type ErrBox struct {
     err           error
 }
 
-var errStyle = lipgloss.NewStyle().Foreground(Theme.Danger)
+var errStyle lipgloss.Style
+
+func init() {
+    OnThemeChange(func() {
+        errStyle = lipgloss.NewStyle().Foreground(Theme.Danger)
+    })
+}
 
 func NewErrBox() *ErrBox {
 	return &ErrBox{}
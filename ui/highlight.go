@@ -0,0 +1,288 @@
+package ui
+
+import (
+	"path/filepath"
+	"strings"
+)
+
+// tokenKind classifies a piece of source code for syntax highlighting.
+type tokenKind int
+
+const (
+	tokPlain tokenKind = iota
+	tokKeyword
+	tokType
+	tokString
+	tokNumber
+	tokComment
+)
+
+// synToken is a piece of a source line.
+type synToken struct {
+	text string
+	kind tokenKind
+}
+
+// maxHighlightLineLength is the length above which lines are not highlighted, e.g. minified files.
+const maxHighlightLineLength = 1000
+
+// language describes just enough of a language's lexical structure to highlight it line by line.
+type language struct {
+	keywords     map[string]bool
+	types        map[string]bool
+	lineComments []string
+	// blockComment holds the start and end delimiters, or is empty.
+	blockComment [2]string
+	// quotes are the characters that delimit string literals.
+	quotes string
+}
+
+func words(s string) map[string]bool {
+	m := make(map[string]bool)
+	for _, w := range strings.Fields(s) {
+		m[w] = true
+	}
+	return m
+}
+
+var (
+	langGo = &language{
+		keywords: words(`break case chan const continue default defer else fallthrough for func go goto if
+			import interface map package range return select struct switch type var nil true false iota`),
+		types: words(`bool byte complex64 complex128 error float32 float64 int int8 int16 int32 int64 rune
+			string uint uint8 uint16 uint32 uint64 uintptr any comparable`),
+		lineComments: []string{"//"},
+		blockComment: [2]string{"/*", "*/"},
+		quotes:       "\"'`",
+	}
+	langC = &language{
+		keywords: words(`auto break case catch class const constexpr continue default delete do else enum
+			explicit extern false for friend goto if inline namespace new nullptr operator private protected
+			public return sizeof static struct switch template this throw true try typedef typename union
+			using virtual volatile while NULL #include #define #ifdef #ifndef #endif #if #else #pragma`),
+		types:        words(`bool char double float int long short signed unsigned void size_t auto std string vector`),
+		lineComments: []string{"//"},
+		blockComment: [2]string{"/*", "*/"},
+		quotes:       "\"'",
+	}
+	langJava = &language{
+		keywords: words(`abstract assert break case catch class const continue default do else enum extends
+			final finally for goto if implements import instanceof interface native new package private
+			protected public return static super switch synchronized this throw throws try volatile while
+			true false null fun val var when object companion data sealed override open internal`),
+		types:        words(`boolean byte char double float int long short void String Integer Long Boolean Object List Map`),
+		lineComments: []string{"//"},
+		blockComment: [2]string{"/*", "*/"},
+		quotes:       "\"'",
+	}
+	langJS = &language{
+		keywords: words(`async await break case catch class const continue debugger default delete do else
+			export extends finally for from function if import in instanceof let new of return static super
+			switch this throw try typeof var void while with yield true false null undefined interface type
+			enum implements private protected public readonly declare namespace as`),
+		types:        words(`string number boolean any unknown never object void Array Promise Record Map Set`),
+		lineComments: []string{"//"},
+		blockComment: [2]string{"/*", "*/"},
+		quotes:       "\"'`",
+	}
+	langRust = &language{
+		keywords: words(`as async await break const continue crate dyn else enum extern false fn for if impl
+			in let loop match mod move mut pub ref return self Self static struct super trait true type
+			unsafe use where while`),
+		types: words(`bool char f32 f64 i8 i16 i32 i64 i128 isize str u8 u16 u32 u64 u128 usize String Vec
+			Option Result Box`),
+		lineComments: []string{"//"},
+		blockComment: [2]string{"/*", "*/"},
+		quotes:       "\"",
+	}
+	langPython = &language{
+		keywords: words(`and as assert async await break class continue def del elif else except False
+			finally for from global if import in is lambda None nonlocal not or pass raise return True try
+			while with yield self`),
+		types:        words(`int float str bool list dict set tuple bytes object`),
+		lineComments: []string{"#"},
+		blockComment: [2]string{`"""`, `"""`},
+		quotes:       "\"'",
+	}
+	langRuby = &language{
+		keywords: words(`alias and begin break case class def defined? do else elsif end ensure false for if
+			in module next nil not or redo rescue retry return self super then true undef unless until when
+			while yield require attr_accessor attr_reader`),
+		lineComments: []string{"#"},
+		quotes:       "\"'",
+	}
+	langShell = &language{
+		keywords: words(`if then else elif fi case esac for while until do done in function return local
+			export set unset echo exit source`),
+		lineComments: []string{"#"},
+		quotes:       "\"'",
+	}
+	langData = &language{
+		keywords:     words(`true false null yes no on off`),
+		lineComments: []string{"#"},
+		quotes:       "\"'",
+	}
+	langSQL = &language{
+		keywords: words(`select from where insert into values update set delete create table alter drop
+			index join left right inner outer on and or not null primary key foreign references as order by
+			group having limit SELECT FROM WHERE INSERT INTO VALUES UPDATE SET DELETE CREATE TABLE ALTER DROP
+			INDEX JOIN LEFT RIGHT INNER OUTER ON AND OR NOT NULL PRIMARY KEY FOREIGN REFERENCES AS ORDER BY
+			GROUP HAVING LIMIT`),
+		types:        words(`int integer text varchar boolean timestamp INT INTEGER TEXT VARCHAR BOOLEAN TIMESTAMP`),
+		lineComments: []string{"--"},
+		blockComment: [2]string{"/*", "*/"},
+		quotes:       "'\"",
+	}
+)
+
+var languagesByExt = map[string]*language{
+	".go":   langGo,
+	".c":    langC,
+	".h":    langC,
+	".cc":   langC,
+	".cpp":  langC,
+	".hpp":  langC,
+	".java": langJava,
+	".kt":   langJava,
+	".cs":   langJava,
+	".js":   langJS,
+	".jsx":  langJS,
+	".mjs":  langJS,
+	".ts":   langJS,
+	".tsx":  langJS,
+	".rs":   langRust,
+	".py":   langPython,
+	".rb":   langRuby,
+	".sh":   langShell,
+	".bash": langShell,
+	".zsh":  langShell,
+	".yaml": langData,
+	".yml":  langData,
+	".toml": langData,
+	".json": langData,
+	".sql":  langSQL,
+}
+
+var languagesByName = map[string]*language{
+	"Makefile":   langShell,
+	"Dockerfile": langShell,
+	"Gemfile":    langRuby,
+	"Rakefile":   langRuby,
+}
+
+// languageFor detects the language of a file from its name. It returns nil for unknown files.
+func languageFor(path string) *language {
+	base := filepath.Base(path)
+	if l, ok := languagesByName[base]; ok {
+		return l
+	}
+	return languagesByExt[strings.ToLower(filepath.Ext(base))]
+}
+
+// lex splits a line into tokens. inComment reports whether the line starts inside a block
+// comment; the returned bool reports whether the next line does.
+func (l *language) lex(line string, inComment bool) ([]synToken, bool) {
+	if len(line) > maxHighlightLineLength {
+		return []synToken{{text: line}}, inComment
+	}
+
+	var tokens []synToken
+	emit := func(text string, kind tokenKind) {
+		if text == "" {
+			return
+		}
+		if n := len(tokens); n > 0 && tokens[n-1].kind == kind {
+			tokens[n-1].text += text
+			return
+		}
+		tokens = append(tokens, synToken{text: text, kind: kind})
+	}
+
+	i := 0
+	if inComment {
+		end := strings.Index(line, l.blockComment[1])
+		if end < 0 {
+			return []synToken{{text: line, kind: tokComment}}, true
+		}
+		i = end + len(l.blockComment[1])
+		emit(line[:i], tokComment)
+	}
+
+	for i < len(line) {
+		rest := line[i:]
+		if start := l.blockComment[0]; start != "" && strings.HasPrefix(rest, start) {
+			end := strings.Index(rest[len(start):], l.blockComment[1])
+			if end < 0 {
+				emit(rest, tokComment)
+				return tokens, true
+			}
+			n := len(start) + end + len(l.blockComment[1])
+			emit(rest[:n], tokComment)
+			i += n
+			continue
+		}
+		if hasAnyPrefix(rest, l.lineComments) {
+			emit(rest, tokComment)
+			break
+		}
+
+		c := line[i]
+		switch {
+		case strings.IndexByte(l.quotes, c) >= 0:
+			j := i + 1
+			for j < len(line) && line[j] != c {
+				if line[j] == '\\' {
+					j++
+				}
+				j++
+			}
+			j = min(j+1, len(line))
+			emit(line[i:j], tokString)
+			i = j
+		case isDigit(c):
+			j := i + 1
+			for j < len(line) && (isWordByte(line[j]) || line[j] == '.') {
+				j++
+			}
+			emit(line[i:j], tokNumber)
+			i = j
+		case isWordByte(c) || c == '#':
+			// '#' starts preprocessor directives in C; languages with '#' comments never get here.
+			j := i + 1
+			for j < len(line) && (isWordByte(line[j]) || line[j] == '?') {
+				j++
+			}
+			word := line[i:j]
+			switch {
+			case l.keywords[word]:
+				emit(word, tokKeyword)
+			case l.types[word]:
+				emit(word, tokType)
+			default:
+				emit(word, tokPlain)
+			}
+			i = j
+		default:
+			emit(line[i:i+1], tokPlain)
+			i++
+		}
+	}
+	return tokens, false
+}
+
+func hasAnyPrefix(s string, prefixes []string) bool {
+	for _, p := range prefixes {
+		if strings.HasPrefix(s, p) {
+			return true
+		}
+	}
+	return false
+}
+
+func isDigit(c byte) bool { return c >= '0' && c <= '9' }
+
+// isWordByte reports whether c can be part of an identifier. Bytes of multi-byte UTF-8 sequences
+// count as word bytes so that runes are never split.
+func isWordByte(c byte) bool {
+	return c == '_' || c == '$' || isDigit(c) || (c|0x20 >= 'a' && c|0x20 <= 'z') || c >= 0x80
+}
@@ -0,0 +1,90 @@
+package ui
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLanguageFor(t *testing.T) {
+	assert.Equal(t, langGo, languageFor("cmd/main.go"))
+	assert.Equal(t, langJS, languageFor("web/App.TSX"))
+	assert.Equal(t, langShell, languageFor("build/Makefile"))
+	assert.Nil(t, languageFor("notes.txt"))
+	assert.Nil(t, languageFor("LICENSE"))
+}
+
+func TestLex(t *testing.T) {
+	tokens, inComment := langGo.lex(`	return "a // b", 42 // done`, false)
+	assert.False(t, inComment)
+	assert.Equal(t, []synToken{
+		{text: "\t", kind: tokPlain},
+		{text: "return", kind: tokKeyword},
+		{text: " ", kind: tokPlain},
+		{text: `"a // b"`, kind: tokString},
+		{text: ", ", kind: tokPlain},
+		{text: "42", kind: tokNumber},
+		{text: " ", kind: tokPlain},
+		{text: "// done", kind: tokComment},
+	}, tokens)
+
+	var text strings.Builder
+	for _, tok := range tokens {
+		text.WriteString(tok.text)
+	}
+	assert.Equal(t, `	return "a // b", 42 // done`, text.String(), "tokens must cover the line")
+
+	// Block comments carry over to the next line.
+	tokens, inComment = langGo.lex("x := 1 /* start", false)
+	assert.True(t, inComment)
+	assert.Equal(t, synToken{text: "/* start", kind: tokComment}, tokens[len(tokens)-1])
+	tokens, inComment = langGo.lex("end */ var y int", true)
+	assert.False(t, inComment)
+	assert.Equal(t, synToken{text: "end */", kind: tokComment}, tokens[0])
+	assert.Contains(t, tokens, synToken{text: "int", kind: tokType})
+}
+
+func TestDiffPaneHighlightsVisibleLinesOnly(t *testing.T) {
+	var diff strings.Builder
+	diff.WriteString("diff --git a/big.go b/big.go\nnew file mode 100644\n--- /dev/null\n+++ b/big.go\n@@ -0,0 +1,500 @@\n")
+	for i := 0; i < 500; i++ {
+		diff.WriteString("+var x = 1\n")
+	}
+	diff.WriteString(testDiff)
+
+	d := NewDiffPane()
+	d.SetSize(100, 12)
+	d.SetDiff(newDiffInstance(t, diff.String()))
+	_ = d.String()
+
+	require.Len(t, d.highlights, 3)
+	require.Len(t, d.highlights[0].hunks, 1)
+	// Only the rows on screen have been lexed.
+	assert.LessOrEqual(t, len(d.highlights[0].hunks[0].lines), 12)
+	assert.Nil(t, d.highlights[1].hunks, "off-screen files are not lexed")
+	assert.Nil(t, d.highlights[2].lang, "text files are not highlighted")
+
+	tokens := d.lineTokens(0, 0, 0)
+	assert.Equal(t, synToken{text: "var", kind: tokKeyword}, tokens[0])
+}
+
+func TestLineSegmentsCoverText(t *testing.T) {
+	d := NewDiffPane()
+	d.SetSize(100, 20)
+	d.SetDiff(newDiffInstance(t, testDiff))
+
+	// The changed pair gets both syntax tokens and word emphasis; the text must be intact.
+	for _, r := range d.rows {
+		if r.kind != rowLine {
+			continue
+		}
+		segs := d.lineSegments(r.file, r.hunk, r.left, r.right)
+		var text strings.Builder
+		for _, s := range segs[1:] {
+			text.WriteString(s.text)
+		}
+		assert.Equal(t, d.files[r.file].Hunks[r.hunk].Lines[r.left].Text, text.String())
+	}
+}
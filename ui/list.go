@@ -1,11 +1,15 @@
 package ui
 
 import (
+    "claude-squad/forge"
     "claude-squad/log"
     "claude-squad/session"
+    "claude-squad/session/resources"
+    "claude-squad/session/usage"
     "errors"
     "fmt"
     "strings"
+    "time"
 
     "github.com/charmbracelet/bubbles/spinner"
     "github.com/charmbracelet/lipgloss"
@@ -14,34 +18,47 @@ import (
 const readyIcon = "● "
 const pausedIcon = "⏸ "
 
-var readyStyle = StyleOk()
-var addedLinesStyle = StyleOk()
-var removedLinesStyle = StyleDanger()
-var pausedStyle = StyleMuted()
+var (
+    readyStyle         lipgloss.Style
+    addedLinesStyle    lipgloss.Style
+    removedLinesStyle  lipgloss.Style
+    pausedStyle        lipgloss.Style
+    titleStyle         lipgloss.Style
+    listDescStyle      lipgloss.Style
+    selectedTitleStyle lipgloss.Style
+    selectedDescStyle  lipgloss.Style
+    mainTitle          lipgloss.Style
+    autoYesStyle       lipgloss.Style
+)
+
+func init() {
+    OnThemeChange(func() {
+        readyStyle = StyleOk()
+        addedLinesStyle = StyleOk()
+        removedLinesStyle = StyleDanger()
+        pausedStyle = StyleMuted()
 
-var titleStyle = lipgloss.NewStyle().
-    Padding(1, 1, 0, 1).
-    Foreground(Theme.Fg)
+        titleStyle = lipgloss.NewStyle().
+            Padding(1, 1, 0, 1).
+            Foreground(Theme.Fg)
 
-var listDescStyle = lipgloss.NewStyle().
-    Padding(0, 1, 1, 1).
-    Foreground(Theme.FgMuted)
+        listDescStyle = lipgloss.NewStyle().
+            Padding(0, 1, 1, 1).
+            Foreground(Theme.FgMuted)
 
-var selectedTitleStyle = lipgloss.NewStyle().
-    Padding(1, 1, 0, 1).
-    Background(Theme.BgAlt).
-    Foreground(Theme.Fg)
+        selectedTitleStyle = StyleSelected().
+            Padding(1, 1, 0, 1)
 
-var selectedDescStyle = lipgloss.NewStyle().
-    Padding(0, 1, 1, 1).
-    Background(Theme.BgAlt).
-    Foreground(Theme.Fg)
+        selectedDescStyle = StyleSelected().
+            Padding(0, 1, 1, 1)
 
-var mainTitle = StyleTitle()
+        mainTitle = StyleTitle()
 
-var autoYesStyle = lipgloss.NewStyle().
-    Background(Theme.BgAlt).
-    Foreground(Theme.Fg)
+        autoYesStyle = lipgloss.NewStyle().
+            Background(Theme.BgAlt).
+            Foreground(Theme.Fg)
+    })
+}
 
 type List struct {
 	items         []*session.Instance
@@ -132,6 +149,12 @@ func (r *InstanceRenderer) Render(i *session.Instance, idx int, selected bool, h
 
 	// Cut the title if it's too long
 	titleText := i.Title
+	if i.Muted {
+		titleText += " (muted)"
+	}
+	if i.AwaitingApproval() {
+		titleText += " (needs approval)"
+	}
 	widthAvail := r.width - 3 - len(prefix) - 1
 	if widthAvail > 0 && widthAvail < len(titleText) && len(titleText) >= widthAvail-3 {
 		titleText = titleText[:widthAvail-3] + "..."
@@ -173,6 +196,76 @@ func (r *InstanceRenderer) Render(i *session.Instance, idx int, selected bool, h
         }
     }
 
+    var prLabel string
+    if i.PullRequest != nil {
+        prLabel = pullRequestLabel(i.PullRequest)
+        prBadge := pullRequestBadgeStyle(i.PullRequest).Background(descS.GetBackground()).Render(prLabel)
+        if diff != "" {
+            diff = lipgloss.JoinHorizontal(lipgloss.Center, diff, " ", prBadge)
+        } else {
+            diff = prBadge
+        }
+    }
+
+    var checkText string
+    if run, ok := i.LastCheck(); ok {
+        checkText = checkLabel(run)
+        checkBadge := checkBadgeStyle(run).Background(descS.GetBackground()).Render(checkText)
+        if diff != "" {
+            diff = lipgloss.JoinHorizontal(lipgloss.Center, diff, " ", checkBadge)
+        } else {
+            diff = checkBadge
+        }
+    }
+
+    var autoYesText string
+    if i.AutoYes {
+        autoYesText = autoYesLabel(i, time.Now())
+        autoYesBadge := StyleBadge().Background(descS.GetBackground()).Render(autoYesText)
+        if diff != "" {
+            diff = lipgloss.JoinHorizontal(lipgloss.Center, diff, " ", autoYesBadge)
+        } else {
+            diff = autoYesBadge
+        }
+    }
+
+    var sandboxText string
+    if i.Sandbox != nil {
+        sandboxText = sandboxLabel(i.Sandbox)
+        sandboxBadge := StyleBadge().Foreground(StyleOk().GetForeground()).Background(descS.GetBackground()).Render(sandboxText)
+        if diff != "" {
+            diff = lipgloss.JoinHorizontal(lipgloss.Center, diff, " ", sandboxBadge)
+        } else {
+            diff = sandboxBadge
+        }
+    }
+
+    var usageText string
+    if usage, ok := i.ResourceUsage(); ok {
+        usageText = usageLabel(usage)
+        usageStyle := StyleBadge().Foreground(StyleMuted().GetForeground())
+        if usage.NearMemoryLimit() {
+            usageStyle = usageStyle.Foreground(StyleWarn().GetForeground())
+        }
+        usageBadge := usageStyle.Background(descS.GetBackground()).Render(usageText)
+        if diff != "" {
+            diff = lipgloss.JoinHorizontal(lipgloss.Center, diff, " ", usageBadge)
+        } else {
+            diff = usageBadge
+        }
+    }
+
+    var costText string
+    if total, ok := i.UsageTotal(); ok {
+        costText = costLabel(total)
+        costBadge := StyleBadge().Background(descS.GetBackground()).Render(costText)
+        if diff != "" {
+            diff = lipgloss.JoinHorizontal(lipgloss.Center, diff, " ", costBadge)
+        } else {
+            diff = costBadge
+        }
+    }
+
 	remainingWidth := r.width
 	remainingWidth -= len(prefix)
 	remainingWidth -= len(branchIcon)
@@ -188,6 +281,42 @@ func (r *InstanceRenderer) Render(i *session.Instance, idx int, selected bool, h
             diffWidth += 1 // space
         }
     }
+    if prLabel != "" {
+        diffWidth += lipgloss.Width(prLabel) + 2
+        if diffWidth > 0 {
+            diffWidth += 1 // space
+        }
+    }
+    if checkText != "" {
+        diffWidth += lipgloss.Width(checkText) + 2
+        if diffWidth > 0 {
+            diffWidth += 1 // space
+        }
+    }
+    if autoYesText != "" {
+        diffWidth += lipgloss.Width(autoYesText) + 2
+        if diffWidth > 0 {
+            diffWidth += 1 // space
+        }
+    }
+    if sandboxText != "" {
+        diffWidth += lipgloss.Width(sandboxText) + 2
+        if diffWidth > 0 {
+            diffWidth += 1 // space
+        }
+    }
+    if usageText != "" {
+        diffWidth += lipgloss.Width(usageText) + 2
+        if diffWidth > 0 {
+            diffWidth += 1 // space
+        }
+    }
+    if costText != "" {
+        diffWidth += lipgloss.Width(costText) + 2
+        if diffWidth > 0 {
+            diffWidth += 1 // space
+        }
+    }
 
 	// Use fixed width for diff stats to avoid layout issues
 	remainingWidth -= diffWidth
@@ -232,6 +361,85 @@ func (r *InstanceRenderer) Render(i *session.Instance, idx int, selected bool, h
     return text
 }
 
+// pullRequestLabel describes a pull request in a few characters, e.g. "#12 open ✓ approved".
+func pullRequestLabel(pr *forge.PullRequestStatus) string {
+	label := fmt.Sprintf("#%d %s", pr.Number, pr.State)
+	if pr.Done() {
+		return label
+	}
+	switch pr.Checks {
+	case forge.ChecksSuccess:
+		label += " ✓"
+	case forge.ChecksFailure:
+		label += " ✗"
+	case forge.ChecksPending:
+		label += " ●"
+	}
+	switch pr.Review {
+	case forge.ReviewApproved:
+		label += " approved"
+	case forge.ReviewChangesRequested:
+		label += " changes requested"
+	}
+	return label
+}
+
+// pullRequestBadgeStyle colors the pull request badge by what needs attention.
+func pullRequestBadgeStyle(pr *forge.PullRequestStatus) lipgloss.Style {
+	switch {
+	case pr.State == forge.StateMerged:
+		return StyleBadge().Foreground(StyleOk().GetForeground())
+	case pr.State == forge.StateClosed:
+		return StyleBadge().Foreground(StyleMuted().GetForeground())
+	case pr.Checks == forge.ChecksFailure:
+		return StyleBadge().Foreground(StyleDanger().GetForeground())
+	case pr.Review == forge.ReviewChangesRequested:
+		return StyleBadge().Foreground(StyleWarn().GetForeground())
+	default:
+		return StyleBadge()
+	}
+}
+
+// autoYesLabel is the text of the AutoYes badge, with what is left of the budget, e.g.
+// "auto-yes 3 left" or "auto-yes 12m left".
+func autoYesLabel(i *session.Instance, now time.Time) string {
+	approvals, left := i.AutoYesRemaining(now)
+	var parts []string
+	if approvals >= 0 {
+		parts = append(parts, fmt.Sprintf("%d", approvals))
+	}
+	if left >= 0 {
+		// Round up so that the last minute doesn't show as 0m.
+		parts = append(parts, fmt.Sprintf("%dm", int((left+time.Minute-1)/time.Minute)))
+	}
+	if len(parts) == 0 {
+		return "auto-yes"
+	}
+	return "auto-yes " + strings.Join(parts, ", ") + " left"
+}
+
+// sandboxLabel is the text of the sandbox badge.
+func sandboxLabel(s *session.SandboxState) string {
+	if !s.Network {
+		return "sandbox offline"
+	}
+	return "sandbox"
+}
+
+// usageLabel is the text of the resource usage badge, e.g. "12% 340M 9p".
+func usageLabel(u resources.Usage) string {
+	return fmt.Sprintf("%.0f%% %s %dp", u.CPU, resources.FormatBytes(u.Memory), u.Processes)
+}
+
+// costLabel is the text of the token usage badge: the cost, e.g. "$1.24", or the tokens for agents
+// without prices, e.g. "48k tok".
+func costLabel(t usage.Totals) string {
+	if t.Cost > 0 {
+		return usage.FormatCost(t.Cost)
+	}
+	return usage.FormatTokens(t.Tokens()) + " tok"
+}
+
 func (l *List) String() string {
 	const titleText = " Instances "
 	const autoYesText = " auto-yes "
@@ -308,6 +516,25 @@ func (l *List) Kill() {
 	l.items = append(l.items[:l.selectedIdx], l.items[l.selectedIdx+1:]...)
 }
 
+// KillInstance kills instance like Kill and keeps the selection on the same instance if it is
+// still in the list.
+func (l *List) KillInstance(instance *session.Instance) {
+	selected := l.GetSelectedInstance()
+	for idx, item := range l.items {
+		if item != instance {
+			continue
+		}
+		l.selectedIdx = idx
+		l.Kill()
+		for i, item := range l.items {
+			if item == selected {
+				l.selectedIdx = i
+			}
+		}
+		return
+	}
+}
+
 func (l *List) Attach() (chan struct{}, error) {
 	targetInstance := l.items[l.selectedIdx]
 	return targetInstance.Attach()
@@ -0,0 +1,25 @@
+package ui
+
+import (
+	"claude-squad/config"
+	"claude-squad/session"
+	"testing"
+	"time"
+)
+
+func TestAutoYesLabel(t *testing.T) {
+	defer session.SetAutoYesBudget(config.AutoYesBudget{})
+	inst := &session.Instance{Title: "feature"}
+	inst.SetAutoYes(true)
+	now := inst.AutoYesSince
+
+	if got := autoYesLabel(inst, now); got != "auto-yes" {
+		t.Errorf("without a budget: got %q", got)
+	}
+
+	session.SetAutoYesBudget(config.AutoYesBudget{Approvals: 10, Minutes: 30})
+	inst.AutoYesApprovals = 3
+	if got := autoYesLabel(inst, now.Add(29*time.Minute+30*time.Second)); got != "auto-yes 7, 1m left" {
+		t.Errorf("with a budget: got %q", got)
+	}
+}
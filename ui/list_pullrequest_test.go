@@ -0,0 +1,47 @@
+package ui
+
+import (
+	"claude-squad/forge"
+	"claude-squad/session"
+	"strings"
+	"testing"
+
+	"github.com/charmbracelet/bubbles/spinner"
+	"github.com/charmbracelet/lipgloss"
+)
+
+func TestPullRequestLabel(t *testing.T) {
+	cases := []struct {
+		pr   forge.PullRequestStatus
+		want string
+	}{
+		{forge.PullRequestStatus{Number: 12, State: forge.StateOpen}, "#12 open"},
+		{forge.PullRequestStatus{Number: 12, State: forge.StateOpen, Checks: forge.ChecksSuccess, Review: forge.ReviewApproved}, "#12 open ✓ approved"},
+		{forge.PullRequestStatus{Number: 3, State: forge.StateOpen, Checks: forge.ChecksFailure, Review: forge.ReviewChangesRequested}, "#3 open ✗ changes requested"},
+		// Checks and reviews don't matter anymore once the pull request is done.
+		{forge.PullRequestStatus{Number: 3, State: forge.StateMerged, Checks: forge.ChecksSuccess, Review: forge.ReviewApproved}, "#3 merged"},
+	}
+	for _, c := range cases {
+		if got := pullRequestLabel(&c.pr); got != c.want {
+			t.Errorf("pullRequestLabel(%+v) = %q, want %q", c.pr, got, c.want)
+		}
+	}
+}
+
+func TestRenderPullRequestBadgeKeepsWidth(t *testing.T) {
+	s := spinner.New()
+	r := &InstanceRenderer{spinner: &s}
+	r.setWidth(60)
+	inst := &session.Instance{Title: "feature", Branch: "me/feature", Status: session.Ready}
+
+	plain := r.Render(inst, 1, false, false)
+	inst.PullRequest = &forge.PullRequestStatus{Number: 12, State: forge.StateOpen, Checks: forge.ChecksPending}
+	badged := r.Render(inst, 1, false, false)
+
+	if !strings.Contains(badged, "#12 open ●") {
+		t.Fatalf("expected the pull request badge in %q", badged)
+	}
+	if lipgloss.Width(badged) != lipgloss.Width(plain) {
+		t.Fatalf("badge changed the row width from %d to %d", lipgloss.Width(plain), lipgloss.Width(badged))
+	}
+}
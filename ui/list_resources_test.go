@@ -0,0 +1,23 @@
+package ui
+
+import (
+	"claude-squad/session/resources"
+	"claude-squad/session/usage"
+	"testing"
+)
+
+func TestUsageLabel(t *testing.T) {
+	usage := resources.Usage{CPU: 12.4, Memory: 340 * 1024 * 1024, Processes: 9}
+	if got := usageLabel(usage); got != "12% 340M 9p" {
+		t.Errorf("got %q", got)
+	}
+}
+
+func TestCostLabel(t *testing.T) {
+	if got := costLabel(usage.Totals{InputTokens: 40_000, OutputTokens: 8_000, Cost: 1.235}); got != "$1.24" {
+		t.Errorf("priced: got %q", got)
+	}
+	if got := costLabel(usage.Totals{InputTokens: 40_000, OutputTokens: 8_000}); got != "48k tok" {
+		t.Errorf("unpriced: got %q", got)
+	}
+}
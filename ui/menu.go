@@ -8,19 +8,27 @@ import (
     "github.com/charmbracelet/lipgloss"
 )
 
-var keyStyle = lipgloss.NewStyle().Foreground(Theme.FgMuted)
-
-var descStyle = lipgloss.NewStyle().Foreground(Theme.Fg)
-
-var sepStyle = lipgloss.NewStyle().Foreground(Theme.FgMuted)
+var (
+    keyStyle         lipgloss.Style
+    descStyle        lipgloss.Style
+    sepStyle         lipgloss.Style
+    actionGroupStyle lipgloss.Style
+    menuStyle        lipgloss.Style
+)
 
-var actionGroupStyle = lipgloss.NewStyle().Foreground(Theme.Accent)
+func init() {
+    OnThemeChange(func() {
+        keyStyle = lipgloss.NewStyle().Foreground(Theme.FgMuted)
+        descStyle = lipgloss.NewStyle().Foreground(Theme.Fg)
+        sepStyle = lipgloss.NewStyle().Foreground(Theme.FgMuted)
+        actionGroupStyle = lipgloss.NewStyle().Foreground(Theme.Accent)
+        menuStyle = lipgloss.NewStyle().Foreground(Theme.Fg)
+    })
+}
 
 var separator = " • "
 var verticalSeparator = " │ "
 
-var menuStyle = lipgloss.NewStyle().Foreground(Theme.Fg)
-
 // MenuState represents different states the menu can be in
 type MenuState int
 
@@ -32,27 +40,28 @@ const (
 )
 
 type Menu struct {
-	options       []keys.KeyName
-	height, width int
-	state         MenuState
-	instance      *session.Instance
-	isInDiffTab   bool
+	options        []keys.KeyName
+	height, width  int
+	state          MenuState
+	instance       *session.Instance
+	isInDiffTab    bool
+	isInCommitsTab bool
 
 	// keyDown is the key which is pressed. The default is -1.
 	keyDown keys.KeyName
 }
 
-var defaultMenuOptions = []keys.KeyName{keys.KeyNew, keys.KeyPrompt, keys.KeyHelp, keys.KeyQuit}
 var newInstanceMenuOptions = []keys.KeyName{keys.KeySubmitName}
 var promptMenuOptions = []keys.KeyName{keys.KeySubmitName}
 
 func NewMenu() *Menu {
-	return &Menu{
-		options:     defaultMenuOptions,
+	m := &Menu{
 		state:       StateEmpty,
 		isInDiffTab: false,
 		keyDown:     -1,
 	}
+	m.updateOptions()
+	return m
 }
 
 func (m *Menu) Keydown(name keys.KeyName) {
@@ -89,19 +98,18 @@ func (m *Menu) SetInDiffTab(inDiffTab bool) {
 	m.updateOptions()
 }
 
+// SetInCommitsTab updates whether we're currently in the commits tab
+func (m *Menu) SetInCommitsTab(inCommitsTab bool) {
+	m.isInCommitsTab = inCommitsTab
+	m.updateOptions()
+}
+
 // updateOptions updates the menu options based on current state and instance
 func (m *Menu) updateOptions() {
 	switch m.state {
-	case StateEmpty:
-		m.options = defaultMenuOptions
-	case StateDefault:
-		if m.instance != nil {
-			// When there is an instance, show that instance's options
-			m.addInstanceOptions()
-		} else {
-			// When there is no instance, show the empty state
-			m.options = defaultMenuOptions
-		}
+	case StateEmpty, StateDefault:
+		// The registry decides which actions apply to the selected instance (or to no instance).
+		m.addRegistryOptions()
 	case StateNewInstance:
 		m.options = newInstanceMenuOptions
 	case StatePrompt:
@@ -109,30 +117,25 @@ func (m *Menu) updateOptions() {
 	}
 }
 
-func (m *Menu) addInstanceOptions() {
-	// Instance management group
-	options := []keys.KeyName{keys.KeyNew, keys.KeyKill}
-
-	// Action group
-	actionGroup := []keys.KeyName{keys.KeyEnter, keys.KeySubmit}
-	if m.instance.Status == session.Paused {
-		actionGroup = append(actionGroup, keys.KeyResume)
-	} else {
-		actionGroup = append(actionGroup, keys.KeyCheckout)
+// actionContext returns the state the menu's actions are evaluated against.
+func (m *Menu) actionContext() keys.ActionContext {
+	return keys.ActionContext{
+		HasInstance:  m.instance != nil,
+		Paused:       m.instance != nil && m.instance.Status == session.Paused,
+		InDiffTab:    m.isInDiffTab,
+		InCommitsTab: m.isInCommitsTab,
 	}
+}
 
-	// Navigation group (when in diff tab)
-	if m.isInDiffTab {
-		actionGroup = append(actionGroup, keys.KeyShiftUp)
+// addRegistryOptions shows every registry action advertised for the current context.
+func (m *Menu) addRegistryOptions() {
+	ctx := m.actionContext()
+	options := make([]keys.KeyName, 0, len(keys.Actions))
+	for _, a := range keys.Actions {
+		if a.InMenu(ctx) {
+			options = append(options, a.Name)
+		}
 	}
-
-	// System group
-	systemGroup := []keys.KeyName{keys.KeyTab, keys.KeyHelp, keys.KeyQuit}
-
-	// Combine all groups
-	options = append(options, actionGroup...)
-	options = append(options, systemGroup...)
-
 	m.options = options
 }
 
@@ -142,18 +145,24 @@ func (m *Menu) SetSize(width, height int) {
 	m.height = height
 }
 
+// groupOf returns the registry group of a menu option. Options that are not part of the registry
+// (like submitting a name) form their own group.
+func groupOf(name keys.KeyName) keys.ActionGroup {
+	if a, ok := keys.LookupAction(name); ok {
+		return a.Group
+	}
+	return keys.GroupActions
+}
+
 func (m *Menu) String() string {
 	s := getBuilder()
 	defer putBuilder(s)
 
-	// Define group boundaries
-	groups := []struct {
-		start int
-		end   int
-	}{
-		{0, 2}, // Instance management group (n, d)
-		{2, 5}, // Action group (enter, submit, pause/resume)
-		{6, 8}, // System group (tab, help, q)
+	// The highlighted group is the one holding the most relevant actions: creating sessions when
+	// there are none, acting on the selected session otherwise.
+	highlighted := keys.GroupActions
+	if m.state == StateEmpty {
+		highlighted = keys.GroupSession
 	}
 
 	for i, k := range m.options {
@@ -170,17 +179,7 @@ func (m *Menu) String() string {
 			localDescStyle = localDescStyle.Underline(true)
 		}
 
-		var inActionGroup bool
-		switch m.state {
-		case StateEmpty:
-			// For empty state, the action group is the first group
-			inActionGroup = i <= 1
-		default:
-			// For other states, the action group is the second group
-			inActionGroup = i >= groups[1].start && i < groups[1].end
-		}
-
-		if inActionGroup {
+		if groupOf(k) == highlighted {
 			s.WriteString(localActionStyle.Render(binding.Help().Key))
 			s.WriteByte(' ')
 			s.WriteString(localActionStyle.Render(binding.Help().Desc))
@@ -192,15 +191,9 @@ func (m *Menu) String() string {
 
 		// Add appropriate separator
 		if i != len(m.options)-1 {
-			isGroupEnd := false
-			for _, group := range groups {
-				if i == group.end-1 {
-					s.WriteString(sepStyle.Render(verticalSeparator))
-					isGroupEnd = true
-					break
-				}
-			}
-			if !isGroupEnd {
+			if groupOf(m.options[i+1]) != groupOf(k) {
+				s.WriteString(sepStyle.Render(verticalSeparator))
+			} else {
 				s.WriteString(sepStyle.Render(separator))
 			}
 		}
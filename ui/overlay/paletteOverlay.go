@@ -0,0 +1,288 @@
+package overlay
+
+import (
+	ui "claude-squad/ui"
+	"sort"
+	"strings"
+	"unicode"
+
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// PaletteItem is a single entry in the command palette.
+type PaletteItem struct {
+	// ID identifies the item to the caller.
+	ID string
+	// Title is the text that is displayed and searched.
+	Title string
+	// Group is displayed next to the title and is searched as well.
+	Group string
+	// Hint is displayed right-aligned, usually the key binding.
+	Hint string
+	// Disabled items are listed but cannot be selected.
+	Disabled bool
+}
+
+// PaletteOverlay is a fuzzy-searchable list of commands.
+type PaletteOverlay struct {
+	title    string
+	input    textinput.Model
+	items    []PaletteItem
+	filtered []PaletteItem
+	cursor   int
+	// offset is the index of the first visible filtered item.
+	offset int
+
+	width      int
+	maxVisible int
+
+	// Selected is set to the chosen item once the overlay is closed by pressing enter.
+	Selected *PaletteItem
+	// Canceled is true if the overlay was closed with esc.
+	Canceled bool
+}
+
+// NewPaletteOverlay creates a new palette with the given title listing the given items.
+func NewPaletteOverlay(title string, items []PaletteItem) *PaletteOverlay {
+	ti := textinput.New()
+	ti.Placeholder = "Type to search..."
+	ti.Prompt = "> "
+	ti.Focus()
+
+	p := &PaletteOverlay{
+		title:      title,
+		input:      ti,
+		items:      items,
+		width:      60,
+		maxVisible: 12,
+	}
+	p.filter()
+	return p
+}
+
+// SetSize sets the width of the overlay and the number of rows it may use for items.
+func (p *PaletteOverlay) SetSize(width, height int) {
+	p.width = width
+	// Leave room for the border, padding, input line and footer.
+	p.maxVisible = max(1, height-8)
+	p.clampOffset()
+}
+
+// HandleKeyPress processes a key press and updates the state.
+// Returns true if the overlay should be closed.
+func (p *PaletteOverlay) HandleKeyPress(msg tea.KeyMsg) bool {
+	switch msg.String() {
+	case "esc", "ctrl+c":
+		p.Canceled = true
+		return true
+	case "enter":
+		if p.cursor < len(p.filtered) && !p.filtered[p.cursor].Disabled {
+			item := p.filtered[p.cursor]
+			p.Selected = &item
+			return true
+		}
+		return false
+	case "up", "ctrl+k", "ctrl+p", "shift+tab":
+		p.move(-1)
+		return false
+	case "down", "ctrl+j", "ctrl+n", "tab":
+		p.move(1)
+		return false
+	}
+
+	before := p.input.Value()
+	p.input, _ = p.input.Update(msg)
+	if p.input.Value() != before {
+		p.filter()
+	}
+	return false
+}
+
+// Query returns the current search text.
+func (p *PaletteOverlay) Query() string {
+	return p.input.Value()
+}
+
+// Items returns the items matching the current query in display order.
+func (p *PaletteOverlay) Items() []PaletteItem {
+	return p.filtered
+}
+
+func (p *PaletteOverlay) move(delta int) {
+	if len(p.filtered) == 0 {
+		return
+	}
+	p.cursor = (p.cursor + delta + len(p.filtered)) % len(p.filtered)
+	p.clampOffset()
+}
+
+// clampOffset keeps the cursor inside the visible window.
+func (p *PaletteOverlay) clampOffset() {
+	if p.cursor < p.offset {
+		p.offset = p.cursor
+	}
+	if p.cursor >= p.offset+p.maxVisible {
+		p.offset = p.cursor - p.maxVisible + 1
+	}
+	if p.offset < 0 {
+		p.offset = 0
+	}
+}
+
+// filter recomputes the matching items for the current query. Items keep their registry order
+// when the query is empty; otherwise they are ranked by match quality.
+func (p *PaletteOverlay) filter() {
+	query := strings.TrimSpace(p.input.Value())
+	p.cursor = 0
+	p.offset = 0
+	if query == "" {
+		p.filtered = append(p.filtered[:0], p.items...)
+		p.skipDisabled()
+		return
+	}
+
+	type scored struct {
+		item  PaletteItem
+		score int
+	}
+	var matches []scored
+	for _, item := range p.items {
+		best, ok := FuzzyScore(query, item.Title)
+		if s, okGroup := FuzzyScore(query, item.Group+" "+item.Title); okGroup && (!ok || s > best) {
+			// Matching through the group is allowed but ranks below a direct title match.
+			best, ok = s-1, true
+		}
+		if ok {
+			matches = append(matches, scored{item: item, score: best})
+		}
+	}
+	sort.SliceStable(matches, func(i, j int) bool {
+		// Available commands first, then by score.
+		if matches[i].item.Disabled != matches[j].item.Disabled {
+			return !matches[i].item.Disabled
+		}
+		return matches[i].score > matches[j].score
+	})
+	p.filtered = p.filtered[:0]
+	for _, m := range matches {
+		p.filtered = append(p.filtered, m.item)
+	}
+	p.skipDisabled()
+}
+
+// skipDisabled moves the cursor to the first selectable item if there is one.
+func (p *PaletteOverlay) skipDisabled() {
+	for i, item := range p.filtered {
+		if !item.Disabled {
+			p.cursor = i
+			p.clampOffset()
+			return
+		}
+	}
+}
+
+// FuzzyScore reports whether every rune of pattern appears in target in order (case-insensitively)
+// and scores the match. Consecutive runs and matches at word starts score higher.
+func FuzzyScore(pattern, target string) (int, bool) {
+	pr := []rune(strings.ToLower(pattern))
+	tr := []rune(target)
+	if len(pr) == 0 {
+		return 0, true
+	}
+
+	score, pi, prevMatch := 0, 0, -2
+	for ti := 0; ti < len(tr) && pi < len(pr); ti++ {
+		if pr[pi] == ' ' {
+			// Spaces in the pattern only separate words.
+			pi++
+			if pi == len(pr) {
+				break
+			}
+		}
+		if unicode.ToLower(tr[ti]) != pr[pi] {
+			continue
+		}
+		score++
+		if prevMatch == ti-1 {
+			score += 3
+		}
+		if ti == 0 || !unicode.IsLetter(tr[ti-1]) {
+			score += 2
+		}
+		prevMatch = ti
+		pi++
+	}
+	if pi < len(pr) {
+		return 0, false
+	}
+	// Prefer shorter targets for equally good matches.
+	return score*100 - len(tr), true
+}
+
+// Render renders the command palette.
+func (p *PaletteOverlay) Render() string {
+	style := lipgloss.NewStyle().
+		Border(lipgloss.RoundedBorder()).
+		BorderForeground(ui.Theme.Accent).
+		Padding(1, 2).
+		Width(p.width)
+
+	titleStyle := lipgloss.NewStyle().Foreground(ui.Theme.Accent).Bold(true)
+	selectedStyle := ui.StyleSelected().Bold(true)
+	itemStyle := lipgloss.NewStyle().Foreground(ui.Theme.Fg)
+	disabledStyle := ui.StyleMuted().Faint(true)
+	hintStyle := ui.StyleMuted()
+
+	inner := max(10, p.width-6)
+	p.input.Width = inner - 3
+	p.input.PlaceholderStyle = ui.StyleMuted()
+
+	var b strings.Builder
+	b.WriteString(titleStyle.Render(p.title))
+	b.WriteString("\n")
+	b.WriteString(p.input.View())
+	b.WriteString("\n\n")
+
+	if len(p.filtered) == 0 {
+		b.WriteString(ui.StyleMuted().Render("No matches"))
+		b.WriteString("\n")
+	}
+	end := min(len(p.filtered), p.offset+p.maxVisible)
+	for i := p.offset; i < end; i++ {
+		item := p.filtered[i]
+		label := item.Title
+		if item.Group != "" {
+			label = item.Group + ": " + label
+		}
+		hint := item.Hint
+		if item.Disabled {
+			hint = strings.TrimSpace(hint + " (unavailable)")
+		}
+		gap := inner - lipgloss.Width(label) - lipgloss.Width(hint)
+		if gap < 1 {
+			// Truncate the label so the hint stays visible.
+			runes := []rune(label)
+			keep := max(0, len(runes)+gap-2)
+			label = string(runes[:min(keep, len(runes))]) + "…"
+			gap = max(1, inner-lipgloss.Width(label)-lipgloss.Width(hint))
+		}
+		var line string
+		switch {
+		case i == p.cursor:
+			line = selectedStyle.Width(inner).Render(label + strings.Repeat(" ", gap) + hint)
+		case item.Disabled:
+			line = disabledStyle.Render(label + strings.Repeat(" ", gap) + hint)
+		default:
+			line = itemStyle.Render(label) + strings.Repeat(" ", gap) + hintStyle.Render(hint)
+		}
+		b.WriteString(line)
+		b.WriteString("\n")
+	}
+
+	b.WriteString("\n")
+	b.WriteString(ui.StyleMuted().Render("↑/↓ to move • Enter to select • Esc to close"))
+
+	return style.Render(b.String())
+}
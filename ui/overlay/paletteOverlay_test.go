@@ -0,0 +1,49 @@
+package overlay
+
+import (
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFuzzyScore(t *testing.T) {
+	_, ok := FuzzyScore("nwss", "Create a new session")
+	assert.True(t, ok)
+
+	_, ok = FuzzyScore("xyz", "Create a new session")
+	assert.False(t, ok)
+
+	// Matches at word starts rank above scattered matches.
+	wordStart, _ := FuzzyScore("ns", "New session")
+	scattered, _ := FuzzyScore("ns", "Scroll down one page")
+	assert.Greater(t, wordStart, scattered)
+}
+
+func TestPaletteOverlaySelection(t *testing.T) {
+	p := NewPaletteOverlay("Commands", []PaletteItem{
+		{ID: "kill", Title: "Kill the selected session", Disabled: true},
+		{ID: "new", Title: "Create a new session"},
+		{ID: "quit", Title: "Quit the application"},
+	})
+
+	// The cursor starts on the first available item.
+	assert.False(t, p.HandleKeyPress(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("q")}))
+	require.Len(t, p.Items(), 1)
+	assert.Equal(t, "quit", p.Items()[0].ID)
+
+	assert.True(t, p.HandleKeyPress(tea.KeyMsg{Type: tea.KeyEnter}))
+	require.NotNil(t, p.Selected)
+	assert.Equal(t, "quit", p.Selected.ID)
+}
+
+func TestPaletteOverlayDisabledItemsCannotRun(t *testing.T) {
+	p := NewPaletteOverlay("Commands", []PaletteItem{{ID: "kill", Title: "Kill", Disabled: true}})
+
+	assert.False(t, p.HandleKeyPress(tea.KeyMsg{Type: tea.KeyEnter}))
+	assert.Nil(t, p.Selected)
+
+	assert.True(t, p.HandleKeyPress(tea.KeyMsg{Type: tea.KeyEsc}))
+	assert.True(t, p.Canceled)
+}
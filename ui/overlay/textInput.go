@@ -105,6 +105,11 @@ func (t *TextInputOverlay) GetValue() string {
 	return t.textarea.Value()
 }
 
+// SetValue replaces the text of the input.
+func (t *TextInputOverlay) SetValue(value string) {
+	t.textarea.SetValue(value)
+}
+
 // IsSubmitted returns whether the form was submitted.
 func (t *TextInputOverlay) IsSubmitted() bool {
 	return t.Submitted
@@ -9,12 +9,20 @@ import (
     "github.com/charmbracelet/lipgloss"
 )
 
-var previewPaneStyle = lipgloss.NewStyle().Foreground(Theme.Fg)
-
 // Reusable styles to avoid per-render allocations
-var previewFooterStyle = StyleMuted()
+var (
+    previewPaneStyle   lipgloss.Style
+    previewFooterStyle lipgloss.Style
+    pauseHintStyle     lipgloss.Style
+)
 
-var pauseHintStyle = StyleWarn()
+func init() {
+    OnThemeChange(func() {
+        previewPaneStyle = lipgloss.NewStyle().Foreground(Theme.Fg)
+        previewFooterStyle = StyleMuted()
+        pauseHintStyle = StyleWarn()
+    })
+}
 
 type PreviewPane struct {
     width  int
@@ -23,6 +31,9 @@ type PreviewPane struct {
     previewState previewState
     isScrolling  bool
     viewport     viewport.Model
+
+    // shell makes the pane show the instance's shell instead of its agent.
+    shell bool
 }
 
 type previewState struct {
@@ -38,6 +49,29 @@ func NewPreviewPane() *PreviewPane {
     }
 }
 
+// NewShellPane returns a preview pane showing the shell opened in the instance's worktree.
+func NewShellPane() *PreviewPane {
+    return &PreviewPane{
+        viewport: viewport.New(0, 0),
+        shell:    true,
+    }
+}
+
+// capture returns the visible content of the agent's or the shell's tmux pane, or its full
+// history.
+func (p *PreviewPane) capture(instance *session.Instance, fullHistory bool) (string, error) {
+    switch {
+    case p.shell && fullHistory:
+        return instance.ShellPreviewFullHistory()
+    case p.shell:
+        return instance.ShellPreview()
+    case fullHistory:
+        return instance.PreviewFullHistory()
+    default:
+        return instance.Preview()
+    }
+}
+
 func (p *PreviewPane) SetSize(width, maxHeight int) {
     if width < 1 { width = 1 }
     if maxHeight < 1 { maxHeight = 1 }
@@ -71,6 +105,10 @@ func (p *PreviewPane) UpdateContent(instance *session.Instance) error {
 			)),
 		))
 		return nil
+	case p.shell && instance.Started() && !instance.HasShell():
+		p.isScrolling = false
+		p.setFallbackState("No shell open. Press '!' to open one in the session's worktree.")
+		return nil
 	}
 
 	var content string
@@ -79,7 +117,7 @@ func (p *PreviewPane) UpdateContent(instance *session.Instance) error {
 	// If in scroll mode but haven't captured content yet, do it now
 	if p.isScrolling && p.viewport.Height > 0 && len(p.viewport.View()) == 0 {
 		// Capture full pane content including scrollback history using capture-pane -p -S -
-		content, err = instance.PreviewFullHistory()
+		content, err = p.capture(instance, true)
 		if err != nil {
 			return err
 		}
@@ -88,7 +126,7 @@ func (p *PreviewPane) UpdateContent(instance *session.Instance) error {
 		p.viewport.SetContent(content)
 	} else if !p.isScrolling {
 		// In normal mode, use the usual preview
-		content, err = instance.Preview()
+		content, err = p.capture(instance, false)
 		if err != nil {
 			return err
 		}
@@ -227,7 +265,7 @@ func (p *PreviewPane) ResetToNormalMode(instance *session.Instance) error {
         p.viewport.Height = p.height
 
 		// Immediately update content instead of waiting for next UpdateContent call
-		content, err := instance.Preview()
+		content, err := p.capture(instance, false)
 		if err != nil {
 			return err
 		}
@@ -323,7 +361,7 @@ func (p *PreviewPane) GotoBottom(instance *session.Instance) error {
 
 // enterScrollMode captures the full history and prepares the viewport footer
 func (p *PreviewPane) enterScrollMode(instance *session.Instance) error {
-    content, err := instance.PreviewFullHistory()
+    content, err := p.capture(instance, true)
     if err != nil {
         return err
     }
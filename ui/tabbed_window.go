@@ -3,6 +3,7 @@ package ui
 import (
     "claude-squad/log"
     "claude-squad/session"
+    "claude-squad/session/git"
     "fmt"
     "github.com/charmbracelet/lipgloss"
 )
@@ -18,22 +19,34 @@ func tabBorderWithBottom(left, middle, right string) lipgloss.Border {
 var (
     inactiveTabBorder = tabBorderWithBottom("┴", "─", "┴")
     activeTabBorder   = tabBorderWithBottom("┘", " ", "└")
-    highlightColor    = Theme.Accent
-    inactiveTabStyle  = lipgloss.NewStyle().
-                        Border(inactiveTabBorder, true).
-                        BorderForeground(highlightColor).
-                        AlignHorizontal(lipgloss.Center)
-    activeTabStyle = inactiveTabStyle.
-                        Border(activeTabBorder, true).
-                        AlignHorizontal(lipgloss.Center)
-    windowStyle = lipgloss.NewStyle().
-                        BorderForeground(highlightColor).
-                        Border(lipgloss.NormalBorder(), false, true, true, true)
+    highlightColor    lipgloss.AdaptiveColor
+    inactiveTabStyle  lipgloss.Style
+    activeTabStyle    lipgloss.Style
+    windowStyle       lipgloss.Style
 )
 
+func init() {
+    OnThemeChange(func() {
+        highlightColor = Theme.Accent
+        inactiveTabStyle = lipgloss.NewStyle().
+            Border(inactiveTabBorder, true).
+            BorderForeground(highlightColor).
+            AlignHorizontal(lipgloss.Center)
+        activeTabStyle = inactiveTabStyle.
+            Border(activeTabBorder, true).
+            AlignHorizontal(lipgloss.Center)
+        windowStyle = lipgloss.NewStyle().
+            BorderForeground(highlightColor).
+            Border(lipgloss.NormalBorder(), false, true, true, true)
+    })
+}
+
 const (
 	PreviewTab int = iota
 	DiffTab
+	CommitsTab
+	ShellTab
+	ChecksTab
 )
 
 type Tab struct {
@@ -52,17 +65,26 @@ type TabbedWindow struct {
 
 	preview  *PreviewPane
 	diff     *DiffPane
+	commits  *CommitsPane
+	shell    *PreviewPane
+	checks   *ChecksPane
 	instance *session.Instance
 }
 
-func NewTabbedWindow(preview *PreviewPane, diff *DiffPane) *TabbedWindow {
+func NewTabbedWindow(preview *PreviewPane, diff *DiffPane, commits *CommitsPane, shell *PreviewPane, checks *ChecksPane) *TabbedWindow {
 	return &TabbedWindow{
 		tabs: []string{
 			"Preview",
 			"Diff",
+			"Commits",
+			"Shell",
+			"Checks",
 		},
 		preview: preview,
 		diff:    diff,
+		commits: commits,
+		shell:   shell,
+		checks:  checks,
 	}
 }
 
@@ -88,6 +110,9 @@ func (w *TabbedWindow) SetSize(width, height int) {
 
 	w.preview.SetSize(contentWidth, contentHeight)
     w.diff.SetSize(contentWidth, contentHeight)
+    w.commits.SetSize(contentWidth, contentHeight)
+    w.shell.SetSize(contentWidth, contentHeight)
+    w.checks.SetSize(contentWidth, contentHeight)
 }
 
 func (w *TabbedWindow) GetPreviewSize() (width, height int) {
@@ -98,22 +123,44 @@ func (w *TabbedWindow) Toggle() {
 	w.activeTab = (w.activeTab + 1) % len(w.tabs)
 }
 
-// ToggleWithReset toggles the tab and resets preview pane to normal mode
+// ToggleWithReset toggles the tab and resets the preview and shell panes to normal mode
 func (w *TabbedWindow) ToggleWithReset(instance *session.Instance) error {
-	// Reset preview pane to normal mode before switching
+	return w.SelectTabWithReset((w.activeTab+1)%len(w.tabs), instance)
+}
+
+// SelectTabWithReset activates the tab at idx and resets the preview and shell panes to normal
+// mode
+func (w *TabbedWindow) SelectTabWithReset(idx int, instance *session.Instance) error {
+	// Reset the panes to normal mode before switching
 	if err := w.preview.ResetToNormalMode(instance); err != nil {
 		return err
 	}
-	w.activeTab = (w.activeTab + 1) % len(w.tabs)
+	if err := w.shell.ResetToNormalMode(instance); err != nil {
+		return err
+	}
+	w.activeTab = max(0, min(idx, len(w.tabs)-1))
 	return nil
 }
 
-// UpdatePreview updates the content of the preview pane. instance may be nil.
+// UpdatePreview updates the content of the preview or shell pane, whichever is active. instance
+// may be nil.
 func (w *TabbedWindow) UpdatePreview(instance *session.Instance) error {
-	if w.activeTab != PreviewTab {
+	if pane := w.terminalPane(); pane != nil {
+		return pane.UpdateContent(instance)
+	}
+	return nil
+}
+
+// terminalPane returns the active pane if it shows a tmux pane, i.e. the preview or the shell.
+func (w *TabbedWindow) terminalPane() *PreviewPane {
+	switch w.activeTab {
+	case PreviewTab:
+		return w.preview
+	case ShellTab:
+		return w.shell
+	default:
 		return nil
 	}
-	return w.preview.UpdateContent(instance)
 }
 
 func (w *TabbedWindow) UpdateDiff(instance *session.Instance) {
@@ -129,29 +176,47 @@ func (w *TabbedWindow) UpdateDiff(instance *session.Instance) {
     }
 }
 
-// ResetPreviewToNormalMode resets the preview pane to normal mode
+// UpdateChecks updates the checks pane if it is active. instance may be nil.
+func (w *TabbedWindow) UpdateChecks(instance *session.Instance) {
+	if w.activeTab == ChecksTab {
+		w.checks.SetCheck(instance)
+	}
+}
+
+// ResetPreviewToNormalMode resets the preview and shell panes to normal mode
 func (w *TabbedWindow) ResetPreviewToNormalMode(instance *session.Instance) error {
-	return w.preview.ResetToNormalMode(instance)
+	if err := w.preview.ResetToNormalMode(instance); err != nil {
+		return err
+	}
+	return w.shell.ResetToNormalMode(instance)
 }
 
 // Add these new methods for handling scroll events
 func (w *TabbedWindow) ScrollUp() {
-    if w.activeTab == PreviewTab {
-        err := w.preview.ScrollUp(w.instance)
+    if pane := w.terminalPane(); pane != nil {
+        err := pane.ScrollUp(w.instance)
         if err != nil {
             log.InfoLog.Printf("tabbed window failed to scroll up: %v", err)
         }
+    } else if w.activeTab == CommitsTab {
+        w.commits.SelectPrev()
+    } else if w.activeTab == ChecksTab {
+        w.checks.ScrollUp()
     } else {
         w.diff.ScrollUp()
     }
 }
 
 func (w *TabbedWindow) ScrollDown() {
-    if w.activeTab == PreviewTab {
-        err := w.preview.ScrollDown(w.instance)
+    if pane := w.terminalPane(); pane != nil {
+        err := pane.ScrollDown(w.instance)
         if err != nil {
             log.InfoLog.Printf("tabbed window failed to scroll down: %v", err)
         }
+    } else if w.activeTab == CommitsTab {
+        w.commits.SelectNext()
+    } else if w.activeTab == ChecksTab {
+        w.checks.ScrollDown()
     } else {
         w.diff.ScrollDown()
     }
@@ -159,10 +224,14 @@ func (w *TabbedWindow) ScrollDown() {
 
 // PageUp scrolls a page in the active pane
 func (w *TabbedWindow) PageUp() {
-    if w.activeTab == PreviewTab {
-        if err := w.preview.PageUp(w.instance); err != nil {
+    if pane := w.terminalPane(); pane != nil {
+        if err := pane.PageUp(w.instance); err != nil {
             log.InfoLog.Printf("tabbed window page up failed: %v", err)
         }
+    } else if w.activeTab == CommitsTab {
+        w.commits.PageUp()
+    } else if w.activeTab == ChecksTab {
+        w.checks.PageUp()
     } else {
         w.diff.PageUp()
     }
@@ -170,10 +239,14 @@ func (w *TabbedWindow) PageUp() {
 
 // PageDown scrolls a page in the active pane
 func (w *TabbedWindow) PageDown() {
-    if w.activeTab == PreviewTab {
-        if err := w.preview.PageDown(w.instance); err != nil {
+    if pane := w.terminalPane(); pane != nil {
+        if err := pane.PageDown(w.instance); err != nil {
             log.InfoLog.Printf("tabbed window page down failed: %v", err)
         }
+    } else if w.activeTab == CommitsTab {
+        w.commits.PageDown()
+    } else if w.activeTab == ChecksTab {
+        w.checks.PageDown()
     } else {
         w.diff.PageDown()
     }
@@ -181,10 +254,14 @@ func (w *TabbedWindow) PageDown() {
 
 // HalfPageUp scrolls half a page in the active pane
 func (w *TabbedWindow) HalfPageUp() {
-    if w.activeTab == PreviewTab {
-        if err := w.preview.HalfPageUp(w.instance); err != nil {
+    if pane := w.terminalPane(); pane != nil {
+        if err := pane.HalfPageUp(w.instance); err != nil {
             log.InfoLog.Printf("tabbed window half page up failed: %v", err)
         }
+    } else if w.activeTab == CommitsTab {
+        w.commits.HalfPageUp()
+    } else if w.activeTab == ChecksTab {
+        w.checks.HalfPageUp()
     } else {
         w.diff.HalfPageUp()
     }
@@ -192,10 +269,14 @@ func (w *TabbedWindow) HalfPageUp() {
 
 // HalfPageDown scrolls half a page in the active pane
 func (w *TabbedWindow) HalfPageDown() {
-    if w.activeTab == PreviewTab {
-        if err := w.preview.HalfPageDown(w.instance); err != nil {
+    if pane := w.terminalPane(); pane != nil {
+        if err := pane.HalfPageDown(w.instance); err != nil {
             log.InfoLog.Printf("tabbed window half page down failed: %v", err)
         }
+    } else if w.activeTab == CommitsTab {
+        w.commits.HalfPageDown()
+    } else if w.activeTab == ChecksTab {
+        w.checks.HalfPageDown()
     } else {
         w.diff.HalfPageDown()
     }
@@ -203,10 +284,14 @@ func (w *TabbedWindow) HalfPageDown() {
 
 // GotoTop moves to top in the active pane
 func (w *TabbedWindow) GotoTop() {
-    if w.activeTab == PreviewTab {
-        if err := w.preview.GotoTop(w.instance); err != nil {
+    if pane := w.terminalPane(); pane != nil {
+        if err := pane.GotoTop(w.instance); err != nil {
             log.InfoLog.Printf("tabbed window goto top failed: %v", err)
         }
+    } else if w.activeTab == CommitsTab {
+        w.commits.GotoTop()
+    } else if w.activeTab == ChecksTab {
+        w.checks.GotoTop()
     } else {
         w.diff.GotoTop()
     }
@@ -214,10 +299,14 @@ func (w *TabbedWindow) GotoTop() {
 
 // GotoBottom moves to bottom in the active pane
 func (w *TabbedWindow) GotoBottom() {
-    if w.activeTab == PreviewTab {
-        if err := w.preview.GotoBottom(w.instance); err != nil {
+    if pane := w.terminalPane(); pane != nil {
+        if err := pane.GotoBottom(w.instance); err != nil {
             log.InfoLog.Printf("tabbed window goto bottom failed: %v", err)
         }
+    } else if w.activeTab == CommitsTab {
+        w.commits.GotoBottom()
+    } else if w.activeTab == ChecksTab {
+        w.checks.GotoBottom()
     } else {
         w.diff.GotoBottom()
     }
@@ -235,9 +324,88 @@ func (w *TabbedWindow) JumpNextFile() { if w.activeTab == DiffTab { w.diff.JumpN
 // JumpPrevFile moves to previous file in diff pane
 func (w *TabbedWindow) JumpPrevFile() { if w.activeTab == DiffTab { w.diff.JumpPrevFile() } }
 
+// ToggleDiffLayout switches the diff pane between unified and side-by-side rendering
+func (w *TabbedWindow) ToggleDiffLayout() { if w.activeTab == DiffTab { w.diff.ToggleLayout() } }
+
+// ToggleDiffCollapsed collapses or expands the current file in the diff pane
+func (w *TabbedWindow) ToggleDiffCollapsed() { if w.activeTab == DiffTab { w.diff.ToggleCollapsed() } }
+
+// ToggleDiffViewed marks or unmarks the current file in the diff pane as viewed
+func (w *TabbedWindow) ToggleDiffViewed() { if w.activeTab == DiffTab { w.diff.ToggleViewed() } }
+
+// ToggleDiffApproved marks or unmarks the selected hunk as approved. It returns false if no hunk
+// is selected.
+func (w *TabbedWindow) ToggleDiffApproved() bool {
+	return w.activeTab == DiffTab && w.diff.ToggleApproved()
+}
+
+// DiffRevertBlocked returns true if the diff tab is active but shows a diff whose hunks can't be
+// reverted or approved, e.g. of a single commit.
+func (w *TabbedWindow) DiffRevertBlocked() bool {
+	return w.activeTab == DiffTab && !w.diff.Revertible()
+}
+
+// SelectedDiffHunk returns a patch of the hunk selected in the diff pane.
+func (w *TabbedWindow) SelectedDiffHunk() (string, bool) {
+	if w.activeTab != DiffTab {
+		return "", false
+	}
+	return w.diff.SelectedHunkPatch()
+}
+
+// SelectedDiffFile returns the path and patch of the file selected in the diff pane.
+func (w *TabbedWindow) SelectedDiffFile() (string, string, bool) {
+	if w.activeTab != DiffTab {
+		return "", "", false
+	}
+	return w.diff.SelectedFilePatch()
+}
+
+// SelectedDiffLine returns an empty comment on the line selected in the diff pane.
+func (w *TabbedWindow) SelectedDiffLine() (session.ReviewComment, bool) {
+	if w.activeTab != DiffTab {
+		return session.ReviewComment{}, false
+	}
+	return w.diff.SelectedLine()
+}
+
+// AddDiffComment adds a review comment to the diff pane's instance.
+func (w *TabbedWindow) AddDiffComment(c session.ReviewComment) { w.diff.AddComment(c) }
+
 // IsInDiffTab returns true if the diff tab is currently active
 func (w *TabbedWindow) IsInDiffTab() bool {
-	return w.activeTab == 1
+	return w.activeTab == DiffTab
+}
+
+// IsInCommitsTab returns true if the commits tab is currently active
+func (w *TabbedWindow) IsInCommitsTab() bool {
+	return w.activeTab == CommitsTab
+}
+
+// SetCommits updates the commits tab. err is shown instead of the list.
+func (w *TabbedWindow) SetCommits(instance *session.Instance, commits []git.Commit, err error) {
+	w.commits.SetCommits(instance, commits, err)
+}
+
+// SelectedCommit returns the commit selected in the commits tab.
+func (w *TabbedWindow) SelectedCommit() (git.Commit, bool) {
+	if w.activeTab != CommitsTab {
+		return git.Commit{}, false
+	}
+	return w.commits.Selected()
+}
+
+// PendingCommitDiff returns the selected commit if its diff needs to be loaded.
+func (w *TabbedWindow) PendingCommitDiff() (string, bool) {
+	if w.activeTab != CommitsTab {
+		return "", false
+	}
+	return w.commits.PendingDiff()
+}
+
+// SetCommitDiff shows the diff of a commit in the commits tab.
+func (w *TabbedWindow) SetCommitDiff(sha string, stats *git.DiffStats) {
+	w.commits.SetCommitDiff(sha, stats)
 }
 
 // GetActiveTab returns the currently active tab index.
@@ -245,9 +413,20 @@ func (w *TabbedWindow) GetActiveTab() int {
 	return w.activeTab
 }
 
-// IsPreviewInScrollMode returns true if the preview pane is in scroll mode
+// IsInShellTab returns true if the shell tab is currently active
+func (w *TabbedWindow) IsInShellTab() bool {
+	return w.activeTab == ShellTab
+}
+
+// IsInChecksTab returns true if the checks tab is currently active
+func (w *TabbedWindow) IsInChecksTab() bool {
+	return w.activeTab == ChecksTab
+}
+
+// IsPreviewInScrollMode returns true if the active preview or shell pane is in scroll mode
 func (w *TabbedWindow) IsPreviewInScrollMode() bool {
-	return w.preview.isScrolling
+	pane := w.terminalPane()
+	return pane != nil && pane.isScrolling
 }
 
 func (w *TabbedWindow) String() string {
@@ -293,10 +472,17 @@ func (w *TabbedWindow) String() string {
 
 	row := lipgloss.JoinHorizontal(lipgloss.Top, renderedTabs...)
 	var content string
-	if w.activeTab == 0 {
+	switch w.activeTab {
+	case PreviewTab:
 		content = w.preview.String()
-	} else {
+	case DiffTab:
 		content = w.diff.String()
+	case CommitsTab:
+		content = w.commits.String()
+	case ShellTab:
+		content = w.shell.String()
+	case ChecksTab:
+		content = w.checks.String()
 	}
     hAvail := w.height - 2 - windowStyle.GetVerticalFrameSize() - tabHeight
     if hAvail < 1 { hAvail = 1 }
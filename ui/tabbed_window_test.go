@@ -6,7 +6,7 @@ import (
 )
 
 func TestGetActiveTabAndToggle(t *testing.T) {
-	tw := NewTabbedWindow(NewPreviewPane(), NewDiffPane())
+	tw := NewTabbedWindow(NewPreviewPane(), NewDiffPane(), NewCommitsPane(), NewShellPane(), NewChecksPane())
 	// Default should be PreviewTab
 	require.Equal(t, PreviewTab, tw.GetActiveTab())
 	require.False(t, tw.IsInDiffTab())
@@ -16,6 +16,24 @@ func TestGetActiveTabAndToggle(t *testing.T) {
 	require.Equal(t, DiffTab, tw.GetActiveTab())
 	require.True(t, tw.IsInDiffTab())
 
+	// Toggle to Commits tab
+	tw.Toggle()
+	require.Equal(t, CommitsTab, tw.GetActiveTab())
+	require.True(t, tw.IsInCommitsTab())
+	require.False(t, tw.IsInDiffTab())
+
+	// Toggle to Shell tab
+	tw.Toggle()
+	require.Equal(t, ShellTab, tw.GetActiveTab())
+	require.True(t, tw.IsInShellTab())
+	require.False(t, tw.IsInCommitsTab())
+
+	// Toggle to Checks tab
+	tw.Toggle()
+	require.Equal(t, ChecksTab, tw.GetActiveTab())
+	require.True(t, tw.IsInChecksTab())
+	require.False(t, tw.IsInShellTab())
+
 	// Toggle back to Preview tab
 	tw.Toggle()
 	require.Equal(t, PreviewTab, tw.GetActiveTab())
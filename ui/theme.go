@@ -1,6 +1,14 @@
 package ui
 
-import "github.com/charmbracelet/lipgloss"
+import (
+    "fmt"
+    "os"
+    "regexp"
+    "sort"
+    "strconv"
+
+    "github.com/charmbracelet/lipgloss"
+)
 
 // Palette centralizes adaptive colors for the TUI
 type Palette struct {
@@ -13,19 +21,162 @@ type Palette struct {
     Warn      lipgloss.AdaptiveColor
     Danger    lipgloss.AdaptiveColor
     Hint      lipgloss.AdaptiveColor
+    // DiffAddBg and DiffDelBg tint the background of added and removed lines in the diff, so the
+    // change stays visible when the text is syntax highlighted.
+    DiffAddBg lipgloss.AdaptiveColor
+    DiffDelBg lipgloss.AdaptiveColor
+    // Reverse marks selections with reverse video instead of a background color. Palettes without
+    // colors need it for the selection to be visible.
+    Reverse bool
+}
+
+const (
+    ThemeDefault      = "default"
+    ThemeHighContrast = "high-contrast"
+    ThemeNoColor      = "no-color"
+)
+
+// Themes holds the built-in palettes by name.
+var Themes = map[string]Palette{
+    ThemeDefault: {
+        Fg:        lipgloss.AdaptiveColor{Light: "#1a1a1a", Dark: "#e5e5e5"},
+        FgMuted:   lipgloss.AdaptiveColor{Light: "#6b7280", Dark: "#9ca3af"},
+        BgAlt:     lipgloss.AdaptiveColor{Light: "#f3f4f6", Dark: "#1f2937"},
+        Accent:    lipgloss.AdaptiveColor{Light: "#6e79d8", Dark: "#8ea2ff"},
+        AccentAlt: lipgloss.AdaptiveColor{Light: "#a78bfa", Dark: "#b79bff"},
+        Ok:        lipgloss.AdaptiveColor{Light: "#22c55e", Dark: "#22c55e"},
+        Warn:      lipgloss.AdaptiveColor{Light: "#f59e0b", Dark: "#fbbf24"},
+        Danger:    lipgloss.AdaptiveColor{Light: "#ef4444", Dark: "#ef4444"},
+        Hint:      lipgloss.AdaptiveColor{Light: "#6b7280", Dark: "#9ca3af"},
+        DiffAddBg: lipgloss.AdaptiveColor{Light: "#e6ffec", Dark: "#12261e"},
+        DiffDelBg: lipgloss.AdaptiveColor{Light: "#ffebe9", Dark: "#2d1517"},
+    },
+    // ThemeHighContrast uses pure black/white text and saturated accents, and marks selections
+    // with reverse video.
+    ThemeHighContrast: {
+        Fg:        lipgloss.AdaptiveColor{Light: "#000000", Dark: "#ffffff"},
+        FgMuted:   lipgloss.AdaptiveColor{Light: "#000000", Dark: "#ffffff"},
+        BgAlt:     lipgloss.AdaptiveColor{Light: "#ffffff", Dark: "#000000"},
+        Accent:    lipgloss.AdaptiveColor{Light: "#0000ee", Dark: "#00ffff"},
+        AccentAlt: lipgloss.AdaptiveColor{Light: "#8b008b", Dark: "#ff00ff"},
+        Ok:        lipgloss.AdaptiveColor{Light: "#006400", Dark: "#00ff00"},
+        Warn:      lipgloss.AdaptiveColor{Light: "#8b4500", Dark: "#ffff00"},
+        Danger:    lipgloss.AdaptiveColor{Light: "#b00000", Dark: "#ff5f5f"},
+        Hint:      lipgloss.AdaptiveColor{Light: "#000000", Dark: "#ffffff"},
+        DiffAddBg: lipgloss.AdaptiveColor{Light: "#ccffcc", Dark: "#003300"},
+        DiffDelBg: lipgloss.AdaptiveColor{Light: "#ffcccc", Dark: "#3d0000"},
+        Reverse:   true,
+    },
+    // ThemeNoColor emits no colors at all. Text attributes like bold are kept.
+    ThemeNoColor: {Reverse: true},
+}
+
+// Theme holds the shared color palette. Use SetTheme to change it.
+var Theme = Themes[ThemeDefault]
+
+var themeListeners []func()
+
+// OnThemeChange registers f to rebuild styles derived from Theme. f is called immediately and
+// again after every SetTheme.
+func OnThemeChange(f func()) {
+    themeListeners = append(themeListeners, f)
+    f()
+}
+
+// SetTheme switches the palette and restyles every registered component.
+func SetTheme(p Palette) {
+    Theme = p
+    for _, f := range themeListeners {
+        f()
+    }
 }
 
-// Theme holds the shared color palette
-var Theme = Palette{
-    Fg:        lipgloss.AdaptiveColor{Light: "#1a1a1a", Dark: "#e5e5e5"},
-    FgMuted:   lipgloss.AdaptiveColor{Light: "#6b7280", Dark: "#9ca3af"},
-    BgAlt:     lipgloss.AdaptiveColor{Light: "#f3f4f6", Dark: "#1f2937"},
-    Accent:    lipgloss.AdaptiveColor{Light: "#6e79d8", Dark: "#8ea2ff"},
-    AccentAlt: lipgloss.AdaptiveColor{Light: "#a78bfa", Dark: "#b79bff"},
-    Ok:        lipgloss.AdaptiveColor{Light: "#22c55e", Dark: "#22c55e"},
-    Warn:      lipgloss.AdaptiveColor{Light: "#f59e0b", Dark: "#fbbf24"},
-    Danger:    lipgloss.AdaptiveColor{Light: "#ef4444", Dark: "#ef4444"},
-    Hint:      lipgloss.AdaptiveColor{Light: "#6b7280", Dark: "#9ca3af"},
+// ThemeNames returns the built-in theme names followed by the custom ones, sorted.
+func ThemeNames(custom map[string]map[string]string) []string {
+    names := []string{ThemeDefault, ThemeHighContrast, ThemeNoColor}
+    var extra []string
+    for name := range custom {
+        if _, builtin := Themes[name]; !builtin {
+            extra = append(extra, name)
+        }
+    }
+    sort.Strings(extra)
+    return append(names, extra...)
+}
+
+// ResolveTheme returns the palette named name, looking at custom palettes first. An empty name
+// selects the default theme, unless the NO_COLOR environment variable is set.
+func ResolveTheme(name string, custom map[string]map[string]string) (Palette, error) {
+    if name == "" {
+        name = ThemeDefault
+        if os.Getenv("NO_COLOR") != "" {
+            name = ThemeNoColor
+        }
+    }
+    if colors, ok := custom[name]; ok {
+        return parsePalette(colors, custom, map[string]bool{name: true})
+    }
+    if p, ok := Themes[name]; ok {
+        return p, nil
+    }
+    return Palette{}, fmt.Errorf("unknown theme %q", name)
+}
+
+var hexColor = regexp.MustCompile(`^#([0-9a-fA-F]{3}|[0-9a-fA-F]{6})$`)
+
+// ParsePalette builds a palette from a user-defined color map. Keys are the palette roles (fg,
+// fg_muted, bg_alt, accent, accent_alt, ok, warn, danger, hint, diff_add_bg, diff_del_bg); values
+// are hex colors or ANSI color numbers. The optional "base" key names the theme that unset roles
+// are taken from: a custom theme, or a built-in one if there is no custom theme by that name or
+// the custom one is already being resolved (so a custom "dark" can be based on the built-in one).
+func ParsePalette(colors map[string]string, custom map[string]map[string]string) (Palette, error) {
+    return parsePalette(colors, custom, map[string]bool{})
+}
+
+// parsePalette is ParsePalette. seen holds the custom themes whose base is being resolved.
+func parsePalette(colors map[string]string, custom map[string]map[string]string, seen map[string]bool) (Palette, error) {
+    base := colors["base"]
+    if base == "" {
+        base = ThemeDefault
+    }
+    var p Palette
+    if baseColors, ok := custom[base]; ok && !seen[base] {
+        seen[base] = true
+        var err error
+        if p, err = parsePalette(baseColors, custom, seen); err != nil {
+            return Palette{}, fmt.Errorf("base theme %q: %w", base, err)
+        }
+    } else if p, ok = Themes[base]; !ok {
+        return Palette{}, fmt.Errorf("unknown base theme %q", base)
+    }
+
+    roles := map[string]*lipgloss.AdaptiveColor{
+        "fg":          &p.Fg,
+        "fg_muted":    &p.FgMuted,
+        "bg_alt":      &p.BgAlt,
+        "accent":      &p.Accent,
+        "accent_alt":  &p.AccentAlt,
+        "ok":          &p.Ok,
+        "warn":        &p.Warn,
+        "danger":      &p.Danger,
+        "hint":        &p.Hint,
+        "diff_add_bg": &p.DiffAddBg,
+        "diff_del_bg": &p.DiffDelBg,
+    }
+    for role, value := range colors {
+        if role == "base" {
+            continue
+        }
+        dst, ok := roles[role]
+        if !ok {
+            return Palette{}, fmt.Errorf("unknown theme color %q", role)
+        }
+        if n, err := strconv.Atoi(value); !hexColor.MatchString(value) && (err != nil || n < 0 || n > 255) {
+            return Palette{}, fmt.Errorf("invalid color %q for %s", value, role)
+        }
+        *dst = lipgloss.AdaptiveColor{Light: value, Dark: value}
+    }
+    return p, nil
 }
 
 // Style helpers
@@ -45,3 +196,7 @@ func StyleBadge() lipgloss.Style {
     return lipgloss.NewStyle().Foreground(Theme.Fg).Background(Theme.BgAlt).Padding(0, 1).Bold(true)
 }
 
+// StyleSelected returns the style for the selected row of a list.
+func StyleSelected() lipgloss.Style {
+    return lipgloss.NewStyle().Background(Theme.BgAlt).Foreground(Theme.Fg).Reverse(Theme.Reverse)
+}
@@ -0,0 +1,93 @@
+package ui
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestResolveTheme(t *testing.T) {
+	t.Setenv("NO_COLOR", "")
+	p, err := ResolveTheme("", nil)
+	require.NoError(t, err)
+	assert.Equal(t, Themes[ThemeDefault], p)
+
+	t.Setenv("NO_COLOR", "1")
+	p, err = ResolveTheme("", nil)
+	require.NoError(t, err)
+	assert.Equal(t, Themes[ThemeNoColor], p)
+
+	// An explicitly configured theme wins over NO_COLOR.
+	p, err = ResolveTheme(ThemeHighContrast, nil)
+	require.NoError(t, err)
+	assert.Equal(t, Themes[ThemeHighContrast], p)
+
+	_, err = ResolveTheme("missing", nil)
+	assert.Error(t, err)
+}
+
+func TestResolveCustomTheme(t *testing.T) {
+	custom := map[string]map[string]string{
+		"solar":  {"base": ThemeHighContrast, "accent": "#b58900", "ok": "2"},
+		"broken": {"accent": "orange"},
+		"typo":   {"acent": "#fff"},
+	}
+
+	p, err := ResolveTheme("solar", custom)
+	require.NoError(t, err)
+	assert.Equal(t, "#b58900", p.Accent.Dark)
+	assert.Equal(t, "2", p.Ok.Light)
+	// Unset roles come from the base theme.
+	assert.Equal(t, Themes[ThemeHighContrast].Fg, p.Fg)
+	assert.True(t, p.Reverse)
+
+	_, err = ResolveTheme("broken", custom)
+	assert.ErrorContains(t, err, "invalid color")
+	_, err = ResolveTheme("typo", custom)
+	assert.ErrorContains(t, err, "unknown theme color")
+
+	assert.Equal(t, []string{ThemeDefault, ThemeHighContrast, ThemeNoColor, "broken", "solar", "typo"}, ThemeNames(custom))
+}
+
+func TestResolveCustomBaseTheme(t *testing.T) {
+	custom := map[string]map[string]string{
+		"solar":       {"base": ThemeHighContrast, "accent": "#b58900"},
+		"solar-night": {"base": "solar", "ok": "2"},
+		// A custom theme named like a built-in one is based on the built-in one.
+		ThemeDefault: {"base": ThemeDefault, "accent": "#fff"},
+		"loop-a":     {"base": "loop-b"},
+		"loop-b":     {"base": "loop-a"},
+		"bad-base":   {"base": "broken"},
+		"broken":     {"accent": "orange"},
+	}
+
+	p, err := ResolveTheme("solar-night", custom)
+	require.NoError(t, err)
+	assert.Equal(t, "#b58900", p.Accent.Dark)
+	assert.Equal(t, "2", p.Ok.Dark)
+	assert.Equal(t, Themes[ThemeHighContrast].Fg, p.Fg)
+
+	p, err = ResolveTheme(ThemeDefault, custom)
+	require.NoError(t, err)
+	assert.Equal(t, "#fff", p.Accent.Dark)
+	assert.Equal(t, Themes[ThemeDefault].Fg, p.Fg)
+
+	_, err = ResolveTheme("loop-a", custom)
+	assert.ErrorContains(t, err, `unknown base theme "loop-a"`)
+	_, err = ResolveTheme("bad-base", custom)
+	assert.ErrorContains(t, err, `base theme "broken": invalid color`)
+}
+
+func TestSetThemeRestyles(t *testing.T) {
+	t.Cleanup(func() { SetTheme(Themes[ThemeDefault]) })
+
+	SetTheme(Themes[ThemeHighContrast])
+	assert.Equal(t, Themes[ThemeHighContrast].Ok, AdditionStyle.GetForeground())
+	assert.Equal(t, Themes[ThemeHighContrast].Accent, inactiveTabStyle.GetBorderTopForeground())
+	assert.True(t, selectedTitleStyle.GetReverse())
+
+	SetTheme(Themes[ThemeDefault])
+	assert.Equal(t, Themes[ThemeDefault].Ok, AdditionStyle.GetForeground())
+	assert.False(t, selectedTitleStyle.GetReverse())
+}